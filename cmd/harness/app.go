@@ -21,6 +21,7 @@ import (
 type App struct {
 	BinaryPath string // Path to the app executable
 	Port       int    // Port to pass as a command line argument.
+	CoverDir   string // Directory of the coverage data, for a binary built with -cover.
 	cmd        AppCmd // The last cmd returned.
 }
 
@@ -32,6 +33,9 @@ func NewApp(binPath string) *App {
 // Cmd returns a command to run the app server using the current configuration.
 func (a *App) Cmd() AppCmd {
 	a.cmd = NewAppCmd(a.BinaryPath, a.Port)
+	if a.CoverDir != "" {
+		a.cmd.Env = append(os.Environ(), "GOCOVERDIR="+a.CoverDir)
+	}
 	return a.cmd
 }
 
@@ -52,6 +56,12 @@ func NewAppCmd(binPath string, port int) AppCmd {
 		fmt.Sprintf("-port=%d", port),
 		fmt.Sprintf("-importPath=%s", revel.ImportPath),
 		fmt.Sprintf("-runMode=%s", revel.RunMode))
+	// Serve https as the harness does, app.conf may be overridden.
+	if revel.HTTPSsl {
+		cmd.Args = append(cmd.Args,
+			fmt.Sprintf("-sslCert=%s", revel.HTTPSslCert),
+			fmt.Sprintf("-sslKey=%s", revel.HTTPSslKey))
+	}
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	return AppCmd{cmd}
 }
@@ -42,15 +42,23 @@ func Build(buildFlags ...string) (app *App, compileError *revel.Error) {
 		sourceInfo.InitImportPaths = append(sourceInfo.InitImportPaths, dbImportPath)
 	}
 
+	// The reverse routes of the actions routed to.
+	routesArgs, compileError := reverseRoutes(sourceInfo)
+	if compileError != nil {
+		return nil, compileError
+	}
+
 	// Generate two source files.
 	templateArgs := map[string]interface{}{
 		"Controllers":    sourceInfo.ControllerSpecs(),
 		"ValidationKeys": sourceInfo.ValidationKeys,
 		"ImportPaths":    calcImportAliases(sourceInfo),
 		"TestSuites":     sourceInfo.TestSuites(),
+		"ORM":            usesORM(sourceInfo),
 	}
 	genSource("tmp", "main.go", RevelMainTemplate, templateArgs)
-	genSource("routes", "routes.go", RevelRoutesTemplate, templateArgs)
+	genSource("routes", "routes.go", RevelRoutesTemplate, routesArgs)
+	genModels(sourceInfo)
 
 	// Read build config.
 	buildTags := revel.Config.StringDefault("build.tags", "")
@@ -84,6 +92,10 @@ func Build(buildFlags ...string) (app *App, compileError *revel.Error) {
 		appVersion := getAppVersion()
 
 		buildTime := time.Now().UTC().Format(time.RFC3339)
+		// A reproducible build has the time of its sources.
+		if epoch, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64); err == nil {
+			buildTime = time.Unix(epoch, 0).UTC().Format(time.RFC3339)
+		}
 		versionLinkerFlags := fmt.Sprintf("-X %s/app.AppVersion=%s -X %s/app.BuildTime=%s",
 			revel.ImportPath, appVersion, revel.ImportPath, buildTime)
 
@@ -148,6 +160,24 @@ func Build(buildFlags ...string) (app *App, compileError *revel.Error) {
 	return nil, nil
 }
 
+// BuildWithCoverage builds the app like Build, with the packages of the app
+// instrumented for coverage and the revelcover build tag, which has the
+// testrunner module write the coverage data, to coverDir.  It needs Go 1.20
+// or later.
+func BuildWithCoverage(coverDir string, buildFlags ...string) (app *App, compileError *revel.Error) {
+	// The last -tags flag wins, so the tags of build.tags are repeated.
+	buildTags := strings.Fields(strings.Replace(revel.Config.StringDefault("build.tags", ""), ",", " ", -1))
+	coverFlags := []string{
+		"-cover", "-coverpkg=" + path.Join(revel.ImportPath, "app", "..."),
+		"-tags", strings.Join(append(buildTags, "revelcover"), ","),
+	}
+	app, compileError = Build(append(coverFlags, buildFlags...)...)
+	if compileError == nil {
+		app.CoverDir = coverDir
+	}
+	return
+}
+
 // Try to define a version string for the compiled app
 // The following is tried (first match returns):
 // - Read a version explicitly specified in the APP_VERSION environment
@@ -228,12 +258,50 @@ func cleanDir(dir string) {
 // genSource renders the given template to produce source code, which it writes
 // to the given directory and file.
 func genSource(dir, filename, templateSource string, args map[string]interface{}) {
+	// Create a fresh dir.
+	cleanSource(dir)
+	writeSource(dir, filename, templateSource, args)
+}
+
+// genModels writes app/tmp/models.go, registering the models of the app
+// the app doesn't register itself, next to the main.go generated first.
+// Apps registering all their models set orm.models.register to false.
+func genModels(sourceInfo *SourceInfo) {
+	if !revel.Config.BoolDefault("orm.models.register", true) {
+		return
+	}
+	models := sourceInfo.ModelSpecs()
+	if len(models) == 0 {
+		return
+	}
+	aliases := make(map[string]string)
+	for _, spec := range models {
+		addAlias(aliases, spec.ImportPath, spec.PackageName)
+	}
+	writeSource("tmp", "models.go", RevelModelsTemplate, map[string]interface{}{
+		"Models":      models,
+		"ImportPaths": aliases,
+	})
+}
+
+// usesORM reports whether the app uses the ORM, setting db.import or
+// having models, for the generated main to take the -seed and -migrate
+// flags.
+func usesORM(sourceInfo *SourceInfo) bool {
+	if _, found := revel.Config.String("db.import"); found {
+		return true
+	}
+	return len(sourceInfo.ModelSpecs()) > 0
+}
+
+// writeSource renders the given template to produce source code, which it
+// writes to the given directory and file, keeping the other files of the
+// directory.
+func writeSource(dir, filename, templateSource string, args map[string]interface{}) {
 	sourceCode := revel.ExecuteTemplate(
 		template.Must(template.New("").Parse(templateSource)),
 		args)
 
-	// Create a fresh dir.
-	cleanSource(dir)
 	tmpPath := filepath.Join(revel.AppPath, dir)
 	err := os.Mkdir(tmpPath, 0777)
 	if err != nil && !os.IsExist(err) {
@@ -314,69 +382,17 @@ func containsValue(m map[string]string, val string) bool {
 	return false
 }
 
-// Parse the output of the "go build" command.
-// Return a detailed Error.
-func newCompileError(output []byte) *revel.Error {
-	errorMatch := regexp.MustCompile(`(?m)^([^:#]+):(\d+):(\d+:)? (.*)$`).
-		FindSubmatch(output)
-	if errorMatch == nil {
-		errorMatch = regexp.MustCompile(`(?m)^(.*?)\:(\d+)\:\s(.*?)$`).FindSubmatch(output)
-
-		if errorMatch == nil {
-			revel.ERROR.Println("Failed to parse build errors:\n", string(output))
-			return &revel.Error{
-				SourceType:  "Go code",
-				Title:       "Go Compilation Error",
-				Description: "See console for build error.",
-			}
-		}
-
-		errorMatch = append(errorMatch, errorMatch[3])
-
-		revel.ERROR.Println("Build errors:\n", string(output))
-	}
-
-	// Read the source for the offending file.
-	var (
-		relFilename    = string(errorMatch[1]) // e.g. "src/revel/sample/app/controllers/app.go"
-		absFilename, _ = filepath.Abs(relFilename)
-		line, _        = strconv.Atoi(string(errorMatch[2]))
-		description    = string(errorMatch[4])
-		compileError   = &revel.Error{
-			SourceType:  "Go code",
-			Title:       "Go Compilation Error",
-			Path:        relFilename,
-			Description: description,
-			Line:        line,
-		}
-	)
-
-	errorLink := revel.Config.StringDefault("error.link", "")
-
-	if errorLink != "" {
-		compileError.SetLink(errorLink)
-	}
-
-	fileStr, err := revel.ReadLines(absFilename)
-	if err != nil {
-		compileError.MetaError = absFilename + ": " + err.Error()
-		revel.ERROR.Println(compileError.MetaError)
-		return compileError
-	}
-
-	compileError.SourceLines = fileStr
-	return compileError
-}
-
 // RevelMainTemplate template for app/tmp/main.go
 const RevelMainTemplate = `// GENERATED CODE - DO NOT EDIT
 package main
 
 import (
-	"flag"
+	"flag"{{if .ORM}}
+	"os"{{end}}
 	"reflect"
 	"github.com/dancewing/revel"{{range $k, $v := $.ImportPaths}}
-	{{$v}} "{{$k}}"{{end}}
+	{{$v}} "{{$k}}"{{end}}{{if .ORM}}
+	revel_orm "github.com/dancewing/revel/orm"{{end}}
 	"github.com/dancewing/revel/testing"
 )
 
@@ -384,7 +400,11 @@ var (
 	runMode    *string = flag.String("runMode", "", "Run mode.")
 	port       *int    = flag.Int("port", 0, "By default, read from app.conf")
 	importPath *string = flag.String("importPath", "", "Go Import Path for the app.")
-	srcPath    *string = flag.String("srcPath", "", "Path to the source root.")
+	srcPath    *string = flag.String("srcPath", "", "Path to the source root."){{if .ORM}}
+	seed       *bool   = flag.Bool("seed", false, "Run the ORM seeds and exit.")
+	migrate    *string = flag.String("migrate", "", "Run the ORM migrations command, up, down, redo or status, and exit."){{end}}
+	sslCert    *string = flag.String("sslCert", "", "Path to the certificate to serve https. By default, read from app.conf")
+	sslKey     *string = flag.String("sslKey", "", "Path to the certificate key to serve https.")
 
 	// So compiler won't complain if the generated code doesn't reference reflect package...
 	_ = reflect.Invalid
@@ -393,6 +413,10 @@ var (
 func main() {
 	flag.Parse()
 	revel.Init(*runMode, *importPath, *srcPath)
+	if *sslCert != "" {
+		revel.HTTPSsl, revel.HTTPSslCert, revel.HTTPSslKey = true, *sslCert, *sslKey
+		revel.CookieSecure = revel.Config.BoolDefault("cookie.secure", true)
+	}
 	revel.INFO.Println("Running revel server")
 	{{range $i, $c := .Controllers}}
 	revel.RegisterController((*{{index $.ImportPaths .ImportPath}}.{{.StructName}})(nil),
@@ -419,24 +443,67 @@ func main() {
 	testing.TestSuites = []interface{}{ {{range .TestSuites}}
 		(*{{index $.ImportPaths .ImportPath}}.{{.StructName}})(nil),{{end}}
 	}
-
+	{{if .ORM}}
+	if *seed {
+		revel.InitServer()
+		if err := revel_orm.RunSeeds(); err != nil {
+			revel.ERROR.Fatalln(err)
+		}
+		return
+	}
+	if *migrate != "" {
+		revel.InitServer()
+		if err := revel_orm.Migrate(*migrate, os.Stdout); err != nil {
+			revel.ERROR.Fatalln(err)
+		}
+		return
+	}
+	{{end}}
 	revel.Run(*port)
 }
 `
 
+// RevelModelsTemplate template for app/tmp/models.go
+const RevelModelsTemplate = `// GENERATED CODE - DO NOT EDIT
+package main
+
+import (
+	"github.com/dancewing/revel"{{range $k, $v := $.ImportPaths}}
+	{{$v}} "{{$k}}"{{end}}
+	revel_orm "github.com/dancewing/revel/orm"
+)
+
+func init() {
+	// After the startup hooks of the app and of its modules, of the default
+	// order 1, which set the database and the ORM settings, and may register
+	// the models themselves.
+	revel.OnAppStart(func() {
+		{{range .Models}}revel_orm.RegisterModelIfAbsent(new({{index $.ImportPaths .ImportPath}}.{{.StructName}}))
+		{{end}}revel_orm.BootStrap()
+	}, modelsStartupOrder)
+}
+
+// modelsStartupOrder is the order of the startup hook bootstrapping the
+// models, after those of the default order.
+const modelsStartupOrder = 100
+`
+
 // RevelRoutesTemplate template for app/conf/routes
 const RevelRoutesTemplate = `// GENERATED CODE - DO NOT EDIT
 package routes
 
-import "github.com/dancewing/revel"
+import (
+	"github.com/dancewing/revel"{{range $k, $v := $.ImportPaths}}
+	{{$v}} "{{$k}}"{{end}}
+)
 
 {{range $i, $c := .Controllers}}
 type t{{.StructName}} struct {}
 var {{.StructName}} t{{.StructName}}
 
-{{range .MethodSpecs}}
+{{range .Actions}}
 func (_ t{{$c.StructName}}) {{.Name}}({{range .Args}}
-		{{.Name}} {{if .ImportPath}}interface{}{{else}}{{.TypeExpr.TypeName ""}}{{end}},{{end}}
+		{{.Name}} {{.Type}},{{end}}
 		) string {
 	args := make(map[string]string)
 	{{range .Args}}
@@ -0,0 +1,89 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package harness
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/dancewing/revel"
+)
+
+var (
+	// e.g. "app/controllers/app.go:12:5: undefined: x", the column optional
+	compileErrorPattern = regexp.MustCompile(`(?m)^([^:#\n]+):(\d+):(?:(\d+):)? (.*)$`)
+
+	// Also matches the paths with a volume name, e.g. "C:\app\app.go:12: ..."
+	compileErrorFallbackPattern = regexp.MustCompile(`(?m)^(.*?)\:(\d+)\:\s(.*?)$`)
+)
+
+// Parse the output of the "go build" command.
+// Return a detailed Error, of the first error reported, with the others
+// as its Related errors.
+func newCompileError(output []byte) *revel.Error {
+	errs := parseCompileErrors(output)
+	if len(errs) == 0 {
+		revel.ERROR.Println("Failed to parse build errors:\n", string(output))
+		return &revel.Error{
+			SourceType:  "Go code",
+			Title:       "Go Compilation Error",
+			Description: "See console for build error.",
+		}
+	}
+
+	// Read the source of the offending files.
+	errorLink := revel.Config.StringDefault("error.link", "")
+	sources := make(map[string][]string)
+	for _, compileError := range errs {
+		if errorLink != "" {
+			compileError.SetLink(errorLink)
+		}
+
+		absFilename, _ := filepath.Abs(compileError.Path)
+		fileStr, ok := sources[absFilename]
+		if !ok {
+			var err error
+			if fileStr, err = revel.ReadLines(absFilename); err != nil {
+				compileError.MetaError = absFilename + ": " + err.Error()
+				revel.ERROR.Println(compileError.MetaError)
+			}
+			sources[absFilename] = fileStr
+		}
+		compileError.SourceLines = fileStr
+	}
+
+	errs[0].Related = errs[1:]
+	return errs[0]
+}
+
+// parseCompileErrors returns the errors of the output of the "go build"
+// command, in the order reported, without their source.
+func parseCompileErrors(output []byte) []*revel.Error {
+	matches := compileErrorPattern.FindAllSubmatch(output, -1)
+	if matches == nil {
+		for _, match := range compileErrorFallbackPattern.FindAllSubmatch(output, -1) {
+			matches = append(matches, [][]byte{match[0], match[1], match[2], nil, match[3]})
+		}
+		if matches != nil {
+			revel.ERROR.Println("Build errors:\n", string(output))
+		}
+	}
+
+	errs := make([]*revel.Error, 0, len(matches))
+	for _, match := range matches {
+		line, _ := strconv.Atoi(string(match[2]))
+		column, _ := strconv.Atoi(string(match[3]))
+		errs = append(errs, &revel.Error{
+			SourceType:  "Go code",
+			Title:       "Go Compilation Error",
+			Path:        string(match[1]), // e.g. "src/revel/sample/app/controllers/app.go"
+			Description: string(match[4]),
+			Line:        line,
+			Column:      column,
+		})
+	}
+	return errs
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package harness
+
+import (
+	"testing"
+)
+
+func TestParseCompileErrors(t *testing.T) {
+	output := []byte(`# github.com/myapp/app/controllers
+app/controllers/app.go:12:5: undefined: x
+app/controllers/app.go:20:2: missing return at end of function
+app/models/post.go:7: syntax error: unexpected newline
+`)
+	errs := parseCompileErrors(output)
+	expected := []struct {
+		path, description string
+		line, column      int
+	}{
+		{"app/controllers/app.go", "undefined: x", 12, 5},
+		{"app/controllers/app.go", "missing return at end of function", 20, 2},
+		{"app/models/post.go", "syntax error: unexpected newline", 7, 0},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, e := range expected {
+		if errs[i].Path != e.path || errs[i].Description != e.description ||
+			errs[i].Line != e.line || errs[i].Column != e.column {
+			t.Errorf("error %d: expected %v, got %+v", i, e, errs[i])
+		}
+	}
+
+	errs = parseCompileErrors([]byte(`C:\gopath\src\myapp\app\init.go:3: imported and not used: "fmt"`))
+	if len(errs) != 1 || errs[0].Path != `C:\gopath\src\myapp\app\init.go` || errs[0].Line != 3 {
+		t.Errorf("unexpected errors of a windows path %+v", errs)
+	}
+
+	if errs = parseCompileErrors([]byte("can't load package: no Go files")); len(errs) != 0 {
+		t.Errorf("expected no errors, got %+v", errs)
+	}
+}
@@ -38,15 +38,23 @@ func GenerateSource(buildFlags ...string) (app *App, compileError *revel.Error)
 		sourceInfo.InitImportPaths = append(sourceInfo.InitImportPaths, dbImportPath)
 	}
 
+	// The reverse routes of the actions routed to.
+	routesArgs, compileError := reverseRoutes(sourceInfo)
+	if compileError != nil {
+		return nil, compileError
+	}
+
 	// Generate two source files.
 	templateArgs := map[string]interface{}{
 		"Controllers":    sourceInfo.ControllerSpecs(),
 		"ValidationKeys": sourceInfo.ValidationKeys,
 		"ImportPaths":    calcImportAliases(sourceInfo),
 		"TestSuites":     sourceInfo.TestSuites(),
+		"ORM":            usesORM(sourceInfo),
 	}
 	genSource("tmp", "main.go", RevelMainTemplate, templateArgs)
-	genSource("routes", "routes.go", RevelRoutesTemplate, templateArgs)
+	genSource("routes", "routes.go", RevelRoutesTemplate, routesArgs)
+	genModels(sourceInfo)
 
 	// Read build config.
 	buildTags := revel.Config.StringDefault("build.tags", "")
@@ -25,8 +25,11 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/dancewing/revel"
 )
@@ -40,11 +43,26 @@ var (
 
 // Harness reverse proxies requests to the application server.
 // It builds / runs / rebuilds / restarts the server when code is changed.
+// The server of the previous build finishes the requests proxied to it,
+// for harness.drain.timeout seconds at most, before being killed.
 type Harness struct {
+	scheme, addr string
+	port         int // harness.port, 0 to run each build on a free port
+	drainTimeout time.Duration
+	excludes     []string // patterns of the directory names not watched
+
+	mu       sync.Mutex
+	backend  *backend          // the server the requests are proxied to
+	draining map[*backend]bool // the servers of the previous builds
+}
+
+// backend is a server of a build of the app, with the requests proxied to
+// it.
+type backend struct {
 	app        *App
 	serverHost string
-	port       int
 	proxy      *httputil.ReverseProxy
+	active     int32 // requests and websockets being proxied
 }
 
 func renderError(w http.ResponseWriter, r *http.Request, err error) {
@@ -71,12 +89,24 @@ func (h *Harness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	atomic.CompareAndSwapInt32(&lastRequestHadError, 1, 0)
 
+	h.mu.Lock()
+	b := h.backend
+	if b != nil {
+		atomic.AddInt32(&b.active, 1)
+	}
+	h.mu.Unlock()
+	if b == nil {
+		renderError(w, r, &revel.Error{Title: "App not running"})
+		return
+	}
+	defer atomic.AddInt32(&b.active, -1)
+
 	// Reverse proxy the request.
 	// (Need special code for websockets, courtesy of bradfitz)
 	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
-		proxyWebsocket(w, r, h.serverHost)
+		proxyWebsocket(w, r, b.serverHost)
 	} else {
-		h.proxy.ServeHTTP(w, r)
+		b.proxy.ServeHTTP(w, r)
 	}
 }
 
@@ -103,53 +133,116 @@ func NewHarness() *Harness {
 		addr = "localhost"
 	}
 
-	if port == 0 {
-		port = getFreePort()
+	return &Harness{
+		scheme:       scheme,
+		addr:         addr,
+		port:         port,
+		drainTimeout: time.Duration(revel.Config.IntDefault("harness.drain.timeout", 5)) * time.Second,
+		excludes:     configList("watch.exclude"),
+		draining:     make(map[*backend]bool),
 	}
+}
 
-	serverURL, _ := url.ParseRequestURI(fmt.Sprintf(scheme+"://%s:%d", addr, port))
+// newBackend returns the backend proxying to the server of app on port.
+func (h *Harness) newBackend(app *App, port int) *backend {
+	serverURL, _ := url.ParseRequestURI(fmt.Sprintf(h.scheme+"://%s:%d", h.addr, port))
 
-	harness := &Harness{
-		port:       port,
-		serverHost: serverURL.String()[len(scheme+"://"):],
+	b := &backend{
+		app:        app,
+		serverHost: serverURL.String()[len(h.scheme+"://"):],
 		proxy:      httputil.NewSingleHostReverseProxy(serverURL),
 	}
 
 	if revel.HTTPSsl {
-		harness.proxy.Transport = &http.Transport{
+		b.proxy.Transport = &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		}
 	}
-	return harness
+	return b
 }
 
-// Refresh method rebuilds the Revel application and run it on the given port.
+// Refresh method rebuilds the Revel application and run it, on the
+// harness.port or else on a free port.  The server of the previous build
+// is stopped once the new one is up, unless they share the port.
 func (h *Harness) Refresh() (err *revel.Error) {
-	if h.app != nil {
-		h.app.Kill()
+	h.mu.Lock()
+	old := h.backend
+	h.mu.Unlock()
+
+	// The binary of a running server can't be replaced on Windows.
+	if old != nil && runtime.GOOS == "windows" {
+		h.stop(old)
+		old = nil
 	}
 
 	revel.TRACE.Println("Rebuild")
-	h.app, err = Build()
+	app, err := Build()
 	if err != nil {
 		return
 	}
 
-	h.app.Port = h.port
-	if err2 := h.app.Cmd().Start(); err2 != nil {
+	port := h.port
+	if port == 0 {
+		port = FreePort()
+	} else if old != nil {
+		h.stop(old)
+		old = nil
+	}
+
+	app.Port = port
+	if err2 := app.Cmd().Start(); err2 != nil {
 		return &revel.Error{
 			Title:       "App failed to start up",
 			Description: err2.Error(),
 		}
 	}
 
+	h.mu.Lock()
+	h.backend = h.newBackend(app, port)
+	h.mu.Unlock()
+	if old != nil {
+		go h.stop(old)
+	}
 	return
 }
 
-// WatchDir method returns false to file matches with doNotWatch
+// stop waits for the requests proxied to the server of b to complete, for
+// the drain timeout at most, then kills it.
+func (h *Harness) stop(b *backend) {
+	h.mu.Lock()
+	h.draining[b] = true
+	h.mu.Unlock()
+
+	deadline := time.Now().Add(h.drainTimeout)
+	for atomic.LoadInt32(&b.active) > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if n := atomic.LoadInt32(&b.active); n > 0 {
+		revel.WARN.Printf("Killing the previous app server with %d requests still active", n)
+	}
+	b.app.Kill()
+
+	h.mu.Lock()
+	delete(h.draining, b)
+	if h.backend == b {
+		h.backend = nil
+	}
+	h.mu.Unlock()
+}
+
+// WatchDir method returns false to file matches with doNotWatch, or with
+// the patterns of watch.exclude, e.g. "node_modules" or "*.bak",
 // otheriwse true
 func (h *Harness) WatchDir(info os.FileInfo) bool {
-	return !revel.ContainsString(doNotWatch, info.Name())
+	if revel.ContainsString(doNotWatch, info.Name()) {
+		return false
+	}
+	for _, pattern := range h.excludes {
+		if matched, _ := filepath.Match(pattern, info.Name()); matched {
+			return false
+		}
+	}
+	return true
 }
 
 // WatchFile method returns true given filename HasSuffix of ".go"
@@ -167,6 +260,13 @@ func (h *Harness) Run() {
 		paths = append(paths, gopaths...)
 	}
 	paths = append(paths, revel.CodePaths...)
+	// Additional paths, e.g. of a library shared by several apps.
+	for _, path := range configList("watch.paths") {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(revel.BasePath, path)
+		}
+		paths = append(paths, path)
+	}
 	watcher = revel.NewWatcher()
 	watcher.Listen(h, paths...)
 
@@ -193,14 +293,29 @@ func (h *Harness) Run() {
 	ch := make(chan os.Signal)
 	signal.Notify(ch, os.Interrupt, os.Kill)
 	<-ch
-	if h.app != nil {
-		h.app.Kill()
+	h.mu.Lock()
+	if h.backend != nil {
+		h.backend.app.Kill()
+	}
+	for b := range h.draining {
+		b.app.Kill()
 	}
+	h.mu.Unlock()
 	os.Exit(1)
 }
 
-// Find an unused port
-func getFreePort() (port int) {
+// configList returns the comma separated values of the config key.
+func configList(key string) (values []string) {
+	for _, value := range strings.Split(revel.Config.StringDefault(key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return
+}
+
+// FreePort finds an unused port.
+func FreePort() (port int) {
 	conn, err := net.Listen("tcp", ":0")
 	if err != nil {
 		revel.ERROR.Fatal(err)
@@ -10,12 +10,14 @@ package harness
 import (
 	"go/ast"
 	"go/build"
-	"go/parser"
 	"go/scanner"
 	"go/token"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/dancewing/revel"
@@ -41,6 +43,17 @@ type SourceInfo struct {
 	// controllerSpecs lists type info for all structs found under
 	// app/controllers/... that embed (directly or indirectly) revel.Controller
 	controllerSpecs []*TypeInfo
+	// modelStructs lists type info for all exported structs found under
+	// app/models/...
+	modelStructs []*TypeInfo
+	// registeredModels lists the structs registered by the app itself, with
+	// a call of orm.RegisterModel, e.g. "myapp/app/models.User".
+	registeredModels []string
+	// ormTagged lists the model structs having fields tagged for the ORM.
+	ormTagged []string
+	// typeUses lists the types the app refers to other than by embedding
+	// them, e.g. "myapp/app/models.User" for &models.User{}.
+	typeUses []string
 	// testSuites list the types that constitute the set of application tests.
 	testSuites []*TypeInfo
 }
@@ -89,10 +102,13 @@ type methodMap map[string][]*MethodSpec
 // ProcessSource parses the app controllers directory and
 // returns a list of the controller types found.
 // Otherwise CompileError if the parsing fails.
+// The files unchanged since the previous call aren't parsed again, see
+// sourceCache.
 func ProcessSource(roots []string) (*SourceInfo, *revel.Error) {
 	var (
 		srcInfo      *SourceInfo
 		compileError *revel.Error
+		seen         = make(map[string]bool)
 	)
 
 	for _, root := range roots {
@@ -119,37 +135,25 @@ func ProcessSource(roots []string) (*SourceInfo, *revel.Error) {
 				pkgImportPath = rootImportPath + "/" + filepath.ToSlash(path[len(root)+1:])
 			}
 
-			// Parse files within the path.
-			var pkgs map[string]*ast.Package
-			fset := token.NewFileSet()
-			pkgs, err = parser.ParseDir(fset, path, func(f os.FileInfo) bool {
-				return !f.IsDir() && !strings.HasPrefix(f.Name(), ".") && strings.HasSuffix(f.Name(), ".go")
-			}, 0)
+			// Parse files within the path, or take them from the cache.
+			infos, err := ioutil.ReadDir(path)
 			if err != nil {
-				if errList, ok := err.(scanner.ErrorList); ok {
-					var pos = errList[0].Pos
-					compileError = &revel.Error{
-						SourceType:  ".go source",
-						Title:       "Go Compilation Error",
-						Path:        pos.Filename,
-						Description: errList[0].Msg,
-						Line:        pos.Line,
-						Column:      pos.Column,
-						SourceLines: revel.MustReadLines(pos.Filename),
-					}
-
-					errorLink := revel.Config.StringDefault("error.link", "")
-
-					if errorLink != "" {
-						compileError.SetLink(errorLink)
-					}
-
+				log.Println("Error scanning app source:", err)
+				return nil
+			}
+			pkgs := make(map[string][]*sourceFile)
+			for _, fi := range infos {
+				if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") || !strings.HasSuffix(fi.Name(), ".go") {
+					continue
+				}
+				filename := filepath.Join(path, fi.Name())
+				seen[filename] = true
+				file, err := sourceCache.file(filename, pkgImportPath, fi)
+				if err != nil {
+					compileError = sourceError(err)
 					return compileError
 				}
-
-				// This is exception, err alredy checked above. Here just a print
-				ast.Print(nil, err)
-				log.Fatalf("Failed to parse dir: %s", err)
+				pkgs[file.pkgName] = append(pkgs[file.pkgName], file)
 			}
 
 			// Skip "main" packages.
@@ -165,19 +169,52 @@ func ProcessSource(roots []string) (*SourceInfo, *revel.Error) {
 				log.Println("Most unexpected! Multiple packages in a single directory:", pkgs)
 			}
 
-			var pkg *ast.Package
+			var files []*sourceFile
 			for _, v := range pkgs {
-				pkg = v
+				files = v
 			}
 
-			srcInfo = appendSourceInfo(srcInfo, processPackage(fset, pkgImportPath, path, pkg))
+			srcInfo = appendSourceInfo(srcInfo, processPackage(pkgImportPath, files))
 			return nil
 		})
 	}
 
+	if compileError == nil {
+		sourceCache.prune(seen)
+	}
 	return srcInfo, compileError
 }
 
+// sourceError returns the compile error of a source file failing to
+// parse.
+func sourceError(err error) *revel.Error {
+	errList, ok := err.(scanner.ErrorList)
+	if !ok {
+		// This is exception, err alredy checked above. Here just a print
+		ast.Print(nil, err)
+		log.Fatalf("Failed to parse dir: %s", err)
+	}
+
+	var pos = errList[0].Pos
+	compileError := &revel.Error{
+		SourceType:  ".go source",
+		Title:       "Go Compilation Error",
+		Path:        pos.Filename,
+		Description: errList[0].Msg,
+		Line:        pos.Line,
+		Column:      pos.Column,
+		SourceLines: revel.MustReadLines(pos.Filename),
+	}
+
+	errorLink := revel.Config.StringDefault("error.link", "")
+
+	if errorLink != "" {
+		compileError.SetLink(errorLink)
+	}
+
+	return compileError
+}
+
 func appendSourceInfo(srcInfo1, srcInfo2 *SourceInfo) *SourceInfo {
 	if srcInfo1 == nil {
 		return srcInfo2
@@ -185,6 +222,10 @@ func appendSourceInfo(srcInfo1, srcInfo2 *SourceInfo) *SourceInfo {
 
 	srcInfo1.StructSpecs = append(srcInfo1.StructSpecs, srcInfo2.StructSpecs...)
 	srcInfo1.InitImportPaths = append(srcInfo1.InitImportPaths, srcInfo2.InitImportPaths...)
+	srcInfo1.modelStructs = append(srcInfo1.modelStructs, srcInfo2.modelStructs...)
+	srcInfo1.registeredModels = append(srcInfo1.registeredModels, srcInfo2.registeredModels...)
+	srcInfo1.ormTagged = append(srcInfo1.ormTagged, srcInfo2.ormTagged...)
+	srcInfo1.typeUses = append(srcInfo1.typeUses, srcInfo2.typeUses...)
 	for k, v := range srcInfo2.ValidationKeys {
 		if _, ok := srcInfo1.ValidationKeys[k]; ok {
 			log.Println("Key conflict when scanning validation calls:", k)
@@ -195,64 +236,180 @@ func appendSourceInfo(srcInfo1, srcInfo2 *SourceInfo) *SourceInfo {
 	return srcInfo1
 }
 
-func processPackage(fset *token.FileSet, pkgImportPath, pkgPath string, pkg *ast.Package) *SourceInfo {
+// processPackage combines the files of a package.
+func processPackage(pkgImportPath string, files []*sourceFile) *SourceInfo {
+	var (
+		structSpecs      []*TypeInfo
+		modelStructs     []*TypeInfo
+		registeredModels []string
+		ormTagged        []string
+		typeUses         []string
+		initImportPaths  []string
+
+		methodSpecs    = make(methodMap)
+		validationKeys = make(map[string]map[int]string)
+	)
+
+	for _, file := range files {
+		structSpecs = append(structSpecs, file.structSpecs...)
+		modelStructs = append(modelStructs, file.modelStructs...)
+		registeredModels = append(registeredModels, file.registeredModels...)
+		ormTagged = append(ormTagged, file.ormTagged...)
+		typeUses = append(typeUses, file.typeUses...)
+		for name, specs := range file.methodSpecs {
+			methodSpecs[name] = append(methodSpecs[name], specs...)
+		}
+		for k, v := range file.validationKeys {
+			validationKeys[k] = v
+		}
+		if file.hasInit {
+			initImportPaths = []string{pkgImportPath}
+		}
+	}
+
+	// Add the method specs to copies of the struct specs, the cached ones
+	// are shared by the next calls.
+	specs := make([]*TypeInfo, len(structSpecs))
+	for i, spec := range structSpecs {
+		copied := *spec
+		copied.MethodSpecs = methodSpecs[spec.StructName]
+		specs[i] = &copied
+	}
+
+	return &SourceInfo{
+		StructSpecs:      specs,
+		ValidationKeys:   validationKeys,
+		InitImportPaths:  initImportPaths,
+		modelStructs:     modelStructs,
+		registeredModels: registeredModels,
+		ormTagged:        ormTagged,
+		typeUses:         typeUses,
+	}
+}
+
+// processFile extracts the metadata of a source file of the package
+// pkgName.
+func processFile(fset *token.FileSet, pkgImportPath, pkgPath, pkgName string, file *ast.File) *sourceFile {
 	var (
-		structSpecs     []*TypeInfo
-		initImportPaths []string
+		structSpecs      []*TypeInfo
+		modelStructs     []*TypeInfo
+		ormTagged        []string
+		registeredModels []string
 
 		methodSpecs     = make(methodMap)
 		validationKeys  = make(map[string]map[int]string)
+		hasInit         bool
 		scanControllers = strings.HasSuffix(pkgImportPath, "/controllers") ||
 			strings.Contains(pkgImportPath, "/controllers/")
 		scanTests = strings.HasSuffix(pkgImportPath, "/tests") ||
 			strings.Contains(pkgImportPath, "/tests/")
+		scanModels = strings.HasSuffix(pkgImportPath, "/models") ||
+			strings.Contains(pkgImportPath, "/models/")
 	)
 
-	// For each source file in the package...
-	for _, file := range pkg.Files {
-
-		// Imports maps the package key to the full import path.
-		// e.g. import "sample/app/models" => "models": "sample/app/models"
-		imports := map[string]string{}
+	// Imports maps the package key to the full import path.
+	// e.g. import "sample/app/models" => "models": "sample/app/models"
+	imports := map[string]string{}
+
+	// For each declaration in the source file...
+	for _, decl := range file.Decls {
+		addImports(imports, decl, pkgPath)
+
+		if scanControllers {
+			// Match and add both structs and methods
+			structSpecs = appendStruct(structSpecs, pkgImportPath, pkgName, decl, imports, fset)
+			appendAction(fset, methodSpecs, decl, pkgImportPath, pkgName, imports)
+		} else if scanTests {
+			structSpecs = appendStruct(structSpecs, pkgImportPath, pkgName, decl, imports, fset)
+		} else if scanModels {
+			modelStructs, ormTagged = appendModel(modelStructs, ormTagged, pkgImportPath, pkgName, decl, imports, fset)
+		}
 
-		// For each declaration in the source file...
-		for _, decl := range file.Decls {
-			addImports(imports, decl, pkgPath)
+		// If this is a func...
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			// Scan it for the models it registers
+			registeredModels = append(registeredModels, getRegisteredModels(funcDecl, pkgImportPath, imports)...)
 
-			if scanControllers {
-				// Match and add both structs and methods
-				structSpecs = appendStruct(structSpecs, pkgImportPath, pkg, decl, imports, fset)
-				appendAction(fset, methodSpecs, decl, pkgImportPath, pkg.Name, imports)
-			} else if scanTests {
-				structSpecs = appendStruct(structSpecs, pkgImportPath, pkg, decl, imports, fset)
+			// Scan it for validation calls
+			lineKeys := getValidationKeys(fset, funcDecl, imports)
+			if len(lineKeys) > 0 {
+				validationKeys[pkgImportPath+"."+getFuncName(funcDecl)] = lineKeys
 			}
 
-			// If this is a func...
-			if funcDecl, ok := decl.(*ast.FuncDecl); ok {
-				// Scan it for validation calls
-				lineKeys := getValidationKeys(fset, funcDecl, imports)
-				if len(lineKeys) > 0 {
-					validationKeys[pkgImportPath+"."+getFuncName(funcDecl)] = lineKeys
-				}
-
-				// Check if it's an init function.
-				if funcDecl.Name.Name == "init" {
-					initImportPaths = []string{pkgImportPath}
-				}
+			// Check if it's an init function.
+			if funcDecl.Name.Name == "init" {
+				hasInit = true
 			}
 		}
 	}
 
-	// Add the method specs to the struct specs.
-	for _, spec := range structSpecs {
-		spec.MethodSpecs = methodSpecs[spec.StructName]
+	return &sourceFile{
+		pkgName:          pkgName,
+		structSpecs:      structSpecs,
+		modelStructs:     modelStructs,
+		ormTagged:        ormTagged,
+		registeredModels: registeredModels,
+		typeUses:         getTypeUses(file, pkgImportPath, imports),
+		methodSpecs:      methodSpecs,
+		validationKeys:   validationKeys,
+		hasInit:          hasInit,
 	}
+}
 
-	return &SourceInfo{
-		StructSpecs:     structSpecs,
-		ValidationKeys:  validationKeys,
-		InitImportPaths: initImportPaths,
+// getTypeUses returns the types the file refers to other than by embedding
+// them or declaring methods on them, e.g. "myapp/app/models.Post" for
+// &models.Post{}.  Every identifier of the package is returned, the
+// callers only looking up the names of structs.
+func getTypeUses(file *ast.File, pkgImportPath string, imports map[string]string) (uses []string) {
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			uses = append(uses, name)
+		}
 	}
+	var visit func(node ast.Node) bool
+	visit = func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.TypeSpec:
+			ast.Inspect(n.Type, visit)
+			return false
+		case *ast.FuncDecl:
+			ast.Inspect(n.Type, visit)
+			if n.Body != nil {
+				ast.Inspect(n.Body, visit)
+			}
+			return false
+		case *ast.Field:
+			// an embedded field
+			return len(n.Names) > 0
+		case *ast.KeyValueExpr:
+			if _, ok := n.Key.(*ast.Ident); !ok {
+				ast.Inspect(n.Key, visit)
+			}
+			ast.Inspect(n.Value, visit)
+			return false
+		case *ast.SelectorExpr:
+			if pkgIdent, ok := n.X.(*ast.Ident); ok {
+				if importPath, ok := imports[pkgIdent.Name]; ok {
+					add(importPath + "." + n.Sel.Name)
+					return false
+				}
+			}
+			ast.Inspect(n.X, visit)
+			return false
+		case *ast.Ident:
+			add(pkgImportPath + "." + n.Name)
+		}
+		return true
+	}
+	for _, decl := range file.Decls {
+		if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Tok == token.IMPORT {
+			continue
+		}
+		ast.Inspect(decl, visit)
+	}
+	return
 }
 
 // getFuncName returns a name for this func or method declaration.
@@ -318,7 +475,7 @@ func addImports(imports map[string]string, decl ast.Decl, srcDir string) {
 
 // If this Decl is a struct type definition, it is summarized and added to specs.
 // Else, specs is returned unchanged.
-func appendStruct(specs []*TypeInfo, pkgImportPath string, pkg *ast.Package, decl ast.Decl, imports map[string]string, fset *token.FileSet) []*TypeInfo {
+func appendStruct(specs []*TypeInfo, pkgImportPath, pkgName string, decl ast.Decl, imports map[string]string, fset *token.FileSet) []*TypeInfo {
 	// Filter out non-Struct type declarations.
 	spec, found := getStructTypeDecl(decl, fset)
 	if !found {
@@ -332,7 +489,7 @@ func appendStruct(specs []*TypeInfo, pkgImportPath string, pkg *ast.Package, dec
 	controllerSpec := &TypeInfo{
 		StructName:  spec.Name.Name,
 		ImportPath:  pkgImportPath,
-		PackageName: pkg.Name,
+		PackageName: pkgName,
 	}
 
 	for _, field := range structType.Fields.List {
@@ -397,6 +554,78 @@ func appendStruct(specs []*TypeInfo, pkgImportPath string, pkg *ast.Package, dec
 	return append(specs, controllerSpec)
 }
 
+// If this Decl is an exported struct type definition, it is summarized and
+// added to models, and also to tagged if it has fields tagged for the ORM.
+// Else, models and tagged are returned unchanged.
+func appendModel(models []*TypeInfo, tagged []string, pkgImportPath, pkgName string, decl ast.Decl, imports map[string]string, fset *token.FileSet) ([]*TypeInfo, []string) {
+	spec, found := getStructTypeDecl(decl, fset)
+	if !found || !spec.Name.IsExported() {
+		return models, tagged
+	}
+
+	models = appendStruct(models, pkgImportPath, pkgName, decl, imports, fset)
+	for _, field := range spec.Type.(*ast.StructType).Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		if _, ok := reflect.StructTag(tag).Lookup("orm"); ok {
+			tagged = append(tagged, models[len(models)-1].String())
+			break
+		}
+	}
+	return models, tagged
+}
+
+// getRegisteredModels returns the structs registered by the calls of
+// orm.RegisterModel, or of its variants, within funcDecl, e.g.
+// "myapp/app/models.User" for orm.RegisterModel(&models.User{}).
+func getRegisteredModels(funcDecl *ast.FuncDecl, pkgImportPath string, imports map[string]string) (models []string) {
+	if funcDecl.Body == nil {
+		return nil
+	}
+
+	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+		callExpr, ok := node.(*ast.CallExpr)
+		if !ok || len(callExpr.Args) == 0 {
+			return true
+		}
+		funcSelector, ok := callExpr.Fun.(*ast.SelectorExpr)
+		if !ok || !strings.HasPrefix(funcSelector.Sel.Name, "RegisterModel") {
+			return true
+		}
+		if pkgIdent, ok := funcSelector.X.(*ast.Ident); !ok || imports[pkgIdent.Name] != revel.RevelImportPath+"/orm" {
+			return true
+		}
+
+		// The model is either &Type{} or new(Type).
+		var typeExpr ast.Expr
+		switch arg := callExpr.Args[0].(type) {
+		case *ast.UnaryExpr:
+			if lit, ok := arg.X.(*ast.CompositeLit); ok && arg.Op == token.AND {
+				typeExpr = lit.Type
+			}
+		case *ast.CallExpr:
+			if ident, ok := arg.Fun.(*ast.Ident); ok && ident.Name == "new" && len(arg.Args) == 1 {
+				typeExpr = arg.Args[0]
+			}
+		}
+		switch t := typeExpr.(type) {
+		case *ast.Ident:
+			models = append(models, pkgImportPath+"."+t.Name)
+		case *ast.SelectorExpr:
+			if pkgIdent, ok := t.X.(*ast.Ident); ok && imports[pkgIdent.Name] != "" {
+				models = append(models, imports[pkgIdent.Name]+"."+t.Sel.Name)
+			}
+		}
+		return true
+	})
+	return
+}
+
 // If decl is a Method declaration, it is summarized and added to the array
 // underneath its receiver type.
 // e.g. "Login" => {MethodSpec, MethodSpec, ..}
@@ -700,6 +929,50 @@ func (s *SourceInfo) TestSuites() []*TypeInfo {
 	return s.testSuites
 }
 
+// ModelSpecs returns the all the structs of the app models tagged for the
+// ORM, directly or through the structs they embed, that the app doesn't
+// register itself.  The structs only embedded in other structs, e.g. the
+// base struct of the models, aren't models of their own, unlike those the
+// app also uses on their own.
+func (s *SourceInfo) ModelSpecs() (models []*TypeInfo) {
+	structs := make(map[string]*TypeInfo)
+	embedded := make(map[string]bool)
+	used := make(map[string]bool, len(s.typeUses))
+	for _, name := range s.typeUses {
+		used[name] = true
+	}
+	for _, spec := range s.modelStructs {
+		structs[spec.String()] = spec
+		for _, embeddedType := range spec.embeddedTypes {
+			embedded[embeddedType.String()] = true
+		}
+	}
+
+	var tagged func(spec *TypeInfo, seen map[string]bool) bool
+	tagged = func(spec *TypeInfo, seen map[string]bool) bool {
+		if revel.ContainsString(s.ormTagged, spec.String()) {
+			return true
+		}
+		seen[spec.String()] = true
+		for _, embeddedType := range spec.embeddedTypes {
+			if base, ok := structs[embeddedType.String()]; ok && !seen[base.String()] && tagged(base, seen) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, spec := range s.modelStructs {
+		if embedded[spec.String()] && !used[spec.String()] || revel.ContainsString(s.registeredModels, spec.String()) {
+			continue
+		}
+		if tagged(spec, make(map[string]bool)) {
+			models = append(models, spec)
+		}
+	}
+	return
+}
+
 // TypeExpr provides a type name that may be rewritten to use a package name.
 type TypeExpr struct {
 	Expr     string // The unqualified type expression, e.g. "[]*MyType"
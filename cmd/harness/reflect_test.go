@@ -181,6 +181,100 @@ NEXT_TEST:
 	}
 }
 
+const modelsSource = `
+package models
+
+type User struct {
+	Id   int64  ` + "`orm:\"pk;auto\"`" + `
+	Name string ` + "`orm:\"size(64)\"`" + `
+}
+
+type Booking struct {
+	Id   int64
+	User *User ` + "`orm:\"rel(fk)\"`" + `
+}
+
+type Hotel struct {
+	Id int64 ` + "`orm:\"pk\"`" + `
+}
+
+// Not tagged for the ORM
+type Page struct {
+	Number int ` + "`json:\"number\"`" + `
+}
+
+type audit struct {
+	Id int64 ` + "`orm:\"pk\"`" + `
+}
+
+// Embedded in the models, not a model of its own
+type Base struct {
+	Id int64 ` + "`orm:\"pk;auto\"`" + `
+}
+
+type Room struct {
+	Base
+	Number int
+}
+
+func (b Base) Key() int64 {
+	return b.Id
+}
+
+type Post struct {
+	Id int64 ` + "`orm:\"pk;auto\"`" + `
+}
+
+// Embedded in a view, but used on its own too
+type PostWithAuthor struct {
+	Post
+	Author string
+}
+
+func NewPost() *Post {
+	return &Post{}
+}
+`
+
+const registerSource = `
+package app
+
+import (
+	models "myapp/app/models"
+	orm "github.com/dancewing/revel/orm"
+)
+
+func init() {
+	orm.RegisterModelWithOptions(&models.Hotel{}, orm.ModelOptions{Table: "hotels"})
+}
+`
+
+func TestModelSpecs(t *testing.T) {
+	var sourceInfo *SourceInfo
+	for _, src := range []struct{ importPath, source string }{
+		{"myapp/app/models", modelsSource},
+		{"myapp/app", registerSource},
+	} {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, src.importPath, src.source, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		processed := processFile(fset, src.importPath, "", file.Name.Name, file)
+		sourceInfo = appendSourceInfo(sourceInfo, processPackage(src.importPath, []*sourceFile{processed}))
+	}
+
+	var actual []string
+	for _, spec := range sourceInfo.ModelSpecs() {
+		actual = append(actual, spec.String())
+	}
+	expected := []string{"myapp/app/models.User", "myapp/app/models.Booking", "myapp/app/models.Room",
+		"myapp/app/models.Post", "myapp/app/models.PostWithAuthor"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Error("Fail, expected", expected, ", was", actual)
+	}
+}
+
 func BenchmarkProcessBookingSource(b *testing.B) {
 	revel.Init("", "github.com/dancewing/examples/booking", "")
 	revel.TRACE = log.New(ioutil.Discard, "", 0)
@@ -0,0 +1,99 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package harness
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/dancewing/revel"
+)
+
+// reverseController is a controller of the generated routes package, with
+// the reverse route helpers of its routed actions.
+type reverseController struct {
+	StructName string
+	Actions    []*reverseAction
+}
+
+// reverseAction is a reverse route helper, e.g.
+// routes.Posts.Show(id int64) string.
+type reverseAction struct {
+	Name string
+	Args []*reverseArg
+}
+
+type reverseArg struct {
+	Name string
+	Type string // e.g. "int64", "models.Status"
+}
+
+// reverseRoutes returns the arguments of RevelRoutesTemplate: the
+// controllers with the actions the routes file routes to, and the aliases of
+// the packages of the types of their arguments.
+func reverseRoutes(src *SourceInfo) (map[string]interface{}, *revel.Error) {
+	routes, err := revel.ParseRoutesFile(filepath.Join(revel.BasePath, "conf", "routes"))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		controllers []*reverseController
+		aliases     = make(map[string]string)
+	)
+	for _, spec := range src.ControllerSpecs() {
+		controller := &reverseController{StructName: spec.StructName}
+		for _, method := range spec.MethodSpecs {
+			if !isRouted(routes, spec.StructName, method.Name) {
+				continue
+			}
+			action := &reverseAction{Name: method.Name}
+			for _, arg := range method.Args {
+				action.Args = append(action.Args, &reverseArg{
+					Name: arg.Name,
+					Type: reverseArgType(aliases, arg),
+				})
+			}
+			controller.Actions = append(controller.Actions, action)
+		}
+		controllers = append(controllers, controller)
+	}
+
+	return map[string]interface{}{
+		"Controllers": controllers,
+		"ImportPaths": aliases,
+	}, nil
+}
+
+// isRouted returns whether a route leads to the action of the controller,
+// either naming it or through a :controller or :action parameter.
+func isRouted(routes []*revel.Route, controllerName, methodName string) bool {
+	for _, route := range routes {
+		if route.ControllerName == "" || route.MethodName == "" {
+			continue
+		}
+		if (route.ControllerName[0] == ':' || route.ControllerName == controllerName) &&
+			(route.MethodName[0] == ':' || route.MethodName == methodName) {
+			return true
+		}
+	}
+	return false
+}
+
+// reverseArgType returns the type of the argument of a reverse route
+// helper, adding the alias of its package to aliases.  The types of the
+// controllers packages, which import the routes package, are left untyped.
+func reverseArgType(aliases map[string]string, arg *MethodArg) string {
+	switch {
+	case arg.TypeExpr.PkgName == "":
+		// A builtin type
+		return arg.TypeExpr.TypeName("")
+	case arg.ImportPath == "" || strings.HasSuffix(arg.ImportPath, "/controllers") ||
+		strings.Contains(arg.ImportPath, "/controllers/"):
+		return "interface{}"
+	}
+	addAlias(aliases, arg.ImportPath, arg.TypeExpr.PkgName)
+	return arg.TypeExpr.TypeName(aliases[arg.ImportPath])
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package harness
+
+import (
+	"go/parser"
+	"testing"
+
+	"github.com/dancewing/revel"
+)
+
+func TestIsRouted(t *testing.T) {
+	routes := []*revel.Route{
+		{Action: "404"},
+		{ControllerName: "Posts", MethodName: "Show"},
+		{ControllerName: "Admin", MethodName: ":action"},
+	}
+	tests := []struct {
+		controller, method string
+		routed             bool
+	}{
+		{"Posts", "Show", true},
+		{"Posts", "Edit", false},
+		{"Admin", "Users", true},
+		{"Hotels", "Show", false},
+	}
+	for _, test := range tests {
+		if routed := isRouted(routes, test.controller, test.method); routed != test.routed {
+			t.Errorf("%s.%s expected routed %v, was %v", test.controller, test.method, test.routed, routed)
+		}
+	}
+}
+
+func TestReverseArgType(t *testing.T) {
+	tests := []struct {
+		typ, importPath, expected string
+	}{
+		{"int64", "", "int64"},
+		{"[]string", "", "[]string"},
+		{"*models.Post", "myapp/app/models", "*models.Post"},
+		{"[]revel.Post", "myapp/app/revel", "[]revel0.Post"},
+		{"Filter", "", "interface{}"},
+		{"shared.Filter", "myapp/app/controllers/shared", "interface{}"},
+	}
+	aliases := make(map[string]string)
+	for _, test := range tests {
+		expr, err := parser.ParseExpr(test.typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arg := &MethodArg{Name: "arg", TypeExpr: NewTypeExpr("controllers", expr), ImportPath: test.importPath}
+		if actual := reverseArgType(aliases, arg); actual != test.expected {
+			t.Errorf("%s expected type %s, was %s", test.typ, test.expected, actual)
+		}
+	}
+	if len(aliases) != 2 || aliases["myapp/app/models"] != "models" {
+		t.Errorf("unexpected aliases %v", aliases)
+	}
+}
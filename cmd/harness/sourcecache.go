@@ -0,0 +1,100 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package harness
+
+import (
+	"crypto/sha1"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// sourceFile is the metadata ProcessSource extracts from a source file:
+// its structs, models, actions, validation calls and init function.
+type sourceFile struct {
+	// The file it was extracted from, to tell whether it changed.
+	importPath string
+	modTime    time.Time
+	size       int64
+	hash       [sha1.Size]byte
+
+	pkgName          string
+	structSpecs      []*TypeInfo
+	modelStructs     []*TypeInfo
+	ormTagged        []string
+	registeredModels []string
+	typeUses         []string
+	methodSpecs      methodMap
+	validationKeys   map[string]map[int]string
+	hasInit          bool
+}
+
+// sourceFiles caches the metadata of the source files between the
+// rebuilds of the harness, so that only the files changed since are
+// parsed again.  A file is unchanged while its modification time and size
+// are, or else while the hash of its content is.
+type sourceFiles struct {
+	sync.Mutex
+	files map[string]*sourceFile
+}
+
+var sourceCache = &sourceFiles{files: make(map[string]*sourceFile)}
+
+// file returns the metadata of the source file filename of the package
+// pkgImportPath, parsing it unless it's cached.  It fails with the
+// scanner.ErrorList of a file failing to parse.
+func (c *sourceFiles) file(filename, pkgImportPath string, info os.FileInfo) (*sourceFile, error) {
+	c.Lock()
+	cached := c.files[filename]
+	c.Unlock()
+	if cached != nil && cached.importPath == pkgImportPath &&
+		cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached, nil
+	}
+
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha1.Sum(src)
+	if cached != nil && cached.importPath == pkgImportPath && cached.hash == hash {
+		// touched only
+		file := *cached
+		file.modTime, file.size = info.ModTime(), info.Size()
+		c.put(filename, &file)
+		return &file, nil
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	file := processFile(fset, pkgImportPath, filepath.Dir(filename), f.Name.Name, f)
+	file.importPath, file.modTime, file.size, file.hash = pkgImportPath, info.ModTime(), info.Size(), hash
+	c.put(filename, file)
+	return file, nil
+}
+
+func (c *sourceFiles) put(filename string, file *sourceFile) {
+	c.Lock()
+	defer c.Unlock()
+	c.files[filename] = file
+}
+
+// prune forgets the files not seen, eg deleted.
+func (c *sourceFiles) prune(seen map[string]bool) {
+	c.Lock()
+	defer c.Unlock()
+	for filename := range c.files {
+		if !seen[filename] {
+			delete(c.files, filename)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package harness
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSourceCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sourcecache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "app.go")
+	write := func(src string, modTime time.Time) os.FileInfo {
+		if err := ioutil.WriteFile(filename, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filename, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+	cache := &sourceFiles{files: make(map[string]*sourceFile)}
+	now := time.Now()
+
+	info := write("package tests\n\ntype AppTest struct{}\n", now)
+	parsed, err := cache.file(filename, "app/tests", info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.pkgName != "tests" || len(parsed.structSpecs) != 1 || parsed.hasInit {
+		t.Errorf("unexpected metadata %+v", parsed)
+	}
+	if cached, _ := cache.file(filename, "app/tests", info); cached != parsed {
+		t.Error("expected the unchanged file not to be parsed again")
+	}
+
+	// touched, the same content
+	info = write("package tests\n\ntype AppTest struct{}\n", now.Add(time.Second))
+	if touched, _ := cache.file(filename, "app/tests", info); touched.structSpecs[0] != parsed.structSpecs[0] {
+		t.Error("expected the touched file not to be parsed again")
+	}
+
+	info = write("package tests\n\ntype AppTest struct{}\n\nfunc init() {}\n", now.Add(2*time.Second))
+	if changed, _ := cache.file(filename, "app/tests", info); changed == parsed || !changed.hasInit {
+		t.Error("expected the changed file to be parsed again")
+	}
+
+	info = write("package tests\n\ntype AppTest struct {\n", now.Add(3*time.Second))
+	if _, err := cache.file(filename, "app/tests", info); err == nil {
+		t.Error("expected a parse error")
+	}
+
+	cache.prune(map[string]bool{})
+	if len(cache.files) != 0 {
+		t.Errorf("expected the deleted files to be forgotten, got %d", len(cache.files))
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/cmd/harness"
+)
+
+var cmdDb = &Command{
+	UsageLine: "db seed [import path] [run mode]",
+	Short:     "run the ORM seeds of a Revel application",
+	Long: `
+Build the Revel application named by the given import path, and run the
+ORM seeds it registers with orm.RegisterSeed on its database.  Every seed
+runs once per database.
+
+The run mode is used to select which set of app.conf configuration should
+apply, and so the database.  Run mode defaults to "dev".
+
+For example:
+
+    revel db seed github.com/dancewing/examples/booking prod
+`,
+}
+
+func init() {
+	cmdDb.Run = dbApp
+}
+
+func dbApp(args []string) {
+	if len(args) < 2 || args[0] != "seed" {
+		fmt.Fprintf(os.Stderr, "%s\n%s", cmdDb.UsageLine, cmdDb.Long)
+		return
+	}
+
+	mode := DefaultRunMode
+	if len(args) >= 3 {
+		mode = args[2]
+	}
+	revel.Init(mode, args[1], "")
+
+	app, err := harness.Build()
+	if err != nil {
+		errorf("Failed to build app: %s", err)
+	}
+	cmd := exec.Command(app.BinaryPath,
+		fmt.Sprintf("-importPath=%s", revel.ImportPath),
+		fmt.Sprintf("-runMode=%s", revel.RunMode),
+		"-seed")
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		errorf("Failed to run the seeds: %s", err)
+	}
+}
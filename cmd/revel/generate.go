@@ -0,0 +1,531 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/dancewing/revel"
+)
+
+var cmdGenerate = &Command{
+	UsageLine: "generate scaffold [Model] [import path]",
+	Short:     "generate the CRUD scaffold of an ORM model",
+	Long: `
+Generate the controller, routes and views listing, showing, creating,
+editing and deleting the rows of a model of a Revel application through
+the ORM.
+
+The model is a struct of the app/models package of the application given
+by its import path.  For a Post model, the generator writes:
+
+    app/controllers/posts.go     the Posts controller and its actions
+    app/views/Posts/*.html       the Index, Show, New and Edit templates
+    conf/routes                  the routes of the actions
+
+The existing files are never overwritten.
+
+For example:
+
+    revel generate scaffold Post github.com/dancewing/examples/blog
+`,
+}
+
+func init() {
+	cmdGenerate.Run = generateApp
+}
+
+// scaffold model the scaffold is generated for
+type scaffold struct {
+	ImportPath string // of the application
+	Model      string // eg Post
+	Plural     string // eg Posts, the name of the controller
+	Var        string // eg post
+	PluralVar  string // eg posts
+	Path       string // eg /posts
+	PKField    string // eg Id
+	PKType     string // eg int64
+	Fields     []scaffoldField
+	Columns    string // the quoted names of the fields edited, eg "Title", "Body"
+}
+
+// scaffoldField exported field of the model
+type scaffoldField struct {
+	Name  string
+	Input string // type of the input editing it, empty if it isn't edited
+}
+
+func generateApp(args []string) {
+	if len(args) < 3 || args[0] != "scaffold" {
+		fmt.Fprintf(os.Stderr, "%s\n%s", cmdGenerate.UsageLine, cmdGenerate.Long)
+		return
+	}
+	revel.Init(DefaultRunMode, args[2], "")
+	generateScaffold(revel.ImportPath, revel.BasePath, args[1])
+}
+
+// generateScaffold writes the scaffold of the model of the application of
+// importPath, in basePath.
+func generateScaffold(importPath, basePath, model string) {
+	appPath := filepath.Join(basePath, "app")
+	s, err := parseScaffold(filepath.Join(appPath, "models"), model)
+	if err != nil {
+		errorf("Failed to read the model: %s", err)
+	}
+	s.ImportPath = importPath
+
+	files := map[string]string{
+		filepath.Join(appPath, "controllers", snakeCase(s.Plural)+".go"): scaffoldController,
+		filepath.Join(appPath, "views", s.Plural, "Index.html"):          scaffoldIndex,
+		filepath.Join(appPath, "views", s.Plural, "Show.html"):           scaffoldShow,
+		filepath.Join(appPath, "views", s.Plural, "New.html"):            scaffoldNew,
+		filepath.Join(appPath, "views", s.Plural, "Edit.html"):           scaffoldEdit,
+		filepath.Join(appPath, "views", s.Plural, "form.html"):           scaffoldForm,
+	}
+	for path := range files {
+		if _, err := os.Stat(path); err == nil {
+			errorf("Abort: %s already exists.", path)
+		}
+	}
+	for path, text := range files {
+		src, err := renderScaffold(text, s)
+		if err == nil && strings.HasSuffix(path, ".go") {
+			src, err = format.Source(src)
+		}
+		if err != nil {
+			errorf("Failed to generate %s: %s", path, err)
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			errorf("Failed to create directory %s: %s", filepath.Dir(path), err)
+		}
+		if err = ioutil.WriteFile(path, src, 0666); err != nil {
+			errorf("Failed to write %s: %s", path, err)
+		}
+		fmt.Println("Created", path)
+	}
+
+	routesPath := filepath.Join(basePath, "conf", "routes")
+	if err = addScaffoldRoutes(routesPath, s); err != nil {
+		errorf("Failed to add the routes: %s", err)
+	}
+	fmt.Println("Routes added to", routesPath)
+}
+
+// parseScaffold reads the struct model in the package of dir.
+func parseScaffold(dir, model string) (*scaffold, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok && ts.Name.Name == model {
+						return newScaffold(model, st)
+					}
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("no struct %s in %s", model, dir)
+}
+
+func newScaffold(model string, st *ast.StructType) (*scaffold, error) {
+	plural := pluralName(model)
+	s := &scaffold{
+		Model:     model,
+		Plural:    plural,
+		Var:       lowerFirst(model),
+		PluralVar: lowerFirst(plural),
+		Path:      "/" + strings.ToLower(snakeCase(plural)),
+	}
+	for _, field := range st.Fields.List {
+		typ := exprString(field.Type)
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+		}
+		if tag.Get("orm") == "-" {
+			continue
+		}
+		for _, name := range field.Names {
+			// the associations aren't scaffolded
+			if !name.IsExported() || strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "[]") {
+				continue
+			}
+			pk := strings.Contains(";"+tag.Get("orm")+";", ";pk;")
+			if pk || (s.PKField == "" && (name.Name == "Id" || name.Name == "ID")) {
+				s.PKField, s.PKType = name.Name, typ
+				continue
+			}
+			input := inputType(typ)
+			s.Fields = append(s.Fields, scaffoldField{Name: name.Name, Input: input})
+			if input != "" {
+				if s.Columns != "" {
+					s.Columns += ", "
+				}
+				s.Columns += `"` + name.Name + `"`
+			}
+		}
+	}
+	if s.PKField == "" {
+		return nil, fmt.Errorf("%s has no primary key", model)
+	}
+	return s, nil
+}
+
+// inputType returns the type of the input editing a field of type typ,
+// empty for the associations and the types the forms don't edit.
+func inputType(typ string) string {
+	switch {
+	case typ == "string":
+		return "text"
+	case typ == "bool":
+		return "checkbox"
+	case strings.HasPrefix(typ, "int"), strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "float"):
+		return "number"
+	}
+	return ""
+}
+
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	}
+	return ""
+}
+
+// pluralName returns the English plural of name, eg Category to Categories.
+func pluralName(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// snakeCase returns name in snake case, eg BlogPosts to blog_posts.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func lowerFirst(name string) string {
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func renderScaffold(text string, s *scaffold) ([]byte, error) {
+	var buf bytes.Buffer
+	tmpl, err := template.New("scaffold").Delims("[[", "]]").Parse(text)
+	if err == nil {
+		err = tmpl.Execute(&buf, s)
+	}
+	return buf.Bytes(), err
+}
+
+// addScaffoldRoutes adds the routes of the actions before the catch all
+// route of the routes file, or at its end.
+func addScaffoldRoutes(path string, s *scaffold) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	routes := []string{"# " + s.Plural}
+	for _, r := range [][3]string{
+		{"GET", "", "Index"},
+		{"GET", "/new", "New"},
+		{"POST", "", "Create"},
+		{"GET", "/:id", "Show"},
+		{"GET", "/:id/edit", "Edit"},
+		{"POST", "/:id", "Update"},
+		{"POST", "/:id/delete", "Delete"},
+	} {
+		routes = append(routes, fmt.Sprintf("%-7s %-39s %s.%s", r[0], s.Path+r[1], s.Plural, r[2]))
+	}
+	routes = append(routes, "")
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	at := len(lines)
+	for i, line := range lines {
+		if fields := strings.Fields(line); len(fields) == 3 && fields[1] == "/:controller/:action" {
+			at = i
+			// with the comment above it
+			for at > 0 && strings.HasPrefix(strings.TrimSpace(lines[at-1]), "#") {
+				at--
+			}
+			break
+		}
+	}
+	if at > 0 && strings.TrimSpace(lines[at-1]) != "" {
+		routes = append([]string{""}, routes...)
+	}
+	if at == len(lines) {
+		routes = routes[:len(routes)-1]
+	}
+	lines = append(lines[:at], append(routes, lines[at:]...)...)
+	return ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0666)
+}
+
+const scaffoldController = `package controllers
+
+import (
+	"errors"
+
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/orm"
+
+	"[[.ImportPath]]/app/models"
+)
+
+// [[.Plural]] lists, shows, creates, edits and deletes the [[.PluralVar]].
+type [[.Plural]] struct {
+	*revel.Controller
+}
+
+// find[[.Model]] returns the [[.Var]] of the given id, nil if there's none.
+func find[[.Model]](id [[.PKType]]) (*models.[[.Model]], error) {
+	obj, err := orm.Database().Get().Get(models.[[.Model]]{}, id)
+	if errors.Is(err, orm.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return obj.(*models.[[.Model]]), nil
+}
+
+func (c [[.Plural]]) Index() revel.Result {
+	var [[.PluralVar]] []*models.[[.Model]]
+	err := orm.Database().Get().CreateCriteria(&[[.PluralVar]]).OrderBy("[[.PKField]]").Fill(&[[.PluralVar]])
+	if err != nil && !orm.NonFatalError(err) {
+		return c.RenderError(err)
+	}
+	return c.Render([[.PluralVar]])
+}
+
+func (c [[.Plural]]) Show(id [[.PKType]]) revel.Result {
+	[[.Var]], err := find[[.Model]](id)
+	if err != nil {
+		return c.RenderError(err)
+	}
+	if [[.Var]] == nil {
+		return c.NotFound("[[.Model]] %v not found", id)
+	}
+	return c.Render([[.Var]])
+}
+
+func (c [[.Plural]]) New() revel.Result {
+	[[.Var]] := &models.[[.Model]]{}
+	return c.Render([[.Var]])
+}
+
+func (c [[.Plural]]) Create([[.Var]] models.[[.Model]]) revel.Result {
+	if err := orm.Database().Get().Insert(&[[.Var]]); err != nil {
+		c.Flash.Error(err.Error())
+		c.FlashParams()
+		return c.Redirect([[.Plural]].New)
+	}
+	c.Flash.Success("[[.Model]] created")
+	return c.Redirect("[[.Path]]/%v", [[.Var]].[[.PKField]])
+}
+
+func (c [[.Plural]]) Edit(id [[.PKType]]) revel.Result {
+	[[.Var]], err := find[[.Model]](id)
+	if err != nil {
+		return c.RenderError(err)
+	}
+	if [[.Var]] == nil {
+		return c.NotFound("[[.Model]] %v not found", id)
+	}
+	return c.Render([[.Var]])
+}
+
+func (c [[.Plural]]) Update(id [[.PKType]], [[.Var]] models.[[.Model]]) revel.Result {
+	[[.Var]].[[.PKField]] = id
+	if _, err := orm.Database().Get().[[if .Columns]]UpdateColumns(&[[.Var]], [[.Columns]])[[else]]Update(&[[.Var]])[[end]]; err != nil {
+		c.Flash.Error(err.Error())
+		c.FlashParams()
+		return c.Redirect("[[.Path]]/%v/edit", id)
+	}
+	c.Flash.Success("[[.Model]] updated")
+	return c.Redirect("[[.Path]]/%v", id)
+}
+
+func (c [[.Plural]]) Delete(id [[.PKType]]) revel.Result {
+	[[.Var]], err := find[[.Model]](id)
+	if err != nil {
+		return c.RenderError(err)
+	}
+	if [[.Var]] == nil {
+		return c.NotFound("[[.Model]] %v not found", id)
+	}
+	if _, err = orm.Database().Get().Delete([[.Var]]); err != nil {
+		return c.RenderError(err)
+	}
+	c.Flash.Success("[[.Model]] deleted")
+	return c.Redirect([[.Plural]].Index)
+}
+`
+
+const scaffoldIndex = `{{set . "title" "[[.Plural]]"}}
+{{template "header.html" .}}
+
+<div class="container">
+  <div class="row">
+    <div class="span12">
+      {{template "flash.html" .}}
+      <h1>[[.Plural]]</h1>
+      <table class="table">
+        <thead>
+          <tr>
+            <th>[[.PKField]]</th>
+[[- range .Fields]]
+            <th>[[.Name]]</th>
+[[- end]]
+            <th></th>
+          </tr>
+        </thead>
+        <tbody>
+          {{range .[[.PluralVar]]}}
+          <tr>
+            <td>{{.[[.PKField]]}}</td>
+[[- range .Fields]]
+            <td>{{.[[.Name]]}}</td>
+[[- end]]
+            <td>
+              <a href="[[.Path]]/{{.[[.PKField]]}}">Show</a>
+              <a href="[[.Path]]/{{.[[.PKField]]}}/edit">Edit</a>
+            </td>
+          </tr>
+          {{end}}
+        </tbody>
+      </table>
+      <a class="btn btn-primary" href="[[.Path]]/new">New [[.Model]]</a>
+    </div>
+  </div>
+</div>
+
+{{template "footer.html" .}}
+`
+
+const scaffoldShow = `{{set . "title" "[[.Model]]"}}
+{{template "header.html" .}}
+
+<div class="container">
+  <div class="row">
+    <div class="span12">
+      {{template "flash.html" .}}
+      <h1>[[.Model]] {{.[[.Var]].[[.PKField]]}}</h1>
+      <dl>
+[[- range .Fields]]
+        <dt>[[.Name]]</dt>
+        <dd>{{.[[$.Var]].[[.Name]]}}</dd>
+[[- end]]
+      </dl>
+      <form action="[[.Path]]/{{.[[.Var]].[[.PKField]]}}/delete" method="POST">
+        <a class="btn btn-default" href="[[.Path]]/{{.[[.Var]].[[.PKField]]}}/edit">Edit</a>
+        <a class="btn btn-default" href="[[.Path]]">Back</a>
+        <button class="btn btn-danger" type="submit">Delete</button>
+      </form>
+    </div>
+  </div>
+</div>
+
+{{template "footer.html" .}}
+`
+
+const scaffoldNew = `{{set . "title" "New [[.Model]]"}}
+{{template "header.html" .}}
+
+<div class="container">
+  <div class="row">
+    <div class="span12">
+      {{template "flash.html" .}}
+      <h1>New [[.Model]]</h1>
+      <form action="[[.Path]]" method="POST">
+        {{template "[[.Plural]]/form.html" .}}
+        <button class="btn btn-primary" type="submit">Create</button>
+        <a class="btn btn-default" href="[[.Path]]">Back</a>
+      </form>
+    </div>
+  </div>
+</div>
+
+{{template "footer.html" .}}
+`
+
+const scaffoldEdit = `{{set . "title" "Edit [[.Model]]"}}
+{{template "header.html" .}}
+
+<div class="container">
+  <div class="row">
+    <div class="span12">
+      {{template "flash.html" .}}
+      <h1>Edit [[.Model]] {{.[[.Var]].[[.PKField]]}}</h1>
+      <form action="[[.Path]]/{{.[[.Var]].[[.PKField]]}}" method="POST">
+        {{template "[[.Plural]]/form.html" .}}
+        <button class="btn btn-primary" type="submit">Save</button>
+        <a class="btn btn-default" href="[[.Path]]/{{.[[.Var]].[[.PKField]]}}">Back</a>
+      </form>
+    </div>
+  </div>
+</div>
+
+{{template "footer.html" .}}
+`
+
+const scaffoldForm = `[[- range .Fields]][[if .Input]]
+{{with $field := field "[[$.Var]].[[.Name]]" .}}
+<div class="form-group {{$field.ErrorClass}}">
+  <label for="{{$field.ID}}">[[.Name]]</label>
+[[- if eq .Input "checkbox"]]
+  <input type="checkbox" id="{{$field.ID}}" name="{{$field.Name}}" value="true" {{if firstof $field.Flash $field.Value}}checked{{end}}>
+[[- else]]
+  <input class="form-control" type="[[.Input]]" id="{{$field.ID}}" name="{{$field.Name}}" value="{{firstof $field.Flash $field.Value}}">
+[[- end]]
+  {{if $field.Error}}<span class="help-block">{{$field.Error.Message}}</span>{{end}}
+</div>
+{{end}}
+[[- end]][[end]]
+`
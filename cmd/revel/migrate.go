@@ -0,0 +1,73 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/cmd/harness"
+)
+
+var cmdMigrate = &Command{
+	UsageLine: "migrate up|down|status|redo [import path] [run mode]",
+	Short:     "run the ORM migrations of a Revel application",
+	Long: `
+Build the Revel application named by the given import path, and run the
+ORM migrations it registers with orm.RegisterMigration on its database:
+
+    up       apply the pending migrations, in the order of their registration
+    down     revert the last migration applied
+    status   list the migrations, applied or pending
+    redo     revert the last migration applied and apply it again
+
+The migrations applied are recorded in the orm_migration table of the
+database.
+
+The run mode is used to select which set of app.conf configuration should
+apply, and so the database.  Run mode defaults to "dev".
+
+For example:
+
+    revel migrate up github.com/dancewing/examples/booking prod
+`,
+}
+
+func init() {
+	cmdMigrate.Run = migrateApp
+}
+
+func migrateApp(args []string) {
+	if len(args) < 2 {
+		fmt.Fprintf(os.Stderr, "%s\n%s", cmdMigrate.UsageLine, cmdMigrate.Long)
+		return
+	}
+	switch args[0] {
+	case "up", "down", "status", "redo":
+	default:
+		errorf("Unknown migrate command %q.\nRun 'revel help migrate' for usage.", args[0])
+	}
+
+	mode := DefaultRunMode
+	if len(args) >= 3 {
+		mode = args[2]
+	}
+	revel.Init(mode, args[1], "")
+
+	app, err := harness.Build()
+	if err != nil {
+		errorf("Failed to build app: %s", err)
+	}
+	cmd := exec.Command(app.BinaryPath,
+		fmt.Sprintf("-importPath=%s", revel.ImportPath),
+		fmt.Sprintf("-runMode=%s", revel.RunMode),
+		fmt.Sprintf("-migrate=%s", args[0]))
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		errorf("Failed to run the migrations: %s", err)
+	}
+}
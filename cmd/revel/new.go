@@ -6,6 +6,7 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"go/build"
 	"log"
@@ -19,7 +20,7 @@ import (
 )
 
 var cmdNew = &Command{
-	UsageLine: "new [path] [skeleton]",
+	UsageLine: "new [-with-orm] [path] [skeleton]",
 	Short:     "create a skeleton Revel application",
 	Long: `
 New creates a few files to get a new Revel application running quickly.
@@ -34,6 +35,16 @@ For example:
     revel new import/path/helloworld
 
     revel new import/path/helloworld import/path/skeleton
+
+With -with-orm, the application uses the ORM: its app.conf configures a
+SQLite database, opened on startup by app/db.go, and it has an example Post
+model, the migration creating its table, and the Posts CRUD controller
+generated for it, as by 'revel generate scaffold Post'.  Create the table
+and run it with:
+
+    revel new -with-orm import/path/blog
+    revel migrate up import/path/blog
+    revel run import/path/blog
 `,
 }
 
@@ -59,6 +70,11 @@ var (
 )
 
 func newApp(args []string) {
+	flags := flag.NewFlagSet("new", flag.ExitOnError)
+	withORM := flags.Bool("with-orm", false, "use the ORM")
+	flags.Parse(args)
+	args = flags.Args()
+
 	// check for proper args by count
 	if len(args) == 0 {
 		errorf("No import path given.\nRun 'revel help new' for usage.\n")
@@ -79,7 +95,7 @@ func newApp(args []string) {
 	setSkeletonPath(args)
 
 	// copy files to new app directory
-	copyNewAppFiles()
+	copyNewAppFiles(*withORM)
 
 	// goodbye world
 	fmt.Fprintln(os.Stdout, "Your application is ready:\n  ", appPath)
@@ -204,17 +220,33 @@ func setSkeletonPath(args []string) {
 	}
 }
 
-func copyNewAppFiles() {
+func copyNewAppFiles(withORM bool) {
 	var err error
 	err = os.MkdirAll(appPath, 0777)
 	panicOnError(err, "Failed to create directory "+appPath)
 
-	_ = mustCopyDir(appPath, skeletonPath, map[string]interface{}{
+	data := map[string]interface{}{
 		// app.conf
 		"AppName":  appName,
 		"BasePath": basePath,
 		"Secret":   generateSecret(),
-	})
+		"WithORM":  withORM,
+
+		// app/migrations
+		"ImportPath": importPath,
+	}
+	_ = mustCopyDir(appPath, skeletonPath, data)
+
+	// The ORM example is added to the skeleton, with the scaffold of its
+	// model.
+	if withORM {
+		revelCmdPkg, err = build.Import(RevelCmdImportPath, "", build.FindOnly)
+		if err != nil {
+			errorf("Abort: Could not find Revel Cmd source code: %s\n", err)
+		}
+		_ = mustCopyDir(appPath, filepath.Join(revelCmdPkg.Dir, "revel", "skeleton-orm"), data)
+		generateScaffold(importPath, appPath, "Post")
+	}
 
 	// Dotfiles are skipped by mustCopyDir, so we have to explicitly copy the .gitignore.
 	gitignore := ".gitignore"
@@ -0,0 +1,160 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+
+	"github.com/dancewing/revel"
+)
+
+var cmdOrm = &Command{
+	UsageLine: "orm reverse [-driver name] [-dsn spec] [-import path] [-pkg name] [-o file] [import path]",
+	Short:     "generate ORM models from an existing database",
+	Long: `
+Generate the ORM models of the tables of an existing database, with the
+orm tags of their keys and columns, relations inferred from foreign keys,
+and the RegisterModel calls.
+
+Given the import path of a Revel application, the db.driver, db.spec and
+db.import options of its app.conf are used by default, and the models are
+written to app/models/models.go.
+
+For example:
+
+    revel orm reverse github.com/dancewing/examples/booking
+
+or, for any database:
+
+    revel orm reverse -driver postgres -import github.com/lib/pq \
+        -dsn "dbname=legacy sslmode=disable" -o models/models.go
+
+The flags are:
+
+    -driver   the database/sql driver name, eg postgres, mysql or sqlite3
+    -dsn      the data source name of the database
+    -import   the import path of the driver package
+    -pkg      the package name of the models, "models" by default
+    -o        the file written, standard output by default
+`,
+}
+
+func init() {
+	cmdOrm.Run = ormApp
+}
+
+func ormApp(args []string) {
+	if len(args) == 0 || args[0] != "reverse" {
+		fmt.Fprintf(os.Stderr, "%s\n%s", cmdOrm.UsageLine, cmdOrm.Long)
+		return
+	}
+
+	flags := flag.NewFlagSet("orm reverse", flag.ExitOnError)
+	driver := flags.String("driver", "", "database/sql driver name")
+	dsn := flags.String("dsn", "", "data source name")
+	driverImport := flags.String("import", "", "import path of the driver")
+	pkg := flags.String("pkg", "models", "package name of the models")
+	out := flags.String("o", "", "output file")
+	flags.Parse(args[1:])
+
+	if flags.NArg() > 0 {
+		revel.Init(DefaultRunMode, flags.Arg(0), "")
+		if *driver == "" {
+			*driver = revel.Config.StringDefault("db.driver", "")
+		}
+		if *dsn == "" {
+			*dsn = revel.Config.StringDefault("db.spec", "")
+		}
+		if *driverImport == "" {
+			*driverImport = revel.Config.StringDefault("db.import", "")
+		}
+		if *out == "" {
+			*out = filepath.Join(revel.AppPath, "models", "models.go")
+		}
+	}
+	if *driver == "" || *dsn == "" || *driverImport == "" {
+		errorf("The driver, dsn and import of the database are needed.\nRun 'revel help orm' for usage.")
+	}
+
+	// The driver is linked into a program inspecting the database.
+	tmpDir, err := ioutil.TempDir("", "revel-orm")
+	if err != nil {
+		errorf("Failed to create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	mainFile := filepath.Join(tmpDir, "main.go")
+	file, err := os.Create(mainFile)
+	if err != nil {
+		errorf("Failed to create %s: %s", mainFile, err)
+	}
+	err = template.Must(template.New("reverse").Parse(reverseMain)).Execute(file, *driverImport)
+	file.Close()
+	if err != nil {
+		errorf("Failed to write %s: %s", mainFile, err)
+	}
+
+	gocmd, err := exec.LookPath("go")
+	if err != nil {
+		errorf("Go executable not found in PATH.")
+	}
+	cmd := exec.Command(gocmd, "run", mainFile, *driver, *dsn, *pkg)
+	cmd.Stderr = os.Stderr
+	src, err := cmd.Output()
+	if err != nil {
+		errorf("Failed to generate the models: %s", err)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(*out), 0777); err != nil {
+		errorf("Failed to create directory %s: %s", filepath.Dir(*out), err)
+	}
+	if err = ioutil.WriteFile(*out, src, 0666); err != nil {
+		errorf("Failed to write %s: %s", *out, err)
+	}
+	fmt.Println("Models written to", *out)
+}
+
+// reverseMain is the program inspecting the database, run with the driver,
+// data source name and package name as arguments.
+const reverseMain = `package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+
+	"github.com/dancewing/revel/orm"
+	_ "{{.}}"
+)
+
+func main() {
+	driver, dsn, pkg := os.Args[1], os.Args[2], os.Args[3]
+	dialect, err := orm.DialectForDriver(driver)
+	if err == nil {
+		var db *sql.DB
+		if db, err = sql.Open(driver, dsn); err == nil {
+			defer db.Close()
+			var schema *orm.DbSchema
+			dbmap := &orm.DbMap{Db: db, Dialect: dialect}
+			if schema, err = dbmap.Inspect(); err == nil {
+				err = orm.GenerateModels(os.Stdout, schema, pkg)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+`
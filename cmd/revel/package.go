@@ -5,16 +5,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/dancewing/revel"
 )
 
 var cmdPackage = &Command{
-	UsageLine: "package [import path] [run mode]",
+	UsageLine: "package [-goos list] [-goarch list] [import path] [run mode]",
 	Short:     "package a Revel application (e.g. for deployment)",
 	Long: `
 Package the Revel web application named by the given import path.
@@ -28,6 +31,21 @@ Run mode defaults to "dev".
 For example:
 
     revel package github.com/dancewing/examples/chat
+
+The application may be cross-compiled for every pair of the comma separated
+operating systems and architectures given, to an archive per target, e.g.
+chat-linux-amd64.tar.gz, with the views, conf and public files:
+
+    revel package -goos linux,darwin,windows -goarch amd64,arm64 github.com/dancewing/examples/chat prod
+
+The archives are reproducible: their files have the modification time of the
+SOURCE_DATE_EPOCH environment variable, or else of the Unix epoch, which is
+also the BuildTime of the app.
+
+The flags are:
+
+    -goos     the target operating systems, GOOS by default
+    -goarch   the target architectures, GOARCH by default
 `,
 }
 
@@ -36,6 +54,12 @@ func init() {
 }
 
 func packageApp(args []string) {
+	flags := flag.NewFlagSet("package", flag.ExitOnError)
+	goos := flags.String("goos", "", "target operating systems")
+	goarch := flags.String("goarch", "", "target architectures")
+	flags.Parse(args)
+	args = flags.Args()
+
 	if len(args) == 0 {
 		fmt.Fprint(os.Stderr, cmdPackage.Long)
 		return
@@ -50,8 +74,36 @@ func packageApp(args []string) {
 	appImportPath := args[0]
 	revel.Init(mode, appImportPath, "")
 
+	// The build time of the app is the one of the archived files.
+	if os.Getenv("SOURCE_DATE_EPOCH") == "" {
+		defer restoreEnv("SOURCE_DATE_EPOCH")()
+		_ = os.Setenv("SOURCE_DATE_EPOCH", "0")
+	}
+
+	if *goos == "" && *goarch == "" {
+		packageTarget(args[0], mode, filepath.Base(revel.BasePath)+".tar.gz")
+		return
+	}
+
+	// Cross-compile the app for each target, with the GOOS and GOARCH of the
+	// go command.
+	targetOSes := splitTargets(*goos, revel.FirstNonEmpty(os.Getenv("GOOS"), runtime.GOOS))
+	targetArches := splitTargets(*goarch, revel.FirstNonEmpty(os.Getenv("GOARCH"), runtime.GOARCH))
+	defer restoreEnv("GOOS")()
+	defer restoreEnv("GOARCH")()
+	for _, targetOS := range targetOSes {
+		for _, targetArch := range targetArches {
+			_ = os.Setenv("GOOS", targetOS)
+			_ = os.Setenv("GOARCH", targetArch)
+			packageTarget(args[0], mode,
+				fmt.Sprintf("%s-%s-%s.tar.gz", filepath.Base(revel.BasePath), targetOS, targetArch))
+		}
+	}
+}
+
+// packageTarget builds the app and archives it to destFile.
+func packageTarget(appImportPath, mode, destFile string) {
 	// Remove the archive if it already exists.
-	destFile := filepath.Base(revel.BasePath) + ".tar.gz"
 	if err := os.Remove(destFile); err != nil && !os.IsNotExist(err) {
 		revel.ERROR.Fatal(err)
 	}
@@ -59,11 +111,42 @@ func packageApp(args []string) {
 	// Collect stuff in a temp directory.
 	tmpDir, err := ioutil.TempDir("", filepath.Base(revel.BasePath))
 	panicOnError(err, "Failed to get temp dir")
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
 
-	buildApp([]string{args[0], tmpDir, mode})
+	buildApp([]string{appImportPath, tmpDir, mode})
 
 	// Create the zip file.
 	archiveName := mustTarGzDir(destFile, tmpDir)
 
 	fmt.Println("Your archive is ready:", archiveName)
 }
+
+// splitTargets returns the comma separated targets, or else the default
+// target.
+func splitTargets(targets, defaultTarget string) []string {
+	var list []string
+	for _, target := range strings.Split(targets, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			list = append(list, target)
+		}
+	}
+	if len(list) == 0 {
+		list = append(list, defaultTarget)
+	}
+	return list
+}
+
+// restoreEnv returns a function restoring the environment variable key to
+// its current value.
+func restoreEnv(key string) func() {
+	value, found := os.LookupEnv(key)
+	return func() {
+		if found {
+			_ = os.Setenv(key, value)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	}
+}
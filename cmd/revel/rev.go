@@ -51,6 +51,10 @@ var commands = []*Command{
 	cmdPackage,
 	cmdClean,
 	cmdTest,
+	cmdOrm,
+	cmdDb,
+	cmdMigrate,
+	cmdGenerate,
 	cmdVersion,
 }
 
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"flag"
 	"go/build"
 	"strconv"
 
@@ -13,7 +14,7 @@ import (
 )
 
 var cmdRun = &Command{
-	UsageLine: "run [import path] [run mode] [port]",
+	UsageLine: "run [-port n] [-https-port n] [-cert file] [-key file] [import path] [run mode] [port]",
 	Short:     "run a Revel application",
 	Long: `
 Run the Revel web application named by the given import path.
@@ -29,7 +30,21 @@ Run mode defaults to "dev".
 
 You can set a port as an optional third parameter.  For example:
 
-    revel run github.com/dancewing/examples/chat prod 8080`,
+    revel run github.com/dancewing/examples/chat prod 8080
+
+The flags override the http options of app.conf, to run several apps side by
+side, or to serve https with a local certificate:
+
+    revel run -https-port 8443 -cert cert.pem -key key.pem github.com/dancewing/examples/chat
+
+The flags are:
+
+    -port         the port to serve http on, http.port
+    -https-port   the port to serve https on instead, with http.ssl enabled
+    -cert         the certificate to serve https with, http.sslcert
+    -key          the certificate key to serve https with, http.sslkey
+
+Setting -cert and -key enables http.ssl, on the port of the app.`,
 }
 
 // RunArgs holds revel run parameters
@@ -37,6 +52,10 @@ type RunArgs struct {
 	ImportPath string
 	Mode       string
 	Port       int
+
+	// Flags overriding app.conf, zero when not set.
+	HTTPSPort int
+	Cert, Key string
 }
 
 func init() {
@@ -49,6 +68,15 @@ func parseRunArgs(args []string) *RunArgs {
 		Mode:       DefaultRunMode,
 		Port:       revel.HTTPPort,
 	}
+
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	port := flags.Int("port", 0, "http port")
+	flags.IntVar(&inputArgs.HTTPSPort, "https-port", 0, "https port")
+	flags.StringVar(&inputArgs.Cert, "cert", "", "certificate file")
+	flags.StringVar(&inputArgs.Key, "key", "", "certificate key file")
+	flags.Parse(args)
+	args = flags.Args()
+
 	switch len(args) {
 	case 3:
 		// Possibile combinations
@@ -100,6 +128,9 @@ func parseRunArgs(args []string) *RunArgs {
 			inputArgs.Mode = args[0]
 		}
 	}
+	if *port != 0 {
+		inputArgs.Port = *port
+	}
 
 	return &inputArgs
 }
@@ -115,6 +146,7 @@ func runApp(args []string) {
 	if runArgs.Port == 0 {
 		runArgs.Port = revel.HTTPPort
 	}
+	overrideHTTPS(runArgs)
 
 	revel.INFO.Printf("Running %s (%s) in %s mode\n", revel.AppName, revel.ImportPath, runArgs.Mode)
 	revel.TRACE.Println("Base path:", revel.BasePath)
@@ -135,3 +167,23 @@ func runApp(args []string) {
 	app.Port = runArgs.Port
 	app.Cmd().Run()
 }
+
+// overrideHTTPS applies the https flags of runArgs to the configuration
+// read from app.conf.
+func overrideHTTPS(runArgs *RunArgs) {
+	if runArgs.Cert != "" {
+		revel.HTTPSslCert = runArgs.Cert
+	}
+	if runArgs.Key != "" {
+		revel.HTTPSslKey = runArgs.Key
+	}
+	if runArgs.HTTPSPort != 0 || (runArgs.Cert != "" && runArgs.Key != "") {
+		revel.HTTPSsl = true
+	}
+	if runArgs.HTTPSPort != 0 {
+		runArgs.Port = runArgs.HTTPSPort
+	}
+	if revel.HTTPSsl && (revel.HTTPSslCert == "" || revel.HTTPSslKey == "") {
+		errorf("Serving https needs a certificate and its key, set with -cert and -key.\nRun 'revel help run' for usage.")
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"database/sql"
+
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/orm"
+)
+
+func init() {
+	// Before the ORM bootstrap of the models, registered by the generated
+	// main package.
+	revel.OnAppStart(InitDB)
+}
+
+// InitDB opens the database of the db.driver and db.spec of app.conf, as
+// the database of the ORM, with the table names affixed with its
+// orm.tableprefix and orm.tablesuffix.
+func InitDB() {
+	driver := revel.Config.StringDefault("db.driver", "")
+	db, err := sql.Open(driver, revel.Config.StringDefault("db.spec", ""))
+	if err != nil {
+		revel.ERROR.Fatalln("Failed to open the database:", err)
+	}
+	dialect, err := orm.DialectForDriver(driver)
+	if err != nil {
+		revel.ERROR.Fatalln(err)
+	}
+	orm.Database().Set(&orm.DbMap{
+		Db:          db,
+		Dialect:     dialect,
+		TablePrefix: revel.Config.StringDefault("orm.tableprefix", ""),
+		TableSuffix: revel.Config.StringDefault("orm.tablesuffix", ""),
+	})
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// Post is an example model.  Having orm tags, it is registered with the ORM
+// when the app is built, and its table is created by the migration of
+// app/migrations.
+type Post struct {
+	Id      int64     `orm:"pk;auto"`
+	Title   string    `orm:"size(200)"`
+	Body    string    `orm:"type(text)"`
+	Created time.Time `orm:"auto_now_add"`
+}
@@ -5,14 +5,18 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/dancewing/revel/cmd/harness"
@@ -21,7 +25,7 @@ import (
 )
 
 var cmdTest = &Command{
-	UsageLine: "test [import path] [run mode] [suite.method]",
+	UsageLine: "test [-parallel n] [-junit file] [-json file] [-cover] [-coverhtml file] [import path] [run mode] [suite.method]",
 	Short:     "run all tests from the command-line",
 	Long: `
 Run all tests for the Revel app named by the given import path.
@@ -35,6 +39,10 @@ apply and may be used to determine logic in the application itself.
 
 Run mode defaults to "dev".
 
+The result of each test is printed as it runs, and the command exits with a
+non-zero status if any test fails, so that it can be used in CI pipelines.
+The results are also written to the test-results directory of the app.
+
 You can run a specific suite (and function) by specifying a third parameter.
 For example, to run all of UserTest:
 
@@ -43,6 +51,34 @@ For example, to run all of UserTest:
 or one of UserTest's methods:
 
     revel test outspoken test UserTest.Test1
+
+The results may also be written, for the CI servers, as a JUnit XML report
+and as a JSON summary with the duration and failure message of each test:
+
+    revel test -junit junit.xml -json results.json outspoken test
+
+With -cover, the app is built with the coverage of its packages, reported
+per package once the tests have run.  The coverage profile is written to
+test-results/coverage.out, and with -coverhtml to an HTML report too:
+
+    revel test -coverhtml coverage.html outspoken test
+
+With -parallel n, the suites run concurrently against n instances of the
+app, each on a port of its own and with its number, 1 to n, in the
+REVEL_TEST_WORKER environment variable.  The instances of an app with a
+database must each be given a database or table prefix of their own, with
+REVEL_TEST_WORKER in the db.spec, orm.tableprefix or orm.tablesuffix of the
+app.conf of the run mode, else the tests are refused:
+
+    db.spec = dbname=myapp_test_${REVEL_TEST_WORKER} sslmode=disable
+
+The flags are:
+
+    -parallel    the number of suites run at once, 1 by default
+    -junit       the file written with the JUnit XML report
+    -json        the file written with the JSON summary
+    -cover       report the coverage of the app packages by the tests
+    -coverhtml   the file written with the HTML coverage report, implies -cover
 `,
 }
 
@@ -51,6 +87,15 @@ func init() {
 }
 
 func testApp(args []string) {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	junitFile := flags.String("junit", "", "JUnit XML report file")
+	jsonFile := flags.String("json", "", "JSON summary file")
+	cover := flags.Bool("cover", false, "report the coverage")
+	parallel := flags.Int("parallel", 1, "number of suites run at once")
+	coverHTML := flags.String("coverhtml", "", "HTML coverage report file")
+	flags.Parse(args)
+	args = flags.Args()
+
 	var err error
 	if len(args) == 0 {
 		errorf("No import path given.\nRun 'revel help test' for usage.\n")
@@ -82,24 +127,59 @@ func testApp(args []string) {
 		errorf("Failed to create test result log file: %s", err)
 	}
 
-	app, reverr := harness.Build()
+	var (
+		app      *harness.App
+		reverr   *revel.Error
+		coverDir = filepath.Join(resultPath, "coverage")
+	)
+	if *cover || *coverHTML != "" {
+		if err = os.Mkdir(coverDir, 0777); err != nil {
+			errorf("Failed to create coverage directory %s: %s", coverDir, err)
+		}
+		app, reverr = harness.BuildWithCoverage(coverDir)
+	} else {
+		app, reverr = harness.Build()
+	}
 	if reverr != nil {
 		errorf("Error building: %s", reverr)
 	}
-	cmd := app.Cmd()
-	cmd.Stderr = io.MultiWriter(cmd.Stderr, file)
-	cmd.Stdout = io.MultiWriter(cmd.Stderr, file)
 
-	// Start the app...
-	if err := cmd.Start(); err != nil {
-		errorf("%s", err)
+	// Start the app, or an instance of it per suite run at once, each on a
+	// port of its own.
+	if *parallel < 1 {
+		*parallel = 1
+	}
+	if *parallel > 1 && !workerDatabases() {
+		errorf("Abort: -parallel %d needs a database per instance, with ${REVEL_TEST_WORKER} in db.spec, orm.tableprefix or orm.tablesuffix", *parallel)
+	}
+	baseURLs := make([]string, *parallel)
+	for i := range baseURLs {
+		port := revel.HTTPPort
+		if *parallel > 1 {
+			port = harness.FreePort()
+			app.Port = port
+		}
+		cmd := app.Cmd()
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, file)
+		cmd.Stdout = io.MultiWriter(cmd.Stderr, file)
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, fmt.Sprintf("REVEL_TEST_WORKER=%d", i+1))
+
+		if err := cmd.Start(); err != nil {
+			errorf("%s", err)
+		}
+		defer cmd.Kill()
+		baseURLs[i] = fmt.Sprintf("http://127.0.0.1:%d", port)
 	}
-	defer cmd.Kill()
 	revel.INFO.Printf("Testing %s (%s) in %s mode\n", revel.AppName, revel.ImportPath, mode)
 
 	// Get a list of tests
-	var baseURL = fmt.Sprintf("http://127.0.0.1:%d", revel.HTTPPort)
-	testSuites, _ := getTestsList(baseURL)
+	testSuites, err := getTestsList(baseURLs[0])
+	if err != nil {
+		errorf("Failed to decode test list: %s", err)
+	}
 
 	// If a specific TestSuite[.Method] is specified, only run that suite/test
 	if len(args) == 3 {
@@ -110,14 +190,27 @@ func testApp(args []string) {
 	fmt.Println()
 
 	// Run each suite.
-	failedResults, overallSuccess := runTestSuites(baseURL, resultPath, testSuites)
+	results, overallSuccess := runTestSuites(baseURLs, resultPath, testSuites)
+
+	if *junitFile != "" {
+		writeReport(*junitFile, controllers.WriteJUnitReport, *results)
+	}
+	if *jsonFile != "" {
+		writeReport(*jsonFile, controllers.WriteJSONReport, *results)
+	}
+	if app.CoverDir != "" {
+		reportCoverage(baseURLs, resultPath, app.CoverDir, *coverHTML)
+	}
 
 	fmt.Println()
 	if overallSuccess {
 		writeResultFile(resultPath, "result.passed", "passed")
 		fmt.Println("All Tests Passed.")
 	} else {
-		for _, failedResult := range *failedResults {
+		for _, failedResult := range *results {
+			if failedResult.Passed {
+				continue
+			}
 			fmt.Printf("Failures:\n")
 			for _, result := range failedResult.Results {
 				if !result.Passed {
@@ -137,6 +230,74 @@ func writeResultFile(resultPath, name, content string) {
 	}
 }
 
+// writeReport writes the results to the file with the report writer.
+func writeReport(filename string, write func(io.Writer, []controllers.TestSuiteResult) error, results []controllers.TestSuiteResult) {
+	file, err := os.Create(filename)
+	if err != nil {
+		errorf("Failed to create report file %s: %s", filename, err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if err = write(file, results); err != nil {
+		errorf("Failed to write report file %s: %s", filename, err)
+	}
+}
+
+// workerDatabases reports whether the instances of the app run by
+// -parallel have a database or table prefix of their own, their app.conf
+// using REVEL_TEST_WORKER, or the app has no database.
+func workerDatabases() bool {
+	if _, found := revel.Config.String("db.spec"); !found {
+		return true
+	}
+	for _, option := range []string{"db.spec", "orm.tableprefix", "orm.tablesuffix"} {
+		if raw, err := revel.Config.Raw().RawString(revel.RunMode, option); err == nil && strings.Contains(raw, "REVEL_TEST_WORKER") {
+			return true
+		}
+	}
+	return false
+}
+
+// reportCoverage has the app instances write their coverage data, and
+// reports the coverage of each package, merging the data into the
+// coverage.out profile of the result path and, if htmlFile is set, into an
+// HTML report.
+func reportCoverage(baseURLs []string, resultPath, coverDir, htmlFile string) {
+	for _, baseURL := range baseURLs {
+		resp, err := http.Post(baseURL+"/@tests.coverage", "", nil)
+		if err != nil {
+			errorf("Failed to write the coverage data: %s", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			errorf("Failed to write the coverage data: %s", resp.Status)
+		}
+	}
+
+	gocmd, err := exec.LookPath("go")
+	if err != nil {
+		errorf("Go executable not found in PATH.")
+	}
+	fmt.Println()
+	fmt.Println("Coverage:")
+	cmd := exec.Command(gocmd, "tool", "covdata", "percent", "-i="+coverDir)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err = cmd.Run(); err != nil {
+		errorf("Failed to report the coverage: %s", err)
+	}
+
+	profile := filepath.Join(resultPath, "coverage.out")
+	if output, err := exec.Command(gocmd, "tool", "covdata", "textfmt", "-i="+coverDir, "-o="+profile).CombinedOutput(); err != nil {
+		errorf("Failed to write the coverage profile %s: %s\n%s", profile, err, output)
+	}
+	if htmlFile != "" {
+		if output, err := exec.Command(gocmd, "tool", "cover", "-html="+profile, "-o="+htmlFile).CombinedOutput(); err != nil {
+			errorf("Failed to write the coverage report %s: %s\n%s", htmlFile, err, output)
+		}
+	}
+}
+
 func pluralize(num int, singular, plural string) string {
 	if num == 1 {
 		return singular
@@ -235,7 +396,11 @@ func getTestsList(baseURL string) (*[]controllers.TestSuiteDesc, error) {
 	return &testSuites, err
 }
 
-func runTestSuites(baseURL, resultPath string, testSuites *[]controllers.TestSuiteDesc) (*[]controllers.TestSuiteResult, bool) {
+// runTestSuites runs the test suites, each against one of the app instances
+// of baseURLs as it is free, and writes their result files.  The result of
+// each test is printed as it completes, or with its suite when several run at
+// once.
+func runTestSuites(baseURLs []string, resultPath string, testSuites *[]controllers.TestSuiteDesc) (*[]controllers.TestSuiteResult, bool) {
 	// Load the result template, which we execute for each suite.
 	module, _ := revel.ModuleByName("testrunner")
 	TemplateLoader := revel.NewTemplateLoader([]string{filepath.Join(module.Path, "app", "views")})
@@ -248,49 +413,42 @@ func runTestSuites(baseURL, resultPath string, testSuites *[]controllers.TestSui
 	}
 
 	var (
-		overallSuccess = true
-		failedResults  []controllers.TestSuiteResult
+		results      = make([]controllers.TestSuiteResult, len(*testSuites))
+		suiteIndexes = make(chan int)
+		outputMutex  sync.Mutex
+		wg           sync.WaitGroup
 	)
-	for _, suite := range *testSuites {
-		// Print the name of the suite we're running.
-		name := suite.Name
-		if len(name) > 22 {
-			name = name[:19] + "..."
-		}
-		fmt.Printf("%-22s", name)
-
-		// Run every test.
-		startTime := time.Now()
-		suiteResult := controllers.TestSuiteResult{Name: suite.Name, Passed: true}
-		for _, test := range suite.Tests {
-			testURL := baseURL + "/@tests/" + suite.Name + "/" + test.Name
-			resp, err := http.Get(testURL)
-			if err != nil {
-				errorf("Failed to fetch test result at url %s: %s", testURL, err)
-			}
-			defer func() {
-				_ = resp.Body.Close()
-			}()
-
-			var testResult controllers.TestResult
-			err = json.NewDecoder(resp.Body).Decode(&testResult)
-			if err == nil && !testResult.Passed {
-				suiteResult.Passed = false
+	for _, baseURL := range baseURLs {
+		wg.Add(1)
+		go func(baseURL string) {
+			defer wg.Done()
+			for i := range suiteIndexes {
+				if len(baseURLs) == 1 {
+					results[i] = runTestSuite(os.Stdout, baseURL, (*testSuites)[i])
+					continue
+				}
+				var output bytes.Buffer
+				results[i] = runTestSuite(&output, baseURL, (*testSuites)[i])
+				outputMutex.Lock()
+				_, _ = output.WriteTo(os.Stdout)
+				outputMutex.Unlock()
 			}
-			suiteResult.Results = append(suiteResult.Results, testResult)
-		}
+		}(baseURL)
+	}
+	for i := range *testSuites {
+		suiteIndexes <- i
+	}
+	close(suiteIndexes)
+	wg.Wait()
+
+	overallSuccess := true
+	for _, suiteResult := range results {
 		overallSuccess = overallSuccess && suiteResult.Passed
 
-		// Print result.  (Just PASSED or FAILED, and the time taken)
-		suiteResultStr, suiteAlert := "PASSED", ""
-		if !suiteResult.Passed {
-			suiteResultStr, suiteAlert = "FAILED", "!"
-			failedResults = append(failedResults, suiteResult)
-		}
-		fmt.Printf("%8s%3s%6ds\n", suiteResultStr, suiteAlert, int(time.Since(startTime).Seconds()))
 		// Create the result HTML file.
+		suiteResultStr, _ := resultString(suiteResult.Passed)
 		suiteResultFilename := filepath.Join(resultPath,
-			fmt.Sprintf("%s.%s.html", suite.Name, strings.ToLower(suiteResultStr)))
+			fmt.Sprintf("%s.%s.html", suiteResult.Name, strings.ToLower(suiteResultStr)))
 		suiteResultFile, err := os.Create(suiteResultFilename)
 		if err != nil {
 			errorf("Failed to create result file %s: %s", suiteResultFilename, err)
@@ -300,5 +458,72 @@ func runTestSuites(baseURL, resultPath string, testSuites *[]controllers.TestSui
 		}
 	}
 
-	return &failedResults, overallSuccess
+	return &results, overallSuccess
+}
+
+// runTestSuite runs the tests of the suite against the app at baseURL,
+// printing their results to w as they complete.
+func runTestSuite(w io.Writer, baseURL string, suite controllers.TestSuiteDesc) controllers.TestSuiteResult {
+	// Print the name of the suite we're running.
+	fmt.Fprintln(w, suite.Name)
+
+	// Run every test, printing its result as it completes.
+	startTime := time.Now()
+	suiteResult := controllers.TestSuiteResult{Name: suite.Name, Passed: true}
+	for _, test := range suite.Tests {
+		testResult := runTest(baseURL, suite.Name, test.Name)
+		if !testResult.Passed {
+			suiteResult.Passed = false
+		}
+		suiteResult.Results = append(suiteResult.Results, testResult)
+
+		resultStr, alert := resultString(testResult.Passed)
+		fmt.Fprintf(w, "    %-28s%8s%3s%8.3fs\n", shorten(test.Name, 28), resultStr, alert, testResult.Duration.Seconds())
+		if !testResult.Passed {
+			fmt.Fprintf(w, "        %s\n", testResult.ErrorSummary)
+		}
+	}
+
+	// Print result.  (Just PASSED or FAILED, and the time taken)
+	suiteResultStr, suiteAlert := resultString(suiteResult.Passed)
+	fmt.Fprintf(w, "%-32s%8s%3s%8ds\n", shorten(suite.Name, 32), suiteResultStr, suiteAlert, int(time.Since(startTime).Seconds()))
+	return suiteResult
+}
+
+// runTest runs the test of the suite on the server, and returns its result.
+// A test whose result can't be fetched fails.
+func runTest(baseURL, suiteName, testName string) controllers.TestResult {
+	testURL := baseURL + "/@tests/" + suiteName + "/" + testName
+	testResult := controllers.TestResult{Name: testName}
+	resp, err := http.Get(testURL)
+	if err != nil {
+		testResult.ErrorSummary = fmt.Sprintf("Failed to fetch test result at url %s: %s", testURL, err)
+		return testResult
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		testResult.ErrorSummary = fmt.Sprintf("Test result at url %s: %s", testURL, resp.Status)
+	} else if err = json.NewDecoder(resp.Body).Decode(&testResult); err != nil {
+		testResult.ErrorSummary = fmt.Sprintf("Failed to decode test result at url %s: %s", testURL, err)
+	}
+	return testResult
+}
+
+// resultString returns PASSED or FAILED, and the alert of a failure.
+func resultString(passed bool) (string, string) {
+	if passed {
+		return "PASSED", ""
+	}
+	return "FAILED", "!"
+}
+
+// shorten truncates the name to width, with an ellipsis.
+func shorten(name string, width int) string {
+	if len(name) > width {
+		return name[:width-3] + "..."
+	}
+	return name
 }
@@ -12,8 +12,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/dancewing/revel"
 )
@@ -105,7 +107,17 @@ func mustCopyDir(destDir, srcDir string, data map[string]interface{}) error {
 	})
 }
 
+// mustTarGzDir archives the files of srcDir to destFilename.  The archive
+// is reproducible: the files are in lexical order, with the modification
+// time of SOURCE_DATE_EPOCH, or else of the Unix epoch, and no owner.
 func mustTarGzDir(destFilename, srcDir string) string {
+	modTime := time.Unix(0, 0)
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		panicOnError(err, "Failed to parse SOURCE_DATE_EPOCH")
+		modTime = time.Unix(seconds, 0)
+	}
+
 	zipFile, err := os.Create(destFilename)
 	panicOnError(err, "Failed to create archive")
 	defer func() {
@@ -113,6 +125,7 @@ func mustTarGzDir(destFilename, srcDir string) string {
 	}()
 
 	gzipWriter := gzip.NewWriter(zipFile)
+	gzipWriter.ModTime = modTime
 	defer func() {
 		_ = gzipWriter.Close()
 	}()
@@ -134,10 +147,10 @@ func mustTarGzDir(destFilename, srcDir string) string {
 		}()
 
 		err = tarWriter.WriteHeader(&tar.Header{
-			Name:    strings.TrimLeft(srcPath[len(srcDir):], string(os.PathSeparator)),
+			Name:    filepath.ToSlash(strings.TrimLeft(srcPath[len(srcDir):], string(os.PathSeparator))),
 			Size:    info.Size(),
-			Mode:    int64(info.Mode()),
-			ModTime: info.ModTime(),
+			Mode:    int64(info.Mode().Perm()),
+			ModTime: modTime,
 		})
 		panicOnError(err, "Failed to write tar entry header")
 
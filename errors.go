@@ -22,6 +22,7 @@ type Error struct {
 	MetaError                string   // Error that occurred producing the error page.
 	Link                     string   // A configurable link to wrap the error source in
 	Status                   int      // Error for http status
+	Related                  []*Error // Other errors reported along, e.g. by the compiler.
 }
 
 // SourceLine structure to hold the per-source-line details.
@@ -29,6 +30,7 @@ type SourceLine struct {
 	Source  string
 	Line    int
 	IsError bool
+	Marker  string // For the error line, a caret under the column of the error.
 }
 
 // NewErrorFromPanic method finds the deepest stack from in user code and
@@ -107,11 +109,28 @@ func (e *Error) ContextSource() []SourceLine {
 	lines := make([]SourceLine, end-start)
 	for i, src := range e.SourceLines[start:end] {
 		fileLine := start + i + 1
-		lines[i] = SourceLine{src, fileLine, fileLine == e.Line}
+		lines[i] = SourceLine{Source: src, Line: fileLine, IsError: fileLine == e.Line}
+		if lines[i].IsError {
+			lines[i].Marker = columnMarker(src, e.Column)
+		}
 	}
 	return lines
 }
 
+// columnMarker returns the caret under the column (in bytes, starting at 1)
+// of the source line, keeping its tabs so that it lines up.
+func columnMarker(src string, column int) string {
+	if column <= 0 || column > len(src)+1 {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		if r != '\t' {
+			return ' '
+		}
+		return r
+	}, src[:column-1]) + "^"
+}
+
 // SetLink method prepares a link and assign to Error.Link attribute
 func (e *Error) SetLink(errorLink string) {
 	errorLink = strings.Replace(errorLink, "{{Path}}", e.Path, -1)
@@ -0,0 +1,77 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package attachments stores uploaded files for any model registered with
+// the ORM.  File contents go to a pluggable Storage backend, while an
+// Attachment row records the owner, the original filename and the key
+// the contents are stored under.
+package attachments
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dancewing/revel/orm"
+)
+
+// Attachment is a file attached to a row of another model, identified by
+// its OwnerType (usually the owner's table name) and OwnerId.
+type Attachment struct {
+	Id          int64  `orm:"pk;auto"`
+	OwnerType   string `orm:"size(64);index"`
+	OwnerId     int64  `orm:"index"`
+	Filename    string `orm:"size(255)"`
+	ContentType string `orm:"size(128)"`
+	Size        int64
+	StorageKey  string    `orm:"size(255);unique"`
+	Created     time.Time `orm:"auto_now_add;type(datetime)"`
+}
+
+// TableName of the attachments table.
+func (a *Attachment) TableName() string {
+	return "attachment"
+}
+
+func init() {
+	orm.RegisterModel(new(Attachment))
+}
+
+// Find returns the attachment with the given id, or nil if there is none.
+func Find(exec orm.SqlExecutor, id int64) (*Attachment, error) {
+	obj, err := exec.Get(Attachment{}, id)
+	if err == orm.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return obj.(*Attachment), nil
+}
+
+// FindByOwner returns the attachments of an owner, oldest first.
+func FindByOwner(exec orm.SqlExecutor, ownerType string, ownerId int64) ([]*Attachment, error) {
+	dialect := orm.Database().Get().Dialect
+	query := fmt.Sprintf("select * from %s where %s=%s and %s=%s order by %s",
+		dialect.QuotedTableForQuery("", "attachment"),
+		dialect.QuoteField("owner_type"), dialect.BindVar(0),
+		dialect.QuoteField("owner_id"), dialect.BindVar(1),
+		dialect.QuoteField("id"))
+
+	list, err := exec.Select(Attachment{}, query, ownerType, ownerId)
+	if err != nil {
+		return nil, err
+	}
+	attachments := make([]*Attachment, len(list))
+	for i, obj := range list {
+		attachments[i] = obj.(*Attachment)
+	}
+	return attachments, nil
+}
+
+// Delete removes the attachment row, then its contents from Store.
+func Delete(exec orm.SqlExecutor, a *Attachment) error {
+	if _, err := exec.Delete(a); err != nil {
+		return err
+	}
+	return Store.Delete(a.StorageKey)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package attachments
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// Store holds the contents of every attachment.  It defaults to a
+	// LocalStorage configured by attachments.path on app start.
+	Store Storage
+
+	// ErrInvalidKey is returned for storage keys escaping the storage root.
+	ErrInvalidKey = errors.New("attachments: invalid storage key")
+)
+
+// Storage is a backend for attachment contents, addressed by key.
+type Storage interface {
+	Put(key string, r io.Reader, contentType string) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalStorage keeps attachments as files below Root.
+type LocalStorage struct {
+	Root string
+}
+
+func (s LocalStorage) Put(key string, r io.Reader, contentType string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(file)
+		return err
+	}
+	return f.Close()
+}
+
+func (s LocalStorage) Open(key string) (io.ReadCloser, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(file)
+}
+
+func (s LocalStorage) Delete(key string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s LocalStorage) path(key string) (string, error) {
+	if key == "" || path.IsAbs(key) || strings.HasPrefix(path.Clean(key), "..") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(path.Clean(key))), nil
+}
+
+// S3Client is the subset of an S3 compatible client used by S3Storage,
+// so applications can plug in the SDK of their choice.
+type S3Client interface {
+	PutObject(bucket, key string, r io.Reader, contentType string) error
+	GetObject(bucket, key string) (io.ReadCloser, error)
+	DeleteObject(bucket, key string) error
+}
+
+// S3Storage keeps attachments as objects of Bucket, with keys prefixed
+// by Prefix.
+type S3Storage struct {
+	Client S3Client
+	Bucket string
+	Prefix string
+}
+
+func (s S3Storage) Put(key string, r io.Reader, contentType string) error {
+	return s.Client.PutObject(s.Bucket, s.Prefix+key, r, contentType)
+}
+
+func (s S3Storage) Open(key string) (io.ReadCloser, error) {
+	return s.Client.GetObject(s.Bucket, s.Prefix+key)
+}
+
+func (s S3Storage) Delete(key string) error {
+	return s.Client.DeleteObject(s.Bucket, s.Prefix+key)
+}
+
+// newStorageKey returns a unique key for a new upload, grouped by month
+// and keeping the extension of the original filename.
+func newStorageKey(filename string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	if len(ext) > 16 || strings.ContainsAny(ext, `/\`) {
+		ext = ""
+	}
+	return time.Now().Format("2006/01/") + hex.EncodeToString(b) + ext, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package attachments
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/orm"
+)
+
+// DefaultMaxSize is the largest upload accepted, unless attachments.maxsize
+// is configured.
+const DefaultMaxSize = 10 << 20
+
+// MaxSize is the largest upload accepted, in bytes.
+var MaxSize int64 = DefaultMaxSize
+
+func init() {
+	revel.OnAppStart(func() {
+		MaxSize = int64(revel.Config.IntDefault("attachments.maxsize", DefaultMaxSize))
+		if Store == nil {
+			Store = LocalStorage{
+				Root: revel.Config.StringDefault("attachments.path", filepath.Join(revel.BasePath, "attachments")),
+			}
+		}
+	})
+}
+
+// Upload stores every file posted in the multipart form field and
+// attaches it to the given owner.  Contents already stored are removed
+// again if a later file fails.
+func Upload(c *revel.Controller, exec orm.SqlExecutor, field, ownerType string, ownerId int64) ([]*Attachment, error) {
+	attachments := make([]*Attachment, 0, len(c.Params.Files[field]))
+	for _, fh := range c.Params.Files[field] {
+		a, err := upload(exec, fh, ownerType, ownerId)
+		if err != nil {
+			for _, a := range attachments {
+				_ = Store.Delete(a.StorageKey)
+			}
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+func upload(exec orm.SqlExecutor, fh *multipart.FileHeader, ownerType string, ownerId int64) (*Attachment, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	filename := filepath.Base(strings.Replace(fh.Filename, `\`, "/", -1))
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxSize {
+		return nil, fmt.Errorf("attachments: %s is larger than %d bytes", filename, MaxSize)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	contentType := fh.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = revel.ContentTypeByFilename(filename)
+	}
+	key, err := newStorageKey(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err = Store.Put(key, f, contentType); err != nil {
+		return nil, err
+	}
+
+	a := &Attachment{
+		OwnerType:   ownerType,
+		OwnerId:     ownerId,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	}
+	if err = exec.Insert(a); err != nil {
+		_ = Store.Delete(key)
+		return nil, err
+	}
+	return a, nil
+}
+
+// Download streams the contents of the attachment to the client.
+func Download(c *revel.Controller, a *Attachment) revel.Result {
+	r, err := Store.Open(a.StorageKey)
+	if os.IsNotExist(err) {
+		return c.NotFound("attachment %d not found", a.Id)
+	}
+	if err != nil {
+		return c.RenderError(err)
+	}
+	c.Response.ContentType = a.ContentType
+	return c.RenderBinary(r, a.Filename, revel.Attachment, a.Created)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package controllers
+
+import (
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/modules/attachments/app/attachments"
+	"github.com/dancewing/revel/orm"
+)
+
+// Attachments controller scaffolds uploading and downloading attachments.
+// It performs no access control: embed it in an application controller
+// with the appropriate interceptors rather than routing to it directly on
+// public sites.
+type Attachments struct {
+	*revel.Controller
+}
+
+// Upload attaches the files posted in the "file" form field to the owner
+// and renders the new attachments as JSON.
+func (c Attachments) Upload(ownerType string, ownerId int64) revel.Result {
+	list, err := attachments.Upload(c.Controller, orm.Database().Get(), "file", ownerType, ownerId)
+	if err != nil {
+		return c.RenderError(err)
+	}
+	return c.RenderJSON(list)
+}
+
+// List renders the attachments of the owner as JSON.
+func (c Attachments) List(ownerType string, ownerId int64) revel.Result {
+	list, err := attachments.FindByOwner(orm.Database().Get(), ownerType, ownerId)
+	if err != nil {
+		return c.RenderError(err)
+	}
+	return c.RenderJSON(list)
+}
+
+// Download streams the attachment with the given id.
+func (c Attachments) Download(id int64) revel.Result {
+	a, err := attachments.Find(orm.Database().Get(), id)
+	if err != nil {
+		return c.RenderError(err)
+	}
+	if a == nil {
+		return c.NotFound("attachment %d not found", id)
+	}
+	return attachments.Download(c.Controller, a)
+}
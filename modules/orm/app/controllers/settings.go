@@ -0,0 +1,31 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package controllers
+
+import (
+	"strings"
+
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/modules/orm/app/settings"
+)
+
+type OrmSettings struct {
+	*revel.Controller
+}
+
+// Reload applies the orm settings of app.conf.  Like the jobs status
+// page, it only answers requests from the local host.
+func (c OrmSettings) Reload() revel.Result {
+	remoteAddress := c.Request.RemoteAddr
+	if !strings.HasPrefix(remoteAddress, "127.0.0.1") &&
+		!strings.HasPrefix(remoteAddress, "::1") &&
+		!strings.HasPrefix(remoteAddress, "[::1]") {
+		return c.Forbidden("%s is not local", remoteAddress)
+	}
+	if err := settings.Reload(); err != nil {
+		return c.RenderError(err)
+	}
+	return c.RenderText("ORM settings reloaded")
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package metrics exposes the ORM statistics to Prometheus.  It counts the
+// statements of the ORM database from the application start; register the
+// collector with the Prometheus registry served by the application:
+//
+//	prometheus.MustRegister(metrics.Collector())
+package metrics
+
+import (
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/orm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	labels = []string{"table", "operation"}
+
+	queriesDesc = prometheus.NewDesc("orm_queries_total",
+		"Number of SQL statements run, by table and operation.", labels, nil)
+	errorsDesc = prometheus.NewDesc("orm_query_errors_total",
+		"Number of SQL statements which failed, by table and operation.", labels, nil)
+	durationDesc = prometheus.NewDesc("orm_query_duration_seconds",
+		"Duration of the SQL statements, by table and operation.", labels, nil)
+	openDesc = prometheus.NewDesc("orm_open_connections",
+		"Number of open connections of the database pool.", nil, nil)
+)
+
+func init() {
+	revel.OnAppStart(func() {
+		orm.Database().Get().AddQueryHook(orm.Metrics())
+	})
+}
+
+// Collector returns the Prometheus collector of the ORM statistics.
+func Collector() prometheus.Collector {
+	return collector{}
+}
+
+type collector struct{}
+
+func (collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queriesDesc
+	ch <- errorsDesc
+	ch <- durationDesc
+	ch <- openDesc
+}
+
+func (collector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range orm.Metrics().Snapshot() {
+		ch <- prometheus.MustNewConstMetric(queriesDesc, prometheus.CounterValue, float64(s.Count), s.Table, s.Operation)
+		ch <- prometheus.MustNewConstMetric(errorsDesc, prometheus.CounterValue, float64(s.Errors), s.Table, s.Operation)
+
+		buckets := make(map[float64]uint64, len(orm.MetricsBuckets))
+		for i, bound := range orm.MetricsBuckets {
+			buckets[bound.Seconds()] = s.Buckets[i]
+		}
+		ch <- prometheus.MustNewConstHistogram(durationDesc, s.Count, s.Sum.Seconds(), buckets, s.Table, s.Operation)
+	}
+
+	if db := orm.Database().Get().Db; db != nil {
+		ch <- prometheus.MustNewConstMetric(openDesc, prometheus.GaugeValue, float64(db.Stats().OpenConnections))
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package querylog shows the ORM statements run by a request on the error
+// page of the request, in dev mode.  Add its filter after the PanicFilter:
+//
+//	revel.Filters = []revel.Filter{
+//		revel.PanicFilter,
+//		querylog.QueryLogFilter,
+//		...
+//	}
+package querylog
+
+import (
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/orm"
+)
+
+// Log keeps the last statements of the ORM database in dev mode, see
+// orm.QueryLog.  Its size is read from `orm.querylog.size`.
+var Log *orm.QueryLog
+
+func init() {
+	revel.OnAppStart(func() {
+		if !revel.DevMode {
+			return
+		}
+		Log = orm.NewQueryLog(revel.Config.IntDefault("orm.querylog.size", 100))
+		orm.Database().Get().AddQueryHook(Log)
+	})
+}
+
+// QueryLogFilter passes the statements run during a failed request to the
+// error page, as the `Queries` view arg.  Statements of requests running
+// at the same time are included too.
+func QueryLogFilter(c *revel.Controller, fc []revel.Filter) {
+	if Log == nil {
+		fc[0](c, fc[1:])
+		return
+	}
+
+	mark := Log.Mark()
+	defer func() {
+		if err := recover(); err != nil {
+			c.ViewArgs["Queries"] = Log.Since(mark)
+			panic(err)
+		}
+	}()
+	fc[0](c, fc[1:])
+
+	// the error result renders the view args of the controller
+	if _, ok := c.Result.(revel.ErrorResult); ok {
+		c.ViewArgs["Queries"] = Log.Since(mark)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package settings reloads the ORM tunables from app.conf while the
+// application runs: on every change of the configuration in dev mode,
+// and on SIGHUP or through the /@orm/reload endpoint in prod.  Only
+// settings registered with orm.RegisterSetting are reloaded; connections
+// are kept.
+package settings
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/config"
+	"github.com/dancewing/revel/orm"
+)
+
+func init() {
+	revel.OnAppStart(func() {
+		if db := orm.Database().Get(); db.QueryLogger == nil {
+			db.QueryLogger = revelLogger{}
+		}
+		if err := Apply(revel.Config); err != nil {
+			revel.ERROR.Println(err)
+		}
+
+		// revel.MainWatcher only notifies its listeners on requests and
+		// is created after the startup hooks, so watch the configuration
+		// with a watcher of our own.
+		if revel.DevMode && revel.Config.BoolDefault("watch", true) {
+			watcher := revel.NewWatcher()
+			watcher.Listen(confListener{}, revel.ConfPaths...)
+			go func() {
+				for range time.Tick(time.Second) {
+					if err := watcher.Notify(); err != nil {
+						revel.ERROR.Println(err)
+					}
+				}
+			}()
+		}
+
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := Reload(); err != nil {
+					revel.ERROR.Println("ORM settings reload failed:", err)
+				} else {
+					revel.INFO.Println("ORM settings reloaded")
+				}
+			}
+		}()
+	})
+}
+
+// Reload reads app.conf again and applies its orm settings, without
+// touching the rest of revel.Config.
+func Reload() error {
+	conf, err := config.LoadContext("app.conf", revel.ConfPaths)
+	if err != nil {
+		return err
+	}
+	conf.SetSection(revel.RunMode)
+	return Apply(conf)
+}
+
+// Apply applies the orm.* options of conf to the ORM database.
+func Apply(conf *config.Context) error {
+	values := make(map[string]string)
+	for _, key := range conf.Options("orm.") {
+		values[key], _ = conf.String(key)
+	}
+	return orm.Database().Get().ApplySettings(values)
+}
+
+// confListener reloads the settings when the configuration changes.
+type confListener struct{}
+
+func (confListener) Refresh() *revel.Error {
+	if err := Reload(); err != nil {
+		return &revel.Error{
+			Title:       "ORM settings error",
+			Description: err.Error(),
+		}
+	}
+	return nil
+}
+
+// revelLogger reports slow and failing statements to the revel logs.
+type revelLogger struct{}
+
+func (revelLogger) LogQuery(level orm.LogLevel, query string, args []interface{}, d time.Duration, err error) {
+	switch level {
+	case orm.LevelWarn:
+		revel.WARN.Printf("slow query (%v): %s %v", d, query, args)
+	case orm.LevelError:
+		revel.ERROR.Printf("query failed (%v): %s %v: %s", d, query, args, err)
+	}
+}
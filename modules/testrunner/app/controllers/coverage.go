@@ -0,0 +1,22 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build revelcover
+// +build revelcover
+
+package controllers
+
+import "runtime/coverage"
+
+// coverageBuilt is set in the apps built by revel test -cover, which needs
+// Go 1.20 or later.
+const coverageBuilt = true
+
+// writeCoverage writes the coverage data of the app to dir.
+func writeCoverage(dir string) error {
+	if err := coverage.WriteMetaDir(dir); err != nil {
+		return err
+	}
+	return coverage.WriteCountersDir(dir)
+}
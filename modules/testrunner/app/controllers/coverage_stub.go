@@ -0,0 +1,14 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !revelcover
+// +build !revelcover
+
+package controllers
+
+const coverageBuilt = false
+
+func writeCoverage(dir string) error {
+	return nil
+}
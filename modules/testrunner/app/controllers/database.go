@@ -0,0 +1,67 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build revelorm
+// +build revelorm
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"github.com/dancewing/revel"
+	"github.com/dancewing/revel/orm"
+	"github.com/dancewing/revel/orm/fixtures"
+)
+
+// fixtureSuite is implemented by the test suites needing fixtures, loaded
+// before each of their tests.  Fixtures returns the names of the fixture
+// files in the tests/fixtures directory of the application.
+type fixtureSuite interface {
+	Fixtures() []string
+}
+
+// truncateSuite is implemented by the test suites whose tests write to the
+// database.  If TruncateTables returns true, the tables registered with the
+// ORM database are truncated before each test, and its fixtures loaded, and
+// after it.  Truncation is the isolation of the tests: the requests they
+// make are served by the app on its own connections, which no transaction
+// of the test runner could cover.
+type truncateSuite interface {
+	TruncateTables() bool
+}
+
+// setUpDatabase truncates the tables and loads the fixtures the test suite
+// asks for, in the apps built with the revelorm tag, eg with
+// build.tags = revelorm in app.conf.  The function returned runs after
+// the test.
+func setUpDatabase(suite interface{}) func() {
+	after := func() {}
+	if ts, ok := suite.(truncateSuite); ok && ts.TruncateTables() {
+		truncateTables()
+		after = truncateTables
+	}
+	if fs, ok := suite.(fixtureSuite); ok {
+		loadFixtures(fs.Fixtures())
+	}
+	return after
+}
+
+// loadFixtures loads the fixture files named names into the ORM database.
+func loadFixtures(names []string) {
+	files := make([]string, len(names))
+	for i, name := range names {
+		files[i] = filepath.Join(revel.BasePath, "tests", "fixtures", name)
+	}
+	if err := fixtures.Load(orm.Database().Get(), files...); err != nil {
+		panic(err)
+	}
+}
+
+// truncateTables truncates the tables registered with the ORM database.
+func truncateTables() {
+	if err := orm.Database().Get().TruncateTables(); err != nil {
+		panic(err)
+	}
+}
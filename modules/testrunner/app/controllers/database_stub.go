@@ -0,0 +1,12 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+//go:build !revelorm
+// +build !revelorm
+
+package controllers
+
+func setUpDatabase(suite interface{}) func() {
+	return func() {}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package controllers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the results of the test suites as a JUnit XML
+// report, as read by the CI servers.
+func WriteJUnitReport(w io.Writer, results []TestSuiteResult) error {
+	report := junitTestSuites{}
+	var total time.Duration
+	for _, suiteResult := range results {
+		suite := junitTestSuite{Name: suiteResult.Name, Tests: len(suiteResult.Results)}
+		var suiteTime time.Duration
+		for _, result := range suiteResult.Results {
+			testCase := junitTestCase{
+				ClassName: suiteResult.Name,
+				Name:      result.Name,
+				Time:      seconds(result.Duration),
+			}
+			if !result.Passed {
+				testCase.Failure = &junitFailure{Message: result.ErrorSummary, Text: result.ErrorSummary}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, testCase)
+			suiteTime += result.Duration
+		}
+		suite.Time = seconds(suiteTime)
+		report.Suites = append(report.Suites, suite)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		total += suiteTime
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// jsonReport is the summary of the results written by WriteJSONReport.
+type jsonReport struct {
+	Passed   bool        `json:"passed"`
+	Tests    int         `json:"tests"`
+	Failures int         `json:"failures"`
+	Duration float64     `json:"duration"` // in seconds
+	Suites   []jsonSuite `json:"suites"`
+}
+
+type jsonSuite struct {
+	Name     string     `json:"name"`
+	Passed   bool       `json:"passed"`
+	Duration float64    `json:"duration"`
+	Tests    []jsonTest `json:"tests"`
+}
+
+type jsonTest struct {
+	Name     string  `json:"name"`
+	Passed   bool    `json:"passed"`
+	Duration float64 `json:"duration"`
+	Failure  string  `json:"failure,omitempty"`
+}
+
+// WriteJSONReport writes the results of the test suites as a JSON summary,
+// with the duration and failure message of each test.
+func WriteJSONReport(w io.Writer, results []TestSuiteResult) error {
+	report := jsonReport{Passed: true, Suites: []jsonSuite{}}
+	var total time.Duration
+	for _, suiteResult := range results {
+		suite := jsonSuite{Name: suiteResult.Name, Passed: suiteResult.Passed, Tests: []jsonTest{}}
+		var suiteTime time.Duration
+		for _, result := range suiteResult.Results {
+			suite.Tests = append(suite.Tests, jsonTest{
+				Name:     result.Name,
+				Passed:   result.Passed,
+				Duration: result.Duration.Seconds(),
+				Failure:  result.ErrorSummary,
+			})
+			if !result.Passed {
+				report.Failures++
+			}
+			suiteTime += result.Duration
+		}
+		suite.Duration = suiteTime.Seconds()
+		report.Suites = append(report.Suites, suite)
+		report.Passed = report.Passed && suiteResult.Passed
+		report.Tests += len(suiteResult.Results)
+		total += suiteTime
+	}
+	report.Duration = total.Seconds()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// seconds formats the duration in seconds, as in the JUnit reports.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
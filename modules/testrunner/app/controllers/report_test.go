@@ -0,0 +1,102 @@
+// Copyright (c) 2012-2016 The Revel Framework Authors, All rights reserved.
+// Revel Framework source code and usage is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+var reportResults = []TestSuiteResult{
+	{Name: "AppTest", Passed: false, Results: []TestResult{
+		{Name: "TestIndex", Passed: true, Duration: 1500 * time.Millisecond},
+		{Name: "TestLogin", Passed: false, ErrorSummary: "expected 200, got 403", Duration: 250 * time.Millisecond},
+	}},
+	{Name: "PostTest", Passed: true, Results: []TestResult{
+		{Name: "TestList", Passed: true, Duration: 2 * time.Second},
+	}},
+}
+
+func TestWriteJUnitReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnitReport(&buf, reportResults); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("expected the XML header, got %q", buf.String())
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Tests != 3 || report.Failures != 1 || report.Time != "3.750" || len(report.Suites) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	app := report.Suites[0]
+	if app.Name != "AppTest" || app.Tests != 2 || app.Failures != 1 || app.Time != "1.750" || len(app.Cases) != 2 {
+		t.Fatalf("unexpected suite %+v", app)
+	}
+	if c := app.Cases[0]; c.ClassName != "AppTest" || c.Name != "TestIndex" || c.Time != "1.500" || c.Failure != nil {
+		t.Errorf("unexpected passing case %+v", c)
+	}
+	if c := app.Cases[1]; c.Name != "TestLogin" || c.Time != "0.250" || c.Failure == nil ||
+		c.Failure.Message != "expected 200, got 403" || c.Failure.Text != "expected 200, got 403" {
+		t.Errorf("unexpected failing case %+v", c)
+	}
+
+	post := report.Suites[1]
+	if post.Name != "PostTest" || post.Tests != 1 || post.Failures != 0 || post.Time != "2.000" {
+		t.Errorf("unexpected suite %+v", post)
+	}
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSONReport(&buf, reportResults); err != nil {
+		t.Fatal(err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed || report.Tests != 3 || report.Failures != 1 || report.Duration != 3.75 || len(report.Suites) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	app := report.Suites[0]
+	if app.Name != "AppTest" || app.Passed || app.Duration != 1.75 || len(app.Tests) != 2 {
+		t.Fatalf("unexpected suite %+v", app)
+	}
+	if test := app.Tests[0]; test.Name != "TestIndex" || !test.Passed || test.Duration != 1.5 || test.Failure != "" {
+		t.Errorf("unexpected passing test %+v", test)
+	}
+	if test := app.Tests[1]; test.Name != "TestLogin" || test.Passed || test.Duration != 0.25 || test.Failure != "expected 200, got 403" {
+		t.Errorf("unexpected failing test %+v", test)
+	}
+	if strings.Count(buf.String(), `"failure"`) != 1 {
+		t.Errorf("expected the failure of the passing tests omitted, got %s", buf.String())
+	}
+
+	post := report.Suites[1]
+	if post.Name != "PostTest" || !post.Passed || post.Duration != 2 || len(post.Tests) != 1 {
+		t.Errorf("unexpected suite %+v", post)
+	}
+
+	// the suites and tests are lists, even when empty
+	buf.Reset()
+	if err := WriteJSONReport(&buf, []TestSuiteResult{{Name: "EmptyTest", Passed: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"tests": []`) {
+		t.Errorf("expected an empty list of tests, got %s", buf.String())
+	}
+}
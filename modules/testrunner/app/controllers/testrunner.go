@@ -9,9 +9,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"os"
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dancewing/revel"
 	"github.com/dancewing/revel/testing"
@@ -54,6 +56,7 @@ type TestResult struct {
 	Passed       bool
 	ErrorHTML    template.HTML
 	ErrorSummary string
+	Duration     time.Duration // Time taken by the test, with its Before and After.
 }
 
 var (
@@ -101,6 +104,7 @@ func (c TestRunner) Run(suite, test string) revel.Result {
 	}
 
 	result := TestResult{Name: test}
+	startTime := time.Now()
 
 	// Found the suite, create a new instance and run the named method.
 	t := testSuites[suiteIndex].Elem
@@ -136,6 +140,10 @@ func (c TestRunner) Run(suite, test string) revel.Result {
 		testSuiteInstance := v.Elem().FieldByName("TestSuite")
 		testSuiteInstance.Set(reflect.ValueOf(testing.NewTestSuite()))
 
+		// Prepare the database of the test suite, in apps built with the
+		// revelorm tag.
+		defer setUpDatabase(v.Interface())()
+
 		// Make sure After method will be executed at the end.
 		if m := v.MethodByName("After"); m.IsValid() {
 			defer m.Call(none)
@@ -152,10 +160,25 @@ func (c TestRunner) Run(suite, test string) revel.Result {
 		// No panic means success.
 		result.Passed = true
 	}()
+	result.Duration = time.Since(startTime)
 
 	return c.RenderJSON(result)
 }
 
+// Coverage writes the coverage data of the tests run, to the GOCOVERDIR
+// directory of an app built with -cover and the revelcover build tag.
+// It is used by revel test command line tool.
+func (c TestRunner) Coverage() revel.Result {
+	dir := os.Getenv("GOCOVERDIR")
+	if dir == "" || !coverageBuilt {
+		return c.NotFound("The app isn't built and run for coverage")
+	}
+	if err := writeCoverage(dir); err != nil {
+		return c.RenderError(err)
+	}
+	return c.RenderJSON(dir)
+}
+
 // List returns a JSON list of test suites and tests.
 // It is used by revel test command line tool.
 func (c TestRunner) List() revel.Result {
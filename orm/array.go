@@ -0,0 +1,185 @@
+package orm
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Array wraps a slice, or a pointer to a slice, of strings, integers,
+// floats or bools so it is bound to and scanned from a native array
+// column, eg a Postgres text[] or bigint[].
+//
+// Fields tagged `type(array)` are wrapped automatically.
+//
+// Example:
+//
+//	dbmap.Select(&users, "select * from users where tags && $1", orm.Array([]string{"a", "b"}))
+func Array(a interface{}) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	return genericArray{a}
+}
+
+type genericArray struct {
+	a interface{}
+}
+
+// Value implements the driver Valuer interface.
+func (g genericArray) Value() (driver.Value, error) {
+	v := reflect.Indirect(reflect.ValueOf(g.a))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("orm: can not bind %T as an array", g.a)
+	}
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return nil, nil
+	}
+
+	b := bytes.Buffer{}
+	b.WriteByte('{')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		e := v.Index(i)
+		switch e.Kind() {
+		case reflect.String:
+			b.WriteByte('"')
+			for _, r := range e.String() {
+				if r == '"' || r == '\\' {
+					b.WriteByte('\\')
+				}
+				b.WriteRune(r)
+			}
+			b.WriteByte('"')
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			b.WriteString(strconv.FormatInt(e.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			b.WriteString(strconv.FormatUint(e.Uint(), 10))
+		case reflect.Float32, reflect.Float64:
+			b.WriteString(strconv.FormatFloat(e.Float(), 'g', -1, 64))
+		case reflect.Bool:
+			if e.Bool() {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte('f')
+			}
+		default:
+			return nil, fmt.Errorf("orm: unsupport array element type %s", e.Type())
+		}
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// Scan implements the Scanner interface.
+func (g genericArray) Scan(src interface{}) error {
+	v := reflect.ValueOf(g.a)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("orm: can not scan an array into %T", g.a)
+	}
+	v = v.Elem()
+
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("orm: can not scan %T into an array", src)
+	}
+
+	elems, err := parseArray(data)
+	if err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(elems), len(elems))
+	for i, elem := range elems {
+		if err := StrTo(elem).setTo(slice.Index(i)); err != nil {
+			return err
+		}
+	}
+	v.Set(slice)
+	return nil
+}
+
+// setTo converts the string to the kind of v and stores it.
+func (f StrTo) setTo(v reflect.Value) (err error) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(f))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(string(f), 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(string(f), 10, v.Type().Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var fl float64
+		if fl, err = strconv.ParseFloat(string(f), v.Type().Bits()); err == nil {
+			v.SetFloat(fl)
+		}
+	case reflect.Bool:
+		v.SetBool(f == "t" || f == "true")
+	default:
+		err = fmt.Errorf("orm: unsupport array element type %s", v.Type())
+	}
+	return
+}
+
+// parseArray splits a one dimensional array literal like {a,"b c",d}
+// into its elements.
+func parseArray(data []byte) ([]string, error) {
+	if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' {
+		return nil, fmt.Errorf("orm: wrong array literal `%s`", data)
+	}
+	data = data[1 : len(data)-1]
+
+	elems := make([]string, 0)
+	if len(data) == 0 {
+		return elems, nil
+	}
+
+	for i := 0; i <= len(data); {
+		b := bytes.Buffer{}
+		if i < len(data) && data[i] == '"' {
+			i++
+			for ; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' && i+1 < len(data) {
+					i++
+				}
+				b.WriteByte(data[i])
+			}
+			if i == len(data) {
+				return nil, fmt.Errorf("orm: unterminated array element in `%s`", data)
+			}
+			i++
+		} else {
+			for ; i < len(data) && data[i] != ','; i++ {
+				if data[i] == '{' {
+					return nil, fmt.Errorf("orm: multi dimensional arrays are not supported")
+				}
+				b.WriteByte(data[i])
+			}
+			if b.String() == "NULL" {
+				return nil, fmt.Errorf("orm: can not scan NULL array element")
+			}
+		}
+		elems = append(elems, b.String())
+		i++
+	}
+	return elems, nil
+}
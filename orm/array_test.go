@@ -0,0 +1,88 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArrayValue(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want interface{}
+	}{
+		{[]string{"a", `b "c"`, `d\e`}, `{"a","b \"c\"","d\\e"}`},
+		{[]int64{1, -2, 3}, `{1,-2,3}`},
+		{[]bool{true, false}, `{t,f}`},
+		{[]string{}, `{}`},
+		{[]string(nil), nil},
+	}
+	for _, test := range tests {
+		got, err := Array(test.in).Value()
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%v: expected %v, got %v", test.in, test.want, got)
+		}
+	}
+}
+
+func TestArrayScan(t *testing.T) {
+	var s []string
+	if err := Array(&s).Scan([]byte(`{a,"b \"c\"","d\\e",f g}`)); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", `b "c"`, `d\e`, "f g"}; !reflect.DeepEqual(s, want) {
+		t.Errorf("expected %q, got %q", want, s)
+	}
+
+	var n []int32
+	if err := Array(&n).Scan("{1,2,3}"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{1, 2, 3}; !reflect.DeepEqual(n, want) {
+		t.Errorf("expected %v, got %v", want, n)
+	}
+
+	if err := Array(&n).Scan(nil); err != nil || n != nil {
+		t.Errorf("expected nil slice, got %v (%v)", n, err)
+	}
+	if err := Array(&s).Scan("{a,NULL}"); err == nil {
+		t.Error("expected an error scanning a NULL element")
+	}
+}
+
+func TestArrayField(t *testing.T) {
+	dbmap := registerTestModels(t, PostgresDialect{}, &arrayPost{})
+	mi, _ := modelCache.get("array_post")
+
+	if !mi.fields.GetByName("Tags").array {
+		t.Fatal("Tags should be an array field")
+	}
+	ddl := mi.SqlForCreate(false)
+	for _, col := range []string{`"tags" text[]`, `"scores" bigint[]`} {
+		if !strings.Contains(ddl, col) {
+			t.Errorf("ddl %q missing %q", ddl, col)
+		}
+	}
+
+	bi, err := mi.bindInsert(reflect.ValueOf(&arrayPost{Tags: []string{"x"}}).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, arg := range bi.args {
+		if _, ok := arg.(genericArray); !ok {
+			t.Errorf("expected array bind value, got %T", arg)
+		}
+	}
+
+	criteria := newTestCriteria(dbmap, &arrayPost{})
+	if sql := Restrictions.ArrayOverlap("Tags", []string{"x"}).ToSqlString(criteria, dbmap); sql != "tags && ?" {
+		t.Errorf("unexpected overlap sql %q", sql)
+	}
+	if sql := Restrictions.ArrayContains("Tags", []string{"x"}).ToSqlString(criteria, dbmap); sql != "tags @> ?" {
+		t.Errorf("unexpected contains sql %q", sql)
+	}
+}
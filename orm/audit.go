@@ -0,0 +1,61 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// userKey is the context key of the user ID, see WithUser.
+type userKey struct{}
+
+// WithUser returns a copy of ctx holding the ID of the user acting, which
+// the DbMap of WithContext writes to the auto_user_add and auto_user
+// fields.
+func WithUser(ctx context.Context, id interface{}) context.Context {
+	return context.WithValue(ctx, userKey{}, id)
+}
+
+// UserFromContext returns the user ID held by ctx, see WithUser.
+func UserFromContext(ctx context.Context) (interface{}, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	id := ctx.Value(userKey{})
+	return id, id != nil
+}
+
+// WithContext returns a copy of m bound to ctx, sharing its models and
+// connections.  Its inserts set the auto_user_add and auto_user fields to
+// the user ID of ctx, its updates the auto_user fields, as do those of
+// the transactions it begins.
+//
+// Example:
+//
+//	dbmap.WithContext(orm.WithUser(ctx, user.Id)).Insert(&post)
+func (m *DbMap) WithContext(ctx context.Context) *DbMap {
+	c := m.clone()
+	c.ctx = ctx
+	return c
+}
+
+// setAuditUser sets the auto_user fields of elem, and its auto_user_add
+// fields on insert, to the user ID of the context of m, if any.
+func setAuditUser(m *DbMap, table *modelInfo, elem reflect.Value, insert bool) error {
+	id, ok := UserFromContext(m.ctx)
+	if !ok {
+		return nil
+	}
+	v := reflect.ValueOf(id)
+	for _, fi := range table.fields.columns {
+		if !fi.autoUser && !(insert && fi.autoUserAdd) {
+			continue
+		}
+		f := elem.FieldByIndex(fi.fieldIndex)
+		if !v.Type().ConvertibleTo(f.Type()) || (v.Kind() == reflect.String) != (f.Kind() == reflect.String) {
+			return fmt.Errorf("<orm.WithContext> user ID %T can't be set to field `%s` %s", id, fi.fullName, f.Type())
+		}
+		f.Set(v.Convert(f.Type()))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package orm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestAuditUser(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &auditPost{})
+	defer Database().Set(nil)
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+
+	post := &auditPost{Title: "orm"}
+	if err := dbmap.Insert(post); err != nil {
+		t.Fatal(err)
+	}
+	if post.CreatedBy != 0 || post.UpdatedBy != 0 {
+		t.Errorf("users set without a context user: %+v", post)
+	}
+
+	if err := dbmap.WithContext(WithUser(context.Background(), 7)).Insert(post); err != nil {
+		t.Fatal(err)
+	}
+	if post.CreatedBy != 7 || post.UpdatedBy != 7 {
+		t.Errorf("unexpected users after insert: %+v", post)
+	}
+
+	post.Id = 1
+	if _, err := dbmap.WithContext(WithUser(context.Background(), int64(9))).Update(post); err != nil {
+		t.Fatal(err)
+	}
+	if post.CreatedBy != 7 || post.UpdatedBy != 9 {
+		t.Errorf("unexpected users after update: %+v", post)
+	}
+
+	statements := dbmap.Statements()
+	if len(statements) != 3 {
+		t.Fatalf("expected 3 statements, got %v", statements)
+	}
+	if update := statements[2].Query; strings.Contains(update, "created_by") || !strings.Contains(update, "`updated_by`=?") {
+		t.Errorf("unexpected update %q", update)
+	}
+
+	if err := dbmap.WithContext(WithUser(context.Background(), "bob")).Insert(post); err == nil {
+		t.Error("expected an error setting a string user ID to an integer field")
+	}
+}
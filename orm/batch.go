@@ -0,0 +1,350 @@
+package orm
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Batch queues inserts, updates and deletes, written together by Flush in
+// a single transaction.  The inserts of a model queued one after the
+// other are sent as multi-row inserts of up to Size rows, and of no more
+// bind variables than the database takes, for ETL-like workloads writing
+// many rows.  With MultiStatements, the updates and deletes of a model
+// queued one after the other are sent as a single Exec of up to Size
+// statements.
+//
+// The multi-row inserts don't read back the keys generated by the
+// database, and skip the tree maintenance of Insert.  The elements of the
+// tree models, of the models implementing HasPostInsert or touching their
+// relations, and all of them on Oracle, are inserted one by one.  The
+// updates and deletes of the tree or versioned models, of the models
+// implementing their hooks or touching their relations, are sent one by
+// one.
+//
+// Example:
+//
+//	batch := dbmap.Batch()
+//	for _, line := range lines {
+//		batch.Insert(&Line{Sku: line[0], Qty: line[1]})
+//	}
+//	err := batch.Flush()
+type Batch struct {
+	// Size is the most rows of a multi-row insert, and statements of a
+	// multi-statement send, 500 by default.
+	Size int
+
+	// MultiStatements sends the updates and deletes together.  The driver
+	// must run the statements of an Exec holding several, as
+	// mattn/go-sqlite3, or go-sql-driver/mysql with multiStatements and
+	// interpolateParams set.  It is on by default for SQLite, and ignored
+	// by the dialects binding other than ?.
+	MultiStatements bool
+
+	dbmap *DbMap
+	ops   []batchOp
+}
+
+type batchOp struct {
+	kind planKind
+	ptr  interface{}
+}
+
+// Batch returns an empty batch of writes through m.
+func (m *DbMap) Batch() *Batch {
+	_, sqlite := m.Dialect.(SqliteDialect)
+	return &Batch{Size: 500, MultiStatements: sqlite, dbmap: m}
+}
+
+// Insert queues the insert of each element of list, pointers to models.
+func (b *Batch) Insert(list ...interface{}) {
+	b.queue(insertPlan, list)
+}
+
+// Update queues the update of each element of list, pointers to models.
+func (b *Batch) Update(list ...interface{}) {
+	b.queue(updatePlan, list)
+}
+
+// Delete queues the delete of each element of list, pointers to models.
+func (b *Batch) Delete(list ...interface{}) {
+	b.queue(deletePlan, list)
+}
+
+func (b *Batch) queue(kind planKind, list []interface{}) {
+	for _, ptr := range list {
+		b.ops = append(b.ops, batchOp{kind: kind, ptr: ptr})
+	}
+}
+
+// Len returns the number of writes queued.
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Flush writes the queued elements in a transaction, and empties the
+// batch once it is committed.  The batch is kept when it fails, nothing
+// being written.
+func (b *Batch) Flush() error {
+	if len(b.ops) == 0 {
+		return nil
+	}
+	tx, err := b.dbmap.Begin()
+	if err != nil {
+		return err
+	}
+	if err = b.flush(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	b.ops = b.ops[:0]
+	return nil
+}
+
+func (b *Batch) flush(tx *Transaction) error {
+	m := b.dbmap
+	size := b.Size
+	if size < 1 {
+		size = 1
+	}
+	for i := 0; i < len(b.ops); {
+		op := b.ops[i]
+		table, _, err := m.tableForPointer(op.ptr, false)
+		if err != nil {
+			return err
+		}
+		j := i + 1
+		if b.batchable(table, op) {
+			limit := size
+			if op.kind == insertPlan {
+				if rows := maxBatchRows(m.Dialect, table); rows < limit {
+					limit = rows
+				}
+			}
+			for ; j < len(b.ops) && j-i < limit; j++ {
+				next := b.ops[j]
+				if next.kind != op.kind {
+					break
+				}
+				if t, _, err := m.tableForPointer(next.ptr, false); err != nil || t != table || !b.batchable(table, next) {
+					break
+				}
+			}
+		}
+		switch {
+		case j == i+1:
+			err = b.write(tx, op)
+		case op.kind == insertPlan:
+			err = batchInsert(m, tx, table, b.ops[i:j])
+		default:
+			err = batchExec(m, tx, b.ops[i:j])
+		}
+		if err != nil {
+			return err
+		}
+		i = j
+	}
+	return nil
+}
+
+// write sends the write of op on its own.
+func (b *Batch) write(tx *Transaction, op batchOp) (err error) {
+	switch op.kind {
+	case insertPlan:
+		err = insert(b.dbmap, tx, op.ptr)
+	case updatePlan:
+		_, err = update(b.dbmap, tx, nil, op.ptr)
+	case deletePlan:
+		_, err = deleteRows(b.dbmap, tx, op.ptr)
+	}
+	return err
+}
+
+// batchable reports whether op, of an element of table, can be sent with
+// the writes of the same kind next to it.
+func (b *Batch) batchable(table *modelInfo, op batchOp) bool {
+	m := b.dbmap
+	if op.kind == insertPlan {
+		return batchInsertable(m, table, op.ptr)
+	}
+	if !b.MultiStatements || m.Dialect.BindVar(0) != "?" {
+		return false
+	}
+	if table.version != nil || table.treeParent != nil || touches(table) {
+		return false
+	}
+	if op.kind == updatePlan {
+		_, pre := op.ptr.(HasPreUpdate)
+		_, post := op.ptr.(HasPostUpdate)
+		return !pre && !post
+	}
+	_, pre := op.ptr.(HasPreDelete)
+	_, post := op.ptr.(HasPostDelete)
+	return !pre && !post
+}
+
+// batchInsertable reports whether ptr, an element of table, can be part of
+// a multi-row insert.
+func batchInsertable(m *DbMap, table *modelInfo, ptr interface{}) bool {
+	if _, ok := m.Dialect.(OracleDialect); ok {
+		return false
+	}
+	if _, ok := ptr.(HasPostInsert); ok || table.treeParent != nil {
+		return false
+	}
+	return !touches(table)
+}
+
+// touches reports whether table touches one of its relations on writes.
+func touches(table *modelInfo) bool {
+	for _, fi := range table.fields.fieldsRel {
+		if fi.touch != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// maxBatchRows returns the most rows of a multi-row insert into table
+// binding no more variables than the database takes.
+func maxBatchRows(dialect Dialect, table *modelInfo) int {
+	var max int
+	switch dialect.(type) {
+	case SqliteDialect:
+		max = 999
+	case SqlServerDialect:
+		max = 2100
+	case PostgresDialect, MySQLDialect:
+		max = 65535
+	default:
+		return math.MaxInt32
+	}
+	n := 0
+	for _, col := range table.insertPlan().insertCols {
+		if !col.auto && col.DefaultValue == "" {
+			n++
+		}
+	}
+	if n == 0 || n > max {
+		return math.MaxInt32
+	}
+	return max / n
+}
+
+// batchInsert inserts the elements of ops, all of table, with a single
+// multi-row insert.
+func batchInsert(m *DbMap, exec SqlExecutor, table *modelInfo, ops []batchOp) error {
+	if err := table.writable(); err != nil {
+		return err
+	}
+	elems := make([]reflect.Value, len(ops))
+	var args []interface{}
+	for i, op := range ops {
+		elems[i] = reflect.ValueOf(op.ptr).Elem()
+		if err := prepareInsert(m, exec, table, elems[i]); err != nil {
+			return err
+		}
+		bi, err := table.bindInsert(elems[i])
+		if err != nil {
+			return err
+		}
+		args = append(args, bi.args...)
+	}
+	if _, err := exec.Exec(sqlForBatchInsert(m.Dialect, table, len(ops)), args...); err != nil {
+		return err
+	}
+	for _, elem := range elems {
+		trackChanges(table, elem)
+	}
+	return nil
+}
+
+// batchExec sends the updates or deletes of ops, all of the same kind and
+// model, as a single Exec of their statements.
+func batchExec(m *DbMap, tx *Transaction, ops []batchOp) error {
+	rec := &batchRecorder{SqlExecutor: tx}
+	for _, op := range ops {
+		var err error
+		if op.kind == updatePlan {
+			_, err = update(m, rec, nil, op.ptr)
+		} else {
+			_, err = deleteRows(m, rec, op.ptr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if rec.query.Len() == 0 {
+		return nil
+	}
+	_, err := tx.Exec(rec.query.String(), rec.args...)
+	return err
+}
+
+// batchRecorder is the executor of the writes of batchExec, adding the
+// statements sent through Exec to query, and reporting the row of each
+// as affected.
+type batchRecorder struct {
+	SqlExecutor
+	query bytes.Buffer
+	args  []interface{}
+}
+
+func (r *batchRecorder) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if r.query.Len() > 0 {
+		r.query.WriteString(" ")
+	}
+	r.query.WriteString(query)
+	if !strings.HasSuffix(query, ";") {
+		r.query.WriteString(";")
+	}
+	r.args = append(r.args, args...)
+	return dryRunResult{}, nil
+}
+
+// sqlForBatchInsert returns the insert of rows rows of table, the columns
+// and arguments of each row being those of its insert plan, in the same
+// order.
+func sqlForBatchInsert(dialect Dialect, table *modelInfo, rows int) string {
+	cols := table.insertPlan().insertCols
+
+	s := bytes.Buffer{}
+	s.WriteString(fmt.Sprintf("insert into %s (", dialect.QuotedTableForQuery(table.schemaName, table.table)))
+	for i, col := range cols {
+		if i > 0 {
+			s.WriteString(",")
+		}
+		s.WriteString(dialect.QuoteField(col.column))
+	}
+	s.WriteString(") values ")
+	n := 0
+	for r := 0; r < rows; r++ {
+		if r > 0 {
+			s.WriteString(", ")
+		}
+		s.WriteString("(")
+		for i, col := range cols {
+			if i > 0 {
+				s.WriteString(",")
+			}
+			switch {
+			case col.auto:
+				s.WriteString(dialect.AutoIncrBindValue())
+			case col.DefaultValue != "":
+				s.WriteString(col.DefaultValue)
+			default:
+				s.WriteString(sqlForBindVar(dialect, col, dialect.BindVar(n)))
+				n++
+			}
+		}
+		s.WriteString(")")
+	}
+	s.WriteString(dialect.QuerySuffix())
+	return s.String()
+}
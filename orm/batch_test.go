@@ -0,0 +1,128 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// batchDriver records the statements executed, failing those containing
+// batchFail when set.
+var batchDriver = &fakeDriver{
+	exec: func(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
+		if batchFail != "" && strings.Contains(query, batchFail) {
+			return nil, errors.New("batch failure")
+		}
+		batchQueries = append(batchQueries, query)
+		return fakeResult{}, nil
+	},
+}
+
+var (
+	batchQueries []string
+	batchFail    string
+)
+
+func TestBatch(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	defer Database().Set(nil)
+	dbmap.Db = batchDriver.open(t)
+
+	batch := dbmap.Batch()
+	batch.Size = 2
+	batch.Insert(&searchArticle{Body: "a"}, &searchArticle{Body: "b"}, &searchArticle{Body: "c"})
+	batch.Update(&searchArticle{Id: 1, Body: "d"})
+	batch.Insert(&searchArticle{Body: "e"}, &searchArticle{Body: "f"})
+	if batch.Len() != 6 {
+		t.Fatalf("expected 6 writes queued, got %d", batch.Len())
+	}
+
+	batchFail = "update"
+	if err := batch.Flush(); err == nil || batch.Len() != 6 {
+		t.Errorf("expected the batch kept on failure, got %v and %d writes", err, batch.Len())
+	}
+	batchFail, batchQueries = "", nil
+	if err := batch.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if batch.Len() != 0 {
+		t.Errorf("expected the batch emptied, got %d writes", batch.Len())
+	}
+
+	// inserts of 2, 1 and 2 rows around the update
+	expected := []int{2, 1, 0, 2}
+	if len(batchQueries) != len(expected) {
+		t.Fatalf("expected %d statements, got %q", len(expected), batchQueries)
+	}
+	for i, q := range batchQueries {
+		if expected[i] == 0 {
+			if !strings.HasPrefix(q, "update") {
+				t.Errorf("statement %d: expected an update, got %q", i, q)
+			}
+		} else if !strings.HasPrefix(q, "insert into `search_article`") || strings.Count(q, "?") != expected[i] || strings.Count(q, "null") != expected[i] {
+			t.Errorf("statement %d: expected an insert of %d rows, got %q", i, expected[i], q)
+		}
+	}
+}
+
+func TestBatchMultiStatements(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+	dbmap.Db = batchDriver.open(t)
+
+	batch := dbmap.Batch()
+	if !batch.MultiStatements {
+		t.Error("expected multi-statement sends on by default for SQLite")
+	}
+	batch.Size = 2
+	batch.Update(&searchArticle{Id: 1, Body: "a"}, &searchArticle{Id: 2, Body: "b"}, &searchArticle{Id: 3, Body: "c"})
+	batch.Delete(&searchArticle{Id: 4}, &searchArticle{Id: 5})
+	batchQueries = nil
+	if err := batch.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// updates of 2 and 1 rows, then the deletes
+	expected := []string{"update", "update", "delete"}
+	counts := []int{2, 1, 2}
+	if len(batchQueries) != len(expected) {
+		t.Fatalf("expected %d sends, got %q", len(expected), batchQueries)
+	}
+	for i, q := range batchQueries {
+		if !strings.HasPrefix(q, expected[i]) || strings.Count(q, expected[i]) != counts[i] || strings.Count(q, ";") != counts[i] {
+			t.Errorf("send %d: expected %d %s statements, got %q", i, counts[i], expected[i], q)
+		}
+	}
+
+	batch.MultiStatements = false
+	batch.Update(&searchArticle{Id: 1, Body: "a"}, &searchArticle{Id: 2, Body: "b"})
+	batchQueries = nil
+	if err := batch.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(batchQueries) != 2 {
+		t.Errorf("expected the updates sent one by one, got %q", batchQueries)
+	}
+}
+
+func TestBatchBindVarLimit(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+	dbmap.Db = batchDriver.open(t)
+
+	batch := dbmap.Batch()
+	batch.Size = 2000
+	for i := 0; i < 1200; i++ {
+		batch.Insert(&searchArticle{Body: "a"})
+	}
+	batchQueries = nil
+	if err := batch.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// SQLite takes 999 bind variables, one per row
+	if len(batchQueries) != 2 || strings.Count(batchQueries[0], "?") != 999 || strings.Count(batchQueries[1], "?") != 201 {
+		t.Errorf("expected inserts of 999 and 201 rows, got %d statements", len(batchQueries))
+	}
+}
@@ -0,0 +1,160 @@
+package orm
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+)
+
+// blobChunkSize is the size of the chunks GetBlob reads, so that a blob
+// read is never held in memory as a whole.
+const blobChunkSize = 1 << 20
+
+// SetBlob writes the content of r to the []byte field of model, which must
+// have a primary key value, with a single update in a transaction.  The
+// content is bound whole, appending it chunk by chunk would have the
+// database rewrite the column for each chunk.  It returns ErrNoRows when no
+// row has the key of model.
+func (m *DbMap) SetBlob(model interface{}, field string, r io.Reader) error {
+	tx, err := m.Begin()
+	if err != nil {
+		return err
+	}
+	if err = setBlob(m, tx, model, field, r); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetBlob returns a reader of the []byte field of model, which must have a
+// primary key value, reading the column chunk by chunk.  The field itself
+// is left untouched.
+func (m *DbMap) GetBlob(model interface{}, field string) (io.ReadCloser, error) {
+	return getBlob(m, m, model, field)
+}
+
+// SetBlob has the same behavior as DbMap.SetBlob(), but runs in a
+// transaction.
+func (t *Transaction) SetBlob(model interface{}, field string, r io.Reader) error {
+	return setBlob(t.dbmap, t, model, field, r)
+}
+
+// GetBlob has the same behavior as DbMap.GetBlob(), but runs in a
+// transaction.
+func (t *Transaction) GetBlob(model interface{}, field string) (io.ReadCloser, error) {
+	return getBlob(t.dbmap, t, model, field)
+}
+
+// blobColumn returns the table and the column of the []byte field of
+// model, along with its primary key.
+func blobColumn(m *DbMap, model interface{}, field string) (*modelInfo, *fieldInfo, interface{}, error) {
+	table, elem, err := m.tableForPointer(model, true)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	fi := table.fields.GetByName(field)
+	if fi == nil || !fi.dbcol {
+		return nil, nil, nil, &FieldError{TypeName: table.fullName, Field: field}
+	}
+	if fi.sf.Type != reflect.TypeOf([]byte(nil)) {
+		return nil, nil, nil, fmt.Errorf("<orm.Blob> field `%s` is not a []byte", fi.fullName)
+	}
+	_, pk, exist := getExistPk(table, elem)
+	if !exist {
+		return nil, nil, nil, ErrMissingPK
+	}
+	return table, fi, pk, nil
+}
+
+func setBlob(m *DbMap, exec SqlExecutor, model interface{}, field string, r io.Reader) error {
+	table, fi, pk, err := blobColumn(m, model, field)
+	if err != nil {
+		return err
+	}
+	if err = table.writable(); err != nil {
+		return err
+	}
+	dialect := m.Dialect
+	quotedTable := dialect.QuotedTableForQuery(table.schemaName, table.table)
+	pkColumn := dialect.QuoteField(table.fields.GetOnePrimaryKey().column)
+
+	// the rows affected by the update don't tell a missing row, MySQL
+	// reporting none when the value doesn't change
+	count, err := exec.SelectInt(fmt.Sprintf("select count(*) from %s where %s=%s", quotedTable, pkColumn, dialect.BindVar(0)), pk)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNoRows
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	_, err = exec.Exec(fmt.Sprintf("update %s set %s=%s where %s=%s", quotedTable, dialect.QuoteField(fi.column), dialect.BindVar(0), pkColumn, dialect.BindVar(1)), data, pk)
+	return err
+}
+
+func getBlob(m *DbMap, exec SqlExecutor, model interface{}, field string) (io.ReadCloser, error) {
+	table, fi, pk, err := blobColumn(m, model, field)
+	if err != nil {
+		return nil, err
+	}
+	dialect := m.Dialect
+	query := fmt.Sprintf("select %s from %s where %s=%s",
+		sqlForBlobSlice(dialect, dialect.QuoteField(fi.column), dialect.BindVar(0), dialect.BindVar(1)),
+		dialect.QuotedTableForQuery(table.schemaName, table.table),
+		dialect.QuoteField(table.fields.GetOnePrimaryKey().column),
+		dialect.BindVar(2))
+	return &blobReader{exec: exec, query: query, pk: pk}, nil
+}
+
+// blobReader reads a blob column by slices of blobChunkSize bytes.
+type blobReader struct {
+	exec   SqlExecutor
+	query  string
+	pk     interface{}
+	offset int64 // of the next slice, from 0
+	buf    []byte
+	eof    bool
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	if len(b.buf) == 0 {
+		if b.eof {
+			return 0, io.EOF
+		}
+		var chunk []byte
+		if err := scanRow(b.exec, b.query, []interface{}{b.offset + 1, blobChunkSize, b.pk}, &chunk); err != nil {
+			return 0, err
+		}
+		b.offset += int64(len(chunk))
+		b.buf = chunk
+		b.eof = len(chunk) < blobChunkSize
+		if len(chunk) == 0 {
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+func (b *blobReader) Close() error {
+	b.buf, b.eof = nil, true
+	return nil
+}
+
+// sqlForBlobSlice returns the expression of the bytes of column starting
+// at the offset, from 1, bound to offsetBindVar.
+func sqlForBlobSlice(dialect Dialect, column, offsetBindVar, lengthBindVar string) string {
+	if bd, ok := dialect.(BlobDialect); ok {
+		return bd.BlobSlice(column, offsetBindVar, lengthBindVar)
+	}
+	return fmt.Sprintf("substring(%s, %s, %s)", column, offsetBindVar, lengthBindVar)
+}
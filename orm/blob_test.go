@@ -0,0 +1,109 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// blobDriver holds a single blob, written and sliced by the queries of
+// SetBlob and GetBlob.
+var blobDriver = &fakeDriver{
+	exec: func(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
+		data := args[0].([]byte)
+		blobWrites++
+		// like MySQL, the rows left unchanged aren't affected
+		if blobMissing || blobData != nil && bytes.Equal(data, blobData) {
+			return driver.RowsAffected(0), nil
+		}
+		blobData = data
+		return driver.RowsAffected(1), nil
+	},
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		if strings.HasPrefix(query, "select count(*)") {
+			if blobMissing {
+				return blobRows(int64(0)), nil
+			}
+			return blobRows(int64(1)), nil
+		}
+		offset, length := int(args[0].(int64))-1, int(args[1].(int64))
+		if offset > len(blobData) {
+			offset = len(blobData)
+		}
+		end := offset + length
+		if end > len(blobData) {
+			end = len(blobData)
+		}
+		return blobRows(blobData[offset:end]), nil
+	},
+}
+
+var (
+	blobData    []byte
+	blobWrites  int
+	blobMissing bool // the row of the blob doesn't exist
+)
+
+func blobRows(value driver.Value) driver.Rows {
+	return &fakeRows{columns: []string{"data"}, values: [][]driver.Value{{value}}}
+}
+
+func TestBlob(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &blobFile{})
+	defer Database().Set(nil)
+	dbmap.Db = blobDriver.open(t)
+
+	content := bytes.Repeat([]byte("0123456789"), blobChunkSize/4)
+	file := &blobFile{Id: 1}
+	if err := dbmap.SetBlob(file, "Data", bytes.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	if blobWrites != 1 || !bytes.Equal(blobData, content) {
+		t.Errorf("expected the blob written at once, got %d writes of %d bytes", blobWrites, len(blobData))
+	}
+
+	r, err := dbmap.GetBlob(file, "Data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got, err := ioutil.ReadAll(r); err != nil || !bytes.Equal(got, content) {
+		t.Errorf("expected the blob read back, got %d bytes (%v)", len(got), err)
+	}
+	if file.Data != nil {
+		t.Error("GetBlob should not fill the field")
+	}
+
+	if _, err = dbmap.GetBlob(file, "Name"); err == nil {
+		t.Error("expected an error reading a string field as a blob")
+	}
+	if err = dbmap.SetBlob(file, "Body", bytes.NewReader(nil)); err == nil {
+		t.Error("expected an error writing an unknown field")
+	}
+
+	// unchanged, and so not affected
+	blobData = []byte{}
+	if err = dbmap.SetBlob(file, "Data", bytes.NewReader(nil)); err != nil {
+		t.Errorf("expected an empty blob written over an empty one, got %v", err)
+	}
+
+	blobMissing = true
+	err = dbmap.SetBlob(&blobFile{Id: 2}, "Data", bytes.NewReader(content))
+	blobMissing = false
+	if err != ErrNoRows {
+		t.Errorf("expected ErrNoRows writing the blob of a missing row, got %v", err)
+	}
+
+	table, err := dbmap.TableFor(reflect.TypeOf(blobFile{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table.readOnly = true
+	if _, ok := dbmap.SetBlob(file, "Data", bytes.NewReader(content)).(*ReadOnlyError); !ok {
+		t.Error("expected a ReadOnlyError writing the blob of a read-only model")
+	}
+}
@@ -0,0 +1,141 @@
+package orm
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// GormTags, when set, makes the fields without an `orm` tag read their
+// `gorm` tag, so that the models of a gorm codebase can be registered
+// without retagging them.  It must be set before the models are
+// registered.  The classic `orm` tags of beego are read as is.
+//
+// The gorm settings translated are:
+//
+//	"-"                        -
+//	column:x                   column(x)
+//	primaryKey                 pk, and auto on integer fields
+//	autoIncrement              auto
+//	unique, uniqueIndex        unique
+//	index                      index
+//	size:n                     size(n)
+//	precision:p;scale:s        digits(p);decimals(s)
+//	type:text, json, date...   type(text), type(json), type(date)...
+//	type:varchar(n)            size(n)
+//	type:decimal(p,s)          digits(p);decimals(s)
+//	default:x                  default(x)
+//	autoCreateTime             auto_now_add
+//	autoUpdateTime             auto_now
+//	embedded                   embedded
+//	embeddedPrefix:x           prefix(x)
+//
+// The other settings, the relations, "not null" and the other
+// constraints among them, are ignored.
+var GormTags = false
+
+const gormStructTagName = "gorm"
+
+var (
+	gormVarchar = regexp.MustCompile(`^(?:var)?char\((\d+)\)$`)
+	gormDecimal = regexp.MustCompile(`^(?:decimal|numeric)\((\d+),\s*(\d+)\)$`)
+)
+
+// fieldTag returns the `orm` tag of sf, translated from its `gorm` tag
+// when it has none and GormTags is set.
+func fieldTag(sf reflect.StructField) string {
+	tag, ok := sf.Tag.Lookup(defaultStructTagName)
+	if ok || !GormTags {
+		return tag
+	}
+	if tag, ok = sf.Tag.Lookup(gormStructTagName); ok {
+		return gormTag(tag, sf.Type)
+	}
+	return ""
+}
+
+// gormTag translates the gorm tag of a field of type typ, see GormTags.
+func gormTag(data string, typ reflect.Type) string {
+	var parts []string
+	var pk, auto, noAuto bool
+	for _, v := range strings.Split(data, defaultStructTagDelim) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		name, value := v, ""
+		if i := strings.Index(v, ":"); i >= 0 {
+			name, value = v[:i], strings.TrimSpace(v[i+1:])
+		}
+		// gorm ignores the case and underscores of the setting names
+		switch strings.ToLower(strings.Replace(name, "_", "", -1)) {
+		case "-":
+			return "-"
+		case "column":
+			parts = append(parts, "column("+value+")")
+		case "primarykey":
+			pk = true
+		case "autoincrement":
+			auto, noAuto = value != "false", value == "false"
+		case "unique", "uniqueindex":
+			parts = append(parts, "unique")
+		case "index":
+			parts = append(parts, "index")
+		case "size":
+			parts = append(parts, "size("+value+")")
+		case "precision":
+			parts = append(parts, "digits("+value+")")
+		case "scale":
+			parts = append(parts, "decimals("+value+")")
+		case "type":
+			t := strings.ToLower(value)
+			if m := gormVarchar.FindStringSubmatch(t); m != nil {
+				parts = append(parts, "size("+m[1]+")")
+			} else if m := gormDecimal.FindStringSubmatch(t); m != nil {
+				parts = append(parts, "digits("+m[1]+")", "decimals("+m[2]+")")
+			} else if supportedType(t) {
+				parts = append(parts, "type("+t+")")
+			} else {
+				DebugLog.Debug("unsupport gorm type %s", value)
+			}
+		case "default":
+			parts = append(parts, "default("+value+")")
+		case "autocreatetime":
+			parts = append(parts, "auto_now_add")
+		case "autoupdatetime":
+			parts = append(parts, "auto_now")
+		case "embedded":
+			parts = append(parts, "embedded")
+		case "embeddedprefix":
+			parts = append(parts, "prefix("+value+")")
+		default:
+			DebugLog.Debug("unsupport gorm tag %s", v)
+		}
+	}
+	if pk {
+		parts = append(parts, "pk")
+	}
+	// gorm increments the integer primary keys by default
+	if auto || pk && !noAuto && isIntegerKind(typ) {
+		parts = append(parts, "auto")
+	}
+	return strings.Join(parts, defaultStructTagDelim)
+}
+
+// supportedType reports whether t is a value of the type tag.
+func supportedType(t string) bool {
+	switch t {
+	case "text", "json", "jsonb", "date", "time", "array", "interval":
+		return true
+	}
+	return false
+}
+
+func isIntegerKind(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
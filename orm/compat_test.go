@@ -0,0 +1,51 @@
+package orm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGormTags(t *testing.T) {
+	GormTags = true
+	defer func() { GormTags = false }()
+	registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &gormProduct{})
+	defer Database().Set(nil)
+
+	mi, _ := modelCache.get("gorm_product")
+	ddl := mi.SqlForCreate(false)
+	for _, col := range []string{
+		"`id` int unsigned not null primary key auto_increment",
+		"`sku` varchar(40) unique",
+		"`name` varchar(100)",
+		"`price` numeric(10, 2)",
+		"`qty` int",
+		"`audit_created_at` datetime",
+	} {
+		if !strings.Contains(ddl, col) {
+			t.Errorf("ddl %q missing %q", ddl, col)
+		}
+	}
+	if strings.Contains(ddl, "secret") {
+		t.Errorf("field tagged - mapped by %q", ddl)
+	}
+	if fi := mi.fields.GetByName("Notes"); fi == nil || fi.fieldType != TypeTextField {
+		t.Error("expected Notes a text field")
+	}
+	if fi := mi.fields.GetByName("Name"); fi == nil || !fi.index {
+		t.Error("expected Name indexed")
+	}
+	if fi := mi.fields.GetByName("Stamps.UpdatedAt"); fi == nil || !fi.autoNow {
+		t.Error("expected Stamps.UpdatedAt set on update")
+	}
+
+	for tag, want := range map[string]string{
+		"PRIMARY_KEY;AUTO_INCREMENT:false":  "pk",
+		"type:decimal(8, 3)":                "digits(8);decimals(3)",
+		"-:all":                             "-",
+		"default:'draft';foreignKey:PostID": "default('draft')",
+	} {
+		if got := gormTag(tag, mi.fields.GetByName("ID").sf.Type); got != want {
+			t.Errorf("gormTag(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
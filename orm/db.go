@@ -13,6 +13,7 @@ package orm
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -38,10 +39,72 @@ type DbMap struct {
 
 	TypeConverter TypeConverter
 
+	// CheckUnique makes Insert and Update query for rows clashing with
+	// unique fields first, and fail with a *UniqueError.
+	CheckUnique bool
+
+	// NamingStrategy names the models registered with this DbMap, nil
+	// means DefaultNaming.
+	NamingStrategy NamingStrategy
+
+	// TablePrefix and TableSuffix are added to the table names of the
+	// models, many-to-many and closure tables included, so the tables of
+	// several applications can share a database.  They apply to the
+	// models registered with this DbMap, and to those registered with
+	// RegisterModel when it's the Database() on BootStrap.
+	TablePrefix string
+	TableSuffix string
+
 	tables        []*modelInfo
 	tablesDynamic map[string]*modelInfo // tables that use same go-struct and different db table names
 	logger        GorpLogger
 	logPrefix     string
+
+	// QueryLogger, when set, receives every statement run through this
+	// DbMap with its arguments, duration and error.
+	QueryLogger Logger
+
+	// SlowQueryThreshold reports statements taking at least this long to
+	// QueryLogger at LevelWarn.  Zero disables slow query reporting.
+	SlowQueryThreshold time.Duration
+
+	// QueryLogLevel is the least severe level of the statements reported
+	// to QueryLogger, the zero LevelDebug reporting them all.
+	QueryLogLevel LogLevel
+
+	// InListLimit is the number of values from which the in lists of
+	// criteria are bound as one array, on dialects with arrays, or else
+	// joined from a temporary table.  Zero means DefaultInListLimit.
+	InListLimit int
+
+	// QueryTimeout cancels the statements still running after it, so a
+	// runaway query can't hold a request forever.  Zero means no timeout.
+	// Criteria.Timeout overrides it for the statements of a criteria.
+	QueryTimeout time.Duration
+
+	// RetryPolicy, when set, retries the statements failing on a deadlock
+	// or a serialization failure, see RunInTransaction for transactions.
+	RetryPolicy *RetryPolicy
+
+	// CriteriaCache, when set, caches the select statements of the
+	// criteria run through this DbMap, see NewCriteriaCache.
+	CriteriaCache *CriteriaCache
+
+	queryHooks []QueryHook
+	rewriters  []QueryRewriter
+	mapper     func(string) string // set by SetMapperFunc
+
+	ctx      context.Context // set by WithContext
+	unscoped bool            // set by Unscoped
+
+	dryRun *dryRunLog // nil unless in dry-run mode
+}
+
+// affix returns the table prefix and suffix of m.
+func (m *DbMap) affix() tableAffix {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return tableAffix{prefix: m.TablePrefix, suffix: m.TableSuffix}
 }
 
 func (m *DbMap) dynamicTableAdd(tableName string, tbl *modelInfo) {
@@ -88,7 +151,96 @@ func (m *DbMap) CreateIndex() error {
 		}
 	}
 
-	return err
+	if err != nil {
+		return err
+	}
+	if err = m.createFieldIndexes(dialect); err != nil {
+		return err
+	}
+	return m.createFullTextIndexes()
+}
+
+// createFieldIndexes creates an index for every registered field tagged
+// `index`, named by the naming strategy of its model, and the composite
+// indexes of the registered models not added to m.
+func (m *DbMap) createFieldIndexes(dialect reflect.Type) error {
+	added := make(map[*modelInfo]bool, len(m.tables))
+	for _, table := range m.tables {
+		added[table] = true
+	}
+	for _, table := range modelCache.allOrdered() {
+		for _, col := range table.fields.fieldsDB {
+			if !col.index {
+				continue
+			}
+			columns := []string{col.column}
+			index := &IndexMap{IndexName: table.namingStrategy().IndexName(table.table, columns), columns: columns}
+			if err := m.createIndexImpl(dialect, table, index); err != nil {
+				return err
+			}
+		}
+		if added[table] {
+			continue
+		}
+		for _, index := range table.indexes {
+			if err := m.createIndexImpl(dialect, table, index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createFullTextIndexes creates the dialect specific full-text index for
+// every registered field tagged `fulltext`. Dialects without native
+// full-text search are skipped.
+func (m *DbMap) createFullTextIndexes() error {
+	fts, ok := m.Dialect.(FullTextSearcher)
+	if !ok {
+		return nil
+	}
+	for _, table := range modelCache.allOrdered() {
+		for _, col := range table.fields.fieldsDB {
+			if !col.fulltext {
+				continue
+			}
+			name := fmt.Sprintf("%s_%s_fts", table.table, col.column)
+			if _, err := m.Exec(fts.FullTextIndex(name, table.schemaName, table.table, col.column)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CreateForeignKeys adds the foreign key constraints of the rel(fk) and
+// rel(one) fields of the registered models, named by the FKName of their
+// naming strategy.  Call it once all the tables are created.  Sqlite
+// can't add constraints to existing tables, so it does nothing there.
+func (m *DbMap) CreateForeignKeys() error {
+	if _, ok := m.Dialect.(SqliteDialect); ok {
+		return nil
+	}
+	for _, table := range modelCache.allOrdered() {
+		for _, col := range table.fields.fieldsDB {
+			if (col.fieldType != RelForeignKey && col.fieldType != RelOneToOne) || col.relModelInfo == nil {
+				continue
+			}
+			ref := col.relModelInfo
+			name := table.namingStrategy().FKName(table.table, col.column, ref.table)
+			s := fmt.Sprintf("alter table %s add constraint %s foreign key (%s) references %s (%s)%s",
+				m.Dialect.QuotedTableForQuery(table.schemaName, table.table),
+				m.Dialect.QuoteField(name),
+				m.Dialect.QuoteField(col.column),
+				m.Dialect.QuotedTableForQuery(ref.schemaName, ref.table),
+				m.Dialect.QuoteField(ref.fields.GetOnePrimaryKey().column),
+				m.Dialect.QuerySuffix())
+			if _, err := m.Exec(s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (m *DbMap) createIndexImpl(dialect reflect.Type,
@@ -207,7 +359,7 @@ func (m *DbMap) RegisterModelWithSchema(i interface{}, schema string) {
 	}
 
 	//t := reflect.TypeOf(i)
-	name := getTableName(val)
+	name := m.affix().apply(getTableName(val, m.naming()))
 
 	// check if we have a table for this type already
 	// if so, update the name and return the existing pointer
@@ -220,7 +372,15 @@ func (m *DbMap) RegisterModelWithSchema(i interface{}, schema string) {
 	}
 
 	//keys := getTableKeys(val)
-	tmap := newModelInfo(val)
+	tmap, err := newModelInfo(val, m.naming())
+	if err != nil {
+		panic(err)
+	}
+	tmap.gotype = typ
+	tmap.table = name
+	tmap.affix = m.affix()
+	tmap.schemaName = schema
+	tmap.engine = getTableEngine(val)
 	m.tables = append(m.tables, tmap)
 
 }
@@ -248,7 +408,10 @@ func (m *DbMap) AddTableDynamic(inp DynamicTable, schema string) *modelInfo {
 	}
 
 	//tmap := &modelInfo{gotype: t, TableName: name, SchemaName: schema}
-	tmap := newModelInfo(val)
+	tmap, err := newModelInfo(val, m.naming())
+	if err != nil {
+		panic(err)
+	}
 	tmap.table = name
 	tmap.schemaName = schema
 
@@ -563,20 +726,23 @@ func (m *DbMap) Update(list ...interface{}) (int64, error) {
 	return update(m, m, nil, list...)
 }
 
-// UpdateColumns runs a SQL UPDATE statement for each element in list.  List
-// items must be pointers.
+// UpdateColumns runs a SQL UPDATE statement for model, which must be a
+// pointer, setting only the columns of the fields named.
 //
-// Only the columns accepted by filter are included in the UPDATE.
+// Example:
 //
-// The hook functions PreUpdate() and/or PostUpdate() will be executed
-// before/after the UPDATE statement if the interface defines them.
+//	dbmap.UpdateColumns(&user, "Name", "Status")
+//
+// The version and auto_user columns are set too.  The hook functions
+// PreUpdate() and/or PostUpdate() will be executed before/after the
+// UPDATE statement if the interface defines them.
 //
 // Returns the number of rows updated.
 //
-// Returns an error if SetKeys has not been called on the modelInfo
-// Panics if any interface in the list has not been registered with AddTable
-func (m *DbMap) UpdateColumns(filter ColumnFilter, list ...interface{}) (int64, error) {
-	return update(m, m, filter, list...)
+// Returns an error if no field is named, or a *FieldError if a field is
+// unknown.
+func (m *DbMap) UpdateColumns(model interface{}, fields ...string) (int64, error) {
+	return updateColumns(m, m, model, fields)
 }
 
 // Delete runs a SQL DELETE statement for each element in list.  List
@@ -590,7 +756,7 @@ func (m *DbMap) UpdateColumns(filter ColumnFilter, list ...interface{}) (int64,
 // Returns an error if SetKeys has not been called on the modelInfo
 // Panics if any interface in the list has not been registered with AddTable
 func (m *DbMap) Delete(list ...interface{}) (int64, error) {
-	return delete(m, m, list...)
+	return deleteRows(m, m, list...)
 }
 
 // Get runs a SQL SELECT to fetch a single row from the table based on the
@@ -604,9 +770,11 @@ func (m *DbMap) Delete(list ...interface{}) (int64, error) {
 // The hook function PostGet() will be executed after the SELECT
 // statement if the interface defines them.
 //
-// Returns a pointer to a struct that matches or nil if no row is found.
+// Returns a pointer to a struct that matches, or ErrNoRows if no row is
+// found.
 //
-// Returns an error if SetKeys has not been called on the modelInfo
+// Returns ErrMissingPK if the model has no primary key, or if keys
+// doesn't hold a value for each of its columns
 // Panics if any interface in the list has not been registered with AddTable
 func (m *DbMap) Get(i interface{}, keys ...interface{}) (interface{}, error) {
 	return get(m, m, i, keys...)
@@ -635,14 +803,34 @@ func (m *DbMap) Select(i interface{}, query string, args ...interface{}) ([]inte
 	return hookedselect(m, m, i, query, args...)
 }
 
+// CreateCriteria returns a criteria on the model of ptrStructOrTableName,
+// run through m, as Transaction.CreateCriteria does in a transaction.
+// Panics if the model has not been registered.
+func (m *DbMap) CreateCriteria(ptrStructOrTableName interface{}) Criteria {
+	criteria := criteriaFor(m, ptrStructOrTableName)
+	if criteria == nil {
+		panic(fmt.Errorf("<DbMap.CreateCriteria> table name: `%s` not exists", ptrStructOrTableName))
+	}
+	return criteria
+}
+
 // Exec runs an arbitrary SQL statement.  args represent the bind parameters.
 // This is equivalent to running:  Exec() using database/sql
-func (m *DbMap) Exec(query string, args ...interface{}) (sql.Result, error) {
-	if m.logger != nil {
-		now := time.Now()
-		defer m.trace(now, query, args...)
+func (m *DbMap) Exec(query string, args ...interface{}) (res sql.Result, err error) {
+	ctx := m.baseContext()
+	if m.hooked() {
+		e := m.beforeQuery(ctx, query, args)
+		defer m.afterQuery(e, &err)
+		ctx, query, args = e.Ctx, e.Query, e.Args
+	}
+	if m.tracing() {
+		defer m.trace(time.Now(), query, args, &err)
 	}
-	return exec(m, query, args...)
+	err = m.retry(func() (err error) {
+		res, err = exec(ctx, m, query, args...)
+		return err
+	})
+	return res, err
 }
 
 // SelectInt is a convenience wrapper around the gorp.SelectInt function
@@ -681,10 +869,9 @@ func (m *DbMap) SelectOne(holder interface{}, query string, args ...interface{})
 }
 
 // Begin starts a gorp Transaction
-func (m *DbMap) Begin() (*Transaction, error) {
-	if m.logger != nil {
-		now := time.Now()
-		defer m.trace(now, "begin;")
+func (m *DbMap) Begin() (t *Transaction, err error) {
+	if m.tracing() {
+		defer m.trace(time.Now(), "begin;", nil, &err)
 	}
 	tx, err := m.Db.Begin()
 	if err != nil {
@@ -693,82 +880,67 @@ func (m *DbMap) Begin() (*Transaction, error) {
 	return &Transaction{m, tx, false}, nil
 }
 
-// TableFor returns the *modelInfo corresponding to the given Go Type
-// If no table is mapped to that type an error is returned.
-// If checkPK is true and the mapped table has no registered PKs, an error is returned.
-func (m *DbMap) TableFor(t reflect.Type, checkPK bool) (*modelInfo, error) {
+// TableFor returns the model of the Go type t, a registered model or a
+// partial, t being dereferenced when a pointer.  The error matches
+// ErrUnknownModel when t isn't registered.
+func (m *DbMap) TableFor(t reflect.Type) (*modelInfo, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
 	table := tableOrNil(m, t, "")
 	if table == nil {
-		return nil, fmt.Errorf("no table found for type: %v", t.Name())
+		return nil, fmt.Errorf("%w for type: %v", ErrUnknownModel, t)
 	}
-
-	if checkPK && len(table.fields.keys) < 1 {
-		e := fmt.Sprintf("gorp: no keys defined for table: %s",
-			table.table)
-		return nil, errors.New(e)
-	}
-
 	return table, nil
 }
 
-func (m *DbMap) TableForName(tableName string, checkPK bool) (*modelInfo, error) {
-
-	table := getTableByName(m, tableName)
-
-	if table == nil {
-		return nil, fmt.Errorf("no table found for type: %v", tableName)
-	}
-
-	if checkPK && len(table.fields.keys) < 1 {
-		e := fmt.Sprintf("gorp: no keys defined for table: %s", table.table)
-		return nil, errors.New(e)
+// MustTableFor is TableFor panicking when t isn't registered.
+func (m *DbMap) MustTableFor(t reflect.Type) *modelInfo {
+	table, err := m.TableFor(t)
+	if err != nil {
+		panic(err)
 	}
-
-	return table, nil
+	return table
 }
 
-func (m *DbMap) GetByFullName(tableName string, checkPK bool) (*modelInfo, error) {
-
+// TableForName returns the model of the table named tableName: a dynamic
+// table, a registered model or a table added with AddTable, in this
+// order.  The error matches ErrUnknownModel when there is none.
+func (m *DbMap) TableForName(tableName string) (*modelInfo, error) {
 	table := getTableByName(m, tableName)
-
 	if table == nil {
-		return nil, fmt.Errorf("no table found for type: %v", tableName)
+		return nil, fmt.Errorf("%w for table: %v", ErrUnknownModel, tableName)
 	}
+	return table, nil
+}
 
-	if checkPK && len(table.fields.keys) < 1 {
-		e := fmt.Sprintf("gorp: no keys defined for table: %s", table.table)
-		return nil, errors.New(e)
+// GetByFullName returns the registered model of the full type name, eg
+// "github.com/user/app/models.Post".  The error matches ErrUnknownModel
+// when there is none.
+func (m *DbMap) GetByFullName(fullName string) (*modelInfo, error) {
+	table, ok := modelCache.getByFullName(fullName)
+	if !ok {
+		return nil, fmt.Errorf("%w for type: %v", ErrUnknownModel, fullName)
 	}
-
 	return table, nil
 }
 
-// DynamicTableFor returns the *modelInfo for the dynamic table corresponding
-// to the input tablename
-// If no table is mapped to that tablename an error is returned.
-// If checkPK is true and the mapped table has no registered PKs, an error is returned.
-func (m *DbMap) DynamicTableFor(tableName string, checkPK bool) (*modelInfo, error) {
+// DynamicTableFor returns the model of the dynamic table named tableName.
+// The error matches ErrUnknownModel when there is none.
+func (m *DbMap) DynamicTableFor(tableName string) (*modelInfo, error) {
 	table, found := m.dynamicTableFind(tableName)
 	if !found {
-		return nil, fmt.Errorf("gorp: no table found for name: %v", tableName)
+		return nil, fmt.Errorf("%w for dynamic table: %v", ErrUnknownModel, tableName)
 	}
-
-	if checkPK && len(table.fields.keys) < 1 {
-		e := fmt.Sprintf("gorp: no keys defined for table: %s",
-			table.table)
-		return nil, errors.New(e)
-	}
-
 	return table, nil
 }
 
 // Prepare creates a prepared statement for later queries or executions.
 // Multiple queries or executions may be run concurrently from the returned statement.
 // This is equivalent to running:  Prepare() using database/sql
-func (m *DbMap) Prepare(query string) (*sql.Stmt, error) {
-	if m.logger != nil {
-		now := time.Now()
-		defer m.trace(now, query, nil)
+func (m *DbMap) Prepare(query string) (stmt *sql.Stmt, err error) {
+	if m.tracing() {
+		defer m.trace(time.Now(), query, nil, &err)
 	}
 	return m.Db.Prepare(query)
 }
@@ -787,20 +959,26 @@ func tableOrNil(m *DbMap, t reflect.Type, name string) *modelInfo {
 			return table
 		}
 	}
+	for _, table := range modelCache.partials {
+		if table.gotype == t {
+			return table
+		}
+	}
 	return nil
 }
 
 func getTableByName(m *DbMap, tableName string) *modelInfo {
-	if tableName != "" {
-		// Search by table name (dynamic tables)
-		if table, found := m.dynamicTableFind(tableName); found {
-			return table
-		}
+	if tableName == "" {
 		return nil
 	}
-
-	for i := range m.tables {
-		table := m.tables[i]
+	// dynamic tables first, then the models and AddTable tables
+	if table, found := m.dynamicTableFind(tableName); found {
+		return table
+	}
+	if table, ok := modelCache.get(tableName); ok {
+		return table
+	}
+	for _, table := range m.tables {
 		if table.table == tableName {
 			return table
 		}
@@ -822,10 +1000,13 @@ func (m *DbMap) tableForPointer(ptr interface{}, checkPK bool) (*modelInfo, refl
 	tableName := ""
 	if dyn, isDyn := ptr.(DynamicTable); isDyn {
 		tableName = dyn.TableName()
-		t, err = m.DynamicTableFor(tableName, checkPK)
+		t, err = m.DynamicTableFor(tableName)
 	} else {
 		etype := reflect.TypeOf(ifc)
-		t, err = m.TableFor(etype, checkPK)
+		t, err = m.TableFor(etype)
+	}
+	if err == nil && checkPK {
+		err = t.requirePK()
 	}
 
 	if err != nil {
@@ -836,26 +1017,52 @@ func (m *DbMap) tableForPointer(ptr interface{}, checkPK bool) (*modelInfo, refl
 }
 
 func (m *DbMap) QueryRow(query string, args ...interface{}) *sql.Row {
-	if m.logger != nil {
-		now := time.Now()
-		defer m.trace(now, query, args...)
+	row, _ := m.queryRow(query, args)
+	return row
+}
+
+// queryRow is QueryRow returning the function releasing the statement
+// context, once the row is scanned.
+func (m *DbMap) queryRow(query string, args []interface{}) (*sql.Row, context.CancelFunc) {
+	ctx := m.baseContext()
+	if m.hooked() {
+		e := m.beforeQuery(ctx, query, args)
+		defer m.afterQuery(e, nil)
+		ctx, query, args = e.Ctx, e.Query, e.Args
+	}
+	if m.tracing() {
+		defer m.trace(time.Now(), query, args, nil)
 	}
-	return m.Db.QueryRow(query, args...)
+	ctx, cancel := statementContext(ctx, m.queryTimeout())
+	return m.Db.QueryRowContext(ctx, query, args...), cancel
 }
 
 func (m *DbMap) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	if m.logger != nil {
-		now := time.Now()
-		defer m.trace(now, query, args...)
-	}
-	return m.Db.Query(query, args...)
+	rows, _, err := m.query(query, args)
+	return rows, err
 }
 
-func (m *DbMap) trace(started time.Time, query string, args ...interface{}) {
-	if m.logger != nil {
-		var margs = argsString(args...)
-		m.logger.Printf("%s%s [%s] (%v)", m.logPrefix, query, margs, (time.Now().Sub(started)))
+// query is Query returning the function releasing the statement context,
+// once the rows are closed.
+func (m *DbMap) query(query string, args []interface{}) (rows *sql.Rows, cancel context.CancelFunc, err error) {
+	ctx := m.baseContext()
+	if m.hooked() {
+		e := m.beforeQuery(ctx, query, args)
+		defer m.afterQuery(e, &err)
+		ctx, query, args = e.Ctx, e.Query, e.Args
+	}
+	if m.tracing() {
+		defer m.trace(time.Now(), query, args, &err)
 	}
+	err = m.retry(func() (err error) {
+		var sctx context.Context
+		sctx, cancel = statementContext(ctx, m.queryTimeout())
+		if rows, err = m.Db.QueryContext(sctx, query, args...); err != nil {
+			cancel()
+		}
+		return err
+	})
+	return rows, cancel, m.translateError(err)
 }
 
 func (m *DbMap) SaveM2M(model interface{}, fields ...string) error {
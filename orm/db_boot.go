@@ -29,6 +29,13 @@ func (r *databaseSingleton) Get() *DbMap {
 	return r.dbmap
 }
 
+// lookup returns the DbMap, or nil if none is set yet.
+func (r *databaseSingleton) lookup() *DbMap {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.dbmap
+}
+
 func Database() *databaseSingleton {
 	if database == nil {
 		database = &databaseSingleton{}
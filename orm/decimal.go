@@ -0,0 +1,78 @@
+package orm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+var decimalRegexp = regexp.MustCompile(`^[-+]?([0-9]+\.?[0-9]*|\.[0-9]+)$`)
+
+// Decimal is an arbitrary-precision number kept as its decimal string, so
+// that it travels to and from numeric(digits, decimals) columns without
+// the rounding of float64.  The zero value is 0.
+//
+// Fields of other decimal types, eg shopspring's decimal.Decimal, are
+// mapped to numeric columns too when tagged with digits and decimals, as
+// long as they implement driver.Valuer and sql.Scanner.
+type Decimal string
+
+// Value implements the driver Valuer interface.
+func (d Decimal) Value() (driver.Value, error) {
+	if d == "" {
+		return "0", nil
+	}
+	if !decimalRegexp.MatchString(string(d)) {
+		return nil, fmt.Errorf("orm: wrong decimal `%s`", string(d))
+	}
+	return string(d), nil
+}
+
+// Scan implements the Scanner interface.
+func (d *Decimal) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case nil:
+		*d = ""
+	case []byte:
+		*d = Decimal(s)
+	case string:
+		*d = Decimal(s)
+	case int64:
+		*d = Decimal(strconv.FormatInt(s, 10))
+	case float64:
+		*d = Decimal(strconv.FormatFloat(s, 'f', -1, 64))
+	default:
+		return fmt.Errorf("orm: can not scan %T into a decimal", src)
+	}
+	return nil
+}
+
+// Float64 returns the nearest float64 of the decimal.
+func (d Decimal) Float64() (float64, error) {
+	if d == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(string(d), 64)
+}
+
+// isDecimalValuer reports whether the field at addr implements both
+// driver.Valuer and sql.Scanner, so it can be bound as a decimal.
+func isDecimalValuer(addr reflect.Value) bool {
+	typ := addr.Type()
+	if typ.Kind() != reflect.Ptr {
+		return false
+	}
+	return typ.Elem().Implements(reflect.TypeOf((*driver.Valuer)(nil)).Elem()) &&
+		typ.Implements(reflect.TypeOf((*sql.Scanner)(nil)).Elem())
+}
+
+// sqlForDecimalType returns the column type of a decimal field.
+func sqlForDecimalType(dialect Dialect, digits, decimals int) string {
+	if dd, ok := dialect.(DecimalDialect); ok {
+		return dd.ToSqlDecimalType(digits, decimals)
+	}
+	return fmt.Sprintf("numeric(%d, %d)", digits, decimals)
+}
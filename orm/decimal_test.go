@@ -0,0 +1,65 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecimalValue(t *testing.T) {
+	for in, want := range map[Decimal]interface{}{"": "0", "12.50": "12.50", "-0.000000000000000001": "-0.000000000000000001", ".5": ".5"} {
+		if got, err := in.Value(); err != nil || got != want {
+			t.Errorf("%q: expected %v, got %v (%v)", in, want, got, err)
+		}
+	}
+	if _, err := Decimal("1e3").Value(); err == nil {
+		t.Error("expected an error binding an exponent")
+	}
+
+	var d Decimal
+	for src, want := range map[interface{}]Decimal{"123456789012345678.99": "123456789012345678.99", int64(3): "3", 0.25: "0.25", nil: ""} {
+		if err := d.Scan(src); err != nil || d != want {
+			t.Errorf("%v: expected %q, got %q (%v)", src, want, d, err)
+		}
+	}
+	if err := d.Scan([]byte("7.10")); err != nil || d != "7.10" {
+		t.Errorf("expected 7.10, got %q (%v)", d, err)
+	}
+}
+
+func TestDecimalField(t *testing.T) {
+	registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &decimalInvoice{})
+	defer Database().Set(nil)
+	mi, _ := modelCache.get("decimal_invoice")
+
+	for _, name := range []string{"Total", "Discount", "Rate", "Paid"} {
+		if fi := mi.fields.GetByName(name); fi.fieldType != TypeDecimalField {
+			t.Errorf("%s should be a decimal field", name)
+		}
+	}
+	ddl := mi.SqlForCreate(false)
+	for _, col := range []string{"`total` numeric(12, 2)", "`discount` numeric(5, 4)", "`rate` numeric(6, 3)", "`paid` numeric(12, 2)"} {
+		if !strings.Contains(ddl, col) {
+			t.Errorf("ddl %q missing %q", ddl, col)
+		}
+	}
+
+	bi, err := mi.bindInsert(reflect.ValueOf(&decimalInvoice{Total: "0.10", Paid: moneyAmount{"12.34"}}).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, arg := range bi.args {
+		if arg == Decimal("0.10") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the decimal to be bound as is, got %v", bi.args)
+	}
+
+	Database().Set(&DbMap{Dialect: OracleDialect{}})
+	if ddl := mi.SqlForCreate(false); !strings.Contains(ddl, `"TOTAL" number(12, 2)`) {
+		t.Errorf("unexpected oracle ddl %q", ddl)
+	}
+}
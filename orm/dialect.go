@@ -11,7 +11,10 @@
 
 package orm
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+)
 
 // The Dialect interface encapsulates behaviors that differ across
 // SQL databases.  At present the Dialect is only used by CreateTables()
@@ -102,6 +105,273 @@ type TargetQueryInserter interface {
 	InsertQueryToTarget(exec SqlExecutor, insertSql, idSql string, target interface{}, params ...interface{}) error
 }
 
+// FullTextSearcher is implemented by dialects that provide native
+// full-text search.  It is used by Restrictions.Search and by
+// DbMap.CreateIndex for fields tagged `fulltext`.
+type FullTextSearcher interface {
+	// FullTextMatch returns the predicate matching column against the
+	// search terms bound to bindVar.
+	FullTextMatch(column, bindVar string) string
+
+	// FullTextIndex returns the statement that creates the index
+	// needed by FullTextMatch for the given column.
+	FullTextIndex(name, schema, table, column string) string
+}
+
+// GeneratedColumner is implemented by dialects whose generated columns,
+// of the fields tagged `generated(expr)`, aren't declared with the
+// standard "generated always as (expr) stored|virtual" clause.
+type GeneratedColumner interface {
+	// GeneratedColumn returns the definition of column, of SQL type
+	// stype, computed from expr when written if stored, or else when
+	// read.
+	GeneratedColumn(column, stype, expr string, stored bool) string
+}
+
+// JSONQuerier is implemented by dialects able to query inside json
+// columns.  It is used by the Restrictions.JSON* criterions.
+type JSONQuerier interface {
+	// JSONContains returns the predicate matching rows whose column
+	// contains the json document bound to bindVar.
+	JSONContains(column, bindVar string) string
+
+	// JSONHasKey returns the predicate matching rows whose column has
+	// the top level key bound to bindVar.
+	JSONHasKey(column, bindVar string) string
+
+	// JSONExtract returns the expression extracting the value found at
+	// path, a list of keys, as text.
+	JSONExtract(column string, path []string) string
+}
+
+// ArrayDialect is implemented by dialects with native array columns,
+// used for fields tagged `type(array)` and the Restrictions.Array*
+// criterions.
+type ArrayDialect interface {
+	// ToSqlArrayType returns the SQL column type of an array of elem.
+	ToSqlArrayType(elem reflect.Type, maxsize int) string
+
+	// ArrayContains returns the predicate matching rows whose column
+	// contains all the elements of the array bound to bindVar.
+	ArrayContains(column, bindVar string) string
+
+	// ArrayOverlap returns the predicate matching rows whose column
+	// has at least one element of the array bound to bindVar.
+	ArrayOverlap(column, bindVar string) string
+
+	// ArrayAny returns the predicate matching rows whose column equals
+	// one of the elements of the array bound to bindVar.
+	ArrayAny(column, bindVar string) string
+}
+
+// SpatialDialect is implemented by dialects with spatial columns, used
+// for Point fields and the Restrictions.DistanceLte criterion.
+type SpatialDialect interface {
+	// ToSqlPointType returns the SQL column type of a point.
+	ToSqlPointType() string
+
+	// PointFromText returns the expression converting the well-known
+	// text bound to bindVar to a point.
+	PointFromText(bindVar string) string
+
+	// DistanceWithin returns the predicate matching rows whose column is
+	// at most the meters bound to distanceBindVar away from the point
+	// whose text is bound to pointBindVar.
+	DistanceWithin(column, pointBindVar, distanceBindVar string) string
+}
+
+// BlobDialect is implemented by dialects which don't slice a binary column
+// with substring, used by GetBlob.
+type BlobDialect interface {
+	// BlobSlice returns the expression of the bytes of column starting
+	// at the offset, from 1, bound to offsetBindVar.
+	BlobSlice(column, offsetBindVar, lengthBindVar string) string
+}
+
+// IntervalDialect is implemented by dialects with an interval column
+// type, used for time.Duration fields tagged `type(interval)`.
+type IntervalDialect interface {
+	// ToSqlIntervalType returns the SQL column type of an interval.
+	ToSqlIntervalType() string
+}
+
+// DecimalDialect is implemented by dialects whose exact numeric column
+// type isn't numeric(digits, decimals).
+type DecimalDialect interface {
+	// ToSqlDecimalType returns the SQL column type of a decimal field.
+	ToSqlDecimalType(digits, decimals int) string
+}
+
+// EnumDialect is implemented by dialects with a native enum column type,
+// used for fields tagged `type(enum=...)`.  The other dialects add a
+// check constraint to the column instead.
+type EnumDialect interface {
+	// ToSqlEnumType returns the SQL column type allowing only values.
+	ToSqlEnumType(values []string) string
+}
+
+// ILiker is implemented by dialects with a case-insensitive like
+// operator, used by Restrictions.Ilike.  The other dialects compare lower
+// cased values.
+type ILiker interface {
+	// ILike returns the predicate matching column against the pattern
+	// bound to bindVar, ignoring case.
+	ILike(column, bindVar string) string
+}
+
+// LikeEscaper is implemented by dialects whose like patterns have
+// wildcards other than % and _, escaped in the values of Restrictions.Like.
+type LikeEscaper interface {
+	// LikeWildcards returns the other wildcards, eg "[".
+	LikeWildcards() string
+}
+
+// DefaultLikeEscaper is implemented by dialects whose like patterns have
+// an escape character when the predicate names none, as the backslash of
+// PostgreSQL and MySQL.  Restrictions.Like escapes it too, naming its own
+// escape character, so that a value like `C:\dir` matches as is.
+type DefaultLikeEscaper interface {
+	// DefaultLikeEscape returns the escape character, eg `\`.
+	DefaultLikeEscape() string
+}
+
+// TableOptioner is implemented by dialects whose tables have options, as
+// the storage engine and character set of MySQL.
+type TableOptioner interface {
+	// CreateTableOptions returns the suffix of the create table statement
+	// of a model, in place of CreateTableSuffix.  The options of the model
+	// replace those of the dialect when not empty.
+	CreateTableOptions(engine, charset, collation string) string
+}
+
+// ReturningInserter is implemented by dialects able to return columns of
+// the inserted row from the insert statement itself.  Insert then reads
+// back the auto increment key, the columns with a database default and
+// the fields tagged `returning`, eg populated by triggers.
+type ReturningInserter interface {
+	// ReturningClause returns the suffix of an insert statement
+	// returning the given, already quoted, columns.
+	ReturningClause(columns []string) string
+}
+
+// LimitedDeleter is implemented by dialects accepting order by and limit
+// clauses on delete statements.  It is used by Criteria.Delete.
+type LimitedDeleter interface {
+	// DeleteLimitClause returns the suffix of a delete statement
+	// removing the first limit rows in the given order.  orderBy may be
+	// empty and limit zero.
+	DeleteLimitClause(orderBy string, limit int) string
+}
+
+// RandomSampler is implemented by dialects able to pick random rows.
+// It is used by Criteria.Random and Criteria.Sample.
+type RandomSampler interface {
+	// RandomValue returns an expression yielding a uniformly
+	// distributed value in [0, 1) for every row.
+	RandomValue() string
+
+	// TableSample returns the clause appended to a table in the from
+	// clause to read roughly percent of its rows, or "" when the
+	// database has no native sampling.
+	TableSample(percent float64) string
+}
+
+// Paginator is implemented by dialects which page the results of a
+// select otherwise than with "limit n offset m", or need a limit with an
+// offset.
+type Paginator interface {
+	// LimitSQL returns the statement selecting at most limit rows of
+	// query, ordered by orderBy, after skipping the first offset ones.
+	// query has no order by clause, orderBy may be empty and limit or
+	// offset zero.
+	LimitSQL(query, orderBy string, offset, limit int) string
+}
+
+// TempTabler is implemented by dialects with temporary tables, private to
+// the connection which created them.  Criteria join oversized in lists
+// from them, see DbMap.InListLimit.
+type TempTabler interface {
+	// TempTable returns the statement creating the temporary table
+	// name, with the single column v of type sqlType.
+	TempTable(name, sqlType string) string
+}
+
+// AdvisoryLocker is implemented by dialects with session level advisory
+// locks, which the database releases when the session ends.  DbMap.Lock
+// holds them besides the lock rows.
+type AdvisoryLocker interface {
+	// TryAdvisoryLock returns the query taking the lock named by bindVar
+	// without waiting, and selecting whether it was taken.
+	TryAdvisoryLock(bindVar string) string
+
+	// AdvisoryUnlock returns the statement releasing the lock named by
+	// bindVar.
+	AdvisoryUnlock(bindVar string) string
+}
+
+// Explainer is implemented by dialects able to show the plan of a query.
+// It is used by Criteria.Explain.
+type Explainer interface {
+	// ExplainPrefix returns the prefix of a statement showing the plan
+	// of the query it is followed by, also running the query when
+	// analyze is set, or "" when the database can't analyze.
+	ExplainPrefix(analyze bool) string
+}
+
+// RetryDetector is implemented by dialects able to tell the errors worth
+// retrying: deadlocks and serialization failures, which the database
+// resolves by aborting one of the transactions.  It is used by
+// DbMap.RetryPolicy.
+type RetryDetector interface {
+	Retryable(err error) bool
+}
+
+// ErrorTranslator is implemented by dialects able to recognize the
+// constraint violations in the errors of their driver.  The statements
+// run through a DbMap return the translated errors.
+type ErrorTranslator interface {
+	// TranslateError returns a *ConstraintError wrapping err if it is a
+	// constraint violation, or else err.
+	TranslateError(err error) error
+}
+
+// Inspector is implemented by dialects able to read the schema of the
+// database.  It is used by DbMap.Inspect.  The queries read the tables of
+// the current schema, ordered by table, then by constraint or index, then
+// by position of the column.
+type Inspector interface {
+	// ColumnsQuery selects the table, name, type, nullability and
+	// default of the columns.
+	ColumnsQuery() string
+
+	// IndexesQuery selects the table, name, uniqueness and column of
+	// the indexed columns.
+	IndexesQuery() string
+
+	// ConstraintsQuery selects the table, name, kind, column, and the
+	// referenced table and column of foreign keys or else "", of the
+	// columns of the constraints.
+	ConstraintsQuery() string
+}
+
+// DialectForDriver returns the dialect of the databases of the
+// database/sql driver registered as driver, eg "postgres" or "mysql".
+func DialectForDriver(driver string) (Dialect, error) {
+	switch driver {
+	case "postgres", "pgx":
+		return PostgresDialect{}, nil
+	case "mysql":
+		return MySQLDialect{"InnoDB", "UTF8"}, nil
+	case "sqlite3", "sqlite":
+		return SqliteDialect{}, nil
+	case "sqlserver", "mssql":
+		return SqlServerDialect{}, nil
+	case "oci8", "godror":
+		return OracleDialect{}, nil
+	}
+	return nil, fmt.Errorf("no dialect for driver %q", driver)
+}
+
 func standardInsertAutoIncr(exec SqlExecutor, insertSql string, params ...interface{}) (int64, error) {
 	res, err := exec.Exec(insertSql, params...)
 	if err != nil {
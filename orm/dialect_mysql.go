@@ -14,6 +14,8 @@ package orm
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -29,6 +31,11 @@ type MySQLDialect struct {
 
 var _ Dialect = new(MySQLDialect)
 
+// DefaultCollation is the collation of the MySQL tables whose model sets
+// neither a charset nor a collation, see the orm.collation setting.  Empty,
+// it's the one of the charset of the dialect.
+var DefaultCollation string
+
 func (d MySQLDialect) QuerySuffix() string { return ";" }
 
 func (d MySQLDialect) ToSqlType(val reflect.Type, maxsize int, isAutoIncr bool) string {
@@ -62,13 +69,15 @@ func (d MySQLDialect) ToSqlType(val reflect.Type, maxsize int, isAutoIncr bool)
 	}
 
 	switch val.Name() {
+	case "NullInt32":
+		return "int"
 	case "NullInt64":
 		return "bigint"
 	case "NullFloat64":
 		return "double"
 	case "NullBool":
 		return "tinyint"
-	case "Time":
+	case "Time", "NullTime":
 		return "datetime"
 	}
 
@@ -127,6 +136,35 @@ func (d MySQLDialect) CreateTableSuffix() string {
 	return fmt.Sprintf(" engine=%s charset=%s", d.Engine, d.Encoding)
 }
 
+// CreateTableOptions returns " ENGINE=%s DEFAULT CHARSET=%s COLLATE=%s",
+// from the options of the model or else the engine and encoding of the
+// dialect and DefaultCollation, leaving out those which are empty.  The
+// collation defaults to the one of the charset.
+func (d MySQLDialect) CreateTableOptions(engine, charset, collation string) string {
+	if engine == "" {
+		engine = d.Engine
+	}
+	if charset == "" && collation == "" {
+		settingsMu.RLock()
+		collation = DefaultCollation
+		settingsMu.RUnlock()
+	}
+	if charset == "" {
+		charset = d.Encoding
+	}
+	s := ""
+	if engine != "" {
+		s += " ENGINE=" + engine
+	}
+	if charset != "" {
+		s += " DEFAULT CHARSET=" + charset
+	}
+	if collation != "" {
+		s += " COLLATE=" + collation
+	}
+	return s
+}
+
 func (m MySQLDialect) CreateIndexSuffix() string {
 	return "using"
 }
@@ -171,3 +209,155 @@ func (d MySQLDialect) IfTableExists(command, schema, table string) string {
 func (d MySQLDialect) IfTableNotExists(command, schema, table string) string {
 	return fmt.Sprintf("%s if not exists", command)
 }
+
+func (d MySQLDialect) FullTextMatch(column, bindVar string) string {
+	return fmt.Sprintf("match (%s) against (%s in natural language mode)", column, bindVar)
+}
+
+func (d MySQLDialect) FullTextIndex(name, schema, table, column string) string {
+	return fmt.Sprintf("create fulltext index %s on %s (%s);", name, d.QuotedTableForQuery(schema, table), d.QuoteField(column))
+}
+
+func (d MySQLDialect) JSONContains(column, bindVar string) string {
+	return fmt.Sprintf("json_contains(%s, %s)", column, bindVar)
+}
+
+func (d MySQLDialect) JSONHasKey(column, bindVar string) string {
+	return fmt.Sprintf("json_contains_path(%s, 'one', concat('$.', %s))", column, bindVar)
+}
+
+func (d MySQLDialect) JSONExtract(column string, path []string) string {
+	return fmt.Sprintf("json_unquote(json_extract(%s, '$.%s'))", column, strings.Join(path, "."))
+}
+
+// DefaultLikeEscape returns the backslash escaping the like patterns by
+// default.
+func (d MySQLDialect) DefaultLikeEscape() string {
+	return `\`
+}
+
+func (d MySQLDialect) RandomValue() string {
+	return "rand()"
+}
+
+func (d MySQLDialect) TableSample(percent float64) string {
+	return ""
+}
+
+func (d MySQLDialect) ExplainPrefix(analyze bool) string {
+	if analyze {
+		return "explain analyze "
+	}
+	return "explain "
+}
+
+func (d MySQLDialect) TryAdvisoryLock(bindVar string) string {
+	return "select get_lock(" + bindVar + ", 0) = 1"
+}
+
+func (d MySQLDialect) AdvisoryUnlock(bindVar string) string {
+	return "select release_lock(" + bindVar + ")"
+}
+
+func (d MySQLDialect) TempTable(name, sqlType string) string {
+	return "create temporary table " + d.QuoteField(name) + " (v " + sqlType + ")"
+}
+
+func (d MySQLDialect) DeleteLimitClause(orderBy string, limit int) string {
+	clause := ""
+	if orderBy != "" {
+		clause += " order by " + orderBy
+	}
+	if limit > 0 {
+		clause += " limit " + strconv.Itoa(limit)
+	}
+	return clause
+}
+
+// Retryable matches the ER_LOCK_DEADLOCK and ER_LOCK_WAIT_TIMEOUT errors.
+func (d MySQLDialect) Retryable(err error) bool {
+	msg := err.Error()
+	return strings.HasPrefix(msg, "Error 1213") || strings.HasPrefix(msg, "Error 1205")
+}
+
+var (
+	mysqlDuplicate  = regexp.MustCompile(`^Error 1062[^:]*: Duplicate entry .* for key '([^']+)'`)
+	mysqlForeignKey = regexp.MustCompile("^Error 145[12][^:]*: .*CONSTRAINT `([^`]+)` FOREIGN KEY \\(`([^`]+)`")
+	mysqlNotNull    = regexp.MustCompile(`^Error 1048[^:]*: Column '([^']+)' cannot be null`)
+	mysqlCheck      = regexp.MustCompile(`^Error 3819[^:]*: Check constraint '([^']+)' is violated`)
+)
+
+// TranslateError recognizes the errors 1062 for unique, 1451 and 1452 for
+// foreign key, 1048 for not null and 3819 for check constraints.
+func (d MySQLDialect) TranslateError(err error) error {
+	msg := err.Error()
+	if m := mysqlDuplicate.FindStringSubmatch(msg); m != nil {
+		// MySQL 8 qualifies the key with its table
+		key := m[1][strings.LastIndex(m[1], ".")+1:]
+		return &ConstraintError{Kind: ErrDuplicateKey, Constraint: key, Err: err}
+	}
+	if m := mysqlForeignKey.FindStringSubmatch(msg); m != nil {
+		return &ConstraintError{Kind: ErrForeignKey, Constraint: m[1], Column: m[2], Err: err}
+	}
+	if m := mysqlNotNull.FindStringSubmatch(msg); m != nil {
+		return &ConstraintError{Kind: ErrNotNull, Column: m[1], Err: err}
+	}
+	if m := mysqlCheck.FindStringSubmatch(msg); m != nil {
+		return &ConstraintError{Kind: ErrCheck, Constraint: m[1], Err: err}
+	}
+	return err
+}
+
+func (d MySQLDialect) ColumnsQuery() string {
+	return `select table_name, column_name, column_type, is_nullable = 'YES', column_default
+from information_schema.columns
+where table_schema = database()
+order by table_name, ordinal_position`
+}
+
+func (d MySQLDialect) IndexesQuery() string {
+	return `select table_name, index_name, non_unique = 0, column_name
+from information_schema.statistics
+where table_schema = database()
+order by table_name, index_name, seq_in_index`
+}
+
+func (d MySQLDialect) ConstraintsQuery() string {
+	return `select tc.table_name, tc.constraint_name, tc.constraint_type, kcu.column_name,
+	coalesce(kcu.referenced_table_name, ''), coalesce(kcu.referenced_column_name, '')
+from information_schema.table_constraints tc
+join information_schema.key_column_usage kcu on kcu.constraint_schema = tc.constraint_schema
+	and kcu.constraint_name = tc.constraint_name and kcu.table_name = tc.table_name
+where tc.table_schema = database()
+order by tc.table_name, tc.constraint_name, kcu.ordinal_position`
+}
+
+// ToSqlEnumType returns the native enum type of the values.
+func (d MySQLDialect) ToSqlEnumType(values []string) string {
+	return "enum(" + sqlForEnumValues(values) + ")"
+}
+
+// ToSqlPointType returns the point type, holding longitude and latitude.
+func (d MySQLDialect) ToSqlPointType() string {
+	return "point"
+}
+
+func (d MySQLDialect) PointFromText(bindVar string) string {
+	return fmt.Sprintf("ST_GeomFromText(%s)", bindVar)
+}
+
+// DistanceWithin compares the spherical distance of the points, in
+// meters.
+func (d MySQLDialect) DistanceWithin(column, pointBindVar, distanceBindVar string) string {
+	return fmt.Sprintf("ST_Distance_Sphere(%s, ST_GeomFromText(%s)) <= %s", column, pointBindVar, distanceBindVar)
+}
+
+func (d MySQLDialect) BlobSlice(column, offsetBindVar, lengthBindVar string) string {
+	return fmt.Sprintf("substring(%s, %s, %s)", column, offsetBindVar, lengthBindVar)
+}
+
+// LimitSQL pages with limit and offset, an offset needing the largest
+// limit.
+func (d MySQLDialect) LimitSQL(query, orderBy string, offset, limit int) string {
+	return limitSQL(query, orderBy, offset, limit, "18446744073709551615")
+}
@@ -28,6 +28,10 @@ func (d OracleDialect) CreateIndexSuffix() string { return "" }
 
 func (d OracleDialect) DropIndexSuffix() string { return "" }
 
+func (d OracleDialect) ToSqlDecimalType(digits, decimals int) string {
+	return fmt.Sprintf("number(%d, %d)", digits, decimals)
+}
+
 func (d OracleDialect) ToSqlType(val reflect.Type, maxsize int, isAutoIncr bool) string {
 	switch val.Kind() {
 	case reflect.Ptr:
@@ -55,6 +59,8 @@ func (d OracleDialect) ToSqlType(val reflect.Type, maxsize int, isAutoIncr bool)
 	}
 
 	switch val.Name() {
+	case "NullInt32":
+		return "integer"
 	case "NullInt64":
 		return "bigint"
 	case "NullFloat64":
@@ -146,3 +152,26 @@ func (d OracleDialect) IfTableExists(command, schema, table string) string {
 func (d OracleDialect) IfTableNotExists(command, schema, table string) string {
 	return fmt.Sprintf("%s if not exists", command)
 }
+
+// GeneratedColumn declares a virtual column, Oracle doesn't store
+// generated columns.
+func (d OracleDialect) GeneratedColumn(column, stype, expr string, stored bool) string {
+	return fmt.Sprintf("%s %s generated always as (%s) virtual", column, stype, expr)
+}
+
+// LimitSQL pages with the rownum of the ordered rows, or with offset and
+// fetch, of Oracle 12c and later, when skipping rows: the row numbers
+// would otherwise be selected with the columns of the query.
+func (d OracleDialect) LimitSQL(query, orderBy string, offset, limit int) string {
+	if orderBy != "" {
+		query += " order by " + orderBy
+	}
+	if offset == 0 {
+		return fmt.Sprintf("select * from (%s) where rownum <= %d", query, limit)
+	}
+	query += fmt.Sprintf(" offset %d rows", offset)
+	if limit > 0 {
+		query += fmt.Sprintf(" fetch next %d rows only", limit)
+	}
+	return query
+}
@@ -14,11 +14,16 @@ package orm
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
 type PostgresDialect struct {
 	suffix string
+
+	// TextSearchConfig is the text search configuration used for
+	// full-text search, "english" if empty.
+	TextSearchConfig string
 }
 
 var _ Dialect = new(PostgresDialect)
@@ -52,6 +57,8 @@ func (d PostgresDialect) ToSqlType(val reflect.Type, maxsize int, isAutoIncr boo
 	}
 
 	switch val.Name() {
+	case "NullInt32":
+		return "integer"
 	case "NullInt64":
 		return "bigint"
 	case "NullFloat64":
@@ -106,14 +113,18 @@ func (d PostgresDialect) BindVar(i int) string {
 }
 
 func (d PostgresDialect) InsertAutoIncrToTarget(exec SqlExecutor, insertSql string, target interface{}, params ...interface{}) error {
-	rows, err := exec.Query(insertSql, params...)
+	rows, done, err := queryRows(exec, insertSql, params...)
 	if err != nil {
 		return err
 	}
+	defer done()
 	defer rows.Close()
 
 	if !rows.Next() {
-		return fmt.Errorf("No serial value returned for insert: %s Encountered error: %s", insertSql, rows.Err())
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("No serial value returned for insert: %s Encountered error: %w", insertSql, err)
+		}
+		return fmt.Errorf("No serial value returned for insert: %s", insertSql)
 	}
 	if err := rows.Scan(target); err != nil {
 		return err
@@ -147,3 +158,198 @@ func (d PostgresDialect) IfTableExists(command, schema, table string) string {
 func (d PostgresDialect) IfTableNotExists(command, schema, table string) string {
 	return fmt.Sprintf("%s if not exists", command)
 }
+
+func (d PostgresDialect) textSearchConfig() string {
+	if d.TextSearchConfig == "" {
+		return "english"
+	}
+	return d.TextSearchConfig
+}
+
+func (d PostgresDialect) FullTextMatch(column, bindVar string) string {
+	return fmt.Sprintf("to_tsvector('%s', %s) @@ plainto_tsquery('%s', %s)", d.textSearchConfig(), column, d.textSearchConfig(), bindVar)
+}
+
+func (d PostgresDialect) FullTextIndex(name, schema, table, column string) string {
+	return fmt.Sprintf("create index %s on %s using gin (to_tsvector('%s', %s));", name, d.QuotedTableForQuery(schema, table), d.textSearchConfig(), d.QuoteField(column))
+}
+
+func (d PostgresDialect) JSONContains(column, bindVar string) string {
+	return fmt.Sprintf("%s @> %s", column, bindVar)
+}
+
+// JSONHasKey uses jsonb_exists, the function behind the ? operator, so
+// the operator is not mistaken for a bind variable.
+func (d PostgresDialect) JSONHasKey(column, bindVar string) string {
+	return fmt.Sprintf("jsonb_exists(%s, %s)", column, bindVar)
+}
+
+func (d PostgresDialect) JSONExtract(column string, path []string) string {
+	if len(path) == 1 {
+		return fmt.Sprintf("%s->>'%s'", column, path[0])
+	}
+	return fmt.Sprintf("%s#>>'{%s}'", column, strings.Join(path, ","))
+}
+
+func (d PostgresDialect) ToSqlArrayType(elem reflect.Type, maxsize int) string {
+	return d.ToSqlType(elem, maxsize, false) + "[]"
+}
+
+func (d PostgresDialect) ArrayContains(column, bindVar string) string {
+	return fmt.Sprintf("%s @> %s", column, bindVar)
+}
+
+func (d PostgresDialect) ArrayOverlap(column, bindVar string) string {
+	return fmt.Sprintf("%s && %s", column, bindVar)
+}
+
+func (d PostgresDialect) ArrayAny(column, bindVar string) string {
+	return fmt.Sprintf("%s = any(%s)", column, bindVar)
+}
+
+func (d PostgresDialect) ILike(column, bindVar string) string {
+	return fmt.Sprintf("%s ilike %s", column, bindVar)
+}
+
+// DefaultLikeEscape returns the backslash escaping the like patterns by
+// default.
+func (d PostgresDialect) DefaultLikeEscape() string {
+	return `\`
+}
+
+func (d PostgresDialect) ToSqlIntervalType() string {
+	return "interval"
+}
+
+// ToSqlPointType returns the PostGIS geography type, so that distances
+// are computed in meters.
+func (d PostgresDialect) ToSqlPointType() string {
+	return "geography(Point,4326)"
+}
+
+func (d PostgresDialect) PointFromText(bindVar string) string {
+	return fmt.Sprintf("ST_GeogFromText(%s)", bindVar)
+}
+
+func (d PostgresDialect) DistanceWithin(column, pointBindVar, distanceBindVar string) string {
+	return fmt.Sprintf("ST_DWithin(%s, ST_GeogFromText(%s), %s)", column, pointBindVar, distanceBindVar)
+}
+
+func (d PostgresDialect) RandomValue() string {
+	return "random()"
+}
+
+func (d PostgresDialect) TableSample(percent float64) string {
+	return fmt.Sprintf(" tablesample bernoulli (%g)", percent)
+}
+
+func (d PostgresDialect) ExplainPrefix(analyze bool) string {
+	if analyze {
+		return "explain analyze "
+	}
+	return "explain "
+}
+
+func (d PostgresDialect) TryAdvisoryLock(bindVar string) string {
+	return "select pg_try_advisory_lock(hashtext(" + bindVar + "))"
+}
+
+func (d PostgresDialect) AdvisoryUnlock(bindVar string) string {
+	return "select pg_advisory_unlock(hashtext(" + bindVar + "))"
+}
+
+func (d PostgresDialect) ReturningClause(columns []string) string {
+	return " returning " + strings.Join(columns, ",")
+}
+
+// Retryable matches the serialization_failure and deadlock_detected
+// states.
+func (d PostgresDialect) Retryable(err error) bool {
+	switch sqlState(err) {
+	case "40001", "40P01":
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "deadlock detected") || strings.Contains(msg, "could not serialize access")
+}
+
+var postgresConstraint = regexp.MustCompile(`constraint "([^"]+)"`)
+var postgresColumn = regexp.MustCompile(`column "([^"]+)"`)
+
+// TranslateError recognizes the integrity violation states, 23505 for
+// unique, 23503 for foreign key, 23502 for not null and 23514 for check
+// constraints.  The constraint and column are read from the fields of the
+// lib/pq and pgx errors, or else from the message.
+func (d PostgresDialect) TranslateError(err error) error {
+	var kind error
+	switch sqlState(err) {
+	case "23505":
+		kind = ErrDuplicateKey
+	case "23503":
+		kind = ErrForeignKey
+	case "23502":
+		kind = ErrNotNull
+	case "23514":
+		kind = ErrCheck
+	default:
+		msg := err.Error()
+		switch {
+		case strings.Contains(msg, "violates unique constraint"):
+			kind = ErrDuplicateKey
+		case strings.Contains(msg, "violates foreign key constraint"):
+			kind = ErrForeignKey
+		case strings.Contains(msg, "violates not-null constraint"):
+			kind = ErrNotNull
+		case strings.Contains(msg, "violates check constraint"):
+			kind = ErrCheck
+		default:
+			return err
+		}
+	}
+	ce := &ConstraintError{
+		Kind:       kind,
+		Constraint: errorField(err, "Constraint", "ConstraintName"),
+		Column:     errorField(err, "Column", "ColumnName"),
+		Err:        err,
+	}
+	if m := postgresConstraint.FindStringSubmatch(err.Error()); ce.Constraint == "" && m != nil {
+		ce.Constraint = m[1]
+	}
+	if m := postgresColumn.FindStringSubmatch(err.Error()); ce.Column == "" && m != nil {
+		ce.Column = m[1]
+	}
+	return ce
+}
+
+func (d PostgresDialect) ColumnsQuery() string {
+	return `select table_name, column_name, data_type, is_nullable = 'YES', column_default
+from information_schema.columns
+where table_schema = current_schema()
+order by table_name, ordinal_position`
+}
+
+func (d PostgresDialect) IndexesQuery() string {
+	return `select t.relname, i.relname, ix.indisunique, a.attname
+from pg_index ix
+join pg_class t on t.oid = ix.indrelid
+join pg_class i on i.oid = ix.indexrelid
+join pg_namespace n on n.oid = t.relnamespace
+join lateral unnest(ix.indkey) with ordinality k(attnum, position) on true
+join pg_attribute a on a.attrelid = t.oid and a.attnum = k.attnum
+where n.nspname = current_schema()
+order by t.relname, i.relname, k.position`
+}
+
+func (d PostgresDialect) ConstraintsQuery() string {
+	return `select tc.table_name, tc.constraint_name, tc.constraint_type, kcu.column_name,
+	coalesce(ref.table_name, ''), coalesce(ref.column_name, '')
+from information_schema.table_constraints tc
+join information_schema.key_column_usage kcu on kcu.constraint_schema = tc.constraint_schema
+	and kcu.constraint_name = tc.constraint_name and kcu.table_name = tc.table_name
+left join information_schema.referential_constraints rc on rc.constraint_schema = tc.constraint_schema
+	and rc.constraint_name = tc.constraint_name
+left join information_schema.key_column_usage ref on ref.constraint_schema = rc.unique_constraint_schema
+	and ref.constraint_name = rc.unique_constraint_name and ref.ordinal_position = kcu.position_in_unique_constraint
+where tc.table_schema = current_schema()
+order by tc.table_name, tc.constraint_name, kcu.ordinal_position`
+}
@@ -14,6 +14,8 @@ package orm
 import (
 	"fmt"
 	"reflect"
+	"regexp"
+	"strings"
 )
 
 type SqliteDialect struct {
@@ -24,6 +26,12 @@ var _ Dialect = new(SqliteDialect)
 
 func (d SqliteDialect) QuerySuffix() string { return ";" }
 
+// ToSqlDecimalType returns text, since numeric columns of SQLite store
+// the values which fit as floating point.
+func (d SqliteDialect) ToSqlDecimalType(digits, decimals int) string {
+	return "text"
+}
+
 func (d SqliteDialect) ToSqlType(val reflect.Type, maxsize int, isAutoIncr bool) string {
 	switch val.Kind() {
 	case reflect.Ptr:
@@ -41,13 +49,15 @@ func (d SqliteDialect) ToSqlType(val reflect.Type, maxsize int, isAutoIncr bool)
 	}
 
 	switch val.Name() {
+	case "NullInt32":
+		return "integer"
 	case "NullInt64":
 		return "integer"
 	case "NullFloat64":
 		return "real"
 	case "NullBool":
 		return "integer"
-	case "Time":
+	case "Time", "NullTime":
 		return "datetime"
 	}
 
@@ -119,3 +129,100 @@ func (d SqliteDialect) IfTableExists(command, schema, table string) string {
 func (d SqliteDialect) IfTableNotExists(command, schema, table string) string {
 	return fmt.Sprintf("%s if not exists", command)
 }
+
+// RandomValue scales random(), a signed 64 bit integer, down to [0, 1).
+func (d SqliteDialect) RandomValue() string {
+	return "(abs(random()) / 9223372036854775808.0)"
+}
+
+func (d SqliteDialect) TableSample(percent float64) string {
+	return ""
+}
+
+func (d SqliteDialect) ExplainPrefix(analyze bool) string {
+	if analyze {
+		return ""
+	}
+	return "explain query plan "
+}
+
+func (d SqliteDialect) TempTable(name, sqlType string) string {
+	return "create temp table " + d.QuoteField(name) + " (v " + sqlType + ")"
+}
+
+// Retryable matches SQLITE_BUSY and SQLITE_LOCKED.
+func (d SqliteDialect) Retryable(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked")
+}
+
+var sqliteConstraint = regexp.MustCompile(`^(UNIQUE|FOREIGN KEY|NOT NULL|CHECK) constraint failed(?:: (\S+))?`)
+
+// TranslateError recognizes the "constraint failed" errors.  Sqlite names
+// the table.column of unique and not null violations, and the constraint
+// of check violations.
+func (d SqliteDialect) TranslateError(err error) error {
+	m := sqliteConstraint.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	ce := &ConstraintError{Err: err}
+	switch m[1] {
+	case "UNIQUE":
+		ce.Kind = ErrDuplicateKey
+	case "FOREIGN KEY":
+		ce.Kind = ErrForeignKey
+	case "NOT NULL":
+		ce.Kind = ErrNotNull
+	case "CHECK":
+		ce.Kind = ErrCheck
+	}
+	if ce.Kind == ErrCheck {
+		ce.Constraint = m[2]
+	} else if m[2] != "" {
+		// only the first column of a composite unique key
+		col := strings.SplitN(m[2], ",", 2)[0]
+		ce.Column = col[strings.LastIndex(col, ".")+1:]
+	}
+	return ce
+}
+
+func (d SqliteDialect) ColumnsQuery() string {
+	return `select m.name, c.name, c.type, not c."notnull", c.dflt_value
+from sqlite_master m join pragma_table_info(m.name) c
+where m.type = 'table' and m.name not like 'sqlite_%'
+order by m.name, c.cid`
+}
+
+func (d SqliteDialect) IndexesQuery() string {
+	return `select m.name, i.name, i."unique", c.name
+from sqlite_master m join pragma_index_list(m.name) i join pragma_index_info(i.name) c
+where m.type = 'table' and m.name not like 'sqlite_%'
+order by m.name, i.name, c.seqno`
+}
+
+// ConstraintsQuery selects the primary and foreign keys.  Sqlite doesn't
+// name them, so foreign keys are named by their number in the table and
+// primary keys "".  Unique constraints are found as indexes.
+func (d SqliteDialect) ConstraintsQuery() string {
+	return `select tbl, name, kind, col, ref_table, ref_col from (
+	select m.name as tbl, '' as name, 'PRIMARY KEY' as kind, c.name as col,
+		'' as ref_table, '' as ref_col, c.pk as position
+	from sqlite_master m join pragma_table_info(m.name) c
+	where m.type = 'table' and m.name not like 'sqlite_%' and c.pk > 0
+	union all
+	select m.name, cast(f.id as text), 'FOREIGN KEY', f."from", f."table", f."to", f.seq
+	from sqlite_master m join pragma_foreign_key_list(m.name) f
+	where m.type = 'table' and m.name not like 'sqlite_%'
+)
+order by tbl, name, position`
+}
+
+func (d SqliteDialect) BlobSlice(column, offsetBindVar, lengthBindVar string) string {
+	return fmt.Sprintf("substr(%s, %s, %s)", column, offsetBindVar, lengthBindVar)
+}
+
+// LimitSQL pages with limit and offset, an offset needing a limit of -1.
+func (d SqliteDialect) LimitSQL(query, orderBy string, offset, limit int) string {
+	return limitSQL(query, orderBy, offset, limit, "-1")
+}
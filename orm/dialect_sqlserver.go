@@ -62,6 +62,8 @@ func (d SqlServerDialect) ToSqlType(val reflect.Type, maxsize int, isAutoIncr bo
 	}
 
 	switch val.Name() {
+	case "NullInt32":
+		return "int"
 	case "NullInt64":
 		return "bigint"
 	case "NullFloat64":
@@ -118,6 +120,11 @@ func (d SqlServerDialect) QuoteField(f string) string {
 	return "[" + strings.Replace(f, "]", "]]", -1) + "]"
 }
 
+// LikeWildcards escapes the character ranges of the like patterns.
+func (d SqlServerDialect) LikeWildcards() string {
+	return "["
+}
+
 func (d SqlServerDialect) QuotedTableForQuery(schema string, table string) string {
 	if strings.TrimSpace(schema) == "" {
 		return d.QuoteField(table)
@@ -152,3 +159,29 @@ func (d SqlServerDialect) IfTableNotExists(command, schema, table string) string
 
 func (d SqlServerDialect) CreateIndexSuffix() string { return "" }
 func (d SqlServerDialect) DropIndexSuffix() string   { return "" }
+
+// GeneratedColumn declares a computed column, persisted if stored.  Its
+// type is the type of expr.
+func (d SqlServerDialect) GeneratedColumn(column, stype, expr string, stored bool) string {
+	if stored {
+		return fmt.Sprintf("%s as (%s) persisted", column, expr)
+	}
+	return fmt.Sprintf("%s as (%s)", column, expr)
+}
+
+func (d SqlServerDialect) BlobSlice(column, offsetBindVar, lengthBindVar string) string {
+	return fmt.Sprintf("substring(%s, %s, %s)", column, offsetBindVar, lengthBindVar)
+}
+
+// LimitSQL pages with offset and fetch, of SQL Server 2012 and later,
+// which need an order by clause.
+func (d SqlServerDialect) LimitSQL(query, orderBy string, offset, limit int) string {
+	if orderBy == "" {
+		orderBy = "(select null)"
+	}
+	query += fmt.Sprintf(" order by %s offset %d rows", orderBy, offset)
+	if limit > 0 {
+		query += fmt.Sprintf(" fetch next %d rows only", limit)
+	}
+	return query
+}
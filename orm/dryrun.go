@@ -0,0 +1,70 @@
+package orm
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Statement is a statement recorded in dry-run mode, see DbMap.DryRun.
+type Statement struct {
+	Query string
+	Args  []interface{}
+}
+
+// dryRunResult is the result of the statements recorded in dry-run mode.
+// It reports the row of the model each Insert, Update or Delete writes as
+// affected, so that the versioned models pass their lock check.
+type dryRunResult struct{}
+
+func (dryRunResult) LastInsertId() (int64, error) { return 0, nil }
+func (dryRunResult) RowsAffected() (int64, error) { return 1, nil }
+
+// dryRunLog holds the statements recorded in dry-run mode.
+type dryRunLog struct {
+	mu         sync.Mutex
+	statements []Statement
+}
+
+// DryRun turns the dry-run mode of m on or off.  In dry-run mode the
+// statements sent through Exec, those of Insert, Update, Delete and the
+// criteria writes included, are recorded instead of being run; see
+// Statements.  Queries still run, so that the ORM can read the rows it
+// would write.  Inserts don't read back generated keys, and the models
+// written keep their version and tracked values.  The mode must not be
+// switched while m is in use.
+func (m *DbMap) DryRun(on bool) {
+	switch {
+	case !on:
+		m.dryRun = nil
+	case m.dryRun == nil:
+		m.dryRun = new(dryRunLog)
+	}
+}
+
+// Statements returns the statements recorded in dry-run mode since the
+// last call, and forgets them.
+func (m *DbMap) Statements() []Statement {
+	if m.dryRun == nil {
+		return nil
+	}
+	m.dryRun.mu.Lock()
+	defer m.dryRun.mu.Unlock()
+	statements := m.dryRun.statements
+	m.dryRun.statements = nil
+	return statements
+}
+
+func (m *DbMap) record(query string, args []interface{}) dryRunResult {
+	m.dryRun.mu.Lock()
+	defer m.dryRun.mu.Unlock()
+	m.dryRun.statements = append(m.dryRun.statements, Statement{Query: query, Args: args})
+	return dryRunResult{}
+}
+
+// keepVersion sets the version of elem back to the one it had before bi
+// was bound, the statements of the dry-run mode aren't run.
+func keepVersion(table *modelInfo, elem reflect.Value, bi bindInstance) {
+	if bi.versField != "" {
+		table.fieldValue(elem, bi.versField).SetInt(bi.existingVersion)
+	}
+}
@@ -0,0 +1,74 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+
+	if err := dbmap.Insert(&searchArticle{Body: "orm"}); err != nil {
+		t.Fatal(err)
+	}
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.Like("Body", "go"))
+	if _, err := criteria.Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := dbmap.Statements()
+	if len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %v", statements)
+	}
+	if !strings.HasPrefix(statements[0].Query, "insert into `search_article` ") || len(statements[0].Args) != 1 || statements[0].Args[0] != "orm" {
+		t.Errorf("unexpected insert %q %v", statements[0].Query, statements[0].Args)
+	}
+	if want := "delete from `search_article` where body  like  ?"; statements[1].Query != want {
+		t.Errorf("expected %q, got %q", want, statements[1].Query)
+	}
+	if len(dbmap.Statements()) != 0 {
+		t.Error("statements not forgotten")
+	}
+
+	query, args, err := criteria.SQL()
+	if err != nil || query != "select * from search_article this_ where body  like  ?" || len(args) != 1 {
+		t.Errorf("unexpected criteria sql %q %v (%v)", query, args, err)
+	}
+}
+
+func TestDryRunVersioned(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &versionedNote{})
+	defer Database().Set(nil)
+	table, err := dbmap.TableFor(reflect.TypeOf(versionedNote{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table.SetVersionCol("Version")
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+
+	inserted := &versionedNote{Body: "new"}
+	if err := dbmap.Insert(inserted); err != nil {
+		t.Fatal(err)
+	}
+	if inserted.Version != 0 || inserted.original != nil {
+		t.Errorf("expected the inserted model untouched, got version %d tracking %v", inserted.Version, inserted.original)
+	}
+
+	note := &versionedNote{Id: 1, Body: "body", Version: 3}
+	if count, err := dbmap.Update(note); err != nil || count != 1 {
+		t.Errorf("expected the update to pass its lock check, got %d %v", count, err)
+	}
+	if note.Version != 3 || note.original != nil {
+		t.Errorf("expected the updated model untouched, got version %d tracking %v", note.Version, note.original)
+	}
+	if count, err := dbmap.Delete(note); err != nil || count != 1 {
+		t.Errorf("expected the delete to pass its lock check, got %d %v", count, err)
+	}
+	if statements := dbmap.Statements(); len(statements) != 3 {
+		t.Errorf("expected 3 statements, got %v", statements)
+	}
+}
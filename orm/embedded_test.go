@@ -0,0 +1,64 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedPrefix(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &embedCustomer{})
+	mi, _ := modelCache.get("embed_customer")
+
+	for name, column := range map[string]string{
+		"Billing.City":    "billing_city",
+		"Shipping.Street": "shipping_street",
+	} {
+		fi := mi.fields.GetByName(name)
+		if fi == nil || fi.column != column {
+			t.Errorf("expected field %s on column %s, got %+v", name, column, fi)
+		}
+	}
+	if !strings.Contains(mi.bindGet().query, "`shipping_city`") {
+		t.Errorf("get query misses the embedded columns: %s", mi.bindGet().query)
+	}
+
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+	c := &embedCustomer{Name: "bob", Billing: embedAddress{City: "Paris"}, Shipping: embedAddress{City: "Lyon"}}
+	if err := dbmap.Insert(c); err != nil {
+		t.Fatal(err)
+	}
+	insert := dbmap.Statements()[0]
+	for _, want := range []string{"Paris", "Lyon"} {
+		found := false
+		for _, arg := range insert.Args {
+			found = found || arg == want
+		}
+		if !found {
+			t.Errorf("insert args %v miss %s", insert.Args, want)
+		}
+	}
+
+	index, _ := columnToFieldIndex(dbmap, reflect.TypeOf(embedCustomer{}), "", []string{"billing_city"})
+	if f := reflect.ValueOf(c).Elem().FieldByIndex(index[0]); f.String() != "Paris" {
+		t.Errorf("billing_city scanned into the wrong field %v", index[0])
+	}
+}
+
+type embedBadRel struct {
+	Author *relAuthor `orm:"rel(fk)"`
+}
+
+type embedBad struct {
+	Id  int64       `orm:"pk;auto"`
+	Rel embedBadRel `orm:"embedded"`
+}
+
+func TestEmbeddedRejectsRelations(t *testing.T) {
+	ResetModelCache()
+	defer ResetModelCache()
+	if err := RegisterModelE(new(embedBad)); err == nil {
+		t.Error("expected a relation in an embedded struct to fail")
+	}
+}
@@ -0,0 +1,57 @@
+package orm
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+)
+
+// checkEnums returns an EnumError if a `type(enum=...)` field of elem
+// holds a value its tag doesn't list.  NULL values are left to the
+// constraints of the column.
+func checkEnums(table *modelInfo, elem reflect.Value) error {
+	for _, fi := range table.fields.columns {
+		if len(fi.enum) == 0 {
+			continue
+		}
+		v, ok := enumValue(elem.FieldByIndex(fi.fieldIndex))
+		if !ok {
+			continue
+		}
+		valid := false
+		for _, e := range fi.enum {
+			if v == e {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return &EnumError{TypeName: table.fullName, Field: fi.name, Value: v, Values: fi.enum}
+		}
+	}
+	return nil
+}
+
+// enumValue returns the string held by field, false if it's NULL.
+func enumValue(field reflect.Value) (string, bool) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return "", false
+		}
+		field = field.Elem()
+	}
+	if ns, ok := field.Interface().(sql.NullString); ok {
+		return ns.String, ns.Valid
+	}
+	return field.String(), true
+}
+
+// sqlForEnumValues returns the list of values quoted as SQL literals, for
+// the check constraint of an enum column.
+func sqlForEnumValues(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.Replace(v, "'", "''", -1) + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,45 @@
+package orm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEnumField(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &enumAccount{})
+	defer Database().Set(nil)
+
+	mi, _ := modelCache.get("enum_account")
+	if ddl := mi.SqlForCreate(false); !strings.Contains(ddl, "`status` enum('active', 'blocked', 'deleted')") {
+		t.Errorf("unexpected mysql ddl %q", ddl)
+	}
+
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+	account := &enumAccount{Id: 1, Status: "active"}
+	if err := dbmap.Insert(account); err != nil {
+		t.Fatal(err)
+	}
+	account.Status = "archived"
+	_, err := dbmap.Update(account)
+	var enumErr *EnumError
+	if !errors.As(err, &enumErr) || !errors.Is(err, ErrCheck) || enumErr.Field != "Status" || enumErr.Value != "archived" {
+		t.Errorf("expected an enum error, got %v", err)
+	}
+	kind := "admin"
+	if err = dbmap.Insert(&enumAccount{Status: "blocked", Kind: &kind}); err == nil {
+		t.Error("expected an error inserting an invalid nullable enum")
+	}
+
+	Database().Set(&DbMap{Dialect: PostgresDialect{}})
+	ddl := mi.SqlForCreate(false)
+	for _, check := range []string{
+		`check ("status" in ('active', 'blocked', 'deleted'))`,
+		`check ("kind" in ('user', 'o''brien'))`,
+	} {
+		if !strings.Contains(ddl, check) {
+			t.Errorf("ddl %q missing %q", ddl, check)
+		}
+	}
+}
@@ -12,7 +12,44 @@
 package orm
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
+)
+
+// The errors of the ORM are matched with errors.Is against these, the
+// errors returned carrying the details.
+var (
+	// ErrNoRows is returned by Get and SelectOne when no row matches.  It
+	// is sql.ErrNoRows, so that comparing with the latter keeps working.
+	ErrNoRows = sql.ErrNoRows
+
+	// ErrMultiRows is returned by SelectOne when several rows match.
+	ErrMultiRows = errors.New("orm: multiple rows returned")
+
+	// ErrMissingPK is returned for models without a primary key where one
+	// is needed, and by Get when not given a value for each key column.
+	ErrMissingPK = errors.New("orm: missing primary key")
+
+	// ErrUnknownModel is returned by TableFor and the other lookups of
+	// the models when none is registered for the type or table.
+	ErrUnknownModel = errors.New("orm: unknown model")
+
+	// ErrStaleVersion matches the OptimisticLockError of Update and
+	// Delete.
+	ErrStaleVersion = errors.New("orm: stale version")
+
+	// ErrDuplicateKey matches the UniqueError of Insert and Update, and
+	// the ConstraintError of a unique constraint violation.
+	ErrDuplicateKey = errors.New("orm: duplicate key")
+
+	// ErrForeignKey, ErrNotNull and ErrCheck match the ConstraintError of
+	// the violations of these constraints.
+	ErrForeignKey = errors.New("orm: foreign key violation")
+	ErrNotNull    = errors.New("orm: not null violation")
+	ErrCheck      = errors.New("orm: check violation")
 )
 
 // A non-fatal error, when a select query returns columns that do not exist
@@ -36,3 +73,150 @@ func NonFatalError(err error) bool {
 		return false
 	}
 }
+
+// UniqueError is returned by Insert and Update, when DbMap.CheckUnique is
+// set, if another row already holds the values of a unique field or set
+// of fields.  Fields lists the struct field names, for form validation.
+type UniqueError struct {
+	TypeName string
+	Fields   []string
+}
+
+func (err *UniqueError) Error() string {
+	if len(err.Fields) == 1 {
+		return fmt.Sprintf("orm: a %s with this %s already exists", err.TypeName, err.Fields[0])
+	}
+	return fmt.Sprintf("orm: a %s with these %s already exists", err.TypeName, strings.Join(err.Fields, ", "))
+}
+
+// Is matches ErrDuplicateKey.
+func (err *UniqueError) Is(target error) bool {
+	return target == ErrDuplicateKey
+}
+
+// EnumError is returned by Insert and Update when a field tagged
+// `type(enum=...)` holds a value not listed in its tag.
+type EnumError struct {
+	TypeName string
+	Field    string
+	Value    string
+	Values   []string
+}
+
+func (err *EnumError) Error() string {
+	return fmt.Sprintf("orm: `%s` isn't a value of %s.%s, one of %s", err.Value, err.TypeName, err.Field, strings.Join(err.Values, ", "))
+}
+
+// Is matches ErrCheck, the violation the database would report.
+func (err *EnumError) Is(target error) bool {
+	return target == ErrCheck
+}
+
+// ConstraintError is a constraint violation reported by the database,
+// translated from the driver error by the ErrorTranslator of the dialect.
+type ConstraintError struct {
+	// Kind is ErrDuplicateKey, ErrForeignKey, ErrNotNull or ErrCheck.
+	Kind error
+
+	// Constraint and Column are the violated constraint and column, ""
+	// when the database doesn't tell.
+	Constraint string
+	Column     string
+
+	// Err is the driver error.
+	Err error
+}
+
+func (err *ConstraintError) Error() string {
+	return fmt.Sprintf("%s: %v", err.Kind, err.Err)
+}
+
+// Is matches the Kind of the violation.
+func (err *ConstraintError) Is(target error) bool {
+	return target == err.Kind
+}
+
+func (err *ConstraintError) Unwrap() error {
+	return err.Err
+}
+
+// translateError returns err as translated by the dialect of m.
+func (m *DbMap) translateError(err error) error {
+	var ce *ConstraintError
+	if et, ok := m.Dialect.(ErrorTranslator); ok && err != nil && !errors.As(err, &ce) {
+		return et.TranslateError(err)
+	}
+	return err
+}
+
+// errorField returns the string field of the first of names held by the
+// struct of a driver error, "" if it has none.  The errors err wraps are
+// searched too.
+func errorField(err error, names ...string) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.Indirect(reflect.ValueOf(err))
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		for _, name := range names {
+			if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String && f.String() != "" {
+				return f.String()
+			}
+		}
+	}
+	return ""
+}
+
+// FieldError is returned when naming a field a model doesn't have, eg in
+// the restrictions, orders or updates of a criteria.
+type FieldError struct {
+	TypeName string
+	Field    string
+}
+
+func (err *FieldError) Error() string {
+	return fmt.Sprintf("orm: no field `%s` in `%s`", err.Field, err.TypeName)
+}
+
+// PartialWriteError is returned when writing a partial, which is a read
+// only view of a model, see RegisterPartial.
+type PartialWriteError struct {
+	TypeName  string
+	ModelName string
+}
+
+func (err *PartialWriteError) Error() string {
+	return fmt.Sprintf("orm: %s is a partial of %s, write through the %s model", err.TypeName, err.ModelName, err.ModelName)
+}
+
+// ReadOnlyError is returned when writing a model registered with the
+// ReadOnly option, see ModelOptions.
+type ReadOnlyError struct {
+	TypeName string
+}
+
+func (err *ReadOnlyError) Error() string {
+	return fmt.Sprintf("orm: %s is read only", err.TypeName)
+}
+
+// CriteriaError is returned by the criteria built from wrong input, eg a
+// json path, an unknown association or a dialect without json queries.
+// The criterions panic with it while rendering, and the criteria return
+// it as an error when they run.
+type CriteriaError struct {
+	Op  string // the method given the input, eg "Restrictions.JSONExtract"
+	Err error
+}
+
+func (err *CriteriaError) Error() string {
+	return fmt.Sprintf("<%s> %v", err.Op, err.Err)
+}
+
+func (err *CriteriaError) Unwrap() error {
+	return err.Err
+}
+
+// criteriaError returns the *CriteriaError of op formatting its error.
+func criteriaError(op, format string, args ...interface{}) *CriteriaError {
+	return &CriteriaError{Op: op, Err: fmt.Errorf(format, args...)}
+}
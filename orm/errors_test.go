@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	if !errors.Is(fmt.Errorf("get: %w", sql.ErrNoRows), ErrNoRows) {
+		t.Error("sql.ErrNoRows is not ErrNoRows")
+	}
+	if !errors.Is(&UniqueError{TypeName: "User", Fields: []string{"Email"}}, ErrDuplicateKey) {
+		t.Error("UniqueError is not ErrDuplicateKey")
+	}
+
+	stale := OptimisticLockError{TableName: "post", RowExists: true}
+	if !errors.Is(stale, ErrStaleVersion) || errors.Is(stale, ErrNoRows) {
+		t.Error("unexpected matches of a stale version")
+	}
+	gone := OptimisticLockError{TableName: "post"}
+	if !errors.Is(gone, ErrStaleVersion) || !errors.Is(gone, ErrNoRows) {
+		t.Error("unexpected matches of a deleted row")
+	}
+
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	if _, err := dbmap.Get(searchArticle{}); !errors.Is(err, ErrMissingPK) {
+		t.Errorf("expected ErrMissingPK without key values, got %v", err)
+	}
+}
+
+type pqError struct {
+	Code       string
+	Constraint string
+	Column     string
+	Message    string
+}
+
+func (e *pqError) Error() string    { return "pq: " + e.Message }
+func (e *pqError) SQLState() string { return e.Code }
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		dialect    ErrorTranslator
+		err        error
+		kind       error
+		constraint string
+		column     string
+	}{
+		{PostgresDialect{}, &pqError{Code: "23505", Constraint: "user_email_key", Message: "duplicate key value"}, ErrDuplicateKey, "user_email_key", ""},
+		{PostgresDialect{}, errors.New(`pq: null value in column "title" of relation "post" violates not-null constraint`), ErrNotNull, "", "title"},
+		{PostgresDialect{}, errors.New(`pq: insert or update on table "comment" violates foreign key constraint "comment_post_id_fkey"`), ErrForeignKey, "comment_post_id_fkey", ""},
+		{MySQLDialect{}, errors.New("Error 1062 (23000): Duplicate entry 'bob' for key 'user.email'"), ErrDuplicateKey, "email", ""},
+		{MySQLDialect{}, errors.New("Error 1452: Cannot add or update a child row: a foreign key constraint fails (`blog`.`comment`, CONSTRAINT `fk_post` FOREIGN KEY (`post_id`) REFERENCES `post` (`id`))"), ErrForeignKey, "fk_post", "post_id"},
+		{MySQLDialect{}, errors.New("Error 1048: Column 'title' cannot be null"), ErrNotNull, "", "title"},
+		{SqliteDialect{}, errors.New("UNIQUE constraint failed: user.email"), ErrDuplicateKey, "", "email"},
+		{SqliteDialect{}, errors.New("CHECK constraint failed: positive_price"), ErrCheck, "positive_price", ""},
+	}
+	for _, test := range tests {
+		err := test.dialect.TranslateError(test.err)
+		ce, ok := err.(*ConstraintError)
+		if !ok {
+			t.Errorf("%T: %q not translated", test.dialect, test.err)
+			continue
+		}
+		if !errors.Is(err, test.kind) || ce.Constraint != test.constraint || ce.Column != test.column || errors.Unwrap(err) != test.err {
+			t.Errorf("%T: %q translated to %+v", test.dialect, test.err, ce)
+		}
+	}
+
+	other := errors.New("Error 1146: Table 'blog.post' doesn't exist")
+	if err := (MySQLDialect{}).TranslateError(other); err != other {
+		t.Errorf("translated %q to %v", other, err)
+	}
+}
+
+// dupKeyDriver fails the reads of the rows of its queries with a unique
+// violation, as lib/pq does for insert returning.
+var dupKeyDriver = &fakeDriver{
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		return dupKeyRows{}, nil
+	},
+}
+
+type dupKeyRows struct{}
+
+func (dupKeyRows) Columns() []string { return []string{"id"} }
+func (dupKeyRows) Close() error      { return nil }
+
+func (dupKeyRows) Next(dest []driver.Value) error {
+	return &pqError{Code: "23505", Constraint: "search_article_body_key", Message: "duplicate key value"}
+}
+
+func TestTranslateInsertReturningError(t *testing.T) {
+	dbmap := registerTestModels(t, PostgresDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+	dbmap.Db = dupKeyDriver.open(t)
+
+	err := dbmap.Insert(&searchArticle{Body: "a"})
+	var ce *ConstraintError
+	if !errors.Is(err, ErrDuplicateKey) || !errors.As(err, &ce) || ce.Constraint != "search_article_body_key" {
+		t.Errorf("expected a duplicate key ConstraintError, got %#v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fakeDriver is a database/sql driver scripted by the tests: the statements
+// run on its connections are handed to exec and query, and the ends of
+// their transactions to begin, commit and rollback, when set. Prepared
+// statements aren't supported.
+type fakeDriver struct {
+	exec     func(ctx context.Context, query string, args []driver.Value) (driver.Result, error)
+	query    func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error)
+	begin    func()
+	commit   func()
+	rollback func()
+}
+
+// open returns a database connected to d, closed at the end of the test.
+func (d *fakeDriver) open(t testing.TB) *sql.DB {
+	db := sql.OpenDB(d)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error)            { return fakeConn{d}, nil }
+func (d *fakeDriver) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{d}, nil }
+func (d *fakeDriver) Driver() driver.Driver                            { return d }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                              { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	if c.d.begin != nil {
+		c.d.begin()
+	}
+	return c, nil
+}
+
+func (c fakeConn) Commit() error {
+	if c.d.commit != nil {
+		c.d.commit()
+	}
+	return nil
+}
+
+func (c fakeConn) Rollback() error {
+	if c.d.rollback != nil {
+		c.d.rollback()
+	}
+	return nil
+}
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if c.d.exec == nil {
+		return nil, errors.New("not supported")
+	}
+	return c.d.exec(ctx, query, fakeValues(args))
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.d.query == nil {
+		return nil, errors.New("not supported")
+	}
+	return c.d.query(ctx, query, fakeValues(args))
+}
+
+func fakeValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// fakeResult reports a single row affected, inserted with the id 1.
+type fakeResult struct{}
+
+func (fakeResult) LastInsertId() (int64, error) { return 1, nil }
+func (fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+// fakeRows returns its values, a row after the other.
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
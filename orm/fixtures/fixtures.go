@@ -0,0 +1,249 @@
+// Package fixtures loads test data into the database of the orm.
+//
+// A fixture file, in YAML or JSON, maps table names to labelled rows:
+//
+//	author:
+//	  alice:
+//	    name: Alice
+//	book:
+//	  go:
+//	    title: The Go Programming Language
+//	    author_id: $alice
+//
+// The primary key of a row is derived from its label, see ID, unless the
+// row sets it.  A string value "$label" is replaced by the key of the row
+// labelled label, so rows refer to each other without knowing their keys.
+// Labels are unique across the files loaded together.
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dancewing/revel/orm"
+
+	"gopkg.in/yaml.v2"
+)
+
+// rows are the rows of a fixture file, by table and label.
+type rows map[string]map[string]map[string]interface{}
+
+// fixture is a labelled row of a table.
+type fixture struct {
+	table, label string
+	row          map[string]interface{}
+}
+
+// ID returns the primary key of the row labelled label, unless it sets
+// it.  Tests use it to find the rows of their fixtures.
+func ID(label string) int64 {
+	h := fnv.New32a()
+	h.Write([]byte(label))
+	return int64(h.Sum32() % (1 << 30))
+}
+
+// Load deletes the rows of the tables of the fixture files, then inserts
+// their rows, in one transaction.  The tables are filled in the order of
+// their references.  The primary keys are read with DbMap.Inspect when the
+// dialect supports it, or else are the id columns.
+func Load(dbmap *orm.DbMap, files ...string) error {
+	var fixtures []*fixture
+	for _, file := range files {
+		f, err := read(file)
+		if err != nil {
+			return err
+		}
+		fixtures = append(fixtures, f...)
+	}
+
+	keys, err := primaryKeys(dbmap)
+	if err != nil {
+		return err
+	}
+	ids := make(map[string]interface{})
+	for _, f := range fixtures {
+		if _, ok := ids[f.label]; ok {
+			return fmt.Errorf("fixtures: label `%s` repeated", f.label)
+		}
+		key := keys(f.table)
+		if _, ok := f.row[key]; !ok {
+			f.row[key] = ID(f.label)
+		}
+		ids[f.label] = f.row[key]
+	}
+	deps := make(map[string]map[string]bool)
+	tables := make(map[string]bool)
+	for _, f := range fixtures {
+		tables[f.table] = true
+		for column, value := range f.row {
+			s, ok := value.(string)
+			if !ok || !strings.HasPrefix(s, "$") {
+				continue
+			}
+			id, ok := ids[s[1:]]
+			if !ok {
+				return fmt.Errorf("fixtures: %s.%s references unknown label `%s`", f.table, f.label, s[1:])
+			}
+			f.row[column] = id
+			for _, g := range fixtures {
+				if g.label == s[1:] && g.table != f.table {
+					if deps[f.table] == nil {
+						deps[f.table] = make(map[string]bool)
+					}
+					deps[f.table][g.table] = true
+				}
+			}
+		}
+	}
+	order := sortTables(tables, deps)
+
+	tx, err := dbmap.Begin()
+	if err != nil {
+		return err
+	}
+	d := dbmap.Dialect
+	for i := len(order) - 1; i >= 0; i-- {
+		if _, err = tx.Exec("delete from " + d.QuotedTableForQuery("", order[i]) + d.QuerySuffix()); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, table := range order {
+		for _, f := range fixtures {
+			if f.table != table {
+				continue
+			}
+			if err = insert(tx, d, f); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("fixtures: %s.%s: %s", f.table, f.label, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// read returns the rows of a fixture file, in the order of their tables
+// and labels.
+func read(file string) ([]*fixture, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var r rows
+	switch ext := filepath.Ext(file); ext {
+	case ".json":
+		err = json.Unmarshal(data, &r)
+	case ".yml", ".yaml":
+		err = yaml.Unmarshal(data, &r)
+	default:
+		return nil, fmt.Errorf("fixtures: unknown format `%s` of %s", ext, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: %s: %s", file, err)
+	}
+
+	tables := make([]string, 0, len(r))
+	for table := range r {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	var fixtures []*fixture
+	for _, table := range tables {
+		labels := make([]string, 0, len(r[table]))
+		for label := range r[table] {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			row := r[table][label]
+			if row == nil {
+				row = make(map[string]interface{})
+			}
+			fixtures = append(fixtures, &fixture{table: table, label: label, row: row})
+		}
+	}
+	return fixtures, nil
+}
+
+// primaryKeys returns the primary key column of the tables.
+func primaryKeys(dbmap *orm.DbMap) (func(table string) string, error) {
+	if _, ok := dbmap.Dialect.(orm.Inspector); !ok {
+		return func(string) string { return "id" }, nil
+	}
+	s, err := dbmap.Inspect()
+	if err != nil {
+		return nil, err
+	}
+	return func(table string) string {
+		if t := s.Table(table); t != nil {
+			for _, c := range t.Constraints {
+				if c.Kind == "PRIMARY KEY" && len(c.Columns) == 1 {
+					return c.Columns[0]
+				}
+			}
+		}
+		return "id"
+	}, nil
+}
+
+// insert inserts the row of f.
+func insert(tx *orm.Transaction, d orm.Dialect, f *fixture) error {
+	columns := make([]string, 0, len(f.row))
+	for column := range f.row {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	var (
+		quoted = make([]string, len(columns))
+		binds  = make([]string, len(columns))
+		args   = make([]interface{}, len(columns))
+	)
+	for i, column := range columns {
+		quoted[i] = d.QuoteField(column)
+		binds[i] = d.BindVar(i)
+		args[i] = f.row[column]
+	}
+	_, err := tx.Exec(fmt.Sprintf("insert into %s (%s) values (%s)%s",
+		d.QuotedTableForQuery("", f.table), strings.Join(quoted, ", "), strings.Join(binds, ", "), d.QuerySuffix()), args...)
+	return err
+}
+
+// sortTables returns the tables, those referenced by others first.
+// Tables referencing each other are left in the order of their names.
+func sortTables(tables map[string]bool, deps map[string]map[string]bool) []string {
+	var (
+		order []string
+		done  = make(map[string]bool)
+		visit func(table string, path map[string]bool)
+	)
+	visit = func(table string, path map[string]bool) {
+		if done[table] || path[table] {
+			return
+		}
+		path[table] = true
+		for _, dep := range sortedKeys(deps[table]) {
+			visit(dep, path)
+		}
+		done[table] = true
+		order = append(order, table)
+	}
+	for _, table := range sortedKeys(tables) {
+		visit(table, make(map[string]bool))
+	}
+	return order
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
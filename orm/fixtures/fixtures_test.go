@@ -0,0 +1,72 @@
+package fixtures
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dancewing/revel/orm"
+)
+
+// recordDriver records the statements run in its transactions.
+type recordDriver struct{}
+
+func (recordDriver) Open(name string) (driver.Conn, error) { return recordConn{}, nil }
+
+type recordConn struct{}
+
+var statements []string
+
+func (recordConn) Prepare(query string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (recordConn) Close() error                              { return nil }
+func (recordConn) Begin() (driver.Tx, error)                 { return recordConn{}, nil }
+func (recordConn) Commit() error                             { return nil }
+func (recordConn) Rollback() error                           { return nil }
+
+func (recordConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	for _, arg := range args {
+		query += fmt.Sprintf(" %v", arg.Value)
+	}
+	statements = append(statements, query)
+	return driver.RowsAffected(1), nil
+}
+
+func init() {
+	sql.Register("fixtures_record", recordDriver{})
+}
+
+func TestLoad(t *testing.T) {
+	db, err := sql.Open("fixtures_record", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	dbmap := &orm.DbMap{Db: db, Dialect: orm.SqlServerDialect{}}
+
+	statements = nil
+	if err := Load(dbmap, "testdata/blog.json"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"delete from [book];",
+		"delete from [author];",
+		fmt.Sprintf("insert into [author] ([id], [name]) values (?, ?); %d Alice", ID("alice")),
+		"insert into [author] ([id], [name]) values (?, ?); 7 Bob",
+		fmt.Sprintf("insert into [book] ([author_id], [id], [title]) values (?, ?, ?); %d %d The Go Programming Language", ID("alice"), ID("go")),
+	}
+	if !reflect.DeepEqual(statements, want) {
+		t.Errorf("unexpected statements:\n%s", strings.Join(statements, "\n"))
+	}
+
+	if err := Load(dbmap, "testdata/loop.json"); err == nil || !strings.Contains(err.Error(), "unknown label `dave`") {
+		t.Errorf("expected an unknown label error, got %v", err)
+	}
+	if err := Load(dbmap, "testdata/blog.json", "testdata/blog.json"); err == nil {
+		t.Error("expected repeated labels to fail")
+	}
+}
@@ -0,0 +1,45 @@
+package orm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratedColumns(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &generatedLine{})
+	defer Database().Set(nil)
+
+	mi, _ := modelCache.get("generated_line")
+	ddl := mi.SqlForCreate(false)
+	for _, col := range []string{
+		"`total` bigint generated always as (price * quantity) stored",
+		"`label` varchar(255) generated always as (concat('#', id)) virtual",
+	} {
+		if !strings.Contains(ddl, col) {
+			t.Errorf("ddl %q missing %q", ddl, col)
+		}
+	}
+
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+	line := &generatedLine{Id: 1, Price: 2, Quantity: 3}
+	if err := dbmap.Insert(line); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dbmap.Update(line); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range dbmap.Statements() {
+		if strings.Contains(s.Query, "total") || strings.Contains(s.Query, "label") {
+			t.Errorf("generated column written by %q", s.Query)
+		}
+	}
+	if plan := mi.bindGet(); !strings.Contains(plan.query, "`total`") {
+		t.Errorf("generated column not read by %q", plan.query)
+	}
+
+	Database().Set(&DbMap{Dialect: SqlServerDialect{}})
+	if ddl := mi.SqlForCreate(false); !strings.Contains(ddl, "[total] as (price * quantity) persisted") {
+		t.Errorf("unexpected sql server ddl %q", ddl)
+	}
+}
@@ -0,0 +1,121 @@
+package orm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// genericRel is a field tagged rel(generic), an interface{} holding a
+// pointer to a model of any registered type, eg
+//
+//	Target     interface{} `orm:"rel(generic)"`
+//	TargetType string
+//	TargetId   int64
+//
+// It's not a column: the table of the model is stored in the <Name>Type
+// field and its primary key in the <Name>Id field.  Insert and Update set
+// them from the model held, LoadRelated loads it back.
+type genericRel struct {
+	name   string
+	index  []int
+	typeFi *fieldInfo // <Name>Type, set by BootStrap
+	idFi   *fieldInfo // <Name>Id, set by BootStrap
+}
+
+// bootGeneric finds the fields backing the generic relations of mi.
+func bootGeneric(mi *modelInfo) (errs []error) {
+	for _, g := range mi.generics {
+		g.typeFi = mi.fields.GetByName(g.name + "Type")
+		if g.typeFi == nil || g.typeFi.fieldType != TypeCharField {
+			errs = append(errs, fmt.Errorf("rel(generic) field `%s.%s` needs a string field `%sType`", mi.fullName, g.name, g.name))
+			continue
+		}
+		g.idFi = mi.fields.GetByName(g.name + "Id")
+		if g.idFi == nil || g.idFi.fieldType&(IsIntegerField|TypeCharField) == 0 {
+			errs = append(errs, fmt.Errorf("rel(generic) field `%s.%s` needs an integer or string field `%sId`", mi.fullName, g.name, g.name))
+		}
+	}
+	return errs
+}
+
+// setGenericRels sets the type and id fields of the generic relations of
+// elem holding a model.  Those holding nil keep their fields.
+func setGenericRels(table *modelInfo, elem reflect.Value) error {
+	for _, g := range table.generics {
+		v := elem.FieldByIndex(g.index)
+		if v.IsNil() {
+			continue
+		}
+		rv := v.Elem()
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return fmt.Errorf("<orm.rel(generic)> field `%s` must hold a pointer to a model, got %s", g.name, rv.Type())
+		}
+		mi, ok := modelCache.getByFullName(getFullName(rv.Type().Elem()))
+		if !ok || len(mi.fields.keys) != 1 {
+			return fmt.Errorf("<orm.rel(generic)> field `%s` holds %s, not a registered model with one key", g.name, rv.Type())
+		}
+		pk := rv.Elem().FieldByIndex(mi.fields.GetOnePrimaryKey().fieldIndex)
+		id := elem.FieldByIndex(g.idFi.fieldIndex)
+		if !pk.Type().ConvertibleTo(id.Type()) || (pk.Kind() == reflect.String) != (id.Kind() == reflect.String) {
+			return fmt.Errorf("<orm.rel(generic)> key %s of %s can't be set to field `%s` %s", pk.Type(), mi.fullName, g.idFi.fullName, id.Type())
+		}
+		elem.FieldByIndex(g.typeFi.fieldIndex).SetString(mi.table)
+		id.Set(pk.Convert(id.Type()))
+	}
+	return nil
+}
+
+// LoadRelated loads the models of the generic relations of model named,
+// or of all of them, resolving their type from the <Name>Type fields.
+// Relations with an empty type, or whose model doesn't exist, are set
+// to nil.
+func (m *DbMap) LoadRelated(model interface{}, fields ...string) error {
+	return loadRelated(m, m, model, fields...)
+}
+
+func loadRelated(m *DbMap, exec SqlExecutor, model interface{}, fields ...string) error {
+	table, elem, err := m.tableForPointer(model, false)
+	if err != nil {
+		return err
+	}
+	generics := table.generics
+	if len(fields) > 0 {
+		generics = make([]*genericRel, 0, len(fields))
+		for _, name := range fields {
+			var found *genericRel
+			for _, g := range table.generics {
+				if g.name == name {
+					found = g
+				}
+			}
+			if found == nil {
+				return &FieldError{TypeName: table.fullName, Field: name}
+			}
+			generics = append(generics, found)
+		}
+	}
+
+	for _, g := range generics {
+		v := elem.FieldByIndex(g.index)
+		typ := elem.FieldByIndex(g.typeFi.fieldIndex).String()
+		if typ == "" {
+			v.Set(reflect.Zero(v.Type()))
+			continue
+		}
+		mi, ok := modelCache.get(typ)
+		if !ok {
+			return fmt.Errorf("<orm.LoadRelated> field `%s` of %s refers to unknown table `%s`", g.name, table.fullName, typ)
+		}
+		related, err := get(m, exec, mi.model, elem.FieldByIndex(g.idFi.fieldIndex).Interface())
+		if errors.Is(err, sql.ErrNoRows) {
+			v.Set(reflect.Zero(v.Type()))
+			continue
+		} else if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(related))
+	}
+	return nil
+}
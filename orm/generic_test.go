@@ -0,0 +1,57 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+// genericDriver returns a row holding the key it's queried with, unless
+// it's 404.
+var genericDriver = &fakeDriver{
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		rows := &fakeRows{columns: []string{"id"}}
+		if args[0] != int64(404) {
+			rows.values = [][]driver.Value{{args[0]}}
+		}
+		return rows, nil
+	},
+}
+
+func TestGenericRel(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &genericComment{}, &genericPhoto{})
+	defer Database().Set(nil)
+	dbmap.Db = genericDriver.open(t)
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+
+	comment := &genericComment{Target: &genericPhoto{Id: 5}}
+	if err := dbmap.Insert(comment); err != nil {
+		t.Fatal(err)
+	}
+	if comment.TargetType != "generic_photo" || comment.TargetId != 5 {
+		t.Errorf("unexpected target fields %q %d", comment.TargetType, comment.TargetId)
+	}
+	if err := dbmap.Insert(&genericComment{Target: genericPhoto{Id: 5}}); err == nil {
+		t.Error("expected an error inserting a target which isn't a pointer")
+	}
+
+	loaded := &genericComment{TargetType: "generic_photo", TargetId: 7}
+	if err := dbmap.LoadRelated(loaded); err != nil {
+		t.Fatal(err)
+	}
+	if photo, ok := loaded.Target.(*genericPhoto); !ok || photo.Id != 7 {
+		t.Errorf("expected photo 7, got %#v", loaded.Target)
+	}
+	loaded.TargetId = 404
+	if err := dbmap.LoadRelated(loaded, "Target"); err != nil || loaded.Target != nil {
+		t.Errorf("expected no target, got %#v (%v)", loaded.Target, err)
+	}
+	loaded.TargetType = "unknown"
+	if err := dbmap.LoadRelated(loaded); err == nil {
+		t.Error("expected an error loading an unknown table")
+	}
+	if err := dbmap.LoadRelated(loaded, "Body"); err == nil {
+		t.Error("expected an error loading an unknown field")
+	}
+}
@@ -12,6 +12,7 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
@@ -86,7 +87,7 @@ type TypeConverter interface {
 // Executor exposes the sql.DB and sql.Tx Exec function so that it can be used
 // on internal functions that convert named parameters for the Exec function.
 type executor interface {
-	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
 // SqlExecutor exposes gorp operations that can be run from Pre/Post
@@ -150,9 +151,9 @@ func argsString(args ...interface{}) string {
 	return margs
 }
 
-// Calls the Exec function on the executor, but attempts to expand any eligible named
+// Calls the Exec function on the executor with ctx, but attempts to expand any eligible named
 // query arguments first.
-func exec(e SqlExecutor, query string, args ...interface{}) (sql.Result, error) {
+func exec(ctx context.Context, e SqlExecutor, query string, args ...interface{}) (sql.Result, error) {
 	var dbMap *DbMap
 	var executor executor
 	switch m := e.(type) {
@@ -167,8 +168,14 @@ func exec(e SqlExecutor, query string, args ...interface{}) (sql.Result, error)
 	if len(args) == 1 {
 		query, args = maybeExpandNamedQuery(dbMap, query, args)
 	}
+	if dbMap.dryRun != nil {
+		return dbMap.record(query, args), nil
+	}
 
-	return executor.Exec(query, args...)
+	ctx, cancel := statementContext(ctx, dbMap.queryTimeout())
+	defer cancel()
+	res, err := executor.ExecContext(ctx, query, args...)
+	return res, dbMap.translateError(err)
 }
 
 // maybeExpandNamedQuery checks the given arg to see if it's eligible to be used
@@ -245,6 +252,22 @@ func columnToFieldIndex(m *DbMap, t reflect.Type, name string, cols []string) ([
 	missingColNames := []string{}
 	for x := range cols {
 		colName := strings.ToLower(cols[x])
+		if m.mapper != nil {
+			colName = strings.ToLower(m.mapper(cols[x]))
+		}
+		if tableMapped {
+			// by index, for the fields of embedded structs
+			if fi := table.fields.GetByColumn(colName); fi != nil && fi.fieldIndex != nil {
+				colToFieldIndex[x] = fi.fieldIndex
+				continue
+			}
+			if m.mapper != nil {
+				if fi, ok := table.fields.GetByAny(m.mapper(cols[x])); ok && fi.fieldIndex != nil {
+					colToFieldIndex[x] = fi.fieldIndex
+					continue
+				}
+			}
+		}
 		field, found := t.FieldByNameFunc(func(fieldName string) bool {
 			// field, _ := t.FieldByName(fieldName)
 			// cArguments := strings.Split(field.Tag.Get("orm"), ";")
@@ -343,7 +366,10 @@ type foundTable struct {
 func tableFor(m *DbMap, t reflect.Type, i interface{}) (*foundTable, error) {
 	if dyn, isDynamic := i.(DynamicTable); isDynamic {
 		tableName := dyn.TableName()
-		table, err := m.DynamicTableFor(tableName, true)
+		table, err := m.DynamicTableFor(tableName)
+		if err == nil {
+			err = table.requirePK()
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -352,7 +378,10 @@ func tableFor(m *DbMap, t reflect.Type, i interface{}) (*foundTable, error) {
 			dynName: &tableName,
 		}, nil
 	}
-	table, err := m.TableFor(t, true)
+	table, err := m.TableFor(t)
+	if err == nil {
+		err = table.requirePK()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -373,6 +402,10 @@ func get(m *DbMap, exec SqlExecutor, i interface{},
 	}
 	table := foundTable.table
 
+	if len(keys) != len(table.fields.keys) {
+		return nil, fmt.Errorf("%w: %s has %d key columns, got %d values", ErrMissingPK, table.table, len(table.fields.keys), len(keys))
+	}
+
 	plan := table.bindGet()
 
 	v := reflect.New(t)
@@ -381,15 +414,17 @@ func get(m *DbMap, exec SqlExecutor, i interface{},
 		retDyn.SetTableName(*foundTable.dynName)
 	}
 
-	dest := make([]interface{}, len(plan.argFields))
+	destp := plan.dests.get(len(plan.argFields))
+	defer plan.dests.put(destp)
+	dest := *destp
 
 	conv := m.TypeConverter
-	custScan := make([]CustomScanner, 0)
+	var custScan []CustomScanner
 
 	for x, fieldName := range plan.argFields {
-		f := v.Elem().FieldByName(fieldName)
-		target := f.Addr().Interface()
-		if conv != nil {
+		f := plan.field(v.Elem(), plan.argIndexes[x], fieldName)
+		target, ok := scanTarget(plan.argCols[x], f.Addr().Interface())
+		if !ok && conv != nil {
 			scanner, ok := conv.FromDb(target)
 			if ok {
 				target = scanner.Holder
@@ -399,12 +434,12 @@ func get(m *DbMap, exec SqlExecutor, i interface{},
 		dest[x] = target
 	}
 
-	row := exec.QueryRow(plan.query, keys...)
-	err = row.Scan(dest...)
+	query, args, err := scopedQuery(m, table, plan.query, keys)
+	if err != nil {
+		return nil, err
+	}
+	err = scanRow(exec, query, args, dest...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			err = nil
-		}
 		return nil, err
 	}
 
@@ -414,6 +449,7 @@ func get(m *DbMap, exec SqlExecutor, i interface{},
 			return nil, err
 		}
 	}
+	trackChanges(table, v.Elem())
 
 	if v, ok := v.Interface().(HasPostGet); ok {
 		err := v.PostGet(exec)
@@ -425,13 +461,28 @@ func get(m *DbMap, exec SqlExecutor, i interface{},
 	return v.Interface(), nil
 }
 
-func delete(m *DbMap, exec SqlExecutor, list ...interface{}) (int64, error) {
+func deleteRows(m *DbMap, exec SqlExecutor, list ...interface{}) (int64, error) {
 	count := int64(0)
 	for _, ptr := range list {
 		table, elem, err := m.tableForPointer(ptr, true)
 		if err != nil {
 			return -1, err
 		}
+		if err = table.writable(); err != nil {
+			return -1, err
+		}
+		if treeTransaction(m, exec, table) {
+			var n int64
+			err = m.RunInTransaction(func(tx *Transaction) (err error) {
+				n, err = deleteRows(m, tx, ptr)
+				return err
+			})
+			if err != nil {
+				return -1, err
+			}
+			count += n
+			continue
+		}
 
 		eval := elem.Addr().Interface()
 		if v, ok := eval.(HasPreDelete); ok {
@@ -446,6 +497,10 @@ func delete(m *DbMap, exec SqlExecutor, list ...interface{}) (int64, error) {
 			return -1, err
 		}
 
+		if err = treeDelete(m, exec, table, elem); err != nil {
+			return -1, err
+		}
+
 		res, err := exec.Exec(bi.query, bi.args...)
 		if err != nil {
 			return -1, err
@@ -462,6 +517,10 @@ func delete(m *DbMap, exec SqlExecutor, list ...interface{}) (int64, error) {
 
 		count += rows
 
+		if err = touchRelated(exec, table, elem); err != nil {
+			return -1, err
+		}
+
 		if v, ok := eval.(HasPostDelete); ok {
 			err := v.PostDelete(exec)
 			if err != nil {
@@ -480,6 +539,9 @@ func update(m *DbMap, exec SqlExecutor, colFilter ColumnFilter, list ...interfac
 		if err != nil {
 			return -1, err
 		}
+		if err = table.writable(); err != nil {
+			return -1, err
+		}
 
 		eval := elem.Addr().Interface()
 		if v, ok := eval.(HasPreUpdate); ok {
@@ -489,7 +551,31 @@ func update(m *DbMap, exec SqlExecutor, colFilter ColumnFilter, list ...interfac
 			}
 		}
 
-		bi, err := table.bindUpdate(elem, colFilter)
+		if m.checkUnique() {
+			if err = checkUnique(m, exec, table, elem); err != nil {
+				return -1, err
+			}
+		}
+
+		if err = setAuditUser(m, table, elem, false); err != nil {
+			return -1, err
+		}
+		if err = checkEnums(table, elem); err != nil {
+			return -1, err
+		}
+		if err = setGenericRels(table, elem); err != nil {
+			return -1, err
+		}
+
+		filter := colFilter
+		if filter == nil {
+			var changed bool
+			if filter, changed = changedFilter(table, elem); !changed {
+				continue
+			}
+		}
+
+		bi, err := table.bindUpdate(elem, filter)
 		if err != nil {
 			return -1, err
 		}
@@ -509,11 +595,19 @@ func update(m *DbMap, exec SqlExecutor, colFilter ColumnFilter, list ...interfac
 				bi.existingVersion, elem, bi.keys...)
 		}
 
-		if bi.versField != "" {
-			elem.FieldByName(bi.versField).SetInt(bi.existingVersion + 1)
+		count += rows
+		if m.dryRun != nil {
+			keepVersion(table, elem, bi)
+		} else {
+			if bi.versField != "" {
+				table.fieldValue(elem, bi.versField).SetInt(bi.existingVersion + 1)
+			}
+			trackChanges(table, elem)
 		}
 
-		count += rows
+		if err = touchRelated(exec, table, elem); err != nil {
+			return -1, err
+		}
 
 		if v, ok := eval.(HasPostUpdate); ok {
 			err = v.PostUpdate(exec)
@@ -525,19 +619,47 @@ func update(m *DbMap, exec SqlExecutor, colFilter ColumnFilter, list ...interfac
 	return count, nil
 }
 
+// updateColumns updates the columns of the fields of model named.
+func updateColumns(m *DbMap, exec SqlExecutor, model interface{}, fields []string) (int64, error) {
+	if len(fields) == 0 {
+		return -1, fmt.Errorf("<orm.UpdateColumns> no field given")
+	}
+	table, _, err := m.tableForPointer(model, true)
+	if err != nil {
+		return -1, err
+	}
+	named := make(map[*fieldInfo]bool, len(fields))
+	for _, name := range fields {
+		fi := table.fields.GetByName(name)
+		if fi == nil || !fi.dbcol {
+			return -1, &FieldError{TypeName: table.fullName, Field: name}
+		}
+		named[fi] = true
+	}
+	return update(m, exec, func(col *fieldInfo) bool {
+		return named[col] || col == table.version || col.autoUser
+	}, model)
+}
+
 func insert(m *DbMap, exec SqlExecutor, list ...interface{}) error {
 	for _, ptr := range list {
 		table, elem, err := m.tableForPointer(ptr, false)
 		if err != nil {
 			return err
 		}
-
-		eval := elem.Addr().Interface()
-		if v, ok := eval.(HasPreInsert); ok {
-			err := v.PreInsert(exec)
-			if err != nil {
+		if err = table.writable(); err != nil {
+			return err
+		}
+		if treeTransaction(m, exec, table) {
+			if err = m.RunInTransaction(func(tx *Transaction) error { return insert(m, tx, ptr) }); err != nil {
 				return err
 			}
+			continue
+		}
+
+		eval := elem.Addr().Interface()
+		if err = prepareInsert(m, exec, table, elem); err != nil {
+			return err
 		}
 
 		bi, err := table.bindInsert(elem)
@@ -545,8 +667,17 @@ func insert(m *DbMap, exec SqlExecutor, list ...interface{}) error {
 			return err
 		}
 
-		if bi.autoIncrIdx > -1 {
-			f := elem.FieldByName(bi.autoIncrFieldName)
+		if m.dryRun != nil {
+			// keys are not generated, record the plain insert
+			if _, err = exec.Exec(bi.query, bi.args...); err != nil {
+				return err
+			}
+		} else if len(bi.returnFields) > 0 {
+			if err = insertReturning(m, exec, table, bi, elem); err != nil {
+				return err
+			}
+		} else if bi.autoIncrIdx > -1 {
+			f := table.fieldValue(elem, bi.autoIncrFieldName)
 			switch inserter := m.Dialect.(type) {
 			case IntegerAutoIncrInserter:
 				id, err := inserter.InsertAutoIncr(exec, bi.query, bi.args...)
@@ -564,7 +695,7 @@ func insert(m *DbMap, exec SqlExecutor, list ...interface{}) error {
 			case TargetedAutoIncrInserter:
 				err := inserter.InsertAutoIncrToTarget(exec, bi.query, f.Addr().Interface(), bi.args...)
 				if err != nil {
-					return err
+					return m.translateError(err)
 				}
 			case TargetQueryInserter:
 				var idQuery = table.ColMap(bi.autoIncrFieldName).GeneratedIdQuery
@@ -585,6 +716,19 @@ func insert(m *DbMap, exec SqlExecutor, list ...interface{}) error {
 			}
 		}
 
+		if err = treeInsert(m, exec, table, elem); err != nil {
+			return err
+		}
+		if m.dryRun != nil {
+			keepVersion(table, elem, bi)
+		} else {
+			trackChanges(table, elem)
+		}
+
+		if err = touchRelated(exec, table, elem); err != nil {
+			return err
+		}
+
 		if v, ok := eval.(HasPostInsert); ok {
 			err := v.PostInsert(exec)
 			if err != nil {
@@ -595,12 +739,95 @@ func insert(m *DbMap, exec SqlExecutor, list ...interface{}) error {
 	return nil
 }
 
+// prepareInsert runs the PreInsert hook of elem, then checks and fills
+// its fields before it's bound.
+func prepareInsert(m *DbMap, exec SqlExecutor, table *modelInfo, elem reflect.Value) error {
+	if v, ok := elem.Addr().Interface().(HasPreInsert); ok {
+		if err := v.PreInsert(exec); err != nil {
+			return err
+		}
+	}
+	if m.checkUnique() {
+		if err := checkUnique(m, exec, table, elem); err != nil {
+			return err
+		}
+	}
+	if err := setAuditUser(m, table, elem, true); err != nil {
+		return err
+	}
+	if err := checkEnums(table, elem); err != nil {
+		return err
+	}
+	return setGenericRels(table, elem)
+}
+
+// insertReturning runs an insert ending with a returning clause and
+// scans the returned columns back into elem.
+func insertReturning(m *DbMap, exec SqlExecutor, table *modelInfo, bi bindInstance, elem reflect.Value) error {
+	dest := make([]interface{}, len(bi.returnFields))
+	custScan := make([]CustomScanner, 0)
+	for x, fieldName := range bi.returnFields {
+		target, ok := scanTarget(table.fields.GetByName(fieldName), table.fieldValue(elem, fieldName).Addr().Interface())
+		if !ok && m.TypeConverter != nil {
+			if scanner, ok := m.TypeConverter.FromDb(target); ok {
+				target = scanner.Holder
+				custScan = append(custScan, scanner)
+			}
+		}
+		dest[x] = target
+	}
+
+	if err := scanRow(exec, bi.query, bi.args, dest...); err != nil {
+		return m.translateError(err)
+	}
+	for _, c := range custScan {
+		if err := c.Bind(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// touchRelated bumps the timestamp field named by the touch tag on
+// every related row of elem, using the same executor so the parent is
+// updated inside the caller's transaction.
+func touchRelated(exec SqlExecutor, table *modelInfo, elem reflect.Value) error {
+	for _, fi := range table.fields.fieldsRel {
+		if fi.touch == "" {
+			continue
+		}
+		rv := elem.FieldByIndex(fi.fieldIndex)
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			continue
+		}
+		rmi := fi.relModelInfo
+		_, pk, exist := getExistPk(rmi, rv.Elem())
+		if !exist {
+			continue
+		}
+
+		tfi := rmi.fields.GetByName(fi.touch)
+		now := time.Now().In(DefaultTimeLoc)
+		if _, err := exec.Exec(rmi.sqlForTouch(tfi), now, pk); err != nil {
+			return err
+		}
+
+		if f := rv.Elem().FieldByIndex(tfi.fieldIndex); f.CanSet() && f.Type() == reflect.TypeOf(now) {
+			f.Set(reflect.ValueOf(now))
+		}
+	}
+	return nil
+}
+
 func saveM2M(m *DbMap, exec SqlExecutor, model interface{}, fields ...string) error {
 
 	table, elem, err := m.tableForPointer(model, false)
 	if err != nil {
 		return err
 	}
+	if err = table.writable(); err != nil {
+		return err
+	}
 
 	//eval := elem.Addr().Interface()
 	// if v, ok := eval.(HasPreInsert); ok {
@@ -459,7 +459,7 @@ func dynamicTablesTestmodelInfo(t *testing.T,
 
 	tableName := inpInst.TableName()
 
-	tblMap, err := dbmap.DynamicTableFor(tableName, true)
+	tblMap, err := dbmap.DynamicTableFor(tableName)
 	if err != nil {
 		t.Errorf("Error while searching for tablemap for tableName: %v, Error:%v", tableName, err)
 	}
@@ -1486,7 +1486,7 @@ func TestCrud(t *testing.T) {
 }
 
 func testCrudInternal(t *testing.T, dbmap *gorp.DbMap, val testable) {
-	table, err := dbmap.TableFor(reflect.TypeOf(val).Elem(), false)
+	table, err := dbmap.TableFor(reflect.TypeOf(val).Elem())
 	if err != nil {
 		t.Errorf("couldn't call TableFor: val=%v err=%v", val, err)
 	}
@@ -1574,9 +1574,7 @@ func TestColumnFilter(t *testing.T) {
 
 	inv1.Memo = "c"
 	inv1.IsPaid = true
-	_updateColumns(dbmap, func(col *gorp.fieldInfo) bool {
-		return col.ColumnName == "Memo"
-	}, inv1)
+	_updateColumns(dbmap, inv1, "Memo")
 
 	inv2 := &Invoice{}
 	inv2 = _get(dbmap, inv2, inv1.Id).(*Invoice)
@@ -2583,8 +2581,8 @@ func _update(dbmap *gorp.DbMap, list ...interface{}) int64 {
 	return count
 }
 
-func _updateColumns(dbmap *gorp.DbMap, filter gorp.ColumnFilter, list ...interface{}) int64 {
-	count, err := dbmap.UpdateColumns(filter, list...)
+func _updateColumns(dbmap *gorp.DbMap, model interface{}, fields ...string) int64 {
+	count, err := dbmap.UpdateColumns(model, fields...)
 	if err != nil {
 		panic(err)
 	}
@@ -2681,7 +2679,7 @@ func rawSelect(dbmap *gorp.DbMap, i interface{}, query string, args ...interface
 
 func tableName(dbmap *gorp.DbMap, i interface{}) string {
 	t := reflect.TypeOf(i)
-	if table, err := dbmap.TableFor(t, false); table != nil && err == nil {
+	if table, err := dbmap.TableFor(t); table != nil && err == nil {
 		return dbmap.Dialect.QuoteField(table.TableName)
 	}
 	return t.Name()
@@ -2689,7 +2687,7 @@ func tableName(dbmap *gorp.DbMap, i interface{}) string {
 
 func columnName(dbmap *gorp.DbMap, i interface{}, fieldName string) string {
 	t := reflect.TypeOf(i)
-	if table, err := dbmap.TableFor(t, false); table != nil && err == nil {
+	if table, err := dbmap.TableFor(t); table != nil && err == nil {
 		return dbmap.Dialect.QuoteField(table.ColMap(fieldName).ColumnName)
 	}
 	return fieldName
@@ -11,6 +11,11 @@
 
 package orm
 
+import (
+	"fmt"
+	"strings"
+)
+
 // IndexMap represents a mapping between a Go struct field and a single
 // index in a table.
 // Unique and MaxSize only inform the
@@ -54,3 +59,72 @@ func (idx *IndexMap) SetIndexType(indtype string) *IndexMap {
 	idx.IndexType = indtype
 	return idx
 }
+
+// parseModelTag reads the tag of the blank field of a model, declaring
+// its composite indexes and unique constraints by field names:
+//
+//	type Post struct {
+//		_       struct{} `orm:"index(Author,Created);unique(Site,Slug)"`
+//		Id      int64    `orm:"pk;auto"`
+//		...
+//	}
+func (t *modelInfo) parseModelTag(data string) error {
+	for _, v := range strings.Split(data, defaultStructTagDelim) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		i := strings.Index(v, "(")
+		if i < 0 || !strings.HasSuffix(v, ")") {
+			return fmt.Errorf("wrong model tag `%s`", v)
+		}
+		names := strings.Split(v[i+1:len(v)-1], ",")
+		for j := range names {
+			names[j] = strings.TrimSpace(names[j])
+		}
+		switch strings.ToLower(v[:i]) {
+		case "index":
+			t.tagIndexes = append(t.tagIndexes, names)
+		case "unique":
+			t.tagUniques = append(t.tagUniques, names)
+		default:
+			return fmt.Errorf("model tag only allow index and unique, unknown `%s`", v)
+		}
+	}
+	return nil
+}
+
+// bootIndexes adds the composite indexes and unique constraints of the
+// model tag and of the TableIndex and TableUnique methods of mi, eg
+//
+//	func (p *Post) TableIndex() [][]string {
+//		return [][]string{{"Author", "Created"}}
+//	}
+//
+// They are created by CreateIndex and CreateTables, named by the naming
+// strategy of the model.
+func bootIndexes(mi *modelInfo) (errs []error) {
+	columns := func(names []string) []string {
+		cols := make([]string, 0, len(names))
+		for _, name := range names {
+			fi, ok := mi.fields.GetByAny(name)
+			if !ok || !fi.dbcol {
+				errs = append(errs, fmt.Errorf("model `%s` has no field `%s` to index", mi.fullName, name))
+				return nil
+			}
+			cols = append(cols, fi.column)
+		}
+		return cols
+	}
+	for _, names := range append(getTableIndex(mi.addrField), mi.tagIndexes...) {
+		if cols := columns(names); len(cols) > 0 {
+			mi.AddIndex("", "", cols)
+		}
+	}
+	for _, names := range append(getTableUnique(mi.addrField), mi.tagUniques...) {
+		if cols := columns(names); len(cols) > 0 {
+			mi.uniqueTogether = append(mi.uniqueTogether, cols)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,49 @@
+package orm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompositeIndexes(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &indexedPost{})
+	defer Database().Set(nil)
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+
+	if err := dbmap.CreateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	statements := dbmap.Statements()
+	want := []string{
+		"create index idx_indexed_post_title_author on indexed_post (`title`, `author`);",
+		"create index idx_indexed_post_author_created on indexed_post (`author`, `created`);",
+	}
+	if len(statements) != len(want) {
+		t.Fatalf("expected %d statements, got %v", len(want), statements)
+	}
+	for i, s := range statements {
+		if s.Query != want[i] {
+			t.Errorf("expected %q, got %q", want[i], s.Query)
+		}
+	}
+
+	mi, _ := modelCache.get("indexed_post")
+	if ddl := mi.SqlForCreate(false); !strings.Contains(ddl, "unique (`site`, `slug`)") {
+		t.Errorf("unique constraint missing from %q", ddl)
+	}
+}
+
+type badIndexPost struct {
+	_  struct{} `orm:"index(Id,Missing)"`
+	Id int64    `orm:"pk;auto"`
+}
+
+func TestCompositeIndexUnknownField(t *testing.T) {
+	ResetModelCache()
+	RegisterModel(&badIndexPost{})
+	err := BootStrapE()
+	if err == nil || !strings.Contains(err.Error(), "no field `Missing`") {
+		t.Errorf("expected an unknown field error, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DbSchema is the schema of a database, as read by DbMap.Inspect.
+type DbSchema struct {
+	Tables []*TableSchema
+}
+
+// Table returns the table named name, or nil.
+func (s *DbSchema) Table(name string) *TableSchema {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// table returns the table named name, adding it if missing.  The tables
+// are read in order, so it is the last one when already there.
+func (s *DbSchema) table(name string) *TableSchema {
+	if n := len(s.Tables); n > 0 && s.Tables[n-1].Name == name {
+		return s.Tables[n-1]
+	}
+	if t := s.Table(name); t != nil {
+		return t
+	}
+	t := &TableSchema{Name: name}
+	s.Tables = append(s.Tables, t)
+	return t
+}
+
+// TableSchema is a table of a DbSchema.
+type TableSchema struct {
+	Name        string
+	Columns     []*ColumnSchema
+	Indexes     []*IndexSchema
+	Constraints []*ConstraintSchema
+}
+
+// Column returns the column named name, or nil.
+func (t *TableSchema) Column(name string) *ColumnSchema {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// ColumnSchema is a column of a TableSchema.  Type is the type as named by
+// the database, eg "character varying" or "varchar(255)".
+type ColumnSchema struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  sql.NullString
+}
+
+// IndexSchema is an index of a TableSchema, with its columns in order.
+type IndexSchema struct {
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+// ConstraintSchema is a constraint of a TableSchema.  Kind is "PRIMARY
+// KEY", "UNIQUE", "FOREIGN KEY" or "CHECK", and RefTable and RefColumns
+// are the columns referenced by a foreign key.
+type ConstraintSchema struct {
+	Name       string
+	Kind       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// Inspect reads the tables of the database, with their columns, indexes
+// and constraints, to compare them with the registered models.  The
+// dialect must be an Inspector.
+func (m *DbMap) Inspect() (*DbSchema, error) {
+	in, ok := m.Dialect.(Inspector)
+	if !ok {
+		return nil, fmt.Errorf("dialect %T does not support inspection", m.Dialect)
+	}
+	s := &DbSchema{}
+
+	err := m.inspect(in.ColumnsQuery(), func(scan func(...interface{}) error) error {
+		var table string
+		c := &ColumnSchema{}
+		if err := scan(&table, &c.Name, &c.Type, &c.Nullable, &c.Default); err != nil {
+			return err
+		}
+		t := s.table(table)
+		t.Columns = append(t.Columns, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.inspect(in.IndexesQuery(), func(scan func(...interface{}) error) error {
+		var table, name, column string
+		var unique bool
+		if err := scan(&table, &name, &unique, &column); err != nil {
+			return err
+		}
+		t := s.table(table)
+		if n := len(t.Indexes); n == 0 || t.Indexes[n-1].Name != name {
+			t.Indexes = append(t.Indexes, &IndexSchema{Name: name, Unique: unique})
+		}
+		idx := t.Indexes[len(t.Indexes)-1]
+		idx.Columns = append(idx.Columns, column)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.inspect(in.ConstraintsQuery(), func(scan func(...interface{}) error) error {
+		var table, name, kind, column, refTable, refColumn string
+		if err := scan(&table, &name, &kind, &column, &refTable, &refColumn); err != nil {
+			return err
+		}
+		t := s.table(table)
+		if n := len(t.Constraints); n == 0 || t.Constraints[n-1].Name != name || t.Constraints[n-1].Kind != kind {
+			t.Constraints = append(t.Constraints, &ConstraintSchema{Name: name, Kind: kind, RefTable: refTable})
+		}
+		c := t.Constraints[len(t.Constraints)-1]
+		c.Columns = append(c.Columns, column)
+		if refColumn != "" {
+			c.RefColumns = append(c.RefColumns, refColumn)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// inspect runs query, calling row with the scan of every row.
+func (m *DbMap) inspect(query string, row func(scan func(...interface{}) error) error) error {
+	rows, done, err := queryRows(m, query)
+	if err != nil {
+		return err
+	}
+	defer done()
+	defer rows.Close()
+	for rows.Next() {
+		if err := row(rows.Scan); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,72 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// schemaDriver answers the inspection queries of SqliteDialect with the
+// schema of a rel_author and a rel_book table.
+var schemaDriver = &fakeDriver{
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		switch {
+		case strings.Contains(query, "pragma_index_list"):
+			return &fakeRows{columns: []string{"table", "name", "unique", "column"}, values: [][]driver.Value{
+				{"rel_book", "idx_rel_book_author", int64(0), "author_id"},
+				{"rel_book", "idx_rel_book_author", int64(0), "editor_id"},
+			}}, nil
+		case strings.Contains(query, "pragma_foreign_key_list"):
+			return &fakeRows{columns: []string{"table", "name", "kind", "column", "ref_table", "ref_column"}, values: [][]driver.Value{
+				{"rel_author", "", "PRIMARY KEY", "id", "", ""},
+				{"rel_book", "", "PRIMARY KEY", "id", "", ""},
+				{"rel_book", "0", "FOREIGN KEY", "author_id", "rel_author", "id"},
+				{"rel_book", "1", "FOREIGN KEY", "editor_id", "rel_author", "id"},
+			}}, nil
+		}
+		return &fakeRows{columns: []string{"table", "name", "type", "nullable", "default"}, values: [][]driver.Value{
+			{"rel_author", "id", "integer", int64(0), nil},
+			{"rel_author", "name", "varchar(255)", int64(1), "''"},
+			{"rel_book", "id", "integer", int64(0), nil},
+			{"rel_book", "author_id", "integer", int64(1), nil},
+			{"rel_book", "editor_id", "integer", int64(1), nil},
+		}}, nil
+	},
+}
+
+func TestInspect(t *testing.T) {
+	dbmap := &DbMap{Db: schemaDriver.open(t), Dialect: SqliteDialect{}}
+
+	s, err := dbmap.Inspect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Tables) != 2 {
+		t.Fatalf("expected 2 tables, got %d", len(s.Tables))
+	}
+	author := s.Table("rel_author")
+	if c := author.Column("name"); c == nil || c.Type != "varchar(255)" || !c.Nullable || c.Default.String != "''" {
+		t.Errorf("unexpected column %+v", c)
+	}
+	if c := author.Column("id"); c.Nullable || c.Default.Valid {
+		t.Errorf("unexpected column %+v", c)
+	}
+
+	book := s.Table("rel_book")
+	if len(book.Indexes) != 1 || !reflect.DeepEqual(book.Indexes[0].Columns, []string{"author_id", "editor_id"}) || book.Indexes[0].Unique {
+		t.Errorf("unexpected indexes %+v", book.Indexes)
+	}
+	if len(book.Constraints) != 3 {
+		t.Fatalf("expected 3 constraints, got %+v", book.Constraints)
+	}
+	if fk := book.Constraints[2]; fk.Kind != "FOREIGN KEY" || fk.RefTable != "rel_author" ||
+		!reflect.DeepEqual(fk.Columns, []string{"editor_id"}) || !reflect.DeepEqual(fk.RefColumns, []string{"id"}) {
+		t.Errorf("unexpected foreign key %+v", fk)
+	}
+
+	if _, err := (&DbMap{Db: dbmap.Db, Dialect: SqlServerDialect{}}).Inspect(); err == nil {
+		t.Error("expected inspection to fail on a dialect without Inspector")
+	}
+}
@@ -0,0 +1,128 @@
+package orm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Interval wraps a time.Duration, or a pointer to one, so it is bound to
+// and scanned from a Postgres interval column.  time.Duration fields are
+// stored as bigint nanoseconds, unless tagged `type(interval)` which wraps
+// them automatically.
+//
+// Example:
+//
+//	dbmap.Select(&jobs, "select * from jobs where timeout > $1", orm.Interval(time.Minute))
+func Interval(d interface{}) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	return durationInterval{d}
+}
+
+type durationInterval struct {
+	d interface{}
+}
+
+// Value implements the driver Valuer interface.
+func (i durationInterval) Value() (driver.Value, error) {
+	v := reflect.ValueOf(i.d)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() != durationType {
+		return nil, fmt.Errorf("orm: can not bind %T as an interval", i.d)
+	}
+	return sqlInterval(time.Duration(v.Int())), nil
+}
+
+// Scan implements the Scanner interface.
+func (i durationInterval) Scan(src interface{}) error {
+	v := reflect.ValueOf(i.d)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("orm: can not scan an interval into %T", i.d)
+	}
+	v = v.Elem()
+	if v.Kind() == reflect.Ptr {
+		if src == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Type() != durationType {
+		return fmt.Errorf("orm: can not scan an interval into %T", i.d)
+	}
+
+	var d time.Duration
+	var err error
+	switch s := src.(type) {
+	case nil:
+	case []byte:
+		d, err = parseInterval(string(s))
+	case string:
+		d, err = parseInterval(s)
+	default:
+		err = fmt.Errorf("orm: can not scan %T into an interval", src)
+	}
+	if err == nil {
+		v.SetInt(int64(d))
+	}
+	return err
+}
+
+// sqlInterval returns the interval literal of d, in microseconds which is
+// the precision of the Postgres intervals.
+func sqlInterval(d time.Duration) string {
+	return strconv.FormatInt(int64(d/time.Microsecond), 10) + " microseconds"
+}
+
+// parseInterval parses the output of a Postgres interval, like
+// "-1 days +02:03:04.5".  Years and months, whose length varies, can't be
+// converted to a duration.
+func parseInterval(s string) (time.Duration, error) {
+	var d time.Duration
+	fields := strings.Fields(s)
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if strings.Contains(f, ":") {
+			sign := time.Duration(1)
+			if f[0] == '-' || f[0] == '+' {
+				if f[0] == '-' {
+					sign = -1
+				}
+				f = f[1:]
+			}
+			parts := strings.Split(f, ":")
+			if len(parts) != 3 {
+				return 0, fmt.Errorf("orm: wrong interval `%s`", s)
+			}
+			t, err := time.ParseDuration(parts[0] + "h" + parts[1] + "m" + parts[2] + "s")
+			if err != nil {
+				return 0, fmt.Errorf("orm: wrong interval `%s`", s)
+			}
+			d += sign * t
+			continue
+		}
+		n, err := strconv.ParseInt(f, 10, 64)
+		if err != nil || i+1 == len(fields) || !strings.HasPrefix(fields[i+1], "day") {
+			return 0, fmt.Errorf("orm: can not convert interval `%s` to a duration", s)
+		}
+		d += time.Duration(n) * 24 * time.Hour
+		i++
+	}
+	return d, nil
+}
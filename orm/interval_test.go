@@ -0,0 +1,91 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"00:00:01.5":             1500 * time.Millisecond,
+		"01:02:03":               time.Hour + 2*time.Minute + 3*time.Second,
+		"3 days":                 72 * time.Hour,
+		"1 day -01:00:00":        23 * time.Hour,
+		"-2 days +00:00:00.0001": -48*time.Hour + 100*time.Microsecond,
+		"00:00:00":               0,
+	}
+	for in, want := range tests {
+		if got, err := parseInterval(in); err != nil || got != want {
+			t.Errorf("%q: expected %v, got %v (%v)", in, want, got, err)
+		}
+	}
+	for _, in := range []string{"1 mon", "2 years 00:00:00", "01:02"} {
+		if _, err := parseInterval(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+}
+
+func TestIntervalScan(t *testing.T) {
+	var d time.Duration
+	if v, _ := Interval(90 * time.Second).Value(); v != "90000000 microseconds" {
+		t.Errorf("unexpected interval value %v", v)
+	}
+	if err := Interval(&d).Scan([]byte("00:01:30")); err != nil || d != 90*time.Second {
+		t.Errorf("expected 1m30s, got %v (%v)", d, err)
+	}
+
+	p := &d
+	if err := Interval(&p).Scan(nil); err != nil || p != nil {
+		t.Errorf("expected a nil duration, got %v (%v)", p, err)
+	}
+	if err := Interval(&p).Scan("00:00:02"); err != nil || p == nil || *p != 2*time.Second {
+		t.Errorf("expected 2s, got %v (%v)", p, err)
+	}
+}
+
+func TestDurationField(t *testing.T) {
+	registerTestModels(t, PostgresDialect{}, &durationJob{})
+	defer Database().Set(nil)
+	mi, _ := modelCache.get("duration_job")
+
+	ddl := mi.SqlForCreate(false)
+	for _, col := range []string{`"timeout" bigint`, `"backoff" interval`, `"grace" interval`} {
+		if !strings.Contains(ddl, col) {
+			t.Errorf("ddl %q missing %q", ddl, col)
+		}
+	}
+
+	job := &durationJob{Timeout: time.Second, Backoff: time.Minute}
+	bi, err := mi.bindInsert(reflect.ValueOf(job).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make([]interface{}, 0)
+	for _, arg := range bi.args {
+		if i, ok := arg.(durationInterval); ok {
+			v, _ := i.Value()
+			values = append(values, v)
+		} else {
+			values = append(values, arg)
+		}
+	}
+	for _, want := range []interface{}{time.Second, "60000000 microseconds", nil} {
+		found := false
+		for _, v := range values {
+			found = found || v == want
+		}
+		if !found {
+			t.Errorf("expected %v bound in %v", want, values)
+		}
+	}
+
+	if p := getFlatParams(mi.fields.GetByName("Backoff"), []interface{}{time.Hour}, time.UTC); p[0] != "3600000000 microseconds" {
+		t.Errorf("unexpected interval param %v", p)
+	}
+	if p := getFlatParams(mi.fields.GetByName("Timeout"), []interface{}{time.Hour}, time.UTC); p[0] != int64(time.Hour) {
+		t.Errorf("unexpected duration param %v", p)
+	}
+}
@@ -0,0 +1,195 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// LockTable is the table holding the database locks, see DbMap.Lock.
+var LockTable = "orm_lock"
+
+var (
+	// ErrLocked is returned by Lock when another owner holds the lock.
+	ErrLocked = errors.New("orm: lock is held")
+
+	// ErrLockLost is returned by Unlock and Extend when the lock expired
+	// and may have been taken by another owner since.
+	ErrLockLost = errors.New("orm: lock was lost")
+)
+
+// DbLock is a lock taken with DbMap.Lock.
+type DbLock struct {
+	Name string
+
+	// Token is the fencing token of the lock, incremented every time the
+	// lock is taken.  Pass it along to the resources the lock protects
+	// so they can reject the writes of an owner whose lock expired.
+	Token int64
+
+	// Expires is when the lock can be taken by another owner.
+	Expires time.Time
+
+	dbmap *DbMap
+	conn  *sql.Conn // session holding the advisory lock
+}
+
+// Lock takes the lock name of the database of Database() for ttl, see
+// DbMap.Lock.
+func Lock(name string, ttl time.Duration) (*DbLock, error) {
+	return Database().Get().Lock(name, ttl)
+}
+
+// Unlock releases l.
+func Unlock(l *DbLock) error {
+	return l.Unlock()
+}
+
+// CreateLockTable creates the LockTable if it does not exist.
+func (m *DbMap) CreateLockTable() error {
+	query := fmt.Sprintf("%s %s (%s %s not null primary key, %s %s not null, %s %s not null)%s",
+		m.Dialect.IfTableNotExists("create table", "", LockTable),
+		m.Dialect.QuotedTableForQuery("", LockTable),
+		m.Dialect.QuoteField("name"), m.Dialect.ToSqlType(reflect.TypeOf(""), 255, false),
+		m.Dialect.QuoteField("token"), m.Dialect.ToSqlType(reflect.TypeOf(int64(0)), 0, false),
+		m.Dialect.QuoteField("expires"), m.Dialect.ToSqlType(reflect.TypeOf(int64(0)), 0, false),
+		m.Dialect.QuerySuffix())
+	_, err := m.Exec(query)
+	return err
+}
+
+// Lock takes the lock name for ttl, for jobs and instances of an
+// application to coordinate through their database.  It fails with
+// ErrLocked, without waiting, if another owner holds the lock.
+//
+// Locks are rows of the LockTable, see CreateLockTable, which expire after
+// ttl if their owner dies.  On dialects with advisory locks the lock is
+// also held by a database session, so the database releases it as soon
+// as its owner disconnects.
+func (m *DbMap) Lock(name string, ttl time.Duration) (*DbLock, error) {
+	l := &DbLock{Name: name, dbmap: m}
+	if al, ok := m.Dialect.(AdvisoryLocker); ok {
+		conn, err := m.Db.Conn(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		var taken bool
+		err = conn.QueryRowContext(context.Background(), al.TryAdvisoryLock(m.Dialect.BindVar(0)), name).Scan(&taken)
+		if err != nil || !taken {
+			conn.Close()
+			if err == nil {
+				err = ErrLocked
+			}
+			return nil, err
+		}
+		l.conn = conn
+	}
+
+	if err := l.take(ttl); err != nil {
+		l.release()
+		return nil, err
+	}
+	return l, nil
+}
+
+// take writes the lock row, bumping its token, if the lock expired or is
+// held through an advisory lock.
+func (l *DbLock) take(ttl time.Duration) error {
+	m := l.dbmap
+	tx, err := m.Begin()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	expires := now.Add(ttl)
+
+	table := m.Dialect.QuotedTableForQuery("", LockTable)
+	name, token, exp := m.Dialect.QuoteField("name"), m.Dialect.QuoteField("token"), m.Dialect.QuoteField("expires")
+	query := fmt.Sprintf("update %s set %s = %s + 1, %s = %s where %s = %s",
+		table, token, token, exp, m.Dialect.BindVar(0), name, m.Dialect.BindVar(1))
+	args := []interface{}{expires.UnixNano(), l.Name}
+	if l.conn == nil {
+		query += fmt.Sprintf(" and %s < %s", exp, m.Dialect.BindVar(2))
+		args = append(args, now.UnixNano())
+	}
+	res, err := tx.Exec(query, args...)
+	if err == nil {
+		var n int64
+		if n, err = res.RowsAffected(); err == nil && n == 0 {
+			_, err = tx.Exec(fmt.Sprintf("insert into %s (%s, %s, %s) values (%s, 1, %s)",
+				table, name, token, exp, m.Dialect.BindVar(0), m.Dialect.BindVar(1)), l.Name, expires.UnixNano())
+			if err != nil {
+				// the row exists and did not expire
+				err = ErrLocked
+			}
+		}
+	}
+	if err == nil {
+		l.Token, err = tx.SelectInt(fmt.Sprintf("select %s from %s where %s = %s", token, table, name, m.Dialect.BindVar(0)), l.Name)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	l.Expires = expires
+	return tx.Commit()
+}
+
+// Extend keeps the lock for ttl from now.  It fails with ErrLockLost if
+// the lock expired and was taken again since.
+func (l *DbLock) Extend(ttl time.Duration) error {
+	expires := time.Now().Add(ttl)
+	if err := l.update(expires.UnixNano()); err != nil {
+		return err
+	}
+	l.Expires = expires
+	return nil
+}
+
+// Unlock releases the lock.  It fails with ErrLockLost if the lock
+// expired and was taken again since, in which case the work done under
+// the lock may have overlapped another owner.
+func (l *DbLock) Unlock() error {
+	err := l.update(0)
+	if e := l.release(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// update sets the expiry of the lock row, as long as it holds our token.
+func (l *DbLock) update(expires int64) error {
+	m := l.dbmap
+	query := fmt.Sprintf("update %s set %s = %s where %s = %s and %s = %s",
+		m.Dialect.QuotedTableForQuery("", LockTable),
+		m.Dialect.QuoteField("expires"), m.Dialect.BindVar(0),
+		m.Dialect.QuoteField("name"), m.Dialect.BindVar(1),
+		m.Dialect.QuoteField("token"), m.Dialect.BindVar(2))
+	res, err := m.Exec(query, expires, l.Name, l.Token)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// release gives the advisory lock and its session back.
+func (l *DbLock) release() error {
+	if l.conn == nil {
+		return nil
+	}
+	al := l.dbmap.Dialect.(AdvisoryLocker)
+	_, err := l.conn.ExecContext(context.Background(), al.AdvisoryUnlock(l.dbmap.Dialect.BindVar(0)), l.Name)
+	if e := l.conn.Close(); err == nil {
+		err = e
+	}
+	l.conn = nil
+	return err
+}
@@ -12,6 +12,7 @@
 package orm
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 )
@@ -46,18 +47,18 @@ func (e OptimisticLockError) Error() string {
 	return fmt.Sprintf("gorp: OptimisticLockError no row found for table=%s keys=%v", e.TableName, e.Keys)
 }
 
+// Is matches ErrStaleVersion, and ErrNoRows when the row is gone.
+func (e OptimisticLockError) Is(target error) bool {
+	return target == ErrStaleVersion || target == ErrNoRows && !e.RowExists
+}
+
 func lockError(m *DbMap, exec SqlExecutor, tableName string,
 	existingVer int64, elem reflect.Value,
 	keys ...interface{}) (int64, error) {
 
-	existing, err := get(m, exec, elem.Interface(), keys...)
-	if err != nil {
+	_, err := get(m, exec, elem.Interface(), keys...)
+	if err != nil && !errors.Is(err, ErrNoRows) {
 		return -1, err
 	}
-
-	ole := OptimisticLockError{tableName, keys, true, existingVer}
-	if existing == nil {
-		ole.RowExists = false
-	}
-	return -1, ole
+	return -1, OptimisticLockError{tableName, keys, err == nil, existingVer}
 }
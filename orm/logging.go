@@ -11,7 +11,57 @@
 
 package orm
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DebugLog receives the diagnostics of the ORM outside of the statements
+// of a DbMap, eg while registering models, which happens before any DbMap
+// exists.  It discards them unless set, eg to a StdDebugLogger:
+//
+//	orm.DebugLog = &orm.StdDebugLogger{Out: log.New(os.Stderr, "[ORM] ", log.LstdFlags)}
+var DebugLog DebugLogger = NopDebugLogger{}
+
+// DebugLogger receives the diagnostics of the ORM, see DebugLog.
+type DebugLogger interface {
+	Debug(format string, v ...interface{})
+	Info(format string, v ...interface{})
+	Error(format string, v ...interface{})
+}
+
+// NopDebugLogger is a DebugLogger discarding everything.
+type NopDebugLogger struct{}
+
+func (NopDebugLogger) Debug(format string, v ...interface{}) {}
+func (NopDebugLogger) Info(format string, v ...interface{})  {}
+func (NopDebugLogger) Error(format string, v ...interface{}) {}
+
+// StdDebugLogger is a DebugLogger writing the diagnostics of at least
+// Level to a GorpLogger, eg a *log.Logger.
+type StdDebugLogger struct {
+	Out   GorpLogger
+	Level LogLevel
+}
+
+func (l *StdDebugLogger) Debug(format string, v ...interface{}) {
+	l.printf(LevelDebug, format, v...)
+}
+
+func (l *StdDebugLogger) Info(format string, v ...interface{}) {
+	l.printf(LevelInfo, format, v...)
+}
+
+func (l *StdDebugLogger) Error(format string, v ...interface{}) {
+	l.printf(LevelError, format, v...)
+}
+
+func (l *StdDebugLogger) printf(level LogLevel, format string, v ...interface{}) {
+	if level.atLeast(l.Level) {
+		l.Out.Printf("[%s] %s", level, fmt.Sprintf(format, v...))
+	}
+}
 
 type GorpLogger interface {
 	Printf(format string, v ...interface{})
@@ -29,6 +79,8 @@ type GorpLogger interface {
 // easily be written for other logging packages (e.g., the golang-sanctioned
 // glog framework).
 func (m *DbMap) TraceOn(prefix string, logger GorpLogger) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
 	m.logger = logger
 	if prefix == "" {
 		m.logPrefix = prefix
@@ -39,6 +91,121 @@ func (m *DbMap) TraceOn(prefix string, logger GorpLogger) {
 
 // TraceOff turns off tracing. It is idempotent.
 func (m *DbMap) TraceOff() {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
 	m.logger = nil
 	m.logPrefix = ""
 }
+
+// LogLevel is the severity a statement is reported to a Logger with.
+type LogLevel int
+
+const (
+	// LevelDebug is used for statements which ran normally.
+	LevelDebug LogLevel = iota
+	// LevelWarn is used for statements slower than SlowQueryThreshold.
+	LevelWarn
+	// LevelError is used for statements which failed.
+	LevelError
+	// LevelInfo is only used for diagnostics, see DebugLogger.  It comes
+	// between LevelDebug and LevelWarn, see atLeast.
+	LevelInfo
+)
+
+// levelRanks orders the levels by severity, LevelInfo being numbered
+// after the others so as not to renumber them.
+var levelRanks = map[LogLevel]int{
+	LevelDebug: 0,
+	LevelInfo:  1,
+	LevelWarn:  2,
+	LevelError: 3,
+}
+
+// atLeast reports whether l is as severe as min.
+func (l LogLevel) atLeast(min LogLevel) bool {
+	return levelRanks[l] >= levelRanks[min]
+}
+
+var levelNames = map[LogLevel]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// parseLogLevel returns the level named name, eg "warn".
+func parseLogLevel(name string) (LogLevel, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+func (l LogLevel) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// Logger receives the statements run through a DbMap, see
+// DbMap.QueryLogger.  err is always nil for QueryRow, whose error only
+// surfaces when the row is scanned.
+type Logger interface {
+	LogQuery(level LogLevel, query string, args []interface{}, d time.Duration, err error)
+}
+
+// LevelLogger is a Logger writing the statements of at least Level to a
+// GorpLogger, eg a *log.Logger, in the TraceOn format.
+type LevelLogger struct {
+	Out   GorpLogger
+	Level LogLevel
+}
+
+func (l *LevelLogger) LogQuery(level LogLevel, query string, args []interface{}, d time.Duration, err error) {
+	if !level.atLeast(l.Level) {
+		return
+	}
+	if err != nil {
+		l.Out.Printf("[%s] %s [%s] (%v) %s", level, query, argsString(args...), d, err)
+	} else {
+		l.Out.Printf("[%s] %s [%s] (%v)", level, query, argsString(args...), d)
+	}
+}
+
+func (m *DbMap) tracing() bool {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return m.logger != nil || m.QueryLogger != nil
+}
+
+// trace reports a statement started at started.  errp points at the
+// result of the statement, and is nil when it isn't known yet.
+func (m *DbMap) trace(started time.Time, query string, args []interface{}, errp *error) {
+	d := time.Since(started)
+	var err error
+	if errp != nil {
+		err = *errp
+	}
+
+	settingsMu.RLock()
+	logger, prefix, threshold, minLevel := m.logger, m.logPrefix, m.SlowQueryThreshold, m.QueryLogLevel
+	settingsMu.RUnlock()
+
+	if logger != nil {
+		logger.Printf("%s%s [%s] (%v)", prefix, query, argsString(args...), d)
+	}
+	if m.QueryLogger != nil {
+		level := LevelDebug
+		if err != nil {
+			level = LevelError
+		} else if threshold > 0 && d >= threshold {
+			level = LevelWarn
+		}
+		if level.atLeast(minLevel) {
+			m.QueryLogger.LogQuery(level, query, args, d, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type recordingLogger struct {
+	levels []LogLevel
+	errs   []error
+}
+
+func (r *recordingLogger) LogQuery(level LogLevel, query string, args []interface{}, d time.Duration, err error) {
+	r.levels = append(r.levels, level)
+	r.errs = append(r.errs, err)
+}
+
+func TestTraceLevels(t *testing.T) {
+	rec := &recordingLogger{}
+	m := &DbMap{QueryLogger: rec, SlowQueryThreshold: time.Hour}
+	if !m.tracing() {
+		t.Fatal("expected tracing with a QueryLogger")
+	}
+
+	failed := errors.New("syntax error")
+	m.trace(time.Now(), "select 1", nil, nil)
+	m.trace(time.Now().Add(-2*time.Hour), "select 2", nil, nil)
+	m.trace(time.Now(), "select 3", []interface{}{1}, &failed)
+
+	want := []LogLevel{LevelDebug, LevelWarn, LevelError}
+	for i, level := range want {
+		if rec.levels[i] != level {
+			t.Errorf("statement %d: got level %s, want %s", i+1, rec.levels[i], level)
+		}
+	}
+	if rec.errs[2] != failed {
+		t.Errorf("error not passed to the logger: %v", rec.errs[2])
+	}
+
+	if err := m.ApplySettings(map[string]string{"orm.slowquery": "250ms"}); err != nil || m.SlowQueryThreshold != 250*time.Millisecond {
+		t.Errorf("slow query threshold not applied: %v (%v)", m.SlowQueryThreshold, err)
+	}
+
+	// the statements less severe than the log level aren't reported
+	if err := m.ApplySettings(map[string]string{"orm.loglevel": "WARN"}); err != nil || m.QueryLogLevel != LevelWarn {
+		t.Fatalf("log level not applied: %v (%v)", m.QueryLogLevel, err)
+	}
+	rec.levels = nil
+	m.trace(time.Now(), "select 4", nil, nil)
+	m.trace(time.Now(), "select 5", nil, &failed)
+	if !reflect.DeepEqual(rec.levels, []LogLevel{LevelError}) {
+		t.Errorf("expected the error only, got %v", rec.levels)
+	}
+	if err := m.ApplySettings(map[string]string{"orm.loglevel": "loud"}); err == nil {
+		t.Error("expected an error for an unknown log level")
+	}
+}
+
+type printfRecorder []string
+
+func (p *printfRecorder) Printf(format string, v ...interface{}) {
+	*p = append(*p, fmt.Sprintf(format, v...))
+}
+
+func TestDebugLog(t *testing.T) {
+	defer func(l DebugLogger) { DebugLog = l }(DebugLog)
+	out := &printfRecorder{}
+	DebugLog = &StdDebugLogger{Out: out, Level: LevelInfo}
+	if LevelWarn != 1 || LevelError != 2 {
+		t.Error("expected LevelWarn and LevelError keeping their values")
+	}
+
+	parseStructTag("pk;wat;size(10)")
+	DebugLog.Info("registered %d models", 2)
+	DebugLog.Error("model %s is invalid", "user")
+	if want := []string{"[info] registered 2 models", "[error] model user is invalid"}; !reflect.DeepEqual([]string(*out), want) {
+		t.Errorf("expected %q, got %q", want, *out)
+	}
+
+	DebugLog.(*StdDebugLogger).Level = LevelDebug
+	parseStructTag("wat")
+	if len(*out) != 3 || (*out)[2] != "[debug] unsupport orm tag wat" {
+		t.Errorf("unexpected diagnostics %q", *out)
+	}
+}
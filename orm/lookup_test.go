@@ -0,0 +1,65 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTableLookups(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &touchPost{}, &touchComment{})
+	defer Database().Set(nil)
+
+	for _, typ := range []reflect.Type{reflect.TypeOf(touchPost{}), reflect.TypeOf(&touchPost{})} {
+		if mi, err := dbmap.TableFor(typ); err != nil || mi.table != "touch_post" {
+			t.Errorf("TableFor(%v) = %v, %v", typ, mi, err)
+		}
+	}
+	if _, err := dbmap.TableFor(reflect.TypeOf(blobFile{})); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("expected ErrUnknownModel, got %v", err)
+	}
+	if mi, err := dbmap.TableForName("touch_comment"); err != nil || mi.table != "touch_comment" {
+		t.Errorf("TableForName = %v, %v", mi, err)
+	}
+	if _, err := dbmap.TableForName("nope"); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("expected ErrUnknownModel, got %v", err)
+	}
+	mi := dbmap.MustTableFor(reflect.TypeOf(touchPost{}))
+	if byName, err := dbmap.GetByFullName(mi.fullName); err != nil || byName != mi {
+		t.Errorf("GetByFullName(%s) = %v, %v", mi.fullName, byName, err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected MustTableFor to panic")
+			}
+		}()
+		dbmap.MustTableFor(reflect.TypeOf(blobFile{}))
+	}()
+}
+
+func TestFlatParamsOfModels(t *testing.T) {
+	registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &touchPost{}, &touchComment{})
+	defer Database().Set(nil)
+
+	mi, _ := modelCache.get("touch_comment")
+	fi := mi.fields.GetByName("Post")
+	post := &touchPost{Id: 7}
+	for _, arg := range []interface{}{post, *post} {
+		if p := getFlatParams(fi, []interface{}{arg}, time.UTC); len(p) != 1 || p[0] != int64(7) {
+			t.Errorf("expected the key of %#v, got %v", arg, p)
+		}
+	}
+
+	comment := &touchComment{Id: 3, Post: post}
+	if v := getFieldValue(comment, "Post"); v != int64(7) {
+		t.Errorf("expected the key of the post, got %#v", v)
+	}
+	if v := getFieldValue(comment, "Id"); v != int64(3) {
+		t.Errorf("expected the id, got %#v", v)
+	}
+	if v := getFieldValue(&touchComment{}, "Post"); v != nil {
+		t.Errorf("expected nil for a nil relation, got %#v", v)
+	}
+}
@@ -0,0 +1,140 @@
+package orm
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsBuckets are the upper bounds of the query duration histograms.
+var MetricsBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+// QueryStats are the statistics of the statements run on a table with an
+// operation, eg "select".
+type QueryStats struct {
+	Table     string
+	Operation string
+	Count     uint64
+	Errors    uint64
+	Sum       time.Duration
+	// Buckets holds the cumulative count of statements faster than each
+	// of MetricsBuckets.
+	Buckets []uint64
+}
+
+// QueryMetrics is a QueryHook counting the statements run by table and
+// operation, see Metrics.
+type QueryMetrics struct {
+	mu    sync.Mutex
+	stats map[[2]string]*QueryStats
+}
+
+var (
+	metrics     *QueryMetrics
+	metricsOnce sync.Once
+)
+
+// Metrics returns the collector of the ORM statistics.  It only counts the
+// statements of the DbMaps it was added to:
+//
+//	dbmap.AddQueryHook(orm.Metrics())
+func Metrics() *QueryMetrics {
+	metricsOnce.Do(func() {
+		metrics = &QueryMetrics{stats: make(map[[2]string]*QueryStats)}
+	})
+	return metrics
+}
+
+func (q *QueryMetrics) BeforeQuery(e *QueryEvent) {}
+
+func (q *QueryMetrics) AfterQuery(e *QueryEvent) {
+	d := time.Since(e.Started)
+	op, table := sqlOperation(e.Query)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	key := [2]string{table, op}
+	s, ok := q.stats[key]
+	if !ok {
+		s = &QueryStats{Table: table, Operation: op, Buckets: make([]uint64, len(MetricsBuckets))}
+		q.stats[key] = s
+	}
+	s.Count++
+	if e.Err != nil {
+		s.Errors++
+	}
+	s.Sum += d
+	for i, bound := range MetricsBuckets {
+		if d <= bound {
+			s.Buckets[i]++
+		}
+	}
+}
+
+// Snapshot returns a copy of the statistics, sorted by table and operation.
+func (q *QueryMetrics) Snapshot() []QueryStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := make([]QueryStats, 0, len(q.stats))
+	for _, s := range q.stats {
+		c := *s
+		c.Buckets = append([]uint64(nil), s.Buckets...)
+		stats = append(stats, c)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Table != stats[j].Table {
+			return stats[i].Table < stats[j].Table
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
+
+// Reset drops the statistics collected so far.
+func (q *QueryMetrics) Reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stats = make(map[[2]string]*QueryStats)
+}
+
+// sqlOperation returns the lower cased first keyword of query and the
+// table it works on, "" when it can't tell.
+func sqlOperation(query string) (op, table string) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return "", ""
+	}
+	op = strings.TrimRight(words[0], ";")
+
+	var after string
+	switch op {
+	case "select", "delete":
+		after = "from"
+	case "insert":
+		after = "into"
+	case "update":
+		after = "update"
+	default:
+		return op, ""
+	}
+	for i, w := range words[:len(words)-1] {
+		if w == after {
+			table = words[i+1]
+			break
+		}
+	}
+	if i := strings.IndexAny(table, "(,;"); i >= 0 {
+		table = table[:i]
+	}
+	return op, strings.Trim(table, "\"`[]")
+}
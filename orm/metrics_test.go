@@ -0,0 +1,40 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSqlOperation(t *testing.T) {
+	for query, want := range map[string][2]string{
+		`select "id","title" from "post" where "id"=$1;`:     {"select", "post"},
+		"insert into `post` (`title`) values (?);":           {"insert", "post"},
+		`update "post" set "title"=$1 where "id"=$2;`:        {"update", "post"},
+		`delete from post where id in (select id from tag);`: {"delete", "post"},
+		`begin;`: {"begin", ""},
+	} {
+		if op, table := sqlOperation(query); op != want[0] || table != want[1] {
+			t.Errorf("%s: got %s %s, want %s %s", query, op, table, want[0], want[1])
+		}
+	}
+}
+
+func TestQueryMetrics(t *testing.T) {
+	q := Metrics()
+	q.Reset()
+	defer q.Reset()
+
+	q.AfterQuery(&QueryEvent{Query: `select * from "post";`, Started: time.Now()})
+	q.AfterQuery(&QueryEvent{Query: `select * from "post";`, Started: time.Now().Add(-time.Minute), Err: errors.New("timeout")})
+	q.AfterQuery(&QueryEvent{Query: `delete from "post";`, Started: time.Now()})
+
+	stats := q.Snapshot()
+	if len(stats) != 2 || stats[1].Operation != "select" || stats[1].Table != "post" {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	s := stats[1]
+	if s.Count != 2 || s.Errors != 1 || s.Buckets[0] != 1 || s.Buckets[len(s.Buckets)-1] != 1 {
+		t.Errorf("unexpected select stats %+v", s)
+	}
+}
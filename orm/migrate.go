@@ -0,0 +1,224 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// MigrationTable is the table recording the migrations applied, see
+// MigrateUp.
+var MigrationTable = "orm_migration"
+
+// migration registered by RegisterMigration
+type migration struct {
+	up, down func(tx *Transaction) error
+}
+
+var migrations struct {
+	sync.Mutex
+	names []string
+	funcs map[string]migration
+}
+
+// RegisterMigration registers the migration name, changing the schema or
+// the data of the database with up and reverting the change with down, eg
+//
+//	orm.RegisterMigration("20170102_add_user_email", func(tx *orm.Transaction) error {
+//		_, err := tx.Exec("alter table user add column email varchar(255)")
+//		return err
+//	}, func(tx *orm.Transaction) error {
+//		_, err := tx.Exec("alter table user drop column email")
+//		return err
+//	})
+//
+// The migrations are applied in the order of their registration.  A nil
+// down makes the migration irreversible.
+func RegisterMigration(name string, up, down func(tx *Transaction) error) {
+	migrations.Lock()
+	defer migrations.Unlock()
+	if _, ok := migrations.funcs[name]; ok {
+		panic(fmt.Errorf("<orm.RegisterMigration> migration `%s` repeat register, must be unique", name))
+	}
+	if up == nil {
+		panic(fmt.Errorf("<orm.RegisterMigration> migration `%s` has no up", name))
+	}
+	if migrations.funcs == nil {
+		migrations.funcs = make(map[string]migration)
+	}
+	migrations.names = append(migrations.names, name)
+	migrations.funcs[name] = migration{up: up, down: down}
+}
+
+// MigrationStatus is whether a registered migration is applied, see
+// DbMap.MigrationStatus.
+type MigrationStatus struct {
+	Name    string
+	Applied time.Time // zero if the migration is pending
+}
+
+// Migrate runs the migration command on the database of Database(),
+// writing what it does to w: "up" applies the pending migrations, "down"
+// reverts the last one applied, "redo" reverts and applies it again, and
+// "status" lists the migrations.
+func Migrate(command string, w io.Writer) error {
+	m := Database().Get()
+	switch command {
+	case "up":
+		applied, err := m.MigrateUp()
+		for _, name := range applied {
+			fmt.Fprintln(w, "Applied", name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(w, "No pending migration")
+		}
+		return err
+	case "down":
+		name, err := m.MigrateDown()
+		if name != "" {
+			fmt.Fprintln(w, "Reverted", name)
+		}
+		return err
+	case "redo":
+		name, err := m.MigrateRedo()
+		if name != "" {
+			fmt.Fprintln(w, "Redone", name)
+		}
+		return err
+	case "status":
+		status, err := m.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			if s.Applied.IsZero() {
+				fmt.Fprintf(w, "%-8s %s\n", "pending", s.Name)
+			} else {
+				fmt.Fprintf(w, "%-8s %s (%s)\n", "applied", s.Name, s.Applied.Format(time.RFC3339))
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("<orm.Migrate> unknown command `%s`, expected up, down, redo or status", command)
+}
+
+// MigrateUp applies the registered migrations not applied yet on the
+// database of m, in the order of their registration, and returns their
+// names.  Each migration runs in a transaction which records it in the
+// MigrationTable, so a failed migration is applied again next time and
+// the migrations after it aren't applied.
+func (m *DbMap) MigrateUp() ([]string, error) {
+	status, err := m.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+	var applied []string
+	for _, s := range status {
+		if !s.Applied.IsZero() {
+			continue
+		}
+		if err = m.runMigration(s.Name, true); err != nil {
+			return applied, err
+		}
+		applied = append(applied, s.Name)
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts the last migration applied on the database of m,
+// and returns its name, empty if none is applied.  It fails if the
+// migration is irreversible.
+func (m *DbMap) MigrateDown() (string, error) {
+	status, err := m.MigrationStatus()
+	if err != nil {
+		return "", err
+	}
+	for i := len(status) - 1; i >= 0; i-- {
+		if !status[i].Applied.IsZero() {
+			return status[i].Name, m.runMigration(status[i].Name, false)
+		}
+	}
+	return "", nil
+}
+
+// MigrateRedo reverts the last migration applied on the database of m
+// and applies it again, and returns its name, empty if none is applied.
+func (m *DbMap) MigrateRedo() (string, error) {
+	name, err := m.MigrateDown()
+	if err != nil || name == "" {
+		return name, err
+	}
+	return name, m.runMigration(name, true)
+}
+
+// MigrationStatus returns the registered migrations, in the order of their
+// registration, with when they were applied on the database of m.  The
+// migrations recorded but no longer registered are left out.
+func (m *DbMap) MigrationStatus() ([]MigrationStatus, error) {
+	query := fmt.Sprintf("%s %s (%s %s not null primary key, %s %s not null)%s",
+		m.Dialect.IfTableNotExists("create table", "", MigrationTable),
+		m.Dialect.QuotedTableForQuery("", MigrationTable),
+		m.Dialect.QuoteField("name"), m.Dialect.ToSqlType(reflect.TypeOf(""), 255, false),
+		m.Dialect.QuoteField("applied"), m.Dialect.ToSqlType(reflect.TypeOf(time.Time{}), 0, false),
+		m.Dialect.QuerySuffix())
+	if _, err := m.Exec(query); err != nil {
+		return nil, err
+	}
+
+	var rows []MigrationStatus
+	_, err := m.Select(&rows, fmt.Sprintf("select %s as Name, %s as Applied from %s",
+		m.Dialect.QuoteField("name"), m.Dialect.QuoteField("applied"),
+		m.Dialect.QuotedTableForQuery("", MigrationTable)))
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Name] = row.Applied
+	}
+
+	migrations.Lock()
+	defer migrations.Unlock()
+	status := make([]MigrationStatus, len(migrations.names))
+	for i, name := range migrations.names {
+		status[i] = MigrationStatus{Name: name, Applied: applied[name]}
+	}
+	return status, nil
+}
+
+// runMigration applies, or reverts, the migration name in a transaction
+// recording it.
+func (m *DbMap) runMigration(name string, up bool) error {
+	migrations.Lock()
+	mig := migrations.funcs[name]
+	migrations.Unlock()
+
+	table := m.Dialect.QuotedTableForQuery("", MigrationTable)
+	err := m.RunInTransaction(func(tx *Transaction) error {
+		if up {
+			if err := mig.up(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(fmt.Sprintf("insert into %s (%s, %s) values (%s, %s)%s", table,
+				m.Dialect.QuoteField("name"), m.Dialect.QuoteField("applied"),
+				m.Dialect.BindVar(0), m.Dialect.BindVar(1), m.Dialect.QuerySuffix()), name, time.Now())
+			return err
+		}
+		if mig.down == nil {
+			return errors.New("migration is irreversible")
+		}
+		if err := mig.down(tx); err != nil {
+			return err
+		}
+		_, err := tx.Exec(fmt.Sprintf("delete from %s where %s = %s%s", table,
+			m.Dialect.QuoteField("name"), m.Dialect.BindVar(0), m.Dialect.QuerySuffix()), name)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("<orm.Migrate> migration `%s`: %w", name, err)
+	}
+	return nil
+}
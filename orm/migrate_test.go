@@ -0,0 +1,128 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// migrationDriver keeps the migrations recorded in the migration table,
+// and fails the transactions inserting into the fail table.
+var migrationDriver = &fakeDriver{
+	begin:  func() { migrationTx = append([]string(nil), migrationApplied...) },
+	commit: func() { migrationApplied = migrationTx },
+	exec: func(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
+		switch {
+		case strings.HasPrefix(query, "insert into fail"):
+			return nil, errors.New("migration failed")
+		case strings.HasPrefix(query, `insert into "orm_migration"`):
+			migrationTx = append(migrationTx, args[0].(string))
+		case strings.HasPrefix(query, `delete from "orm_migration"`):
+			for i, name := range migrationTx {
+				if name == args[0].(string) {
+					migrationTx = append(migrationTx[:i:i], migrationTx[i+1:]...)
+					break
+				}
+			}
+		}
+		return driver.RowsAffected(1), nil
+	},
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		rows := &fakeRows{columns: []string{"Name", "Applied"}}
+		for _, name := range migrationApplied {
+			rows.values = append(rows.values, []driver.Value{name, time.Date(2017, 1, 2, 0, 0, 0, 0, time.UTC)})
+		}
+		return rows, nil
+	},
+}
+
+var (
+	migrationApplied []string
+	migrationTx      []string // the migrations applied in the transaction
+)
+
+func TestMigrate(t *testing.T) {
+	dbmap := &DbMap{Db: migrationDriver.open(t), Dialect: SqliteDialect{}}
+	defer func() { migrations.names, migrations.funcs = nil, nil }()
+
+	calls := make(map[string]int)
+	step := func(name string) func(tx *Transaction) error {
+		return func(tx *Transaction) error {
+			calls[name]++
+			return nil
+		}
+	}
+	RegisterMigration("1_users", step("1_users up"), step("1_users down"))
+	RegisterMigration("2_emails", step("2_emails up"), nil)
+	fail := true
+	RegisterMigration("3_orders", func(tx *Transaction) error {
+		calls["3_orders up"]++
+		if fail {
+			_, err := tx.Exec("insert into fail (name) values ('x')")
+			return err
+		}
+		return nil
+	}, step("3_orders down"))
+
+	applied, err := dbmap.MigrateUp()
+	if err == nil || !strings.Contains(err.Error(), "migration `3_orders`") {
+		t.Errorf("expected the orders migration to fail, got %v", err)
+	}
+	if strings.Join(applied, ",") != "1_users,2_emails" || strings.Join(migrationApplied, ",") != "1_users,2_emails" {
+		t.Errorf("unexpected migrations applied %v, recorded %v", applied, migrationApplied)
+	}
+
+	fail = false
+	if applied, err = dbmap.MigrateUp(); err != nil || strings.Join(applied, ",") != "3_orders" {
+		t.Errorf("expected the orders migration applied, got %v %v", applied, err)
+	}
+	if applied, err = dbmap.MigrateUp(); err != nil || len(applied) != 0 {
+		t.Errorf("expected no migration to apply, got %v %v", applied, err)
+	}
+
+	if name, err := dbmap.MigrateRedo(); err != nil || name != "3_orders" {
+		t.Errorf("expected the orders migration redone, got %q %v", name, err)
+	}
+	if calls["3_orders up"] != 3 || calls["3_orders down"] != 1 {
+		t.Errorf("unexpected migration calls %v", calls)
+	}
+	if name, err := dbmap.MigrateDown(); err != nil || name != "3_orders" {
+		t.Errorf("expected the orders migration reverted, got %q %v", name, err)
+	}
+	if name, err := dbmap.MigrateDown(); err == nil || !strings.Contains(err.Error(), "irreversible") || name != "2_emails" {
+		t.Errorf("expected the emails migration to be irreversible, got %q %v", name, err)
+	}
+
+	status, err := dbmap.MigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 3 || status[1].Applied.IsZero() || !status[2].Applied.IsZero() {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	Database().Set(dbmap)
+	defer Database().Set(nil)
+	var out bytes.Buffer
+	if err := Migrate("status", &out); err != nil {
+		t.Fatal(err)
+	}
+	expected := "applied  1_users (2017-01-02T00:00:00Z)\napplied  2_emails (2017-01-02T00:00:00Z)\npending  3_orders\n"
+	if out.String() != expected {
+		t.Errorf("unexpected status output %q", out.String())
+	}
+	if err := Migrate("sideways", &out); err == nil {
+		t.Error("expected an unknown command to fail")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a repeated migration to panic")
+		}
+	}()
+	RegisterMigration("1_users", step("again"), nil)
+}
@@ -15,7 +15,6 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 )
@@ -34,13 +33,10 @@ type modelInfo struct {
 	//keys           []*fieldInfo
 	indexes        []*IndexMap
 	uniqueTogether [][]string
+	tagIndexes     [][]string // of the model tag, see parseModelTag
+	tagUniques     [][]string
 	version        *fieldInfo
-	insertPlan     bindPlan
-	updatePlan     bindPlan
-	deletePlan     bindPlan
-	getPlan        bindPlan
-	m2mInsertPlan  bindPlan
-	m2mQueryPlan   bindPlan
+	plans          planCache
 
 	pkg       string
 	name      string
@@ -52,56 +48,127 @@ type modelInfo struct {
 	addrField reflect.Value //store the original struct value
 	uniques   []string
 	isThrough bool
+
+	treeParent  *fieldInfo // self-referential rel(fk) tagged tree
+	treePath    *fieldInfo // materialized path of tree(path) models
+	treeClosure *modelInfo // closure table of tree(closure) models
+
+	generics []*genericRel // fields tagged rel(generic)
+
+	policies []Policy // row-level security, see RegisterPolicy
+	scopes   []Scope  // default scopes, see RegisterScope
+
+	partialOf *modelInfo // model of a partial, see RegisterPartial
+	readOnly  bool       // see ModelOptions
+
+	engine, charset, collation string // of the MySQL table, see ModelOptions
+
+	naming NamingStrategy // names of the model, see NamingStrategy
+	affix  tableAffix     // of the table name, see DbMap.TablePrefix
 }
 
-// new model info
-func newModelInfo(val reflect.Value) (mi *modelInfo) {
-	mi = &modelInfo{}
+// new model info, failing with the errors of all its invalid fields
+func newModelInfo(val reflect.Value, naming NamingStrategy) (mi *modelInfo, err error) {
+	mi = &modelInfo{naming: naming}
 	mi.fields = newFields()
 	ind := reflect.Indirect(val)
 	mi.addrField = val
 	mi.name = ind.Type().Name()
 	mi.fullName = getFullName(ind.Type())
-	addModelFields(mi, ind, "", []int{})
+	err = addModelFields(mi, ind, "", []int{}, nil)
 	return
 }
 
+// embedding is a named struct field tagged embedded, whose fields map to
+// prefixed columns of the model, eg
+//
+//	Address Address `orm:"embedded;prefix(address_)"`
+//
+// maps the City field of Address to the address_city column, with the
+// name Address.City in the model.
+type embedding struct {
+	name   string // prefix of the field names, eg "Address."
+	prefix string // prefix of the columns, eg "address_"
+}
+
 // index: FieldByIndex returns the nested field corresponding to index
-func addModelFields(mi *modelInfo, ind reflect.Value, mName string, index []int) {
+func addModelFields(mi *modelInfo, ind reflect.Value, mName string, index []int, embed *embedding) error {
 	var (
-		err error
-		fi  *fieldInfo
-		sf  reflect.StructField
+		errs []error
+		sf   reflect.StructField
 	)
 
 	for i := 0; i < ind.NumField(); i++ {
 		field := ind.Field(i)
 		sf = ind.Type().Field(i)
+		// the blank field holds the tags of the model, see parseModelTag
+		if sf.Name == "_" && embed == nil {
+			if err := mi.parseModelTag(sf.Tag.Get(defaultStructTagName)); err != nil {
+				errs = append(errs, fmt.Errorf("model: %s, %s", ind.Type(), err))
+			}
+			continue
+		}
 		// if the field is unexported skip
 		if sf.PkgPath != "" {
 			continue
 		}
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
 		// add anonymous struct fields
 		if sf.Anonymous {
-			addModelFields(mi, field, mName+"."+sf.Name, append(index, i))
+			if err := addModelFields(mi, field, mName+"."+sf.Name, fieldIndex, embed); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		attrs, tags := parseStructTag(fieldTag(sf))
+		// add the fields of embedded structs
+		if attrs["embedded"] {
+			if field.Kind() != reflect.Struct {
+				errs = append(errs, fmt.Errorf("field: %s.%s, embedded only allow on struct field", ind.Type(), sf.Name))
+				continue
+			}
+			sub := &embedding{name: sf.Name + ".", prefix: tags["prefix"]}
+			if embed != nil {
+				sub.name = embed.name + sub.name
+				sub.prefix = embed.prefix + sub.prefix
+			}
+			if err := addModelFields(mi, field, mName+"."+sf.Name, fieldIndex, sub); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		// generic relations are backed by other fields, see genericRel
+		if tags["rel"] == "generic" {
+			if field.Kind() != reflect.Interface {
+				errs = append(errs, fmt.Errorf("field: %s.%s, rel(generic) only allow on interface{} field", ind.Type(), sf.Name))
+				continue
+			}
+			g := &genericRel{name: sf.Name, index: fieldIndex}
+			if embed != nil {
+				g.name = embed.name + g.name
+			}
+			mi.generics = append(mi.generics, g)
 			continue
 		}
 
-		fi, err = newFieldInfo(mi, field, sf, mName)
+		fi, err := newFieldInfo(mi, field, sf, mName)
 		if err == errSkipField {
-			err = nil
 			continue
-		} else if err != nil {
-			break
+		} else if err == nil && embed != nil {
+			err = fi.embed(embed)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field: %s.%s, %s", ind.Type(), sf.Name, err))
+			continue
 		}
 		//record current field index
-		fi.fieldIndex = append(index, i)
+		fi.fieldIndex = fieldIndex
 		fi.mi = mi
 		fi.gotype = field.Type()
 		fi.inModel = true
 		if !mi.fields.Add(fi) {
-			err = fmt.Errorf("duplicate column name: %s", fi.column)
-			break
+			errs = append(errs, fmt.Errorf("field: %s.%s, duplicate column name: %s", ind.Type(), sf.Name, fi.column))
+			continue
 		}
 		if fi.pk {
 			// if mi.fields.pk != nil {
@@ -115,22 +182,14 @@ func addModelFields(mi *modelInfo, ind reflect.Value, mName string, index []int)
 		}
 	}
 
-	if err != nil {
-		fmt.Println(fmt.Errorf("field: %s.%s, %s", ind.Type(), sf.Name, err))
-		os.Exit(2)
-	}
+	return errors.Join(errs...)
 }
 
 // ResetSql removes cached insert/update/select/delete SQL strings
 // associated with this modelInfo.  Call this if you've modified
 // any column names or the table name itself.
 func (t *modelInfo) ResetSql() {
-	t.insertPlan = bindPlan{}
-	t.updatePlan = bindPlan{}
-	t.deletePlan = bindPlan{}
-	t.getPlan = bindPlan{}
-	t.m2mInsertPlan = bindPlan{}
-	t.m2mQueryPlan = bindPlan{}
+	t.plans.reset()
 }
 
 // SetKeys lets you specify the fields on a struct that map to primary
@@ -185,16 +244,22 @@ func (t *modelInfo) SetUniqueTogether(fieldNames ...string) *modelInfo {
 
 // ColMap returns the fieldInfo pointer matching the given struct field
 // name.  It panics if the struct does not contain a field matching this
-// name.
+// name, see ColMapE.
 func (t *modelInfo) ColMap(field string) *fieldInfo {
+	col, err := t.ColMapE(field)
+	if err != nil {
+		panic(err.Error())
+	}
+	return col
+}
+
+// ColMapE is ColMap failing with a *FieldError instead of panicking.
+func (t *modelInfo) ColMapE(field string) (*fieldInfo, error) {
 	col := colMapOrNil(t, field)
 	if col == nil {
-		e := fmt.Sprintf("No fieldInfo in table %s type %s with field %s",
-			t.table, t.gotype.Name(), field)
-
-		panic(e)
+		return nil, &FieldError{TypeName: t.fullName, Field: field}
 	}
-	return col
+	return col, nil
 }
 
 // GetByAny return fieldInfo
@@ -214,6 +279,15 @@ func (t *modelInfo) GetByAny(name string) (*fieldInfo, bool) {
 	return nil, false
 }
 
+// requirePK returns an error matching ErrMissingPK when the model has no
+// primary key.
+func (t *modelInfo) requirePK() error {
+	if len(t.fields.keys) < 1 {
+		return fmt.Errorf("%w for table: %s", ErrMissingPK, t.table)
+	}
+	return nil
+}
+
 func colMapOrNil(t *modelInfo, field string) *fieldInfo {
 	for _, col := range t.fields.columns {
 		if col.name == field || col.column == field {
@@ -237,10 +311,14 @@ func (t *modelInfo) IdxMap(field string) *IndexMap {
 // This operation is idempotent. If index is already mapped, the
 // existing *IndexMap is returned
 // Function will panic if one of the given for index columns does not exists
+// An empty name is replaced by the IndexName of the naming strategy.
 //
 // Automatically calls ResetSql() to ensure SQL statements are regenerated.
 //
 func (t *modelInfo) AddIndex(name string, idxtype string, columns []string) *IndexMap {
+	if name == "" {
+		name = t.namingStrategy().IndexName(t.table, columns)
+	}
 	// check if we have a index with this name already
 	for _, idx := range t.indexes {
 		if idx.IndexName == name {
@@ -315,11 +393,42 @@ func (t *modelInfo) SqlForCreate(ifNotExists bool) string {
 				stype = dialect.ToSqlType(col.relModelInfo.fields.GetOnePrimaryKey().gotype, col.relModelInfo.fields.GetOnePrimaryKey().size, false)
 			}
 
+		} else if col.array {
+			ad, ok := dialect.(ArrayDialect)
+			if !ok {
+				panic(fmt.Errorf("dialect %T does not support array field `%s`", dialect, col.fullName))
+			}
+			stype = ad.ToSqlArrayType(col.gotype.Elem(), col.size)
+		} else if col.interval {
+			id, ok := dialect.(IntervalDialect)
+			if !ok {
+				panic(fmt.Errorf("dialect %T does not support interval field `%s`", dialect, col.fullName))
+			}
+			stype = id.ToSqlIntervalType()
+		} else if col.fieldType == TypeDecimalField {
+			stype = sqlForDecimalType(dialect, col.digits, col.decimals)
+		} else if col.point {
+			stype = spatialDialect(dialect, col.fullName).ToSqlPointType()
+		} else if ed, ok := dialect.(EnumDialect); ok && len(col.enum) > 0 {
+			stype = ed.ToSqlEnumType(col.enum)
 		} else {
 			stype = dialect.ToSqlType(col.gotype, col.size, col.auto)
 		}
 		//stype := dialect.ToSqlType(col.gotype, col.size, col.auto)
 
+		if col.generated != "" {
+			if gc, ok := dialect.(GeneratedColumner); ok {
+				s.WriteString(gc.GeneratedColumn(dialect.QuoteField(col.column), stype, col.generated, !col.virtual))
+			} else {
+				kind := "stored"
+				if col.virtual {
+					kind = "virtual"
+				}
+				s.WriteString(fmt.Sprintf("%s %s generated always as (%s) %s", dialect.QuoteField(col.column), stype, col.generated, kind))
+			}
+			x++
+			continue
+		}
 		s.WriteString(fmt.Sprintf("%s %s", dialect.QuoteField(col.column), stype))
 
 		if col.pk || col.isNotNull {
@@ -334,6 +443,9 @@ func (t *modelInfo) SqlForCreate(ifNotExists bool) string {
 		if col.auto {
 			s.WriteString(fmt.Sprintf(" %s", dialect.AutoIncrStr()))
 		}
+		if _, ok := dialect.(EnumDialect); !ok && len(col.enum) > 0 {
+			s.WriteString(fmt.Sprintf(" check (%s in (%s))", dialect.QuoteField(col.column), sqlForEnumValues(col.enum)))
+		}
 
 		x++
 
@@ -364,11 +476,26 @@ func (t *modelInfo) SqlForCreate(ifNotExists bool) string {
 		}
 	}
 	s.WriteString(") ")
-	s.WriteString(dialect.CreateTableSuffix())
+	if to, ok := dialect.(TableOptioner); ok {
+		s.WriteString(to.CreateTableOptions(t.engine, t.charset, t.collation))
+	} else {
+		s.WriteString(dialect.CreateTableSuffix())
+	}
 	s.WriteString(dialect.QuerySuffix())
 	return s.String()
 }
 
+// SqlForDrop returns the statement dropping the table of the model, the
+// counterpart of SqlForCreate.
+func (t *modelInfo) SqlForDrop(ifExists bool) string {
+	dialect := Database().Get().Dialect
+	tableDrop := "drop table"
+	if ifExists {
+		tableDrop = dialect.IfTableExists(tableDrop, t.schemaName, t.table)
+	}
+	return fmt.Sprintf("%s %s;", tableDrop, dialect.QuotedTableForQuery(t.schemaName, t.table))
+}
+
 // parse orm model struct field tag expression.
 func (t *modelInfo) parseExprs(exprs []string) (index, name string, info *fieldInfo, success bool) {
 
@@ -421,7 +548,7 @@ func newFieldInfo(mi *modelInfo, field reflect.Value, sf reflect.StructField, mN
 		}
 	}
 
-	attrs, tags = parseStructTag(sf.Tag.Get(defaultStructTagName))
+	attrs, tags = parseStructTag(fieldTag(sf))
 
 	if _, ok := attrs["-"]; ok {
 		return nil, errSkipField
@@ -495,6 +622,11 @@ checkType:
 			}
 		}
 
+		if (digits != "" || decimals != "") && isDecimalValuer(addrField) {
+			fieldType = TypeDecimalField
+			break checkType
+		}
+
 		fieldType, err = getFieldType(addrField)
 		if err != nil {
 			goto end
@@ -509,6 +641,29 @@ checkType:
 				fieldType = TypeJsonbField
 			}
 		}
+		if tags["type"] == "array" {
+			if field.Kind() != reflect.Slice || field.Type().Elem().Kind() == reflect.Uint8 {
+				err = fmt.Errorf("type(array) only allow on slice field")
+				goto end
+			}
+			fi.array = true
+		}
+		if tags["type"] == "interval" {
+			if addrField.Type() != reflect.PtrTo(durationType) {
+				err = fmt.Errorf("type(interval) only allow on time.Duration field")
+				goto end
+			}
+			fi.interval = true
+		}
+		if v := tags["type"]; strings.HasPrefix(v, "enum=") {
+			if fieldType != TypeCharField {
+				err = fmt.Errorf("type(enum) only allow on string field")
+				goto end
+			}
+			for _, e := range strings.Split(v[len("enum="):], ",") {
+				fi.enum = append(fi.enum, strings.TrimSpace(e))
+			}
+		}
 		if fieldType == TypeFloatField && (digits != "" || decimals != "") {
 			fieldType = TypeDecimalField
 		}
@@ -548,16 +703,29 @@ checkType:
 
 	fi.fieldType = fieldType
 	fi.name = sf.Name
-	fi.column = getColumnName(fieldType, addrField, sf, tags["column"])
+	fi.column = getColumnName(fieldType, addrField, sf, tags["column"], mi.naming)
 	fi.addrValue = addrField
 	fi.sf = sf
 	fi.fullName = mi.fullName + mName + "." + sf.Name
 
-	fi.null = attrs["null"]
+	fi.null = attrs["null"] || isNullType(field.Type())
+	fi.point = field.Type() == reflect.TypeOf(Point{}) || field.Type() == reflect.TypeOf(&Point{})
 	fi.index = attrs["index"]
 	fi.auto = attrs["auto"]
 	fi.pk = attrs["pk"]
 	fi.unique = attrs["unique"]
+	fi.fulltext = attrs["fulltext"]
+	fi.returning = attrs["returning"]
+	fi.generated = tags["generated"]
+	fi.virtual = attrs["virtual"]
+	if fi.virtual && fi.generated == "" {
+		err = fmt.Errorf("virtual only allow on generated field")
+		goto end
+	}
+	if _, ok := tags["default"]; fi.generated != "" && (attrs["pk"] || attrs["auto"] || ok) {
+		err = fmt.Errorf("generated field can not be pk, auto or have a default")
+		goto end
+	}
 
 	// Mark object property if there is attribute "default" in the orm configuration
 	if _, ok := tags["default"]; ok {
@@ -610,6 +778,40 @@ checkType:
 		fi.onDelete = onDelete
 	}
 
+	if tv, ok := tags["touch"]; ok {
+		if fieldType != RelForeignKey && fieldType != RelOneToOne {
+			err = fmt.Errorf("touch only allow on rel(fk) or rel(one) field")
+			goto end
+		}
+		fi.touch = tv
+	}
+
+	if tv, ok := tags["related_name"]; ok {
+		if !fi.rel {
+			err = fmt.Errorf("related_name only allow on rel field")
+			goto end
+		}
+		fi.relatedName = tv
+	}
+
+	if tv, ok := tags["tree"]; ok {
+		if fieldType != RelForeignKey {
+			err = fmt.Errorf("tree only allow on rel(fk) field")
+			goto end
+		}
+		if tv != TreeAdjacency && tv != TreePath && tv != TreeClosure {
+			err = fmt.Errorf("tree value expected choice in `adjacency,path,closure`, unknown `%s`", tv)
+			goto end
+		}
+		fi.tree = tv
+	}
+
+	fi.treePath = attrs["tree_path"]
+	if fi.treePath && fieldType&(TypeCharField|TypeTextField) == 0 {
+		err = fmt.Errorf("tree_path only allow on string field")
+		goto end
+	}
+
 	switch fieldType {
 	case TypeBooleanField:
 	case TypeCharField, TypeJSONField, TypeJsonbField:
@@ -652,6 +854,18 @@ checkType:
 		}
 	}
 
+	if fi.fulltext && fieldType&(TypeCharField|TypeTextField) == 0 {
+		err = fmt.Errorf("fulltext only allow on string field")
+		goto end
+	}
+
+	fi.autoUser = attrs["auto_user"]
+	fi.autoUserAdd = attrs["auto_user_add"]
+	if (fi.autoUser || fi.autoUserAdd) && fieldType&(IsIntegerField|TypeCharField) == 0 {
+		err = fmt.Errorf("auto_user/auto_user_add only allow on integer or string field")
+		goto end
+	}
+
 	if fieldType&IsIntegerField == 0 {
 		if fi.auto {
 			err = fmt.Errorf("non-integer type cannot set auto")
@@ -723,6 +937,17 @@ wrongTag:
 	return nil, fmt.Errorf("wrong tag format: `%s:\"%s\"`, %s", tag, tagValue, err)
 }
 
+// embed qualifies fi, a field of an embedded struct, with the name and
+// column prefix of the embedding.
+func (fi *fieldInfo) embed(e *embedding) error {
+	if fi.pk || fi.auto || fi.rel || fi.reverse {
+		return fmt.Errorf("embedded struct fields can not be pk, auto or relations")
+	}
+	fi.name = e.name + fi.name
+	fi.column = e.prefix + fi.column
+	return nil
+}
+
 // combine related model info to new model info.
 // prepare for relation models query.
 func newM2MModelInfo(m1, m2 *modelInfo) (mi *modelInfo) {
@@ -753,10 +978,13 @@ func newM2MModelInfo(m1, m2 *modelInfo) (mi *modelInfo) {
 	mi = new(modelInfo)
 
 	mi.manual = false
+	mi.naming = m1.naming
+	mi.affix = m1.affix
 
 	mi.fields = newFields()
-	mi.table = m1.table + "_" + m2.table
-	mi.name = camelString(mi.table)
+	table := m1.affix.trim(m1.table) + "_" + m2.affix.trim(m2.table)
+	mi.table = mi.affix.apply(table)
+	mi.name = camelString(table)
 	mi.fullName = m1.pkg + "." + mi.name
 
 	//	fa := new(fieldInfo) // pk
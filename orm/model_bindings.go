@@ -15,7 +15,10 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // CustomScanner binds a database column value to a Go type
@@ -50,16 +53,230 @@ type bindPlan struct {
 	argFields         []string
 	keyFields         []string
 	versField         string
+	argIndexes        [][]int      // field indexes of argFields, nil for versFieldConst
+	argCols           []*fieldInfo // columns of argFields, nil for versFieldConst
+	keyIndexes        [][]int      // field indexes of keyFields
+	versIndex         []int        // field index of versField
 	autoIncrIdx       int
 	autoIncrFieldName string
-	once              sync.Once
+	mi                *modelInfo
+	gen               uint64 // generation of the planCache it was built for
 	paramValues       []interface{}
+	convFields        map[string]*fieldInfo // arrays and relations, not bound as is
+	insertCols        []*fieldInfo          // columns of the insert, in order
+	returnFields      []string              // fields read back from insert ... returning
+	dests             scanPool              // destinations of the rows scanned with the plan
+}
+
+// scanPool recycles the destination slices given to rows.Scan, so that
+// scanning doesn't allocate a slice per row.
+type scanPool struct {
+	pool sync.Pool
+}
+
+// get returns a slice of n destinations, to give back with put.
+func (p *scanPool) get(n int) *[]interface{} {
+	dest, _ := p.pool.Get().(*[]interface{})
+	if dest == nil || cap(*dest) < n {
+		s := make([]interface{}, n)
+		dest = &s
+	}
+	*dest = (*dest)[:n]
+	return dest
+}
+
+// put clears dest, not to retain the scanned values, and recycles it.
+func (p *scanPool) put(dest *[]interface{}) {
+	for i := range *dest {
+		(*dest)[i] = nil
+	}
+	p.pool.Put(dest)
+}
+
+type planKind int
+
+const (
+	insertPlan planKind = iota
+	updatePlan
+	deletePlan
+	getPlan
+	planKinds
+)
+
+// planCache holds the bind plans of a model.  Plans are immutable once
+// built and published atomically.  ResetSql bumps the generation of the
+// cache, so plans built for the previous mapping are rebuilt on next use
+// while the statements already using them finish undisturbed.
+type planCache struct {
+	gen     uint64
+	plans   [planKinds]atomic.Value // *bindPlan
+	updates sync.Map // plans of filtered updates, by updateKey
+}
+
+func (c *planCache) reset() {
+	atomic.AddUint64(&c.gen, 1)
+}
+
+// get returns the plan of kind for the current generation, calling build
+// to fill a new one if needed.  Concurrent callers may build the same plan
+// more than once; any of them can be kept, as they are identical.
+func (c *planCache) get(mi *modelInfo, kind planKind, build func(plan *bindPlan)) *bindPlan {
+	gen := atomic.LoadUint64(&c.gen)
+	if plan, ok := c.plans[kind].Load().(*bindPlan); ok && plan.gen == gen {
+		return plan
+	}
+	plan := &bindPlan{mi: mi, gen: gen}
+	build(plan)
+	plan.resolveIndexes()
+	c.plans[kind].Store(plan)
+	return plan
+}
+
+// getUpdate is get for the update plan of the columns accepted by a
+// ColumnFilter, cached by the set of these columns, see updateKey.
+func (c *planCache) getUpdate(mi *modelInfo, key string, build func(plan *bindPlan)) *bindPlan {
+	gen := atomic.LoadUint64(&c.gen)
+	if v, ok := c.updates.Load(key); ok && v.(*bindPlan).gen == gen {
+		return v.(*bindPlan)
+	}
+	plan := &bindPlan{mi: mi, gen: gen}
+	build(plan)
+	plan.resolveIndexes()
+	c.updates.Store(key, plan)
+	return plan
+}
+
+// updateKey returns the sorted names of the columns of t accepted by
+// colFilter, which identify the plan of a filtered update.
+func updateKey(t *modelInfo, colFilter ColumnFilter) string {
+	names := make([]string, 0, len(t.fields.columns))
+	for _, col := range t.fields.columns {
+		if colFilter(col) {
+			names = append(names, col.name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// addArgField appends the field to the plan arguments, remembering the
+// fields which need to be bound as native arrays or by related key.
+func (plan *bindPlan) addArgField(col *fieldInfo) {
+	plan.argFields = append(plan.argFields, col.name)
+	if col.array || col.interval || col.fieldType&IsRelField > 0 {
+		if plan.convFields == nil {
+			plan.convFields = make(map[string]*fieldInfo)
+		}
+		plan.convFields[col.name] = col
+	}
+}
+
+// resolveIndexes looks up the field indexes of the arguments and keys of
+// the built plan, so binding an element doesn't look fields up by name.
+func (plan *bindPlan) resolveIndexes() {
+	index := func(name string) []int {
+		if fi := plan.mi.fields.GetByName(name); fi != nil {
+			return fi.fieldIndex
+		}
+		return nil
+	}
+	plan.argIndexes = make([][]int, len(plan.argFields))
+	plan.argCols = make([]*fieldInfo, len(plan.argFields))
+	for i, name := range plan.argFields {
+		plan.argIndexes[i] = index(name)
+		plan.argCols[i] = plan.mi.fields.GetByName(name)
+	}
+	plan.keyIndexes = make([][]int, len(plan.keyFields))
+	for i, name := range plan.keyFields {
+		plan.keyIndexes[i] = index(name)
+	}
+	if plan.versField != "" {
+		plan.versIndex = index(plan.versField)
+	}
+}
+
+// field returns the field of elem at index, or named name when the plan
+// has no index for it.
+func (plan *bindPlan) field(elem reflect.Value, index []int, name string) reflect.Value {
+	if index != nil {
+		return elem.FieldByIndex(index)
+	}
+	return plan.mi.fieldValue(elem, name)
+}
+
+// fieldValue returns the field name of elem, a struct of the model, found
+// by its index so the fields of embedded structs resolve too.
+func (t *modelInfo) fieldValue(elem reflect.Value, name string) reflect.Value {
+	if fi := t.fields.GetByName(name); fi != nil && fi.fieldIndex != nil {
+		return elem.FieldByIndex(fi.fieldIndex)
+	}
+	return elem.FieldByName(name)
+}
+
+// bindValue returns the value bound for the field fi of elem: the field
+// wrapped by Array for arrays, the primary key of the related model, or
+// nil for NULL, for relations.
+func bindValue(fi *fieldInfo, elem reflect.Value) interface{} {
+	v := elem.FieldByIndex(fi.fieldIndex)
+	if fi.array {
+		return Array(v.Interface())
+	}
+	if fi.interval {
+		return Interval(v.Interface())
+	}
+	if v.IsNil() {
+		return nil
+	}
+	pk := fi.relModelInfo.fields.GetOnePrimaryKey()
+	return v.Elem().FieldByIndex(pk.fieldIndex).Interface()
+}
+
+// scanTarget returns what to scan the column of fi into, when it can not
+// be scanned into the address of the field, target, directly.
+func scanTarget(fi *fieldInfo, target interface{}) (interface{}, bool) {
+	switch {
+	case fi == nil:
+		return target, false
+	case fi.array:
+		return Array(target), true
+	case fi.interval:
+		return Interval(target), true
+	case fi.fieldType&IsRelField > 0 && fi.dbcol:
+		return &relScanner{fi: fi, target: reflect.ValueOf(target).Elem()}, true
+	}
+	return target, false
+}
+
+// relScanner scans a foreign key column into a pointer to the related
+// model, leaving it nil for NULL and setting only the primary key of the
+// related model otherwise.
+type relScanner struct {
+	fi     *fieldInfo
+	target reflect.Value
+}
+
+// Scan implements the Scanner interface.
+func (s *relScanner) Scan(src interface{}) error {
+	if src == nil {
+		s.target.Set(reflect.Zero(s.target.Type()))
+		return nil
+	}
+	if b, ok := src.([]byte); ok {
+		src = string(b)
+	}
+	rel := reflect.New(s.target.Type().Elem())
+	pk := s.fi.relModelInfo.fields.GetOnePrimaryKey()
+	if err := StrTo(ToStr(src)).setTo(rel.Elem().FieldByIndex(pk.fieldIndex)); err != nil {
+		return err
+	}
+	s.target.Set(rel)
+	return nil
 }
 
 func (plan *bindPlan) createBindInstance(elem reflect.Value, conv TypeConverter) (bindInstance, error) {
-	bi := bindInstance{query: plan.query, autoIncrIdx: plan.autoIncrIdx, autoIncrFieldName: plan.autoIncrFieldName, versField: plan.versField}
+	bi := bindInstance{query: plan.query, autoIncrIdx: plan.autoIncrIdx, autoIncrFieldName: plan.autoIncrFieldName, versField: plan.versField, returnFields: plan.returnFields}
 	if plan.versField != "" {
-		bi.existingVersion = elem.FieldByName(plan.versField).Int()
+		bi.existingVersion = plan.field(elem, plan.versIndex, plan.versField).Int()
 	}
 
 	var err error
@@ -71,11 +288,13 @@ func (plan *bindPlan) createBindInstance(elem reflect.Value, conv TypeConverter)
 			newVer := bi.existingVersion + 1
 			bi.args = append(bi.args, newVer)
 			if bi.existingVersion == 0 {
-				elem.FieldByName(plan.versField).SetInt(int64(newVer))
+				plan.field(elem, plan.versIndex, plan.versField).SetInt(int64(newVer))
 			}
 		} else {
-			val := elem.FieldByName(k).Interface()
-			if conv != nil {
+			val := plan.field(elem, plan.argIndexes[i], k).Interface()
+			if fi := plan.convFields[k]; fi != nil {
+				val = bindValue(fi, elem)
+			} else if conv != nil {
 				val, err = conv.ToDb(val)
 				if err != nil {
 					return bindInstance{}, err
@@ -87,7 +306,7 @@ func (plan *bindPlan) createBindInstance(elem reflect.Value, conv TypeConverter)
 
 	for i := 0; i < len(plan.keyFields); i++ {
 		k := plan.keyFields[i]
-		val := elem.FieldByName(k).Interface()
+		val := plan.field(elem, plan.keyIndexes[i], k).Interface()
 		if conv != nil {
 			val, err = conv.ToDb(val)
 			if err != nil {
@@ -108,11 +327,16 @@ type bindInstance struct {
 	versField         string
 	autoIncrIdx       int
 	autoIncrFieldName string
+	returnFields      []string
 }
 
 func (t *modelInfo) bindInsert(elem reflect.Value) (bindInstance, error) {
-	plan := &t.insertPlan
-	plan.once.Do(func() {
+	return t.insertPlan().createBindInstance(elem, Database().Get().TypeConverter)
+}
+
+// insertPlan returns the plan of the inserts of the model.
+func (t *modelInfo) insertPlan() *bindPlan {
+	return t.plans.get(t, insertPlan, func(plan *bindPlan) {
 		plan.autoIncrIdx = -1
 
 		s := bytes.Buffer{}
@@ -121,11 +345,12 @@ func (t *modelInfo) bindInsert(elem reflect.Value) (bindInstance, error) {
 
 		x := 0
 		first := true
+		var autoCol *fieldInfo
 		for _, col := range t.fields.columns {
 			//col := t.Columns[y]
 			if !(col.auto && Database().Get().Dialect.AutoIncrBindValue() == "") {
 
-				if col.transient || col.fieldType == RelManyToMany || col.fieldType == RelReverseMany {
+				if col.transient || col.generated != "" || col.fieldType == RelManyToMany || col.fieldType == RelReverseMany {
 
 				} else {
 					if !first {
@@ -133,14 +358,16 @@ func (t *modelInfo) bindInsert(elem reflect.Value) (bindInstance, error) {
 						s2.WriteString(",")
 					}
 					s.WriteString(Database().Get().Dialect.QuoteField(col.column))
+					plan.insertCols = append(plan.insertCols, col)
 
 					if col.auto {
 						s2.WriteString(Database().Get().Dialect.AutoIncrBindValue())
 						plan.autoIncrIdx = x
 						plan.autoIncrFieldName = col.name
+						autoCol = col
 					} else {
 						if col.DefaultValue == "" {
-							s2.WriteString(Database().Get().Dialect.BindVar(x))
+							s2.WriteString(sqlForBindVar(Database().Get().Dialect, col, Database().Get().Dialect.BindVar(x)))
 							if col == t.version {
 								plan.versField = col.name
 								plan.argFields = append(plan.argFields, versFieldConst)
@@ -150,7 +377,7 @@ func (t *modelInfo) bindInsert(elem reflect.Value) (bindInstance, error) {
 								if col.fieldType == RelManyToMany || col.fieldType == RelReverseMany {
 
 								} else {
-									plan.argFields = append(plan.argFields, col.name)
+									plan.addArgField(col)
 								}
 
 							}
@@ -165,49 +392,54 @@ func (t *modelInfo) bindInsert(elem reflect.Value) (bindInstance, error) {
 			} else {
 				plan.autoIncrIdx = x
 				plan.autoIncrFieldName = col.name
+				autoCol = col
 			}
 			x++
 		}
 		s.WriteString(") values (")
 		s.WriteString(s2.String())
 		s.WriteString(")")
-		if plan.autoIncrIdx > -1 {
-			s.WriteString(Database().Get().Dialect.AutoIncrInsertSuffix(t.fields.GetByIndex(plan.autoIncrIdx)))
+		if ri, ok := Database().Get().Dialect.(ReturningInserter); ok {
+			columns := make([]string, 0)
+			for _, col := range t.fields.fieldsDB {
+				if col == autoCol || col.DefaultValue != "" || col.returning {
+					plan.returnFields = append(plan.returnFields, col.name)
+					columns = append(columns, Database().Get().Dialect.QuoteField(col.column))
+				}
+			}
+			if len(columns) > 0 {
+				s.WriteString(ri.ReturningClause(columns))
+			}
+		} else if autoCol != nil {
+			s.WriteString(Database().Get().Dialect.AutoIncrInsertSuffix(autoCol))
 		}
 		s.WriteString(Database().Get().Dialect.QuerySuffix())
 
 		plan.query = s.String()
 	})
-
-	return plan.createBindInstance(elem, Database().Get().TypeConverter)
 }
 
 func (t *modelInfo) bindUpdate(elem reflect.Value, colFilter ColumnFilter) (bindInstance, error) {
-	if colFilter == nil {
-		colFilter = acceptAllFilter
-	}
-
-	plan := &t.updatePlan
-	plan.once.Do(func() {
+	build := func(plan *bindPlan) {
 		s := bytes.Buffer{}
 		s.WriteString(fmt.Sprintf("update %s set ", Database().Get().Dialect.QuotedTableForQuery(t.schemaName, t.table)))
 		x := 0
 
 		for _, col := range t.fields.columns {
 			//col := t.Columns[y]
-			if !col.auto && !col.transient && colFilter(col) {
+			if !col.auto && !col.autoUserAdd && !col.transient && col.generated == "" && colFilter(col) {
 				if x > 0 {
 					s.WriteString(", ")
 				}
 				s.WriteString(Database().Get().Dialect.QuoteField(col.column))
 				s.WriteString("=")
-				s.WriteString(Database().Get().Dialect.BindVar(x))
+				s.WriteString(sqlForBindVar(Database().Get().Dialect, col, Database().Get().Dialect.BindVar(x)))
 
 				if col == t.version {
 					plan.versField = col.name
 					plan.argFields = append(plan.argFields, versFieldConst)
 				} else {
-					plan.argFields = append(plan.argFields, col.name)
+					plan.addArgField(col)
 				}
 				x++
 			}
@@ -239,14 +471,22 @@ func (t *modelInfo) bindUpdate(elem reflect.Value, colFilter ColumnFilter) (bind
 		s.WriteString(Database().Get().Dialect.QuerySuffix())
 
 		plan.query = s.String()
-	})
+	}
 
+	// filtered updates, whose filter may depend on the element, have a
+	// plan per set of columns
+	var plan *bindPlan
+	if colFilter == nil {
+		colFilter = acceptAllFilter
+		plan = t.plans.get(t, updatePlan, build)
+	} else {
+		plan = t.plans.getUpdate(t, updateKey(t, colFilter), build)
+	}
 	return plan.createBindInstance(elem, Database().Get().TypeConverter)
 }
 
 func (t *modelInfo) bindDelete(elem reflect.Value) (bindInstance, error) {
-	plan := &t.deletePlan
-	plan.once.Do(func() {
+	plan := t.plans.get(t, deletePlan, func(plan *bindPlan) {
 		s := bytes.Buffer{}
 		s.WriteString(fmt.Sprintf("delete from %s", Database().Get().Dialect.QuotedTableForQuery(t.schemaName, t.table)))
 
@@ -291,8 +531,7 @@ func (t *modelInfo) bindDelete(elem reflect.Value) (bindInstance, error) {
 }
 
 func (t *modelInfo) bindGet() *bindPlan {
-	plan := &t.getPlan
-	plan.once.Do(func() {
+	return t.plans.get(t, getPlan, func(plan *bindPlan) {
 		s := bytes.Buffer{}
 		s.WriteString("select ")
 
@@ -327,6 +566,23 @@ func (t *modelInfo) bindGet() *bindPlan {
 
 		plan.query = s.String()
 	})
+}
 
-	return plan
+// sqlForTouch builds the statement used to bump the given timestamp
+// field of a single row of this table, keyed by its primary key.
+func (t *modelInfo) sqlForTouch(field *fieldInfo) string {
+	dialect := Database().Get().Dialect
+	pk := t.fields.GetOnePrimaryKey()
+
+	s := bytes.Buffer{}
+	s.WriteString(fmt.Sprintf("update %s set ", dialect.QuotedTableForQuery(t.schemaName, t.table)))
+	s.WriteString(dialect.QuoteField(field.column))
+	s.WriteString("=")
+	s.WriteString(dialect.BindVar(0))
+	s.WriteString(" where ")
+	s.WriteString(dialect.QuoteField(pk.column))
+	s.WriteString("=")
+	s.WriteString(dialect.BindVar(1))
+	s.WriteString(dialect.QuerySuffix())
+	return s.String()
 }
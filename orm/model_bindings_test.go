@@ -0,0 +1,147 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSqlForTouch(t *testing.T) {
+	registerTestModels(t, PostgresDialect{}, &touchPost{}, &touchComment{})
+
+	mi, ok := modelCache.get("touch_post")
+	if !ok {
+		t.Fatal("touch_post not registered")
+	}
+	cmi, _ := modelCache.get("touch_comment")
+	if fi := cmi.fields.GetByName("Post"); fi.touch != "Updated" {
+		t.Errorf("expected touch field Updated, got %q", fi.touch)
+	}
+
+	got := mi.sqlForTouch(mi.fields.GetByName("Updated"))
+	want := `update "touch_post" set "updated"=$1 where "id"=$2;`
+	if got != want {
+		t.Errorf("sqlForTouch:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestBindInsertReturning(t *testing.T) {
+	registerTestModels(t, PostgresDialect{}, &returningEvent{})
+	mi, _ := modelCache.get("returning_event")
+
+	bi, err := mi.bindInsert(reflect.ValueOf(&returningEvent{Name: "a"}).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(bi.query, ` returning "id","updated";`) {
+		t.Errorf("unexpected insert %s", bi.query)
+	}
+	if !reflect.DeepEqual(bi.returnFields, []string{"Id", "Updated"}) {
+		t.Errorf("unexpected return fields %v", bi.returnFields)
+	}
+
+	registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &returningEvent{})
+	mi, _ = modelCache.get("returning_event")
+	bi, err = mi.bindInsert(reflect.ValueOf(&returningEvent{Name: "a"}).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(bi.query, ") returning ") || len(bi.returnFields) != 0 {
+		t.Errorf("unexpected returning on mysql: %s", bi.query)
+	}
+}
+
+func TestPlanCacheReset(t *testing.T) {
+	registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	mi, _ := modelCache.get("search_article")
+
+	plan := mi.bindGet()
+	if mi.bindGet() != plan {
+		t.Error("expected the plan to be cached")
+	}
+	mi.ColMap("Body").Rename("content")
+	renamed := mi.bindGet()
+	if renamed == plan || !strings.Contains(renamed.query, "`content`") {
+		t.Errorf("expected a plan for the renamed column, got %q", renamed.query)
+	}
+	if strings.Contains(plan.query, "`content`") {
+		t.Error("the previous plan changed")
+	}
+
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func() {
+			for j := 0; j < 100; j++ {
+				mi.bindGet()
+				mi.ResetSql()
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+}
+
+func TestPlanIndexes(t *testing.T) {
+	registerTestModels(t, PostgresDialect{}, &embedCustomer{})
+	mi, _ := modelCache.get("embed_customer")
+
+	customer := &embedCustomer{Id: 7, Billing: embedAddress{City: "Paris"}, Shipping: embedAddress{City: "Lyon"}}
+	bi, err := mi.bindUpdate(reflect.ValueOf(customer).Elem(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := mi.plans.plans[updatePlan].Load().(*bindPlan)
+	for i, name := range plan.argFields {
+		if fi := mi.fields.GetByName(name); !reflect.DeepEqual(plan.argIndexes[i], fi.fieldIndex) {
+			t.Errorf("%s: expected index %v, got %v", name, fi.fieldIndex, plan.argIndexes[i])
+		}
+		if want := mi.fieldValue(reflect.ValueOf(customer).Elem(), name).Interface(); bi.args[i] != want {
+			t.Errorf("%s: expected %v bound, got %v", name, want, bi.args[i])
+		}
+	}
+	if len(bi.keys) != 1 || bi.keys[0] != int64(7) {
+		t.Errorf("unexpected keys %v", bi.keys)
+	}
+}
+
+func TestFilteredUpdatePlans(t *testing.T) {
+	registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &embedCustomer{})
+	mi, _ := modelCache.get("embed_customer")
+	elem := reflect.ValueOf(&embedCustomer{Id: 1, Name: "a"}).Elem()
+
+	only := func(names ...string) ColumnFilter {
+		return func(col *fieldInfo) bool {
+			for _, name := range names {
+				if col.name == name {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	name, err := mi.bindUpdate(elem, only("Name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	city, err := mi.bindUpdate(elem, only("Billing.City"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name.query != "update `embed_customer` set `name`=? where `id`=?;" {
+		t.Errorf("unexpected name update %q", name.query)
+	}
+	if city.query != "update `embed_customer` set `billing_city`=? where `id`=?;" {
+		t.Errorf("unexpected city update %q", city.query)
+	}
+
+	plan := mi.plans.getUpdate(mi, updateKey(mi, only("Name")), nil)
+	if plan.query != name.query {
+		t.Errorf("expected the name plan to be cached, got %q", plan.query)
+	}
+	mi.ColMap("Name").Rename("full_name")
+	if renamed, _ := mi.bindUpdate(elem, only("Name")); !strings.Contains(renamed.query, "`full_name`") {
+		t.Errorf("expected a plan for the renamed column, got %q", renamed.query)
+	}
+}
@@ -165,15 +165,26 @@ type fieldInfo struct {
 	null                bool //is null
 	index               bool
 	unique              bool
-	colDefault          bool  // whether has default tag
-	initial             StrTo // store the default value
+	fulltext            bool
+	array               bool     // bound as a native array column
+	returning           bool     // set by the database, read back after insert
+	generated           string   // expression of a generated column, read only
+	virtual             bool     // generated column computed when read
+	enum                []string // values allowed by type(enum=...)
+	point               bool     // Point stored in a spatial column
+	interval            bool     // time.Duration stored in an interval column
+	colDefault          bool     // whether has default tag
+	initial             StrTo    // store the default value
 	size                int
 	toText              bool
 	autoNow             bool
 	autoNowAdd          bool
+	autoUser            bool // set to the user ID of the context on write
+	autoUserAdd         bool // set to the user ID of the context on insert
 	rel                 bool // if type equal to RelForeignKey, RelOneToOne, RelManyToMany then true
 	reverse             bool
 	reverseField        string
+	relatedName         string // name of the reverse field on the related model
 	reverseFieldInfo    *fieldInfo
 	reverseFieldInfoTwo *fieldInfo
 	reverseFieldInfoM2M *fieldInfo
@@ -185,14 +196,21 @@ type fieldInfo struct {
 	decimals            int
 	isFielder           bool // implement Fielder interface
 	onDelete            string
+	touch               string // field of the related model bumped to now on write
+	tree                string // tree storage strategy of a self-referential rel
+	treePath            bool   // holds the materialized path of a tree model
 }
 
 // Rename allows you to specify the column name in the table
 //
 // Example:  table.ColMap("Updated").Rename("date_updated")
 //
+// Automatically calls ResetSql() on the table of the column.
 func (c *fieldInfo) Rename(colname string) *fieldInfo {
 	c.column = colname
+	if c.mi != nil {
+		c.mi.ResetSql()
+	}
 	return c
 }
 
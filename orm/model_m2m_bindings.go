@@ -40,181 +40,174 @@ func (plan *bindPlan) createM2MBindInstance(conv TypeConverter) (bindInstance, e
 
 func (t *modelInfo) bindM2MInsert(elem reflect.Value, field string, args []interface{}) (bindInstance, error) {
 
-	plan := &t.m2mInsertPlan
+	// the plan holds the values of elem and args, so it is built for each call
+	plan := &bindPlan{}
+	plan.autoIncrIdx = -1
 
-	plan.once.Do(func() {
+	s := bytes.Buffer{}
 
-		plan.autoIncrIdx = -1
+	relField := t.fields.GetByName(field)
 
-		s := bytes.Buffer{}
-
-		relField := t.fields.GetByName(field)
+	if relField == nil {
+		panic(fmt.Sprintf("Can't find relation field :%s", field))
+	}
 
-		if relField == nil {
-			panic(fmt.Sprintf("Can't find relation field :%s", field))
-		}
+	relThroughModelInfo := relField.relThroughModelInfo
+	relModelInfo := relField.relModelInfo
 
-		relThroughModelInfo := relField.relThroughModelInfo
-		relModelInfo := relField.relModelInfo
+	s.WriteString(fmt.Sprintf("insert into %s ", Database().Get().Dialect.QuotedTableForQuery(t.schemaName, relThroughModelInfo.table)))
 
-		s.WriteString(fmt.Sprintf("insert into %s ", Database().Get().Dialect.QuotedTableForQuery(t.schemaName, relThroughModelInfo.table)))
+	plan.paramValues = make([]interface{}, 0)
 
-		plan.paramValues = make([]interface{}, 0)
+	mPK := t.fields.GetOnePrimaryKey()
+	fPK := relModelInfo.fields.GetOnePrimaryKey()
 
-		mPK := t.fields.GetOnePrimaryKey()
-		fPK := relModelInfo.fields.GetOnePrimaryKey()
+	reveseKeyValue := getFieldValue(elem.Interface(), mPK.name)
 
-		reveseKeyValue := getFieldValue(elem.Interface(), mPK.name)
+	loop := 0
+	for index := range args {
 
-		loop := 0
-		for index := range args {
+		if loop == 0 {
+			s.WriteString("(")
+		}
 
-			if loop == 0 {
-				s.WriteString("(")
-			}
+		x := 0
 
-			x := 0
+		av := args[index]
 
-			av := args[index]
+		s2 := bytes.Buffer{}
+		s3 := bytes.Buffer{}
 
-			s2 := bytes.Buffer{}
-			s3 := bytes.Buffer{}
+		for _, col := range relThroughModelInfo.fields.columns {
 
-			for _, col := range relThroughModelInfo.fields.columns {
+			//col := t.Columns[y]
+			if !(col.auto && Database().Get().Dialect.AutoIncrBindValue() == "") {
 
-				//col := t.Columns[y]
-				if !(col.auto && Database().Get().Dialect.AutoIncrBindValue() == "") {
+				if col.transient || col.fieldType == RelManyToMany || col.fieldType == RelReverseMany {
 
-					if col.transient || col.fieldType == RelManyToMany || col.fieldType == RelReverseMany {
+				} else {
 
-					} else {
+					if x > 0 {
+						s2.WriteString(",")
+						s3.WriteString(",")
+					}
 
-						if x > 0 {
-							s2.WriteString(",")
-							s3.WriteString(",")
-						}
+					s3.WriteString(Database().Get().Dialect.QuoteField(col.column))
 
-						s3.WriteString(Database().Get().Dialect.QuoteField(col.column))
+					if col.auto {
+						s2.WriteString(Database().Get().Dialect.AutoIncrBindValue())
+						plan.autoIncrIdx = x
+						plan.autoIncrFieldName = col.column
+					} else {
+						if col.DefaultValue == "" {
+							s2.WriteString(Database().Get().Dialect.BindVar(x))
+							if col == t.version {
+								plan.versField = col.name
+								if loop == 0 {
+									plan.argFields = append(plan.argFields, versFieldConst)
+								}
+							} else {
+								if col.fieldType == RelManyToMany || col.fieldType == RelReverseMany {
 
-						if col.auto {
-							s2.WriteString(Database().Get().Dialect.AutoIncrBindValue())
-							plan.autoIncrIdx = x
-							plan.autoIncrFieldName = col.column
-						} else {
-							if col.DefaultValue == "" {
-								s2.WriteString(Database().Get().Dialect.BindVar(x))
-								if col == t.version {
-									plan.versField = col.name
-									if loop == 0 {
-										plan.argFields = append(plan.argFields, versFieldConst)
-									}
 								} else {
-									if col.fieldType == RelManyToMany || col.fieldType == RelReverseMany {
-
-									} else {
-										if loop == 0 {
-											plan.argFields = append(plan.argFields, col.column)
-										}
+									if loop == 0 {
+										plan.argFields = append(plan.argFields, col.column)
 									}
-
 								}
-								x++
-							} else {
-								s2.WriteString(col.DefaultValue)
-							}
-						}
 
-						if mPK.column == col.column {
-							plan.paramValues = append(plan.paramValues, reveseKeyValue)
+							}
+							x++
 						} else {
-							plan.paramValues = append(plan.paramValues, getFieldValue(av, fPK.name))
+							s2.WriteString(col.DefaultValue)
 						}
+					}
 
+					if mPK.column == col.column {
+						plan.paramValues = append(plan.paramValues, reveseKeyValue)
+					} else {
+						plan.paramValues = append(plan.paramValues, getFieldValue(av, fPK.name))
 					}
 
-				} else {
-					plan.autoIncrIdx = x
-					plan.autoIncrFieldName = col.name
 				}
-				x++
-
-			}
 
-			if loop == 0 {
-				s.WriteString(s3.String())
-				s.WriteString(") values (")
-				s.WriteString(s2.String())
-				s.WriteString(")")
 			} else {
-				s.WriteString(", (")
-				s.WriteString(s2.String())
-				s.WriteString(")")
+				plan.autoIncrIdx = x
+				plan.autoIncrFieldName = col.name
 			}
-
-			loop++
+			x++
 
 		}
 
-		if plan.autoIncrIdx > -1 {
-			s.WriteString(Database().Get().Dialect.AutoIncrInsertSuffix(t.fields.GetByIndex(plan.autoIncrIdx)))
+		if loop == 0 {
+			s.WriteString(s3.String())
+			s.WriteString(") values (")
+			s.WriteString(s2.String())
+			s.WriteString(")")
+		} else {
+			s.WriteString(", (")
+			s.WriteString(s2.String())
+			s.WriteString(")")
 		}
-		s.WriteString(Database().Get().Dialect.QuerySuffix())
 
-		plan.query = s.String()
+		loop++
 
-	})
+	}
+
+	if plan.autoIncrIdx > -1 {
+		s.WriteString(Database().Get().Dialect.AutoIncrInsertSuffix(t.fields.GetByIndex(plan.autoIncrIdx)))
+	}
+	s.WriteString(Database().Get().Dialect.QuerySuffix())
+
+	plan.query = s.String()
 
 	return plan.createM2MBindInstance(Database().Get().TypeConverter)
 }
 
 func (t *modelInfo) bindM2MQuery(elem reflect.Value, field string) (bindInstance, error) {
 
-	plan := &t.m2mQueryPlan
+	// the plan holds the values of elem, so it is built for each call
+	plan := &bindPlan{}
 
-	plan.once.Do(func() {
+	pk := t.fields.GetOnePrimaryKey()
+	pkName := pk.name
 
-		pk := t.fields.GetOnePrimaryKey()
-		pkName := pk.name
+	reveseKeyValue := getFieldValue(elem.Interface(), pkName)
 
-		reveseKeyValue := getFieldValue(elem.Interface(), pkName)
-
-		if reveseKeyValue == nil {
-			panic(fmt.Sprintf("can't find m2m as %s 's key(%s) is null", t.name, pkName))
-		}
-
-		plan.paramValues = make([]interface{}, 0)
+	if reveseKeyValue == nil {
+		panic(fmt.Sprintf("can't find m2m as %s 's key(%s) is null", t.name, pkName))
+	}
 
-		plan.paramValues = append(plan.paramValues, reveseKeyValue)
+	plan.paramValues = make([]interface{}, 0)
 
-		plan.autoIncrIdx = -1
+	plan.paramValues = append(plan.paramValues, reveseKeyValue)
 
-		s := bytes.Buffer{}
+	plan.autoIncrIdx = -1
 
-		relField := t.fields.GetByName(field)
+	s := bytes.Buffer{}
 
-		if relField == nil {
-			panic(fmt.Sprintf("Can't find relation field :%s", field))
-		}
+	relField := t.fields.GetByName(field)
 
-		relThroughModelInfo := relField.relThroughModelInfo
+	if relField == nil {
+		panic(fmt.Sprintf("Can't find relation field :%s", field))
+	}
 
-		relModelInfo := relField.relModelInfo
+	relThroughModelInfo := relField.relThroughModelInfo
 
-		joinColumn := relModelInfo.fields.GetOnePrimaryKey().column
+	relModelInfo := relField.relModelInfo
 
-		targetTable := Database().Get().Dialect.QuotedTableForQuery(relModelInfo.schemaName, relModelInfo.table)
-		joinTable := Database().Get().Dialect.QuotedTableForQuery(relThroughModelInfo.schemaName, relThroughModelInfo.table)
+	joinColumn := relModelInfo.fields.GetOnePrimaryKey().column
 
-		//Select
-		s.WriteString(fmt.Sprintf("select %s.* from %s left join %s on %s.%s = %s.%s ", targetTable, targetTable, joinTable, targetTable, Database().Get().Dialect.QuoteField(joinColumn), joinTable, Database().Get().Dialect.QuoteField(joinColumn)))
-		//Where
-		s.WriteString(fmt.Sprintf("where %s.%s = ? ", joinTable, Database().Get().Dialect.QuoteField(pk.column)))
+	targetTable := Database().Get().Dialect.QuotedTableForQuery(relModelInfo.schemaName, relModelInfo.table)
+	joinTable := Database().Get().Dialect.QuotedTableForQuery(relThroughModelInfo.schemaName, relThroughModelInfo.table)
 
-		s.WriteString(Database().Get().Dialect.QuerySuffix())
+	//Select
+	s.WriteString(fmt.Sprintf("select %s.* from %s left join %s on %s.%s = %s.%s ", targetTable, targetTable, joinTable, targetTable, Database().Get().Dialect.QuoteField(joinColumn), joinTable, Database().Get().Dialect.QuoteField(joinColumn)))
+	//Where
+	s.WriteString(fmt.Sprintf("where %s.%s = ? ", joinTable, Database().Get().Dialect.QuoteField(pk.column)))
 
-		plan.query = s.String()
+	s.WriteString(Database().Get().Dialect.QuerySuffix())
 
-	})
+	plan.query = s.String()
 
 	return plan.createM2MBindInstance(Database().Get().TypeConverter)
 }
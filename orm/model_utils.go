@@ -15,8 +15,8 @@ func getFullName(typ reflect.Type) string {
 
 // getTableName get struct table name.
 // If the struct implement the TableName, then get the result as tablename
-// else use the struct name which will apply snakeString.
-func getTableName(val reflect.Value) string {
+// else name the struct with the naming strategy.
+func getTableName(val reflect.Value, naming NamingStrategy) string {
 	if fun := val.MethodByName("TableName"); fun.IsValid() {
 		vals := fun.Call([]reflect.Value{})
 		// has return and the first val is string
@@ -24,7 +24,23 @@ func getTableName(val reflect.Value) string {
 			return vals[0].String()
 		}
 	}
-	return snakeString(reflect.Indirect(val).Type().Name())
+	return naming.TableName(reflect.Indirect(val).Type())
+}
+
+// tableAffix is the prefix and suffix of the table names of a DbMap, see
+// DbMap.TablePrefix.
+type tableAffix struct {
+	prefix, suffix string
+}
+
+// apply returns name with the prefix and suffix.
+func (a tableAffix) apply(name string) string {
+	return a.prefix + name + a.suffix
+}
+
+// trim returns name without the prefix and suffix.
+func (a tableAffix) trim(name string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(name, a.prefix), a.suffix)
 }
 
 //getTableKeys get table primary keys
@@ -92,15 +108,14 @@ func parseStructTag(data string) (attrs map[string]bool, tags map[string]string)
 		v = strings.TrimSpace(v)
 		if t := strings.ToLower(v); supportTag[t] == 1 {
 			attrs[t] = true
-		} else if i := strings.Index(v, "("); i > 0 && strings.Index(v, ")") == len(v)-1 {
+		} else if i := strings.Index(v, "("); i > 0 && strings.HasSuffix(v, ")") {
 			name := t[:i]
 			if supportTag[name] == 2 {
 				v = v[i+1 : len(v)-1]
 				tags[name] = v
 			}
 		} else {
-			//DebugLog.Println("unsupport orm tag", v)
-			fmt.Println("unsupport orm tag %s", v)
+			DebugLog.Debug("unsupport orm tag %s", v)
 		}
 	}
 	return
@@ -116,7 +131,9 @@ func getFieldType(val reflect.Value) (ft int, err error) {
 	case reflect.TypeOf(new(int32)),
 		reflect.TypeOf(new(int)):
 		ft = TypeIntegerField
-	case reflect.TypeOf(new(int64)):
+	case reflect.TypeOf(new(int64)),
+		reflect.TypeOf(new(time.Duration)):
+		// durations are stored as nanoseconds
 		ft = TypeBigIntegerField
 	case reflect.TypeOf(new(uint8)):
 		ft = TypePositiveBitField
@@ -136,6 +153,11 @@ func getFieldType(val reflect.Value) (ft int, err error) {
 		ft = TypeCharField
 	case reflect.TypeOf(new(time.Time)):
 		ft = TypeDateTimeField
+	case reflect.TypeOf(new(Decimal)):
+		ft = TypeDecimalField
+	case reflect.TypeOf(new(Point)):
+		// bound as well-known text
+		ft = TypeCharField
 	default:
 		elm := reflect.Indirect(val)
 		switch elm.Kind() {
@@ -170,13 +192,15 @@ func getFieldType(val reflect.Value) (ft int, err error) {
 			switch elm.Interface().(type) {
 			case sql.NullInt64:
 				ft = TypeBigIntegerField
+			case sql.NullInt32:
+				ft = TypeIntegerField
 			case sql.NullFloat64:
 				ft = TypeFloatField
 			case sql.NullBool:
 				ft = TypeBooleanField
 			case sql.NullString:
 				ft = TypeCharField
-			case time.Time:
+			case time.Time, sql.NullTime, NullTime:
 				ft = TypeDateTimeField
 			}
 		}
@@ -187,11 +211,24 @@ func getFieldType(val reflect.Value) (ft int, err error) {
 	return
 }
 
-// get snaked column name
-func getColumnName(ft int, addrField reflect.Value, sf reflect.StructField, col string) string {
+// isNullType reports whether typ is one of the sql.Null* types, or NullTime,
+// which are mapped to nullable columns of the type they wrap.
+func isNullType(typ reflect.Type) bool {
+	switch typ {
+	case reflect.TypeOf(sql.NullString{}), reflect.TypeOf(sql.NullInt64{}),
+		reflect.TypeOf(sql.NullInt32{}), reflect.TypeOf(sql.NullFloat64{}),
+		reflect.TypeOf(sql.NullBool{}), reflect.TypeOf(sql.NullTime{}),
+		reflect.TypeOf(NullTime{}):
+		return true
+	}
+	return false
+}
+
+// get column name, from the tag or else the naming strategy
+func getColumnName(ft int, addrField reflect.Value, sf reflect.StructField, col string, naming NamingStrategy) string {
 	column := col
 	if col == "" {
-		column = snakeString(sf.Name)
+		column = naming.ColumnName(sf)
 	}
 	switch ft {
 	case RelForeignKey, RelOneToOne:
@@ -0,0 +1,308 @@
+package orm
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// registerTestModels resets the model cache, installs a DbMap using the
+// given dialect and registers and bootstraps the given models.
+func registerTestModels(t *testing.T, dialect Dialect, models ...interface{}) *DbMap {
+	ResetModelCache()
+	dbmap := &DbMap{Dialect: dialect}
+	Database().Set(dbmap)
+	for _, m := range models {
+		RegisterModel(m)
+	}
+	BootStrap()
+	return dbmap
+}
+
+type touchPost struct {
+	Id      int64 `orm:"pk;auto"`
+	Title   string
+	Updated time.Time
+}
+
+type touchComment struct {
+	Id   int64 `orm:"pk;auto"`
+	Body string
+	Post *touchPost `orm:"rel(fk);touch(Updated)"`
+}
+
+type searchArticle struct {
+	Id   int64  `orm:"pk;auto"`
+	Body string `orm:"type(text);fulltext"`
+}
+
+// newTestCriteria creates a criteria rooted at the given registered model.
+func newTestCriteria(dbmap *DbMap, model interface{}) Criteria {
+	typ := reflect.Indirect(reflect.ValueOf(model)).Type()
+	mi, err := dbmap.TableFor(typ)
+	if err != nil {
+		panic(err)
+	}
+	return newCriteria(dbmap, mi, model, typ)
+}
+
+type pathCategory struct {
+	Id     int64         `orm:"pk;auto"`
+	Name   string        `orm:"size(64)"`
+	Parent *pathCategory `orm:"rel(fk);null;on_delete(cascade);tree(path)"`
+	Path   string        `orm:"tree_path"`
+}
+
+type adjCategory struct {
+	Id     int64        `orm:"pk;auto"`
+	Name   string       `orm:"size(64)"`
+	Parent *adjCategory `orm:"rel(fk);null;tree(adjacency)"`
+}
+
+type selfRefNode struct {
+	Id   int64        `orm:"pk;auto"`
+	Next *selfRefNode `orm:"rel(fk);null"`
+}
+
+type closureCategory struct {
+	Id     int64            `orm:"pk;auto"`
+	Name   string           `orm:"size(64)"`
+	Parent *closureCategory `orm:"rel(fk);null;on_delete(cascade);tree(closure)"`
+}
+
+type jsonDocument struct {
+	Id   int64  `orm:"pk;auto"`
+	Data string `orm:"type(jsonb)"`
+}
+
+type arrayPost struct {
+	Id     int64    `orm:"pk;auto"`
+	Tags   []string `orm:"type(array)"`
+	Scores []int64  `orm:"type(array)"`
+}
+
+type uniqueAccount struct {
+	Id    int64   `orm:"pk;auto"`
+	Email string  `orm:"unique"`
+	Org   string  `orm:"size(32)"`
+	Login *string `orm:"null"`
+}
+
+type returningEvent struct {
+	Id      int64     `orm:"pk;auto"`
+	Name    string    `orm:"size(32)"`
+	Updated time.Time `orm:"returning"`
+}
+
+type nullProfile struct {
+	Id       int64          `orm:"pk;auto"`
+	Nickname sql.NullString `orm:"size(32)"`
+	Age      sql.NullInt32  `orm:"default(18)"`
+	Score    sql.NullFloat64
+	Active   sql.NullBool
+	Seen     sql.NullTime
+	Deleted  NullTime
+	Owner    *nullProfile `orm:"rel(fk);null;on_delete(set_null)"`
+}
+
+type relAuthor struct {
+	Id   int64 `orm:"pk;auto"`
+	Name string
+}
+
+type relBook struct {
+	Id     int64      `orm:"pk;auto"`
+	Author *relAuthor `orm:"rel(fk)"`
+	Editor *relAuthor `orm:"rel(fk)"`
+	Critic *relAuthor `orm:"rel(fk);related_name(Reviewed)"`
+}
+
+type fetchAuthor struct {
+	Id    int64 `orm:"pk;auto"`
+	Name  string
+	Books []*fetchBook `orm:"reverse(many)"`
+}
+
+type fetchBook struct {
+	Id     int64 `orm:"pk;auto"`
+	Title  string
+	Author *fetchAuthor `orm:"rel(fk)"`
+}
+
+type exampleUser struct {
+	Id     int64 `orm:"pk;auto"`
+	Name   string
+	City   string
+	Age    int
+	Author *relAuthor `orm:"rel(fk)"`
+}
+
+type partialPost struct {
+	Id     int64      `orm:"pk;auto"`
+	Title  string     `orm:"size(100)"`
+	Body   string     `orm:"type(text)"`
+	Author *relAuthor `orm:"rel(fk)"`
+}
+
+type partialPostSummary struct {
+	Id     int64
+	Title  string     `orm:"size(100)"`
+	Author *relAuthor `orm:"rel(fk)"`
+}
+
+type embedAddress struct {
+	Street string `orm:"size(100)"`
+	City   string `orm:"size(50)"`
+}
+
+type embedCustomer struct {
+	Id       int64 `orm:"pk;auto"`
+	Name     string
+	Billing  embedAddress `orm:"embedded;prefix(billing_)"`
+	Shipping embedAddress `orm:"embedded;prefix(shipping_)"`
+}
+
+type auditPost struct {
+	Id        int64 `orm:"pk;auto"`
+	Title     string
+	CreatedBy int64 `orm:"auto_user_add"`
+	UpdatedBy int64 `orm:"auto_user"`
+}
+
+type trackedUser struct {
+	Tracked
+	Id    int64 `orm:"pk;auto"`
+	Name  string
+	Email string
+}
+
+type versionedNote struct {
+	Tracked
+	Id      int64 `orm:"pk;auto"`
+	Body    string
+	Version int64
+}
+
+type scopedNote struct {
+	Id        int64 `orm:"pk;auto"`
+	TenantId  int64
+	DeletedAt time.Time `orm:"null"`
+}
+
+type genericComment struct {
+	Id         int64       `orm:"pk;auto"`
+	Target     interface{} `orm:"rel(generic)"`
+	TargetType string
+	TargetId   int64
+}
+
+type genericPhoto struct {
+	Id int64 `orm:"pk"`
+}
+
+type indexedPost struct {
+	_       struct{} `orm:"index(Author,Created);unique(Site,Slug)"`
+	Id      int64    `orm:"pk;auto"`
+	Title   string
+	Author  string
+	Site    string
+	Slug    string
+	Created time.Time
+}
+
+func (p *indexedPost) TableIndex() [][]string {
+	return [][]string{{"Title", "Author"}}
+}
+
+type generatedLine struct {
+	Id       int64 `orm:"pk;auto"`
+	Price    int64
+	Quantity int64
+	Total    int64  `orm:"generated(price * quantity)"`
+	Label    string `orm:"generated(concat('#', id));virtual"`
+}
+
+type enumAccount struct {
+	Id     int64   `orm:"pk;auto"`
+	Status string  `orm:"type(enum=active,blocked,deleted)"`
+	Kind   *string `orm:"null;type(enum=user,o'brien)"`
+}
+
+type pointStore struct {
+	Id       int64 `orm:"pk;auto"`
+	Location Point
+	Previous *Point `orm:"null"`
+}
+
+// moneyAmount mimics the decimal types of other packages, eg shopspring's.
+type moneyAmount struct{ cents string }
+
+func (m moneyAmount) Value() (driver.Value, error) { return m.cents, nil }
+
+func (m *moneyAmount) Scan(src interface{}) error {
+	m.cents = fmt.Sprint(src)
+	return nil
+}
+
+type decimalInvoice struct {
+	Id       int64       `orm:"pk;auto"`
+	Total    Decimal     `orm:"digits(12);decimals(2)"`
+	Discount *Decimal    `orm:"null;digits(5);decimals(4)"`
+	Rate     float64     `orm:"digits(6);decimals(3)"`
+	Paid     moneyAmount `orm:"digits(12);decimals(2)"`
+}
+
+type durationJob struct {
+	Id      int64 `orm:"pk;auto"`
+	Timeout time.Duration
+	Backoff time.Duration  `orm:"type(interval)"`
+	Grace   *time.Duration `orm:"null;type(interval)"`
+}
+
+type blobFile struct {
+	Id   int64 `orm:"pk"`
+	Name string
+	Data []byte
+}
+
+type gormTimestamps struct {
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+type gormProduct struct {
+	ID      uint           `gorm:"primaryKey"`
+	Code    string         `gorm:"column:sku;type:varchar(40);uniqueIndex"`
+	Name    string         `gorm:"size:100;index;not null"`
+	Price   float64        `gorm:"precision:10;scale:2"`
+	Notes   string         `gorm:"type:text;comment:free text"`
+	Secret  string         `gorm:"-"`
+	Stock   int            `orm:"column(qty)" gorm:"column:stock"`
+	Stamps  gormTimestamps `gorm:"embedded;embeddedPrefix:audit_"`
+	Ignored string
+}
+
+type reportBody struct {
+	Text string `orm:"column(body)"`
+}
+
+type reportSuffix struct {
+	Tra string
+}
+
+// reportLine isn't a model, see SelectStruct.
+type reportLine struct {
+	reportBody
+	ID    int64
+	Extra reportSuffix `orm:"embedded;prefix(ex)"`
+}
+
+type engineLog struct {
+	Id   int64 `orm:"pk;auto"`
+	Line string
+}
+
+func (engineLog) TableEngine() string { return "MyISAM" }
@@ -0,0 +1,99 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// RawNamed expands the :name parameters of query with the dialect of the
+// database of Database(), see DbMap.RawNamed.
+func RawNamed(query string, params map[string]interface{}) (string, []interface{}, error) {
+	return Database().Get().RawNamed(query, params)
+}
+
+// RawNamed replaces the :name parameters of query with the positional
+// bind variables of the dialect and returns the values of params in their
+// order, to run hand-written queries:
+//
+//	query, args, err := dbmap.RawNamed("select * from user where name = :name",
+//		map[string]interface{}{"name": "bob"})
+//	...
+//	_, err = dbmap.Select(&users, query, args...)
+//
+// A parameter may appear several times.  Quoted strings and identifiers
+// and postgres casts such as ::text are left alone.  A parameter missing
+// from params is an error.
+func (m *DbMap) RawNamed(query string, params map[string]interface{}) (string, []interface{}, error) {
+	var (
+		sql   strings.Builder
+		args  []interface{}
+		quote rune
+	)
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ':' && i+1 < len(runes) && runes[i+1] == ':':
+			sql.WriteString("::")
+			i++
+			continue
+		case r == ':' && i+1 < len(runes) && isNameRune(runes[i+1]):
+			j := i + 1
+			for j < len(runes) && isNameRune(runes[j]) {
+				j++
+			}
+			name := string(runes[i+1 : j])
+			value, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("<orm.RawNamed> missing parameter `%s`", name)
+			}
+			sql.WriteString(m.Dialect.BindVar(len(args)))
+			args = append(args, value)
+			i = j - 1
+			continue
+		}
+		sql.WriteRune(r)
+	}
+	if quote != 0 {
+		return "", nil, fmt.Errorf("<orm.RawNamed> unterminated %c quote", quote)
+	}
+	return sql.String(), args, nil
+}
+
+// bindVars replaces the ? bind variables of query, as the criterions
+// render them, with those of dialect numbered from n, and returns the
+// next number.  Quoted strings and identifiers are left alone, as by
+// RawNamed.
+func bindVars(dialect Dialect, query string, n int) (string, int) {
+	var (
+		sql   strings.Builder
+		quote rune
+	)
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			sql.WriteString(dialect.BindVar(n))
+			n++
+			continue
+		}
+		sql.WriteRune(r)
+	}
+	return sql.String(), n
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
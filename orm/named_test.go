@@ -0,0 +1,23 @@
+package orm
+
+import "testing"
+
+func TestRawNamed(t *testing.T) {
+	m := &DbMap{Dialect: PostgresDialect{}}
+	query, args, err := m.RawNamed(
+		`select * from "user" where name = :name and created::date > :since and note <> ':name' or alias = :name`,
+		map[string]interface{}{"name": "bob", "since": "2016-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `select * from "user" where name = $1 and created::date > $2 and note <> ':name' or alias = $3`; query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+	if len(args) != 3 || args[0] != "bob" || args[1] != "2016-01-01" || args[2] != "bob" {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	if _, _, err = m.RawNamed("select * from post where id = :id", nil); err == nil {
+		t.Error("expected an error for a missing parameter")
+	}
+}
@@ -0,0 +1,66 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NamingStrategy names the tables, columns, indexes and foreign keys the
+// models don't name themselves, with a TableName method, a column tag or
+// AddIndex.  The names are chosen when the models are registered, so the
+// strategy must be set before.
+type NamingStrategy interface {
+	// TableName names the table of the model struct typ.
+	TableName(typ reflect.Type) string
+	// ColumnName names the column of the model field.  Relations get a
+	// "_id" suffix appended by the orm.
+	ColumnName(field reflect.StructField) string
+	// IndexName names the index on the columns of table.
+	IndexName(table string, columns []string) string
+	// FKName names the foreign key constraint of column in table
+	// referencing refTable.
+	FKName(table, column, refTable string) string
+}
+
+// SnakeNaming is the default NamingStrategy, naming the XxYy struct or
+// field xx_yy.
+type SnakeNaming struct{}
+
+func (SnakeNaming) TableName(typ reflect.Type) string {
+	return snakeString(typ.Name())
+}
+
+func (SnakeNaming) ColumnName(field reflect.StructField) string {
+	return snakeString(field.Name)
+}
+
+// IndexName returns "idx_<table>_<columns>".
+func (SnakeNaming) IndexName(table string, columns []string) string {
+	return fmt.Sprintf("idx_%s_%s", table, strings.Join(columns, "_"))
+}
+
+// FKName returns "fk_<table>_<column>".
+func (SnakeNaming) FKName(table, column, refTable string) string {
+	return fmt.Sprintf("fk_%s_%s", table, column)
+}
+
+// DefaultNaming names the models registered with RegisterModel, and those
+// of the DbMaps without a NamingStrategy.
+var DefaultNaming NamingStrategy = SnakeNaming{}
+
+// namingStrategy returns the NamingStrategy the model was registered with.
+func (t *modelInfo) namingStrategy() NamingStrategy {
+	if t.naming != nil {
+		return t.naming
+	}
+	return DefaultNaming
+}
+
+// naming returns the NamingStrategy of the models registered with m.
+func (m *DbMap) naming() NamingStrategy {
+	if m.NamingStrategy != nil {
+		return m.NamingStrategy
+	}
+	return DefaultNaming
+}
@@ -0,0 +1,92 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// upperNaming names tables and columns after their Go names.
+type upperNaming struct{ SnakeNaming }
+
+func (upperNaming) TableName(typ reflect.Type) string           { return "T" + typ.Name() }
+func (upperNaming) ColumnName(field reflect.StructField) string { return field.Name }
+
+func TestNamingStrategy(t *testing.T) {
+	DefaultNaming = upperNaming{}
+	defer func() { DefaultNaming = SnakeNaming{} }()
+
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, new(relAuthor), new(relBook))
+	book, ok := modelCache.get("TrelBook")
+	if !ok {
+		t.Fatal("table not named by the naming strategy")
+	}
+	if fi := book.fields.GetByName("Author"); fi.column != "Author_id" {
+		t.Errorf("expected column Author_id, got %s", fi.column)
+	}
+	author, _ := modelCache.get("TrelAuthor")
+	if idx := author.AddIndex("", "", []string{"Name"}); idx.IndexName != "idx_TrelAuthor_Name" {
+		t.Errorf("unexpected index name %s", idx.IndexName)
+	}
+
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+	if err := dbmap.CreateForeignKeys(); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, stmt := range dbmap.Statements() {
+		found = found || strings.Contains(stmt.Query, "`fk_TrelBook_Editor_id` foreign key (`Editor_id`) references `TrelAuthor` (`Id`)")
+	}
+	if !found {
+		t.Errorf("foreign key not created: %v", dbmap.Statements())
+	}
+}
+
+func TestDbMapNamingStrategy(t *testing.T) {
+	ResetModelCache()
+	dbmap := &DbMap{Dialect: SqliteDialect{}, NamingStrategy: upperNaming{}}
+	dbmap.RegisterModel(new(relAuthor))
+	if table := dbmap.tables[0]; table.table != "TrelAuthor" || table.fields.GetByName("Name").column != "Name" {
+		t.Errorf("model not named by the naming strategy of the DbMap: %s", table.table)
+	}
+}
+
+type affixTag struct {
+	Id   int64 `orm:"pk;auto;column(tag_id)"`
+	Name string
+}
+
+type affixPost struct {
+	Id   int64       `orm:"pk;auto;column(post_id)"`
+	Tags []*affixTag `orm:"rel(m2m)"`
+}
+
+func TestTablePrefix(t *testing.T) {
+	ResetModelCache()
+	Database().Set(&DbMap{Dialect: SqliteDialect{}, TablePrefix: "app_", TableSuffix: "_v1"})
+	defer Database().Set(nil)
+	for _, m := range []interface{}{new(affixTag), new(affixPost), new(closureCategory)} {
+		RegisterModel(m)
+	}
+	BootStrap()
+
+	for _, table := range []string{"app_affix_tag_v1", "app_affix_post_v1", "app_affix_post_affix_tag_v1", "app_closure_category_closure_v1"} {
+		if _, ok := modelCache.get(table); !ok {
+			t.Errorf("table %s not registered", table)
+		}
+	}
+	post, _ := modelCache.get("app_affix_post_v1")
+	if fi := post.fields.GetByName("Tags"); fi.relTable != "app_affix_post_affix_tag_v1" {
+		t.Errorf("m2m field bound to table %s", fi.relTable)
+	}
+	if s := post.SqlForDrop(true); s != `drop table if exists "app_affix_post_v1";` {
+		t.Errorf("unexpected drop statement %q", s)
+	}
+
+	dbmap := &DbMap{Dialect: SqliteDialect{}, TablePrefix: "app_"}
+	dbmap.RegisterModel(new(relAuthor))
+	if table := dbmap.tables[0].table; table != "app_rel_author" {
+		t.Errorf("expected table app_rel_author, got %s", table)
+	}
+}
@@ -0,0 +1,73 @@
+package orm
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNullTypeFields(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		columns []string
+	}{
+		{PostgresDialect{}, []string{`"nickname" varchar(32)`, `"age" integer`, `"score" double precision`,
+			`"active" boolean`, `"seen" timestamp with time zone`, `"deleted" timestamp with time zone`, `"owner_id" bigint`}},
+		{MySQLDialect{"InnoDB", "UTF8"}, []string{"`nickname` varchar(32)", "`age` int", "`score` double",
+			"`active` tinyint", "`seen` datetime", "`deleted` datetime"}},
+	}
+	for _, test := range tests {
+		registerTestModels(t, test.dialect, &nullProfile{})
+		mi, _ := modelCache.get("null_profile")
+
+		for _, name := range []string{"Nickname", "Age", "Score", "Active", "Seen", "Deleted"} {
+			if !mi.fields.GetByName(name).null {
+				t.Errorf("%T: %s should be nullable", test.dialect, name)
+			}
+		}
+		ddl := mi.SqlForCreate(false)
+		for _, col := range test.columns {
+			if !strings.Contains(ddl, col) {
+				t.Errorf("%T: ddl %q missing %q", test.dialect, ddl, col)
+			}
+		}
+	}
+}
+
+func TestNullRelBinding(t *testing.T) {
+	registerTestModels(t, PostgresDialect{}, &nullProfile{})
+	mi, _ := modelCache.get("null_profile")
+	owner := mi.fields.GetByName("Owner")
+
+	elem := reflect.ValueOf(&nullProfile{}).Elem()
+	if v := bindValue(owner, elem); v != nil {
+		t.Errorf("expected NULL for a nil relation, got %v", v)
+	}
+	elem = reflect.ValueOf(&nullProfile{Owner: &nullProfile{Id: 7}}).Elem()
+	if v := bindValue(owner, elem); v != int64(7) {
+		t.Errorf("expected the related key, got %v", v)
+	}
+
+	bi, err := mi.bindInsert(reflect.ValueOf(&nullProfile{Nickname: sql.NullString{String: "x", Valid: true}}).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, arg := range bi.args {
+		if _, ok := arg.(*nullProfile); ok {
+			t.Errorf("relation bound as a struct pointer: %v", bi.args)
+		}
+	}
+
+	p := &nullProfile{}
+	target, ok := scanTarget(owner, &p.Owner)
+	if !ok {
+		t.Fatal("expected a relation scanner")
+	}
+	if err := target.(sql.Scanner).Scan([]byte("42")); err != nil || p.Owner == nil || p.Owner.Id != 42 {
+		t.Errorf("unexpected scanned relation %v (%v)", p.Owner, err)
+	}
+	if err := target.(sql.Scanner).Scan(nil); err != nil || p.Owner != nil {
+		t.Errorf("expected a nil relation, got %v (%v)", p.Owner, err)
+	}
+}
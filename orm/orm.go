@@ -2,6 +2,7 @@ package orm
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 )
@@ -27,26 +28,77 @@ const (
 	defaultStructTagDelim = ";"
 )
 
+// Params maps field names to values, as used by Criteria.Update
+type Params map[string]interface{}
+
+type operator int
+
+// operators for ColValue
+const (
+	ColAdd operator = iota
+	ColMinus
+	ColMultiply
+	ColExcept
+)
+
+var colOperators = map[operator]string{
+	ColAdd:      "+",
+	ColMinus:    "-",
+	ColMultiply: "*",
+	ColExcept:   "/",
+}
+
+type colValue struct {
+	value int64
+	opt   operator
+}
+
+// ColValue updates a column relative to its current value, eg
+//	criteria.Update(orm.Params{"Stock": orm.ColValue(orm.ColMinus, 50)})
+// sets `stock = stock - 50` atomically.
+func ColValue(opt operator, value interface{}) interface{} {
+	if _, ok := colOperators[opt]; !ok {
+		panic(fmt.Errorf("orm.ColValue wrong operator"))
+	}
+	v, err := StrTo(ToStr(value)).Int64()
+	if err != nil {
+		panic(fmt.Errorf("orm.ColValue doesn't support non string/numeric type, %s", err))
+	}
+	return colValue{value: v, opt: opt}
+}
+
 var supportTag = map[string]int{
-	"-":            1,
-	"null":         1,
-	"index":        1,
-	"unique":       1,
-	"pk":           1,
-	"auto":         1,
-	"auto_now":     1,
-	"auto_now_add": 1,
-	"size":         2,
-	"column":       2,
-	"default":      2,
-	"rel":          2,
-	"reverse":      2,
-	"rel_table":    2,
-	"rel_through":  2,
-	"digits":       2,
-	"decimals":     2,
-	"on_delete":    2,
-	"type":         2,
+	"-":             1,
+	"null":          1,
+	"index":         1,
+	"unique":        1,
+	"pk":            1,
+	"auto":          1,
+	"auto_now":      1,
+	"auto_now_add":  1,
+	"auto_user":     1,
+	"auto_user_add": 1,
+	"fulltext":      1,
+	"tree_path":     1,
+	"returning":     1,
+	"embedded":      1,
+	"virtual":       1,
+	"size":          2,
+	"column":        2,
+	"default":       2,
+	"rel":           2,
+	"reverse":       2,
+	"rel_table":     2,
+	"rel_through":   2,
+	"digits":        2,
+	"decimals":      2,
+	"on_delete":     2,
+	"type":          2,
+	"touch":         2,
+	"tree":          2,
+	"related_name":  2,
+	"prefix":        2,
+	"generated":     2,
 }
 
 var (
@@ -62,7 +114,10 @@ type _modelCache struct {
 	orders          []string
 	cache           map[string]*modelInfo
 	cacheByFullName map[string]*modelInfo
+	partials        map[string]*modelInfo // read-only views, by full name
+	errs            []error               // of the models which failed to register
 	done            bool
+	err             error // of BootStrapE
 }
 
 // get all model info
@@ -106,12 +161,41 @@ func (mc *_modelCache) set(table string, mi *modelInfo) *modelInfo {
 	return mii
 }
 
+// set partial model info, which shares the table of its model
+func (mc *_modelCache) setPartial(mi *modelInfo) {
+	if mc.partials == nil {
+		mc.partials = make(map[string]*modelInfo)
+	}
+	mc.partials[mi.fullName] = mi
+	mc.cacheByFullName[mi.fullName] = mi
+}
+
+// setAffix adds the prefix and suffix of a to the tables of the registered
+// models and their partials.
+func (mc *_modelCache) setAffix(a tableAffix) {
+	orders, cache := mc.orders, mc.cache
+	mc.orders = make([]string, 0, len(orders))
+	mc.cache = make(map[string]*modelInfo, len(cache))
+	for _, table := range orders {
+		mi := cache[table]
+		mi.affix = a
+		mi.table = a.apply(table)
+		mc.set(mi.table, mi)
+	}
+	for _, mi := range mc.partials {
+		mi.table = mi.partialOf.table
+	}
+}
+
 // clean all model info.
 func (mc *_modelCache) clean() {
 	mc.orders = make([]string, 0)
 	mc.cache = make(map[string]*modelInfo)
 	mc.cacheByFullName = make(map[string]*modelInfo)
+	mc.partials = nil
+	mc.errs = nil
 	mc.done = false
+	mc.err = nil
 }
 
 // ResetModelCache Clean model cache. Then you can re-RegisterModel.
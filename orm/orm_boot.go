@@ -1,6 +1,7 @@
 package orm
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
@@ -12,22 +13,98 @@ func RegisterModel(i interface{}) {
 }
 
 // RegisterModelWithSchema , RegisterModel with schema name.
+// An invalid model makes it exit the process, see RegisterModelE.
 func RegisterModelWithSchema(model interface{}, schema string) {
+	RegisterModelWithOptions(model, ModelOptions{Schema: schema})
+}
+
+// ModelOptions are the settings of a model registered with
+// RegisterModelWithOptions, which take precedence over its methods.
+type ModelOptions struct {
+	// Table names the table, instead of the TableName method of the
+	// model or the naming strategy.
+	Table string
+
+	// Schema is the schema of the table.
+	Schema string
+
+	// Engine, Charset and Collation are the storage engine, character
+	// set and collation of the MySQL table, instead of the TableEngine
+	// method of the model and the Engine and Encoding of the
+	// MySQLDialect.
+	Engine    string
+	Charset   string
+	Collation string
+
+	// Prefix is prepended to the table name, the TablePrefix of the
+	// DbMap coming before it.
+	Prefix string
+
+	// ReadOnly makes the writes of the model fail with a ReadOnlyError,
+	// eg for the tables another application owns.
+	ReadOnly bool
+}
+
+// RegisterModelWithOptions is RegisterModel with the settings of opts.
+// An invalid model makes it exit the process, see RegisterModelE.
+func RegisterModelWithOptions(model interface{}, opts ModelOptions) {
+	if err := RegisterModelWithOptionsE(model, opts); err != nil {
+		DebugLog.Error("%v", err)
+		os.Exit(2)
+	}
+}
+
+// RegisterModelE is RegisterModel returning the errors of an invalid model,
+// those of all its fields, instead of exiting.  BootStrapE reports them
+// again, so init functions can leave them to it.
+func RegisterModelE(model interface{}) error {
+	return RegisterModelWithOptionsE(model, ModelOptions{})
+}
+
+// RegisterModelIfAbsent is RegisterModelE skipping the models already
+// registered, eg those the app registers itself before the registration
+// generated by the harness.
+func RegisterModelIfAbsent(model interface{}) error {
+	typ := reflect.Indirect(reflect.ValueOf(model)).Type()
+	if _, ok := modelCache.getByFullName(getFullName(typ)); ok {
+		return nil
+	}
+	return RegisterModelE(model)
+}
+
+// RegisterModelWithSchemaE is RegisterModelWithSchema returning errors,
+// see RegisterModelE.
+func RegisterModelWithSchemaE(model interface{}, schema string) error {
+	return RegisterModelWithOptionsE(model, ModelOptions{Schema: schema})
+}
+
+// RegisterModelWithOptionsE is RegisterModelWithOptions returning errors,
+// see RegisterModelE.
+func RegisterModelWithOptionsE(model interface{}, opts ModelOptions) (err error) {
+	defer func() {
+		if err != nil {
+			modelCache.errs = append(modelCache.errs, err)
+		}
+	}()
 	val := reflect.ValueOf(model)
 	typ := reflect.Indirect(val).Type()
 
 	if val.Kind() != reflect.Ptr {
-		panic(fmt.Errorf("<orm.RegisterModel> cannot use non-ptr model struct `%s`", getFullName(typ)))
+		return fmt.Errorf("<orm.RegisterModel> cannot use non-ptr model struct `%s`", getFullName(typ))
 	}
 	// For this case:
 	// u := &User{}
 	// registerModel(&u)
 	if typ.Kind() == reflect.Ptr {
-		panic(fmt.Errorf("<orm.RegisterModel> only allow ptr model struct, it looks you use two reference to the struct `%s`", typ))
+		return fmt.Errorf("<orm.RegisterModel> only allow ptr model struct, it looks you use two reference to the struct `%s`", typ)
 	}
 
 	//t := reflect.TypeOf(i)
-	table := getTableName(val)
+	table := opts.Table
+	if table == "" {
+		table = getTableName(val, DefaultNaming)
+	}
+	table = opts.Prefix + table
 
 	// check if we have a table for this type already
 	// if so, update the name and return the existing pointer
@@ -35,48 +112,66 @@ func RegisterModelWithSchema(model interface{}, schema string) {
 	// models's fullname is pkgpath + struct name
 	name := getFullName(typ)
 	if _, ok := modelCache.getByFullName(name); ok {
-		fmt.Printf("<orm.RegisterModel> model `%s` repeat register, must be unique\n", name)
-		os.Exit(2)
+		return fmt.Errorf("<orm.RegisterModel> model `%s` repeat register, must be unique", name)
 	}
 
 	if _, ok := modelCache.get(table); ok {
-		fmt.Printf("<orm.RegisterModel> table name `%s` repeat register, must be unique\n", table)
-		os.Exit(2)
+		return fmt.Errorf("<orm.RegisterModel> table name `%s` repeat register, must be unique", table)
 	}
 
-	mi := newModelInfo(val)
+	mi, err := newModelInfo(val, DefaultNaming)
+	if err != nil {
+		return fmt.Errorf("<orm.RegisterModel> model `%s`: %w", name, err)
+	}
 	mi.gotype = typ
 	//keys := getTableKeys(val)
 
 	//mi := initialmodelInfo(typ, table, schema, keys)
 
 	mi.table = table
+	mi.schemaName = opts.Schema
+	mi.engine = opts.Engine
+	if mi.engine == "" {
+		mi.engine = getTableEngine(val)
+	}
+	mi.charset = opts.Charset
+	mi.collation = opts.Collation
+	mi.readOnly = opts.ReadOnly
 	mi.pkg = typ.PkgPath()
 	mi.model = model
 	mi.manual = true
 	modelCache.set(table, mi)
-
+	return nil
 }
 
 // BootStrap bootrap models.
 // make all model parsed and can not add more models
+// Invalid models make it exit the process, see BootStrapE.
 func BootStrap() {
-	if modelCache.done {
-		return
+	if err := BootStrapE(); err != nil {
+		DebugLog.Error("%v", err)
+		os.Exit(2)
 	}
+}
+
+// BootStrapE is BootStrap returning the errors of all the invalid models,
+// those met by RegisterModelE included, in one report instead of exiting.
+// Further calls return the same errors.
+func BootStrapE() error {
 	modelCache.Lock()
 	defer modelCache.Unlock()
-	bootStrap()
-	modelCache.done = true
+	if !modelCache.done {
+		modelCache.err = bootStrap()
+		modelCache.done = true
+	}
+	return modelCache.err
 }
 
-// boostrap models
-func bootStrap() {
-	if modelCache.done {
-		return
-	}
+// boostrap models.  The errors of the models are collected until the end
+// of the step which found them, as the next steps rely on its results.
+func bootStrap() error {
 	var (
-		err    error
+		errs   = append([]error(nil), modelCache.errs...)
 		models map[string]*modelInfo
 	)
 	// if dataBaseCache.getDefault() == nil {
@@ -84,9 +179,19 @@ func bootStrap() {
 	// 	goto end
 	// }
 
+	// name the tables with the prefix and suffix of the database
+	if m := Database().lookup(); m != nil && m.affix() != (tableAffix{}) {
+		modelCache.setAffix(m.affix())
+	}
+
 	// set rel and reverse model
 	// RelManyToMany set the relTable
 	models = modelCache.all()
+	for _, mi := range models {
+		errs = append(errs, bootGeneric(mi)...)
+		errs = append(errs, bootIndexes(mi)...)
+		errs = append(errs, bootPoints(mi)...)
+	}
 	for _, mi := range models {
 		for _, fi := range mi.fields.columns {
 
@@ -99,11 +204,50 @@ func bootStrap() {
 				name := getFullName(elm)
 				mii, ok := modelCache.getByFullName(name)
 				if !ok || mii.pkg != elm.PkgPath() {
-					err = fmt.Errorf("can not find rel in field `%s`, `%s` may be miss register", fi.fullName, elm.String())
-					goto end
+					errs = append(errs, fmt.Errorf("can not find rel in field `%s`, `%s` may be miss register", fi.fullName, elm.String()))
+					continue
 				}
 				fi.relModelInfo = mii
 
+				if fi.touch != "" {
+					tfi := mii.fields.GetByName(fi.touch)
+					if tfi == nil || tfi.fieldType&(TypeDateField|TypeDateTimeField) == 0 {
+						errs = append(errs, fmt.Errorf("field `%s` wrong touch value `%s`, must be a date/datetime field of `%s`", fi.fullName, fi.touch, mii.fullName))
+						continue
+					}
+				}
+
+				if fi.tree != "" {
+					if mii != mi {
+						errs = append(errs, fmt.Errorf("field `%s` tree need a rel(fk) to the model itself", fi.fullName))
+						continue
+					}
+					if len(mi.fields.keys) != 1 {
+						errs = append(errs, fmt.Errorf("tree model `%s` must have one key", mi.fullName))
+						continue
+					}
+					mi.treeParent = fi
+					switch fi.tree {
+					case TreePath:
+						for _, ffi := range mi.fields.fieldsDB {
+							if ffi.treePath {
+								mi.treePath = ffi
+							}
+						}
+						if mi.treePath == nil {
+							errs = append(errs, fmt.Errorf("tree(path) model `%s` need a field tagged tree_path", mi.fullName))
+							continue
+						}
+					case TreeClosure:
+						i := newClosureModelInfo(mi)
+						if v := modelCache.set(i.table, i); v != nil {
+							errs = append(errs, fmt.Errorf("the closure table name `%s` already registered, cannot be use, please change one", i.table))
+							continue
+						}
+						mi.treeClosure = i
+					}
+				}
+
 				switch fi.fieldType {
 				case RelManyToMany:
 					if fi.relThrough != "" {
@@ -111,14 +255,14 @@ func bootStrap() {
 							pn := fi.relThrough[:i]
 							rmi, ok := modelCache.getByFullName(fi.relThrough)
 							if !ok || pn != rmi.pkg {
-								err = fmt.Errorf("field `%s` wrong rel_through value `%s` cannot find table", fi.fullName, fi.relThrough)
-								goto end
+								errs = append(errs, fmt.Errorf("field `%s` wrong rel_through value `%s` cannot find table", fi.fullName, fi.relThrough))
+								continue
 							}
 							fi.relThroughModelInfo = rmi
 							fi.relTable = rmi.table
 						} else {
-							err = fmt.Errorf("field `%s` wrong rel_through value `%s`", fi.fullName, fi.relThrough)
-							goto end
+							errs = append(errs, fmt.Errorf("field `%s` wrong rel_through value `%s`", fi.fullName, fi.relThrough))
+							continue
 						}
 					} else {
 						i := newM2MModelInfo(mi, mii)
@@ -126,8 +270,8 @@ func bootStrap() {
 							i.table = fi.relTable
 						}
 						if v := modelCache.set(i.table, i); v != nil {
-							err = fmt.Errorf("the rel table name `%s` already registered, cannot be use, please change one", fi.relTable)
-							goto end
+							errs = append(errs, fmt.Errorf("the rel table name `%s` already registered, cannot be use, please change one", fi.relTable))
+							continue
 						}
 						fi.relTable = i.table
 						fi.relThroughModelInfo = i
@@ -139,53 +283,71 @@ func bootStrap() {
 		}
 	}
 
+	if len(errs) > 0 {
+		goto end
+	}
+
 	// check the rel filed while the relModelInfo also has filed point to current model
-	// if not exist, add a new field to the relModelInfo
-	models = modelCache.all()
-	for _, mi := range models {
+	// if not exist, add a new field to the relModelInfo, see reverseName.
+	// models are walked in registration order and fields in declaration
+	// order, so the generated names don't change between runs.
+	for _, mi := range modelCache.allOrdered() {
 		for _, fi := range mi.fields.fieldsRel {
 			switch fi.fieldType {
 			case RelForeignKey, RelOneToOne, RelManyToMany:
-				inModel := false
-				for _, ffi := range fi.relModelInfo.fields.fieldsReverse {
-					if ffi.relModelInfo == mi {
-						inModel = true
-						break
+				rmi := fi.relModelInfo
+				if fi.relatedName != "" {
+					if ffi := rmi.fields.GetByName(fi.relatedName); ffi != nil {
+						if !ffi.reverse || ffi.relModelInfo != mi {
+							errs = append(errs, fmt.Errorf("field `%s` related_name `%s` clashes with field `%s`", fi.fullName, fi.relatedName, ffi.fullName))
+							continue
+						}
+						continue
 					}
+				} else {
+					inModel := false
+					for _, ffi := range rmi.fields.fieldsReverse {
+						if ffi.relModelInfo == mi && !relatedNameTaken(mi, rmi, ffi.name) {
+							inModel = true
+							break
+						}
+					}
+					if inModel {
+						continue
+					}
+				}
+
+				ffi := new(fieldInfo)
+				ffi.reverse = true
+				ffi.relModelInfo = mi
+				ffi.mi = rmi
+				if fi.fieldType == RelOneToOne {
+					ffi.fieldType = RelReverseOne
+				} else {
+					ffi.fieldType = RelReverseMany
 				}
-				if !inModel {
-					rmi := fi.relModelInfo
-					ffi := new(fieldInfo)
-					ffi.name = mi.name
+				added := false
+				for _, name := range reverseNames(mi, fi) {
+					ffi.name = name
 					ffi.column = ffi.name
 					ffi.fullName = rmi.fullName + "." + ffi.name
-					ffi.reverse = true
-					ffi.relModelInfo = mi
-					ffi.mi = rmi
-					if fi.fieldType == RelOneToOne {
-						ffi.fieldType = RelReverseOne
-					} else {
-						ffi.fieldType = RelReverseMany
-					}
-					if !rmi.fields.Add(ffi) {
-						added := false
-						for cnt := 0; cnt < 5; cnt++ {
-							ffi.name = fmt.Sprintf("%s%d", mi.name, cnt)
-							ffi.column = ffi.name
-							ffi.fullName = rmi.fullName + "." + ffi.name
-							if added = rmi.fields.Add(ffi); added {
-								break
-							}
-						}
-						if !added {
-							panic(fmt.Errorf("cannot generate auto reverse field info `%s` to `%s`", fi.fullName, ffi.fullName))
-						}
+					if added = rmi.fields.Add(ffi); added {
+						break
 					}
 				}
+				if !added {
+					errs = append(errs, fmt.Errorf("cannot generate reverse field of `%s` in model `%s`, set one with related_name", fi.fullName, rmi.fullName))
+					continue
+				}
+				fi.relatedName = ffi.name
 			}
 		}
 	}
 
+	if len(errs) > 0 {
+		goto end
+	}
+
 	models = modelCache.all()
 	for _, mi := range models {
 		for _, fi := range mi.fields.fieldsRel {
@@ -204,14 +366,18 @@ func bootStrap() {
 					}
 				}
 				if fi.reverseFieldInfoTwo == nil {
-					err = fmt.Errorf("can not find m2m field for m2m model `%s`, ensure your m2m model defined correct",
-						fi.relThroughModelInfo.fullName)
-					goto end
+					errs = append(errs, fmt.Errorf("can not find m2m field for m2m model `%s`, ensure your m2m model defined correct",
+						fi.relThroughModelInfo.fullName))
+					continue
 				}
 			}
 		}
 	}
 
+	if len(errs) > 0 {
+		goto end
+	}
+
 	models = modelCache.all()
 	for _, mi := range models {
 		for _, fi := range mi.fields.fieldsReverse {
@@ -219,7 +385,7 @@ func bootStrap() {
 			case RelReverseOne:
 				found := false
 			mForA:
-				for _, ffi := range fi.relModelInfo.fields.fieldsByType[RelOneToOne] {
+				for _, ffi := range pairedRels(fi, mi, fi.relModelInfo.fields.fieldsByType[RelOneToOne]) {
 					if ffi.relModelInfo == mi {
 						found = true
 						fi.reverseField = ffi.name
@@ -231,13 +397,13 @@ func bootStrap() {
 					}
 				}
 				if !found {
-					err = fmt.Errorf("reverse field `%s` not found in model `%s`", fi.fullName, fi.relModelInfo.fullName)
-					goto end
+					errs = append(errs, fmt.Errorf("reverse field `%s` not found in model `%s`", fi.fullName, fi.relModelInfo.fullName))
+					continue
 				}
 			case RelReverseMany:
 				found := false
 			mForB:
-				for _, ffi := range fi.relModelInfo.fields.fieldsByType[RelForeignKey] {
+				for _, ffi := range pairedRels(fi, mi, fi.relModelInfo.fields.fieldsByType[RelForeignKey]) {
 					if ffi.relModelInfo == mi {
 						found = true
 						fi.reverseField = ffi.name
@@ -251,7 +417,7 @@ func bootStrap() {
 				}
 				if !found {
 				mForC:
-					for _, ffi := range fi.relModelInfo.fields.fieldsByType[RelManyToMany] {
+					for _, ffi := range pairedRels(fi, mi, fi.relModelInfo.fields.fieldsByType[RelManyToMany]) {
 						conditions := fi.relThrough != "" && fi.relThrough == ffi.relThrough ||
 							fi.relTable != "" && fi.relTable == ffi.relTable ||
 							fi.relThrough == "" && fi.relTable == ""
@@ -270,16 +436,62 @@ func bootStrap() {
 					}
 				}
 				if !found {
-					err = fmt.Errorf("reverse field for `%s` not found in model `%s`", fi.fullName, fi.relModelInfo.fullName)
-					goto end
+					errs = append(errs, fmt.Errorf("reverse field for `%s` not found in model `%s`", fi.fullName, fi.relModelInfo.fullName))
+					continue
 				}
 			}
 		}
 	}
 
+	if len(errs) > 0 {
+		goto end
+	}
+
+	for _, mi := range modelCache.partials {
+		mi.resolvePartial()
+	}
+
 end:
-	if err != nil {
-		fmt.Println(err)
-		os.Exit(2)
+	return errors.Join(errs...)
+}
+
+// reverseNames returns the candidate names of the reverse field generated
+// on the related model of fi, the field of mi:  the related_name of fi when
+// set, else the name of mi, then the name of mi followed by the name of fi,
+// eg `Post` then `PostEditor` for the Editor field of Post.
+func reverseNames(mi *modelInfo, fi *fieldInfo) []string {
+	if fi.relatedName != "" {
+		return []string{fi.relatedName}
+	}
+	return []string{mi.name, mi.name + fi.name}
+}
+
+// pairedRels returns the rel fields pointing to mi which the reverse field
+// fi can pair with:  the one whose related_name is fi, else the ones
+// without a related_name.
+func pairedRels(fi *fieldInfo, mi *modelInfo, rels []*fieldInfo) []*fieldInfo {
+	var free []*fieldInfo
+	for _, ffi := range rels {
+		if ffi.relModelInfo != mi {
+			continue
+		}
+		if ffi.relatedName == fi.name {
+			return []*fieldInfo{ffi}
+		}
+		if ffi.relatedName == "" {
+			free = append(free, ffi)
+		}
+	}
+	return free
+}
+
+// relatedNameTaken reports whether a rel field of mi to rmi already uses
+// the reverse field name of rmi.
+func relatedNameTaken(mi, rmi *modelInfo, name string) bool {
+	for _, fi := range mi.fields.fieldsRel {
+		if fi.relModelInfo == rmi && fi.relatedName == name {
+			return true
+		}
 	}
+	return false
 }
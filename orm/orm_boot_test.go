@@ -0,0 +1,169 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReverseFieldNames(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		registerTestModels(t, SqliteDialect{}, new(relAuthor), new(relBook))
+		author, _ := modelCache.getByFullName(getFullName(reflect.TypeOf(relAuthor{})))
+
+		for name, rel := range map[string]string{
+			"relBook":       "Author",
+			"relBookEditor": "Editor",
+			"Reviewed":      "Critic",
+		} {
+			fi := author.fields.GetByName(name)
+			if fi == nil || !fi.reverse {
+				t.Fatalf("reverse field %s not generated", name)
+			}
+			if fi.reverseFieldInfo == nil || fi.reverseFieldInfo.name != rel {
+				t.Errorf("reverse field %s paired with %v, want %s", name, fi.reverseFieldInfo, rel)
+			}
+		}
+	}
+}
+
+type badTags struct {
+	Id    int64  `orm:"pk;auto"`
+	Title string `orm:"size(big)"`
+	Body  string `orm:"touch(Updated)"`
+}
+
+type badRel struct {
+	Id     int64      `orm:"pk;auto"`
+	Author *relAuthor `orm:"rel(fk)"`
+}
+
+func TestBootStrapE(t *testing.T) {
+	ResetModelCache()
+	defer ResetModelCache()
+
+	err := RegisterModelE(new(badTags))
+	if err == nil {
+		t.Fatal("expected the invalid fields to fail")
+	}
+	for _, field := range []string{"badTags.Title", "badTags.Body"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error %q doesn't report %s", err, field)
+		}
+	}
+	if err := RegisterModelE(badTags{}); err == nil {
+		t.Error("expected a non-ptr model to fail")
+	}
+	if err := RegisterModelE(new(badRel)); err != nil {
+		t.Fatal(err)
+	}
+
+	err = BootStrapE()
+	if err == nil {
+		t.Fatal("expected BootStrapE to fail")
+	}
+	for _, want := range []string{"badTags.Title", "non-ptr", "relAuthor` may be miss register"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("report %q misses %q", err, want)
+		}
+	}
+	if BootStrapE() != err {
+		t.Error("expected the same report from further calls")
+	}
+}
+
+func TestRegisterModelIfAbsent(t *testing.T) {
+	ResetModelCache()
+	defer ResetModelCache()
+
+	RegisterModelWithOptions(&searchArticle{}, ModelOptions{Table: "articles"})
+	if err := RegisterModelIfAbsent(&searchArticle{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterModelIfAbsent(&touchPost{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := BootStrapE(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := modelCache.get("articles"); !ok {
+		t.Error("expected the registration of the app to be kept")
+	}
+	if _, ok := modelCache.get("touch_post"); !ok {
+		t.Error("expected the absent model to be registered")
+	}
+}
+
+func TestRegisterModelWithOptions(t *testing.T) {
+	ResetModelCache()
+	defer ResetModelCache()
+	dbmap := &DbMap{Dialect: PostgresDialect{}}
+	Database().Set(dbmap)
+	defer Database().Set(nil)
+
+	RegisterModelWithOptions(&searchArticle{}, ModelOptions{Table: "articles", Schema: "cms", Prefix: "legacy_", ReadOnly: true})
+	RegisterModelWithSchema(&touchPost{}, "blog")
+	BootStrap()
+
+	mi, ok := modelCache.get("legacy_articles")
+	if !ok {
+		t.Fatal("expected the table named by the options")
+	}
+	if ddl := mi.SqlForCreate(false); !strings.Contains(ddl, `create table cms."legacy_articles"`) {
+		t.Errorf("expected the table in the cms schema, got %q", ddl)
+	}
+	if post, _ := modelCache.get("touch_post"); post.schemaName != "blog" {
+		t.Errorf("expected RegisterModelWithSchema to keep its schema, got %q", post.schemaName)
+	}
+
+	dbmap.DryRun(true)
+	err := dbmap.Insert(&searchArticle{Body: "a"})
+	if _, ok := err.(*ReadOnlyError); !ok {
+		t.Errorf("expected a ReadOnlyError, got %v", err)
+	}
+	if len(dbmap.Statements()) != 0 {
+		t.Error("expected nothing written")
+	}
+}
+
+func TestMySQLTableOptions(t *testing.T) {
+	ResetModelCache()
+	defer ResetModelCache()
+	Database().Set(&DbMap{Dialect: MySQLDialect{"InnoDB", "utf8mb4"}})
+	defer Database().Set(nil)
+
+	RegisterModel(&searchArticle{})
+	RegisterModelWithOptions(&engineLog{}, ModelOptions{Collation: "utf8mb4_unicode_ci"})
+	RegisterModelWithOptions(&touchPost{}, ModelOptions{Charset: "latin1", Collation: "latin1_swedish_ci"})
+	BootStrap()
+
+	for table, suffix := range map[string]string{
+		"search_article": " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;",
+		"engine_log":     " ENGINE=MyISAM DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;",
+		"touch_post":     " ENGINE=InnoDB DEFAULT CHARSET=latin1 COLLATE=latin1_swedish_ci;",
+	} {
+		mi, _ := modelCache.get(table)
+		if ddl := mi.SqlForCreate(false); !strings.HasSuffix(ddl, suffix) {
+			t.Errorf("expected %s created with %q, got %q", table, suffix, ddl)
+		}
+	}
+	if s := (MySQLDialect{}).CreateTableOptions("", "utf8", ""); s != " DEFAULT CHARSET=utf8" {
+		t.Errorf("expected the empty options left out, got %q", s)
+	}
+
+	DefaultCollation = "utf8mb4_bin"
+	defer func() { DefaultCollation = "" }()
+	for table, suffix := range map[string]string{
+		"search_article": " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;",
+		"engine_log":     " ENGINE=MyISAM DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;",
+		"touch_post":     " ENGINE=InnoDB DEFAULT CHARSET=latin1 COLLATE=latin1_swedish_ci;",
+	} {
+		mi, _ := modelCache.get(table)
+		if ddl := mi.SqlForCreate(false); !strings.HasSuffix(ddl, suffix) {
+			t.Errorf("expected %s created with %q under the default collation, got %q", table, suffix, ddl)
+		}
+	}
+	if s := (MySQLDialect{}).CreateTableOptions("", "latin1", ""); s != " DEFAULT CHARSET=latin1" {
+		t.Errorf("expected the default collation left out for another charset, got %q", s)
+	}
+}
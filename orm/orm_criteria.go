@@ -1,19 +1,59 @@
 package orm
 
 import (
+	"database/sql"
+	"fmt"
+	"math/rand"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 )
 
 type Criteria interface {
 	Add(criterion Criterion) Criteria
 	GetCriterions() []Criterion
 	List() ([]interface{}, error)
+	Fill(dest interface{}) error
+	Update(values Params) (int64, error)
+	UpdateBatch(values Params, chunkSize int) (int64, error)
+	Delete() (int64, error)
+	Explain(analyze bool) ([]string, error)
+	SQL() (string, []interface{}, error)
 	UniqueResult() interface{}
+	Count() (int64, error)
+	Exists() (bool, error)
 	GetAlias() string
 	SetProjection(projection Projection) Criteria
 	GetProjection() Projection
 	GetEntityType() reflect.Type
 	GetEntity() interface{}
+	Random(n int) Criteria
+	GetRandom() int
+	Sample(percent float64) Criteria
+	GetSample() float64
+	For(principal interface{}) Criteria
+	GetPrincipal() (interface{}, bool)
+	Unscoped() Criteria
+	IsUnscoped() bool
+	SetResultDistinct() Criteria
+	IsResultDistinct() bool
+	SetResultTransformer(transformer ResultTransformer) Criteria
+	GetResultTransformer() ResultTransformer
+	OrderBy(fieldNames ...string) Criteria
+	AddOrder(orders ...Ordering) Criteria
+	GetOrders() []string
+	Limit(n int) Criteria
+	GetLimit() int
+	SetFirstResult(n int) Criteria
+	GetFirstResult() int
+	SetMaxResults(n int) Criteria
+	GetMaxResults() int
+	Timeout(d time.Duration) Criteria
+	GetTimeout() time.Duration
+	CreateCriteria(fieldName string) Criteria
+	CreateAlias(fieldName, alias string, joinType JoinType) Criteria
+	SetFetchMode(fieldName string, mode FetchMode) Criteria
 }
 
 var _ Criteria = new(criteriaImpl)
@@ -24,16 +64,45 @@ type criteriaImpl struct {
 	rootEntity     interface{}
 	criterions     []Criterion
 	projection     Projection
+	random         int
+	sample         float64
+	principal      interface{}
+	secured        bool
+	unscoped       bool
+	distinct       bool
+	transformer    ResultTransformer
+	orders         []string
+	limit          int
+	offset         int
+	timeout        time.Duration
 	dbmap          *DbMap
 	tmap           *modelInfo
+	joins          []*criteriaJoin // associations joined by CreateCriteria
+	join           *criteriaJoin   // the joined model of the criteria, nil at the root
+	fetches        []criteriaFetch // associations loaded with the results
+	err            error           // of CreateCriteria or CreateAlias, returned when run
+	tableAlias     string          // replaces {alias} in the statements not aliasing the table, see scopedQuery
 }
 
 type CriteriaTranslator struct {
 	criteria Criteria
 	dbmap    *DbMap
+	exec     SqlExecutor             // runs the statements, the DbMap by default
+	tempIn   map[Criterion]Criterion // in lists joined from temporary tables
+}
+
+// executor returns where the statements of the criteria run.
+func (ct CriteriaTranslator) executor() SqlExecutor {
+	if ct.exec != nil {
+		return ct.exec
+	}
+	return ct.dbmap
 }
 
 func (ci criteriaImpl) Add(criterion Criterion) Criteria {
+	if ci.join != nil {
+		criterion = joinCriterion{join: ci.join, criterion: criterion}
+	}
 	ci.criterions = append(ci.criterions, criterion)
 	return ci
 }
@@ -44,19 +113,133 @@ func (ci criteriaImpl) GetCriterions() []Criterion {
 
 func (ci criteriaImpl) List() ([]interface{}, error) {
 	ct := &CriteriaTranslator{
-		criteria: ci,
-		dbmap:    ci.dbmap,
+		criteria: ci.root(),
+		dbmap:    ci.dbmap.withQueryTimeout(ci.timeout),
 	}
 	return ct.List()
 }
 
-func (ci criteriaImpl) UniqueResult() interface{} {
+// Fill lists the results of the criteria into dest, a pointer to a slice
+// of the models, or of model pointers, eg &[]*User{}, or of the values of
+// the projection, eg &[]int64{} for Projections.Property("Id").  The
+// results are appended to the slice, even with a NonFatalError.
+func (ci criteriaImpl) Fill(dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("<Criteria.Fill> dest must be a pointer to a slice, got %T", dest)
+	}
+	list, err := ci.List()
+	if err != nil && !NonFatalError(err) {
+		return err
+	}
+	if ferr := fillSlice(v.Elem(), list); ferr != nil {
+		return ferr
+	}
+	return err
+}
 
+// fillSlice appends the results of a criteria to slice, dereferencing
+// the models, parsing the text of the numeric columns or converting the
+// values its elements need.
+func fillSlice(slice reflect.Value, list []interface{}) error {
+	elem := slice.Type().Elem()
+	for _, result := range list {
+		r := reflect.ValueOf(result)
+		parsed, parsable, err := parseText(result, elem)
+		switch {
+		case !r.IsValid():
+			r = reflect.Zero(elem)
+		case r.Type().AssignableTo(elem):
+		case r.Kind() == reflect.Ptr && r.Type().Elem().AssignableTo(elem):
+			r = r.Elem()
+		case parsable && err != nil:
+			return fmt.Errorf("<Criteria.Fill> cannot fill a %s with %q: %v", slice.Type(), result, err)
+		case parsable:
+			r = parsed
+		case r.Type().ConvertibleTo(elem) && r.Kind() != reflect.String && elem.Kind() != reflect.String:
+			r = r.Convert(elem)
+		default:
+			return fmt.Errorf("<Criteria.Fill> cannot fill a %s with %T", slice.Type(), result)
+		}
+		slice.Set(reflect.Append(slice, r))
+	}
 	return nil
 }
 
-func (ci criteriaImpl) GetAlias() string {
+func (ci criteriaImpl) Update(values Params) (int64, error) {
+	ct := &CriteriaTranslator{
+		criteria: ci.root(),
+		dbmap:    ci.dbmap.withQueryTimeout(ci.timeout),
+	}
+	return ct.Update(values)
+}
+
+func (ci criteriaImpl) UpdateBatch(values Params, chunkSize int) (int64, error) {
+	ct := &CriteriaTranslator{
+		criteria: ci.root(),
+		dbmap:    ci.dbmap.withQueryTimeout(ci.timeout),
+	}
+	return ct.UpdateBatch(values, chunkSize)
+}
+
+func (ci criteriaImpl) Delete() (int64, error) {
+	ct := &CriteriaTranslator{
+		criteria: ci.root(),
+		dbmap:    ci.dbmap.withQueryTimeout(ci.timeout),
+	}
+	return ct.Delete()
+}
+
+func (ci criteriaImpl) SQL() (string, []interface{}, error) {
+	ct := &CriteriaTranslator{
+		criteria: ci.root(),
+		dbmap:    ci.dbmap.withQueryTimeout(ci.timeout),
+	}
+	return ct.SQL()
+}
+
+func (ci criteriaImpl) Explain(analyze bool) ([]string, error) {
+	ct := &CriteriaTranslator{
+		criteria: ci.root(),
+		dbmap:    ci.dbmap.withQueryTimeout(ci.timeout),
+	}
+	return ct.Explain(analyze)
+}
 
+// UniqueResult returns the first result of the criteria, eg the count of
+// a RowCount projection, or nil when no row matched or the query failed.
+func (ci criteriaImpl) UniqueResult() interface{} {
+	list, err := ci.List()
+	if err != nil || len(list) == 0 {
+		return nil
+	}
+	return list[0]
+}
+
+// Count returns the number of results of the criteria, counted by the
+// database.
+func (ci criteriaImpl) Count() (int64, error) {
+	ct := &CriteriaTranslator{
+		criteria: ci.root(),
+		dbmap:    ci.dbmap.withQueryTimeout(ci.timeout),
+	}
+	return ct.Count()
+}
+
+// Exists reports whether the criteria has a result, selecting one row at
+// most.
+func (ci criteriaImpl) Exists() (bool, error) {
+	ct := &CriteriaTranslator{
+		criteria: ci.root(),
+		dbmap:    ci.dbmap.withQueryTimeout(ci.timeout),
+	}
+	return ct.Exists()
+}
+
+func (ci criteriaImpl) GetAlias() string {
+	if ci.join != nil {
+		return ci.join.alias
+	}
 	return ci.rootAlias
 }
 
@@ -69,7 +252,12 @@ func (ci criteriaImpl) GetProjection() Projection {
 	return ci.projection
 }
 
+// GetEntityType returns the type of the model of the criteria, the
+// associated model of the criteria created by CreateCriteria.
 func (ci criteriaImpl) GetEntityType() reflect.Type {
+	if ci.join != nil {
+		return ci.join.tmap.gotype
+	}
 	return ci.rootEntityType
 }
 
@@ -77,6 +265,122 @@ func (ci criteriaImpl) GetEntity() interface{} {
 	return ci.rootEntity
 }
 
+// Random limits the results to n rows picked at random.
+func (ci criteriaImpl) Random(n int) Criteria {
+	ci.random = n
+	return ci
+}
+
+func (ci criteriaImpl) GetRandom() int {
+	return ci.random
+}
+
+// Sample reads roughly percent (0 to 100) of the rows, picked at random.
+func (ci criteriaImpl) Sample(percent float64) Criteria {
+	ci.sample = percent
+	return ci
+}
+
+func (ci criteriaImpl) GetSample() float64 {
+	return ci.sample
+}
+
+// For scopes the criteria to the rows principal may access, according
+// to the policies registered for the model.
+func (ci criteriaImpl) For(principal interface{}) Criteria {
+	ci.principal = principal
+	ci.secured = true
+	return ci
+}
+
+func (ci criteriaImpl) GetPrincipal() (interface{}, bool) {
+	return ci.principal, ci.secured
+}
+
+// Unscoped ignores the scopes registered for the model.
+func (ci criteriaImpl) Unscoped() Criteria {
+	ci.unscoped = true
+	return ci
+}
+
+func (ci criteriaImpl) IsUnscoped() bool {
+	return ci.unscoped
+}
+
+// SetResultDistinct removes the duplicate results, eg the models repeated
+// by the joins of to-many associations, selecting distinct rows.
+func (ci criteriaImpl) SetResultDistinct() Criteria {
+	ci.distinct = true
+	return ci
+}
+
+func (ci criteriaImpl) IsResultDistinct() bool {
+	return ci.distinct
+}
+
+// OrderBy sorts the results by the given fields, descending when the
+// name is prefixed with "-", eg OrderBy("-Created", "Id").
+func (ci criteriaImpl) OrderBy(fieldNames ...string) Criteria {
+	ci.orders = append(append([]string{}, ci.orders...), fieldNames...)
+	return ci
+}
+
+// AddOrder sorts the results by the given orderings, after the previous
+// ones, eg AddOrder(Order.Desc("Created"), Order.Asc("Id")).
+func (ci criteriaImpl) AddOrder(orders ...Ordering) Criteria {
+	names := make([]string, len(orders))
+	for i, o := range orders {
+		names[i] = o.String()
+	}
+	return ci.OrderBy(names...)
+}
+
+func (ci criteriaImpl) GetOrders() []string {
+	return ci.orders
+}
+
+// Limit returns, updates or deletes at most n rows.
+func (ci criteriaImpl) Limit(n int) Criteria {
+	ci.limit = n
+	return ci
+}
+
+func (ci criteriaImpl) GetLimit() int {
+	return ci.limit
+}
+
+// SetFirstResult skips the first n rows the criteria lists or deletes,
+// eg SetFirstResult(40).SetMaxResults(20) lists the third page of 20 rows.
+// The rows are paged by the Paginator of the dialect, if any.
+func (ci criteriaImpl) SetFirstResult(n int) Criteria {
+	ci.offset = n
+	return ci
+}
+
+func (ci criteriaImpl) GetFirstResult() int {
+	return ci.offset
+}
+
+// SetMaxResults is Limit.
+func (ci criteriaImpl) SetMaxResults(n int) Criteria {
+	return ci.Limit(n)
+}
+
+func (ci criteriaImpl) GetMaxResults() int {
+	return ci.limit
+}
+
+// Timeout cancels the statements of the criteria still running after d,
+// instead of after the QueryTimeout of the DbMap.
+func (ci criteriaImpl) Timeout(d time.Duration) Criteria {
+	ci.timeout = d
+	return ci
+}
+
+func (ci criteriaImpl) GetTimeout() time.Duration {
+	return ci.timeout
+}
+
 func newCriteria(dbmap *DbMap, tmap *modelInfo, m interface{}, typ reflect.Type) Criteria {
 	c := new(criteriaImpl)
 	c.dbmap = dbmap
@@ -89,7 +393,535 @@ func newCriteria(dbmap *DbMap, tmap *modelInfo, m interface{}, typ reflect.Type)
 }
 
 //List get results from criteria
-func (ct CriteriaTranslator) List() ([]interface{}, error) {
+func (ct CriteriaTranslator) List() (list []interface{}, err error) {
+	err = ct.withTempIn(func(ct CriteriaTranslator) error {
+		list, err = ct.list()
+		if err != nil && !NonFatalError(err) {
+			return err
+		}
+		if ferr := ct.fetch(list); ferr != nil {
+			return ferr
+		}
+		return err
+	})
+	return list, err
+}
+
+func (ct CriteriaTranslator) list() ([]interface{}, error) {
+	n := ct.criteria.GetRandom()
+	if n > 0 {
+		cond, keys, err := ct.randomKeyRange(n)
+		if err != nil {
+			return nil, err
+		}
+		if cond != "" {
+			selectSQL, args, err := ct.toSelect(cond, keys)
+			if err != nil {
+				return nil, err
+			}
+			list, err := ct.selectList(selectSQL.ToStatementString(), args)
+			if err != nil || len(list) >= n {
+				return list, err
+			}
+			// too few of the picked keys exist, sort the whole table instead
+		}
+	}
+
+	selectSQL, args, err := ct.toSelect("", nil)
+	if err != nil {
+		return nil, err
+	}
+	return ct.selectList(selectSQL.ToStatementString(), args)
+}
+
+// selectList runs the select statement of the criteria, scanning the
+// rows into models, or into the values of the projection when set.
+func (ct CriteriaTranslator) selectList(query string, args []interface{}) ([]interface{}, error) {
+	if ct.criteria.GetProjection() != nil {
+		return ct.listProjected(query, args)
+	}
+	return ct.executor().Select(ct.criteria.GetEntity(), query, args...)
+}
+
+//Count counts the rows of the select statement of the criteria, paged
+//and grouped as listed.
+func (ct CriteriaTranslator) Count() (count int64, err error) {
+	err = ct.withTempIn(func(ct CriteriaTranslator) error {
+		query, args, err := ct.toCount()
+		if err != nil {
+			return err
+		}
+		count, err = ct.executor().SelectInt(query, args...)
+		return err
+	})
+	return count, err
+}
+
+func (ct CriteriaTranslator) toCount() (string, []interface{}, error) {
+	selectSQL, args, err := ct.toSelect("", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	// the order only matters to the rows of a page
+	if selectSQL.limit == 0 && selectSQL.offset == 0 {
+		selectSQL.orderByClause = ""
+	}
+	return "select count(*) from (" + selectSQL.ToStatementString() + ") t_", args, nil
+}
+
+//Exists reports whether the select statement of the criteria returns a
+//row, selecting its first row only.
+func (ct CriteriaTranslator) Exists() (exists bool, err error) {
+	err = ct.withTempIn(func(ct CriteriaTranslator) error {
+		query, args, err := ct.toExists()
+		if err != nil {
+			return err
+		}
+		rows, done, err := queryRows(ct.executor(), query, args...)
+		if err != nil {
+			return err
+		}
+		defer done()
+		defer rows.Close()
+		exists = rows.Next()
+		return rows.Err()
+	})
+	return exists, err
+}
+
+func (ct CriteriaTranslator) toExists() (string, []interface{}, error) {
+	selectSQL, args, err := ct.toSelect("", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	selectSQL.selectClause = "1"
+	if selectSQL.offset == 0 {
+		selectSQL.orderByClause = ""
+	}
+	selectSQL.limit = 1
+	return selectSQL.ToStatementString(), args, nil
+}
+
+//SQL returns the select statement of the criteria and its bind values,
+//without running it.  Use DbMap.DryRun to see the statements of writes.
+func (ct CriteriaTranslator) SQL() (string, []interface{}, error) {
+	selectSQL, args, err := ct.toSelect("", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return selectSQL.ToStatementString(), args, nil
+}
+
+//Explain returns the plan of the select statement of the criteria, one
+//string per row of the plan, with the columns of the row as name=value
+//pairs when the database returns more than one.  With analyze set the
+//statement is run to report actual times, which is only supported by
+//some databases.
+func (ct CriteriaTranslator) Explain(analyze bool) ([]string, error) {
+	query, args, err := ct.toExplain(analyze)
+	if err != nil {
+		return nil, err
+	}
+	rows, done, err := queryRows(ct.executor(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	plan := make([]string, 0)
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if len(columns) == 1 {
+			plan = append(plan, values[0].String)
+			continue
+		}
+		pairs := make([]string, len(columns))
+		for i, column := range columns {
+			pairs[i] = column + "=" + values[i].String
+		}
+		plan = append(plan, strings.Join(pairs, " "))
+	}
+	return plan, rows.Err()
+}
+
+func (ct CriteriaTranslator) toExplain(analyze bool) (string, []interface{}, error) {
+	explainer, ok := ct.dbmap.Dialect.(Explainer)
+	if !ok {
+		return "", nil, fmt.Errorf("<Criteria.Explain> dialect %T does not support explain", ct.dbmap.Dialect)
+	}
+	prefix := explainer.ExplainPrefix(analyze)
+	if prefix == "" {
+		return "", nil, fmt.Errorf("<Criteria.Explain> dialect %T does not support explain analyze", ct.dbmap.Dialect)
+	}
+	selectSQL, args, err := ct.toSelect("", nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return prefix + selectSQL.ToStatementString(), args, nil
+}
+
+// criterions returns the criterions of the criteria followed by those of
+// the scopes of the model and of the policies it is scoped to.  It fails
+// with the *CriteriaError of the CreateCriteria or CreateAlias joining
+// the models of the criteria.
+func (ct CriteriaTranslator) criterions() ([]Criterion, error) {
+	if ci, ok := ct.criteria.(criteriaImpl); ok && ci.err != nil {
+		return nil, ci.err
+	}
+	policies, err := policyCriterions(ct.criteria, ct.dbmap)
+	if err != nil {
+		return nil, err
+	}
+	var scopes []Criterion
+	if !ct.criteria.IsUnscoped() {
+		tmap, err := ct.dbmap.TableFor(ct.criteria.GetEntityType())
+		if err != nil {
+			return nil, err
+		}
+		scopes = scopeCriterions(tmap, ct.dbmap)
+	}
+	criterions := make([]Criterion, 0, len(ct.criteria.GetCriterions())+len(scopes)+len(policies))
+	for _, cr := range ct.criteria.GetCriterions() {
+		if t, ok := ct.tempIn[cr]; ok {
+			cr = t
+		}
+		criterions = append(criterions, cr)
+	}
+	criterions = append(criterions, scopes...)
+	return append(criterions, policies...), nil
+}
+
+// conditions renders criterions, appending their values to args.  It
+// fails with the *FieldError of a criterion naming an unknown field, or
+// the *CriteriaError of a criterion built from wrong input.
+func (ct CriteriaTranslator) conditions(criterions []Criterion, args []interface{}) (conds []string, _ []interface{}, err error) {
+	defer recoverCriteriaError(&err)
+	conds = make([]string, 0, len(criterions))
+	for _, cr := range criterions {
+		conds = append(conds, cr.ToSqlString(ct.criteria, ct.dbmap))
+		args = append(args, cr.GetValues(ct.criteria, ct.dbmap)...)
+	}
+	return conds, args, nil
+}
+
+// values returns the bind values of the criterions, in the order of
+// their conditions.
+func (ct CriteriaTranslator) values(criterions []Criterion) (args []interface{}, err error) {
+	defer recoverCriteriaError(&err)
+	args = make([]interface{}, 0, len(criterions))
+	for _, cr := range criterions {
+		args = append(args, cr.GetValues(ct.criteria, ct.dbmap)...)
+	}
+	return args, nil
+}
+
+// recoverCriteriaError recovers the *FieldError, see findColumns, and the
+// *CriteriaError the criterions and projections panic with into err.
+func recoverCriteriaError(err *error) {
+	if r := recover(); r != nil {
+		switch e := r.(type) {
+		case *FieldError:
+			*err = e
+		case *CriteriaError:
+			*err = e
+		default:
+			panic(r)
+		}
+	}
+}
+
+//Update sets the given fields on every row matched by the criteria
+func (ct CriteriaTranslator) Update(values Params) (count int64, err error) {
+	err = ct.withTempIn(func(ct CriteriaTranslator) error {
+		query, args, err := ct.toUpdate(values, "", nil)
+		if err != nil {
+			return err
+		}
+		count, err = execCount(ct.executor(), query, args)
+		return err
+	})
+	return count, err
+}
+
+//UpdateBatch works like Update, but updates the rows in chunks of
+//chunkSize consecutive primary keys, each in its own transaction, so a
+//huge update never holds its locks for long.  Chunks already committed
+//stay updated if a later one fails.  It needs a single integer key.
+func (ct CriteriaTranslator) UpdateBatch(values Params, chunkSize int) (int64, error) {
+	if chunkSize <= 0 {
+		return 0, fmt.Errorf("<Criteria.UpdateBatch> chunk size must be positive")
+	}
+	if err := noJoins(ct.criteria, "UpdateBatch"); err != nil {
+		return 0, err
+	}
+	tmap, err := ct.dbmap.TableFor(ct.criteria.GetEntityType())
+	if err != nil {
+		return 0, err
+	}
+	key := integerKey(tmap)
+	if key == nil {
+		return 0, fmt.Errorf("<Criteria.UpdateBatch> `%s` needs a single integer primary key", tmap.fullName)
+	}
+	lo, hi, err := ct.keyRange(tmap, key)
+	if err != nil || !lo.Valid {
+		return 0, err
+	}
+
+	count := int64(0)
+	for start := lo.Int64; start <= hi.Int64; start += int64(chunkSize) {
+		cond := key.column + " >= ? and " + key.column + " < ?"
+		query, args, err := ct.toUpdate(values, cond, []interface{}{start, start + int64(chunkSize)})
+		if err != nil {
+			return count, err
+		}
+		rows, err := ct.updateChunk(query, args)
+		if err != nil {
+			return count, err
+		}
+		count += rows
+	}
+	return count, nil
+}
+
+func (ct CriteriaTranslator) updateChunk(query string, args []interface{}) (int64, error) {
+	tx, err := ct.dbmap.Begin()
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return rows, tx.Commit()
+}
+
+//Delete removes every row matched by the criteria, or with OrderBy and
+//Limit only the first ones, eg to purge old rows incrementally
+func (ct CriteriaTranslator) Delete() (count int64, err error) {
+	err = ct.withTempIn(func(ct CriteriaTranslator) error {
+		query, args, err := ct.toDelete()
+		if err != nil {
+			return err
+		}
+		count, err = execCount(ct.executor(), query, args)
+		return err
+	})
+	return count, err
+}
+
+// execCount runs query and returns the number of rows it affected.
+func execCount(exec SqlExecutor, query string, args []interface{}) (int64, error) {
+	res, err := exec.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// toDelete builds the delete statement of the criteria.  Ordered or
+// limited deletes use the dialect's LimitedDeleter, or else, as do the
+// deletes skipping rows, delete the keys picked by a paged subquery.
+func (ct CriteriaTranslator) toDelete() (string, []interface{}, error) {
+	if err := noJoins(ct.criteria, "Delete"); err != nil {
+		return "", nil, err
+	}
+	tmap, err := ct.dbmap.TableFor(ct.criteria.GetEntityType())
+	if err != nil {
+		return "", nil, err
+	}
+	if err = tmap.writable(); err != nil {
+		return "", nil, err
+	}
+	criterions, err := ct.criterions()
+	if err != nil {
+		return "", nil, err
+	}
+	orderBy, err := ct.orderBy()
+	if err != nil {
+		return "", nil, err
+	}
+
+	conds, args, err := ct.conditions(criterions, make([]interface{}, 0))
+	if err != nil {
+		return "", nil, err
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " where " + strings.Join(conds, " and ")
+	}
+
+	table := ct.dbmap.Dialect.QuotedTableForQuery(tmap.schemaName, tmap.table)
+	limit, offset := ct.criteria.GetLimit(), ct.criteria.GetFirstResult()
+	if orderBy == "" && limit <= 0 && offset <= 0 {
+		return "delete from " + table + where, args, nil
+	}
+	ld, limited := ct.dbmap.Dialect.(LimitedDeleter)
+	if limited && offset <= 0 {
+		return "delete from " + table + where + ld.DeleteLimitClause(orderBy, limit), args, nil
+	}
+
+	keys := make([]string, 0, len(tmap.fields.keys))
+	for _, fi := range tmap.fields.fieldsDB {
+		if fi.pk {
+			keys = append(keys, fi.column)
+		}
+	}
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("<Criteria.Delete> ordered or limited delete of `%s` needs a primary key", tmap.fullName)
+	}
+	columns := strings.Join(keys, ", ")
+	if len(keys) > 1 {
+		columns = "(" + columns + ")"
+	}
+	sub := &Select{
+		selectClause:  strings.Join(keys, ", "),
+		fromClause:    table,
+		whereClause:   strings.Join(conds, " and "),
+		orderByClause: orderBy,
+		limit:         limit,
+		offset:        offset,
+		dialect:       ct.dbmap.Dialect,
+	}
+	query := sub.ToStatementString()
+	if limited {
+		// the dialects deleting with limits refuse them in subqueries,
+		// but not in derived tables
+		query = "select " + sub.selectClause + " from (" + query + ") t_"
+	}
+	return "delete from " + table + " where " + columns + " in (" + query + ")", args, nil
+}
+
+// orderBy returns the order by clause of the criteria orders.
+func (ct CriteriaTranslator) orderBy() (string, error) {
+	orders := make([]string, 0, len(ct.criteria.GetOrders()))
+	for _, name := range ct.criteria.GetOrders() {
+		desc := strings.HasPrefix(name, "-")
+		cols, err := ct.dbmap.findColumnsE(ct.criteria, strings.TrimPrefix(name, "-"))
+		if err != nil {
+			return "", err
+		}
+		if desc {
+			orders = append(orders, cols[0]+" desc")
+		} else {
+			orders = append(orders, cols[0])
+		}
+	}
+	return strings.Join(orders, ", "), nil
+}
+
+// toUpdate builds the update statement of the criteria, with cond and
+// its args appended to the where clause when not empty.
+func (ct CriteriaTranslator) toUpdate(values Params, cond string, condArgs []interface{}) (string, []interface{}, error) {
+	if len(values) == 0 {
+		return "", nil, fmt.Errorf("<Criteria.Update> no values to update")
+	}
+	if err := noJoins(ct.criteria, "Update"); err != nil {
+		return "", nil, err
+	}
+	tmap, err := ct.dbmap.TableFor(ct.criteria.GetEntityType())
+	if err != nil {
+		return "", nil, err
+	}
+	if err = tmap.writable(); err != nil {
+		return "", nil, err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sets := make([]string, 0, len(names))
+	args := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		cols, err := ct.dbmap.findColumnsE(ct.criteria, name)
+		if err != nil {
+			return "", nil, err
+		}
+		col := cols[0]
+		switch v := values[name].(type) {
+		case colValue:
+			sets = append(sets, fmt.Sprintf("%s = %s %s ?", col, col, colOperators[v.opt]))
+			args = append(args, v.value)
+		default:
+			sets = append(sets, col+" = ?")
+			args = append(args, v)
+		}
+	}
+
+	criterions, err := ct.criterions()
+	if err != nil {
+		return "", nil, err
+	}
+	conds, args, err := ct.conditions(criterions, args)
+	if err != nil {
+		return "", nil, err
+	}
+	if cond != "" {
+		conds = append(conds, cond)
+		args = append(args, condArgs...)
+	}
+
+	query := "update " + ct.dbmap.Dialect.QuotedTableForQuery(tmap.schemaName, tmap.table) + " set " + strings.Join(sets, ", ")
+	if len(conds) > 0 {
+		query += " where " + strings.Join(conds, " and ")
+	}
+	return query, args, nil
+}
+
+// toSelect builds the statement of the criteria, with cond and its
+// args appended to the where clause when not empty.
+func (ct CriteriaTranslator) toSelect(cond string, condArgs []interface{}) (*Select, []interface{}, error) {
+	criterions, err := ct.criterions()
+	if err != nil {
+		return nil, nil, err
+	}
+	cache := ct.dbmap.CriteriaCache
+	if cache == nil || cond != "" {
+		return ct.buildSelect(criterions, cond, condArgs)
+	}
+	key, ok := ct.cacheKey(criterions)
+	if !ok {
+		return ct.buildSelect(criterions, cond, condArgs)
+	}
+	// the statement is cached, not the bind values of the criterions
+	values, err := ct.values(criterions)
+	if err != nil {
+		return nil, nil, err
+	}
+	if selectSQL, extra, ok := cache.get(key); ok {
+		return selectSQL, append(values, extra...), nil
+	}
+	selectSQL, args, err := ct.buildSelect(criterions, cond, condArgs)
+	if err == nil {
+		cache.put(key, selectSQL, args[len(values):])
+	}
+	return selectSQL, args, err
+}
+
+// buildSelect builds the select statement of the criteria with its
+// criterions resolved, see toSelect.
+func (ct CriteriaTranslator) buildSelect(criterions []Criterion, cond string, condArgs []interface{}) (*Select, []interface{}, error) {
 
 	args := make([]interface{}, 0)
 
@@ -101,21 +933,70 @@ func (ct CriteriaTranslator) List() ([]interface{}, error) {
 		outerJoinsAfterWhere string
 		orderByClause        string
 		groupByClause        string
+		limit                int
 	)
 
 	if ct.criteria.GetProjection() == nil {
-		selectClause = "*"
+		selectClause = qualify(ct.criteria, "*")
+		// partials only scan their own columns
+		if tmap, err := ct.dbmap.TableFor(ct.criteria.GetEntityType()); err == nil && tmap.partialOf != nil {
+			columns := make([]string, len(tmap.fields.dbcols))
+			for i, column := range tmap.fields.dbcols {
+				columns[i] = qualify(ct.criteria, ct.dbmap.Dialect.QuoteField(column))
+			}
+			selectClause = strings.Join(columns, ",")
+		}
 	} else {
-		selectClause = ct.criteria.GetProjection().ToSqlString(ct.criteria, 0, ct.dbmap)
+		var err error
+		if selectClause, groupByClause, err = ct.projection(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if ct.criteria.IsResultDistinct() && !strings.HasPrefix(selectClause, "distinct ") {
+		selectClause = "distinct " + selectClause
 	}
 
-	fromClause = ct.dbmap.getObjectSQLAlias(ct.criteria)
+	fromClause = ct.dbmap.getObjectSQLAlias(ct.criteria) + joinClauses(ct.criteria)
 
-	for _, cr := range ct.criteria.GetCriterions() {
-		whereClause += cr.ToSqlString(ct.criteria, ct.dbmap)
+	conds, args, err := ct.conditions(criterions, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	random, sample := ct.criteria.GetRandom(), ct.criteria.GetSample()
+	if random > 0 || sample > 0 {
+		sampler, ok := ct.dbmap.Dialect.(RandomSampler)
+		if !ok {
+			return nil, nil, fmt.Errorf("<Criteria.Random> dialect %T does not support random selection", ct.dbmap.Dialect)
+		}
+		if sample > 0 {
+			if ts := sampler.TableSample(sample); ts != "" {
+				fromClause += ts
+			} else {
+				conds = append(conds, sampler.RandomValue()+" < ?")
+				args = append(args, sample/100)
+			}
+		}
+		if random > 0 {
+			orderByClause = sampler.RandomValue()
+			limit = random
+		}
+	}
+	if orderByClause == "" {
+		if orderByClause, err = ct.orderBy(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if limit == 0 {
+		limit = ct.criteria.GetLimit()
+	}
 
-		args = append(args, cr.GetValues(ct.criteria, ct.dbmap))
+	if cond != "" {
+		conds = append(conds, cond)
+		args = append(args, condArgs...)
 	}
+	whereClause = strings.Join(conds, " and ")
 
 	//ct.dbmap.getSQLAlias(ct.criteria, nil)
 
@@ -127,7 +1008,86 @@ func (ct CriteriaTranslator) List() ([]interface{}, error) {
 		outerJoinsAfterWhere: outerJoinsAfterWhere,
 		orderByClause:        orderByClause,
 		groupByClause:        groupByClause,
+		limit:                limit,
+		offset:               ct.criteria.GetFirstResult(),
+		dialect:              ct.dbmap.Dialect,
+	}
+
+	return selectSQL, args, nil
+}
+
+// randomKeyRange avoids sorting a whole table to pick n random rows:
+// when the table has a single integer key spread over a wide range, it
+// picks candidate keys at random between the smallest and the largest
+// one and returns the condition restricting the query to them.
+func (ct CriteriaTranslator) randomKeyRange(n int) (string, []interface{}, error) {
+	tmap, err := ct.dbmap.TableFor(ct.criteria.GetEntityType())
+	if err != nil {
+		return "", nil, err
+	}
+	key := integerKey(tmap)
+	if key == nil {
+		return "", nil, nil
 	}
 
-	return ct.dbmap.Select(ct.criteria.GetEntity(), selectSQL.ToStatementString(), args...)
+	lo, hi, err := ct.keyRange(tmap, key)
+	if err != nil {
+		return "", nil, err
+	}
+	if !lo.Valid || hi.Int64-lo.Int64 < int64(randomKeyRangeMin*n) {
+		return "", nil, nil
+	}
+
+	keys := randomKeys(lo.Int64, hi.Int64, randomKeyCandidates*n)
+	return qualify(ct.criteria, key.column) + " in (?" + strings.Repeat(", ?", len(keys)-1) + ")", keys, nil
+}
+
+// integerKey returns the primary key of tmap when it is a single integer
+// field, nil otherwise.
+func integerKey(tmap *modelInfo) *fieldInfo {
+	if len(tmap.fields.keys) != 1 {
+		return nil
+	}
+	for _, key := range tmap.fields.keys {
+		if key.fieldType&IsIntegerField > 0 {
+			return key
+		}
+	}
+	return nil
+}
+
+// keyRange returns the smallest and the largest value of key, both
+// invalid when the table is empty.
+func (ct CriteriaTranslator) keyRange(tmap *modelInfo, key *fieldInfo) (lo, hi sql.NullInt64, err error) {
+	query := fmt.Sprintf("select min(%s), max(%s) from %s", key.column, key.column,
+		ct.dbmap.Dialect.QuotedTableForQuery(tmap.schemaName, tmap.table))
+	err = scanRow(ct.dbmap, query, nil, &lo, &hi)
+	return
+}
+
+const (
+	// randomKeyRangeMin is how many keys per requested row the range
+	// must span before candidate keys are picked.
+	randomKeyRangeMin = 10
+
+	// randomKeyCandidates is how many candidate keys are picked per
+	// requested row, to make up for gaps in the key range.
+	randomKeyCandidates = 3
+)
+
+// randomKeys returns n distinct keys picked at random in [lo, hi].
+func randomKeys(lo, hi int64, n int) []interface{} {
+	if span := hi - lo + 1; int64(n) > span {
+		n = int(span)
+	}
+	seen := make(map[int64]bool, n)
+	keys := make([]interface{}, 0, n)
+	for len(keys) < n {
+		k := lo + rand.Int63n(hi-lo+1)
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
 }
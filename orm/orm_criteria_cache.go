@@ -0,0 +1,274 @@
+package orm
+
+import (
+	"container/list"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CriteriaCache is a least recently used cache of the select statements
+// of the criteria, so that the criteria run again and again, eg by a
+// controller, skip building their statement.  The statements are keyed by
+// the structure of the criteria: its model, criterions, projection,
+// joins, order and page.  The bind values of the criterions are keyed by
+// their shape only, their type and length, so that the criteria binding
+// other values share the statement, and their values are taken from the
+// criterions again on each use.  Set it on a DbMap:
+//
+//	dbmap.CriteriaCache = orm.NewCriteriaCache(1000)
+//
+// The criteria whose criterions hold functions or channels aren't cached.
+type CriteriaCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration            // zero keeps the statements until evicted
+	entries map[string]*list.Element // the elements of lru, by key
+	lru     *list.List               // of *criteriaCacheEntry, most recently used first
+}
+
+// criteriaCacheEntry select statement of a criteria, with the bind values
+// following those of its criterions, eg of its sample, set by the key.
+type criteriaCacheEntry struct {
+	key       string
+	selectSQL Select
+	extra     []interface{}
+	cached    time.Time
+}
+
+// NewCriteriaCache returns a cache of the statements of size criteria at
+// most.
+func NewCriteriaCache(size int) *CriteriaCache {
+	return &CriteriaCache{size: size, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// SetTTL makes the statements expire ttl after they're cached, eg so that
+// those of the criteria run once in a while don't stay.  Zero, the default,
+// keeps them until they're the least recently used.
+func (c *CriteriaCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
+
+// Len returns the number of statements cached.
+func (c *CriteriaCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *CriteriaCache) get(key string) (*Select, []interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, nil, false
+	}
+	entry := e.Value.(*criteriaCacheEntry)
+	if c.ttl > 0 && time.Since(entry.cached) >= c.ttl {
+		c.lru.Remove(e)
+		delete(c.entries, key)
+		return nil, nil, false
+	}
+	c.lru.MoveToFront(e)
+	// copies, the callers change the statement and append to the args
+	selectSQL := entry.selectSQL
+	return &selectSQL, append([]interface{}{}, entry.extra...), true
+}
+
+func (c *CriteriaCache) put(key string, selectSQL *Select, extra []interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	entry := &criteriaCacheEntry{key: key, selectSQL: *selectSQL, extra: append([]interface{}{}, extra...), cached: time.Now()}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*criteriaCacheEntry).key)
+	}
+}
+
+// cacheKey returns the key of the select statement of the criteria, with
+// its criterions resolved, false if it can't be cached.
+func (ct CriteriaTranslator) cacheKey(criterions []Criterion) (string, bool) {
+	ci, ok := ct.criteria.(criteriaImpl)
+	if !ok || ct.tempIn != nil {
+		return "", false
+	}
+	var b strings.Builder
+	w := keyWriter{b: &b}
+	// the statements differ by database and by table names
+	if !w.write(reflect.ValueOf(&ct.dbmap.Dialect).Elem(), 0) {
+		return "", false
+	}
+	affix := ct.dbmap.affix()
+	b.WriteString("|" + affix.prefix + "|" + affix.suffix + "|")
+	b.WriteString(ci.rootEntityType.PkgPath() + "." + ci.rootEntityType.Name())
+	b.WriteString("|" + ci.GetAlias())
+	for _, j := range ci.joins {
+		b.WriteString("|" + j.alias + "=" + j.parent + "." + j.field.fullName + " " + j.joinType.String())
+	}
+	b.WriteString("|" + strings.Join(ci.orders, ","))
+	b.WriteString("|" + strconv.Itoa(ci.limit) + "," + strconv.Itoa(ci.offset) + "," + strconv.Itoa(ci.random))
+	b.WriteString("," + strconv.FormatFloat(ci.sample, 'g', -1, 64) + "," + strconv.FormatBool(ci.distinct) + "|")
+	if !w.write(reflect.ValueOf(&ci.projection).Elem(), 0) {
+		return "", false
+	}
+	for _, cr := range criterions {
+		b.WriteString("|")
+		if !w.write(reflect.ValueOf(&cr).Elem(), 0) {
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// keyWriter writes the structure of the criterions of a criteria into a
+// cache key.  The values held by empty interfaces are the bind values of
+// the criterions, only their shape is written, unless values is set.
+type keyWriter struct {
+	b      *strings.Builder
+	values bool
+}
+
+var (
+	modelInfoType        = reflect.TypeOf(&modelInfo{})
+	fieldInfoType        = reflect.TypeOf(&fieldInfo{})
+	exampleCriterionType = reflect.TypeOf(ExampleCriterion{})
+)
+
+// write writes v, reporting false if it can't be part of a key.
+func (w keyWriter) write(v reflect.Value, depth int) bool {
+	if depth > 32 {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		w.b.WriteString("nil")
+	case reflect.Bool:
+		w.b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		w.b.WriteString("i" + strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.b.WriteString("u" + strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		w.b.WriteString("f" + strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		w.b.WriteString("c" + strconv.FormatFloat(real(c), 'g', -1, 64) + "," + strconv.FormatFloat(imag(c), 'g', -1, 64))
+	case reflect.String:
+		// length prefixed, so that no string reads as the next values
+		w.b.WriteString("s" + strconv.Itoa(v.Len()) + ":" + v.String())
+	case reflect.Ptr:
+		if v.IsNil() {
+			w.b.WriteString("nil")
+			return true
+		}
+		// the models and fields by name, they're registered once
+		switch v.Type() {
+		case modelInfoType:
+			w.b.WriteString(v.Elem().FieldByName("fullName").String())
+			return true
+		case fieldInfoType:
+			w.b.WriteString(v.Elem().FieldByName("fullName").String())
+			return true
+		}
+		w.b.WriteString("&")
+		return w.write(v.Elem(), depth+1)
+	case reflect.Interface:
+		if v.IsNil() {
+			w.b.WriteString("nil")
+			return true
+		}
+		if v.NumMethod() == 0 && !w.values {
+			w.writeShape(v.Elem())
+			return true
+		}
+		w.b.WriteString(v.Elem().Type().String())
+		return w.write(v.Elem(), depth+1)
+	case reflect.Struct:
+		if v.Type() == exampleCriterionType && !w.values {
+			// the conditions of an example depend on the fields set in its sample
+			return keyWriter{b: w.b, values: true}.write(v, depth)
+		}
+		w.b.WriteString(v.Type().String() + "{")
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				w.b.WriteString(",")
+			}
+			if !w.write(v.Field(i), depth+1) {
+				return false
+			}
+		}
+		w.b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			w.b.WriteString("nil")
+			return true
+		}
+		w.b.WriteString("[" + strconv.Itoa(v.Len()) + ":")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				w.b.WriteString(",")
+			}
+			if !w.write(v.Index(i), depth+1) {
+				return false
+			}
+		}
+		w.b.WriteString("]")
+	case reflect.Map:
+		// sorted, the maps aren't iterated in order
+		pairs := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var pair strings.Builder
+			pw := keyWriter{b: &pair, values: w.values}
+			if !pw.write(iter.Key(), depth+1) {
+				return false
+			}
+			pair.WriteString(":")
+			if !pw.write(iter.Value(), depth+1) {
+				return false
+			}
+			pairs = append(pairs, pair.String())
+		}
+		sort.Strings(pairs)
+		w.b.WriteString("map[" + strings.Join(pairs, ",") + "]")
+	default:
+		// functions, channels and unsafe pointers
+		return false
+	}
+	return true
+}
+
+// writeShape writes the shape of a bind value: its type, whether it's
+// nil and its length, all its SQL depends on, eg that of an in list.
+func (w keyWriter) writeShape(v reflect.Value) {
+	w.b.WriteString("?" + v.Type().String())
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			w.b.WriteString("(nil)")
+		}
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			w.b.WriteString("(nil)")
+			return
+		}
+		w.b.WriteString("[" + strconv.Itoa(v.Len()) + "]")
+	case reflect.Array:
+		w.b.WriteString("[" + strconv.Itoa(v.Len()) + "]")
+	}
+}
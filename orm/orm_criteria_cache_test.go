@@ -0,0 +1,121 @@
+package orm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// countingRenders counts the times countingCriterion is rendered.
+var countingRenders int
+
+// countingCriterion holds its value in an empty interface, so that it's
+// keyed by its shape.
+type countingCriterion struct {
+	value interface{}
+}
+
+func (c countingCriterion) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	countingRenders++
+	return "id = ?"
+}
+
+func (c countingCriterion) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return []interface{}{c.value}
+}
+
+// funcCriterion holds a function, so it can't be cached.
+type funcCriterion struct {
+	value func() int
+}
+
+func (c funcCriterion) ToSqlString(criteria Criteria, dbmap *DbMap) string { return "id = ?" }
+
+func (c funcCriterion) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return []interface{}{c.value()}
+}
+
+func TestCriteriaCache(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+	dbmap.CriteriaCache = NewCriteriaCache(2)
+
+	countingRenders = 0
+	sql := func(value int) (string, []interface{}) {
+		criteria := newTestCriteria(dbmap, &searchArticle{}).
+			Add(countingCriterion{value: value}).
+			Add(Restrictions.In("Id", []int{1, 2})).
+			Add(Restrictions.Eq("Id", value)).
+			OrderBy("Id")
+		ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+		s, args, err := ct.toSelect("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the cached statement can't be changed by its callers
+		query := s.ToStatementString()
+		s.selectClause = "changed"
+		args = append(args, "appended")
+		return query, args[:len(args)-1]
+	}
+
+	query, args := sql(1)
+	if want := "select * from search_article this_ where id = ? and id in (?,?) and id = ?  order by  id"; query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+	if want := []interface{}{1, 1, 2, 1}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, got %v", want, args)
+	}
+	cached, cachedArgs := sql(1)
+	if cached != query || !reflect.DeepEqual(cachedArgs, args) || countingRenders != 1 {
+		t.Errorf("expected the cached statement, got %q %v rendered %d times", cached, cachedArgs, countingRenders)
+	}
+	if dbmap.CriteriaCache.Len() != 1 {
+		t.Errorf("expected 1 cached statement, got %d", dbmap.CriteriaCache.Len())
+	}
+
+	// other values share the statement, binding their own values
+	cached, cachedArgs = sql(2)
+	if want := []interface{}{2, 1, 2, 2}; cached != query || !reflect.DeepEqual(cachedArgs, want) || countingRenders != 1 {
+		t.Errorf("expected the cached statement with args %v, got %q %v rendered %d times", want, cached, cachedArgs, countingRenders)
+	}
+
+	// other shapes don't, least recently used evicted
+	shaped := func(ids ...interface{}) (string, []interface{}) {
+		criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.In("Id", ids))
+		ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+		s, args, err := ct.toSelect("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.ToStatementString(), args
+	}
+	if query, args := shaped(1, 2, 3); query != "select * from search_article this_ where id in (?,?,?)" || len(args) != 3 {
+		t.Errorf("unexpected statement %q %v", query, args)
+	}
+	if query, args := shaped(4); query != "select * from search_article this_ where id in (?)" || !reflect.DeepEqual(args, []interface{}{4}) {
+		t.Errorf("unexpected statement %q %v", query, args)
+	}
+	if dbmap.CriteriaCache.Len() != 2 {
+		t.Errorf("expected 2 cached statements, got %d", dbmap.CriteriaCache.Len())
+	}
+	sql(1)
+	if countingRenders != 2 {
+		t.Errorf("expected the evicted statement to be built again, rendered %d times", countingRenders)
+	}
+
+	// expired, built again
+	if err := dbmap.ApplySettings(map[string]string{"orm.criteriacache.ttl": "1ns"}); err != nil {
+		t.Fatal(err)
+	}
+	sql(1)
+	if countingRenders != 3 {
+		t.Errorf("expected the expired statement to be built again, rendered %d times", countingRenders)
+	}
+	dbmap.CriteriaCache.SetTTL(0)
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Add(funcCriterion{value: func() int { return 1 }})
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	if _, ok := ct.cacheKey(criteria.GetCriterions()); ok {
+		t.Error("expected a criterion holding a function not to be cached")
+	}
+}
@@ -0,0 +1,141 @@
+package orm
+
+// DetachedCriteria is a criteria built without a DbMap, eg by a service
+// layer defining its queries once, then attached to the DbMap running it,
+// or used as a subquery, see Subqueries.  Like a Criteria, its methods
+// return a modified copy, so that a detached criteria can be shared.
+type DetachedCriteria struct {
+	model      interface{}
+	criterions []Criterion
+	projection Projection
+	orders     []string
+	limit      int
+	offset     int
+}
+
+// DetachedCriteriaFor returns the detached criteria of the model of
+// ptrStructOrTableName, eg &User{}, resolved when attached.
+func DetachedCriteriaFor(ptrStructOrTableName interface{}) DetachedCriteria {
+	return DetachedCriteria{model: ptrStructOrTableName}
+}
+
+// Add is Criteria.Add.
+func (d DetachedCriteria) Add(criterion Criterion) DetachedCriteria {
+	d.criterions = append(append([]Criterion{}, d.criterions...), criterion)
+	return d
+}
+
+// SetProjection is Criteria.SetProjection.
+func (d DetachedCriteria) SetProjection(projection Projection) DetachedCriteria {
+	d.projection = projection
+	return d
+}
+
+// OrderBy is Criteria.OrderBy.
+func (d DetachedCriteria) OrderBy(fieldNames ...string) DetachedCriteria {
+	d.orders = append(append([]string{}, d.orders...), fieldNames...)
+	return d
+}
+
+// AddOrder is Criteria.AddOrder.
+func (d DetachedCriteria) AddOrder(orders ...Ordering) DetachedCriteria {
+	for _, o := range orders {
+		d = d.OrderBy(o.String())
+	}
+	return d
+}
+
+// SetMaxResults is Criteria.SetMaxResults.
+func (d DetachedCriteria) SetMaxResults(n int) DetachedCriteria {
+	d.limit = n
+	return d
+}
+
+// SetFirstResult is Criteria.SetFirstResult.
+func (d DetachedCriteria) SetFirstResult(n int) DetachedCriteria {
+	d.offset = n
+	return d
+}
+
+// Attach returns the criteria of d running on m.  It panics with a
+// *CriteriaError if the model of d isn't registered.
+func (d DetachedCriteria) Attach(m *DbMap) Criteria {
+	criteria := criteriaFor(m, d.model)
+	if criteria == nil {
+		panic(criteriaError("DetachedCriteria.Attach", "table name: `%s` not exists", d.model))
+	}
+	for _, cr := range d.criterions {
+		criteria = criteria.Add(cr)
+	}
+	if d.projection != nil {
+		criteria = criteria.SetProjection(d.projection)
+	}
+	return criteria.OrderBy(d.orders...).Limit(d.limit).SetFirstResult(d.offset)
+}
+
+var (
+	Subqueries = SubqueryFactory{}
+)
+
+// SubqueryFactory builds the criterions comparing a field with the
+// results of a detached criteria, see Subqueries.
+type SubqueryFactory struct{}
+
+// PropertyIn matches rows whose field equals one of the results of
+// detached, eg
+//
+//	Subqueries.PropertyIn("Author", DetachedCriteriaFor(&Author{}).
+//		Add(Restrictions.Eq("Country", "fr")).SetProjection(Projections.Property("Id")))
+//
+// The subquery selects the primary key of its model when it has no
+// projection.
+func (s SubqueryFactory) PropertyIn(fieldName string, detached DetachedCriteria) Criterion {
+	return &subqueryExpression{fieldName: fieldName, detached: detached}
+}
+
+// PropertyNotIn matches rows whose field equals none of the results of
+// detached, see PropertyIn.
+func (s SubqueryFactory) PropertyNotIn(fieldName string, detached DetachedCriteria) Criterion {
+	return &subqueryExpression{fieldName: fieldName, detached: detached, not: true}
+}
+
+// subqueryExpression in criterion on the results of a detached criteria
+type subqueryExpression struct {
+	fieldName string
+	detached  DetachedCriteria
+	not       bool
+}
+
+func (s subqueryExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, s.fieldName)
+	query, _ := s.subquery(dbmap)
+	return cols[0] + notIn(s.not) + " (" + query + ")"
+}
+
+func (s subqueryExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	_, args := s.subquery(dbmap)
+	return args
+}
+
+// subquery returns the select statement of the detached criteria and
+// its bind values.  It panics with a *CriteriaError wrapping the error
+// building it.
+func (s subqueryExpression) subquery(dbmap *DbMap) (string, []interface{}) {
+	criteria := s.detached.Attach(dbmap)
+	if criteria.GetProjection() == nil {
+		tmap, err := dbmap.TableFor(criteria.GetEntityType())
+		if err != nil {
+			panic(&CriteriaError{Op: "Subqueries", Err: err})
+		}
+		if err = tmap.requirePK(); err != nil {
+			panic(&CriteriaError{Op: "Subqueries", Err: err})
+		}
+		criteria = criteria.SetProjection(Projections.Property(tmap.fields.GetOnePrimaryKey().name))
+	}
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	selectSQL, args, err := ct.toSelect("", nil)
+	if err != nil {
+		panic(&CriteriaError{Op: "Subqueries", Err: err})
+	}
+	return selectSQL.ToStatementString(), args
+}
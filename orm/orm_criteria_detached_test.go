@@ -0,0 +1,53 @@
+package orm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetachedCriteria(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &relAuthor{}, &relBook{})
+	defer Database().Set(nil)
+
+	authors := DetachedCriteriaFor(&relAuthor{}).Add(Restrictions.Like("Name", "x"))
+	named := authors.OrderBy("Name").SetMaxResults(5)
+	if len(authors.orders) != 0 || authors.limit != 0 {
+		t.Error("expected the detached criteria not to be modified")
+	}
+
+	sql, args, err := named.Attach(dbmap).SQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select * from rel_author this_ where name  like  ?  order by  name limit 5"; sql != want || len(args) != 1 {
+		t.Errorf("expected %q, got %q %v", want, sql, args)
+	}
+
+	tests := []struct {
+		criterion Criterion
+		sql       string
+	}{
+		{Subqueries.PropertyIn("Author", authors),
+			"select * from rel_book this_ where id > ? and author_id in (select id from rel_author this_ where name  like  ?)"},
+		{Subqueries.PropertyNotIn("Editor", authors.SetProjection(Projections.Property("Id")).SetMaxResults(2)),
+			"select * from rel_book this_ where id > ? and editor_id not in (select id from rel_author this_ where name  like  ? limit 2)"},
+	}
+	for _, test := range tests {
+		criteria := newTestCriteria(dbmap, &relBook{}).Add(Restrictions.Gt("Id", 7)).Add(test.criterion)
+		sql, args, err := criteria.SQL()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != test.sql {
+			t.Errorf("expected %q, got %q", test.sql, sql)
+		}
+		if want := []interface{}{7, "%x%"}; !reflect.DeepEqual(args, want) {
+			t.Errorf("expected args %v, got %v", want, args)
+		}
+	}
+
+	unknown := Subqueries.PropertyIn("Author", authors.Add(Restrictions.Eq("Title", "x")))
+	if _, _, err := newTestCriteria(dbmap, &relBook{}).Add(unknown).SQL(); err == nil {
+		t.Error("expected an error restricting an unknown field in the subquery")
+	}
+}
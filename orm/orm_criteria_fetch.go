@@ -0,0 +1,228 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FetchMode is how an association of the models listed by a criteria is
+// loaded, see Criteria.SetFetchMode.
+type FetchMode int
+
+const (
+	// FetchLazy leaves the association as scanned: the related models of
+	// a foreign key only hold their primary key, the others are nil.
+	FetchLazy FetchMode = iota
+	// FetchSelect loads the associated models with one more select of
+	// the associated models of all the results.
+	FetchSelect
+	// FetchJoin loads the associated models with one more select through
+	// the joins of the criteria, so that the restrictions on a joined
+	// association also restrict the associated models loaded.
+	FetchJoin
+)
+
+// criteriaFetch association loaded with the results of a criteria
+type criteriaFetch struct {
+	fieldName string
+	mode      FetchMode
+}
+
+// SetFetchMode sets how the association fieldName of the listed models is
+// loaded, eg
+//
+//	criteria.CreateAlias("Orders", "o", InnerJoin).
+//		Add(Restrictions.Eq("o.Status", "open")).
+//		SetFetchMode("Orders", FetchJoin)
+//
+// lists the customers with open orders, each holding its open orders
+// only.  Many to many associations can only be fetched by select.
+func (ci criteriaImpl) SetFetchMode(fieldName string, mode FetchMode) Criteria {
+	fetches := make([]criteriaFetch, 0, len(ci.fetches)+1)
+	for _, f := range ci.fetches {
+		if f.fieldName != fieldName {
+			fetches = append(fetches, f)
+		}
+	}
+	if mode != FetchLazy {
+		fetches = append(fetches, criteriaFetch{fieldName: fieldName, mode: mode})
+	}
+	ci.fetches = fetches
+	return ci
+}
+
+// fetch loads the associations of the models of list set by
+// SetFetchMode.
+func (ct CriteriaTranslator) fetch(list []interface{}) error {
+	ci, ok := ct.criteria.(criteriaImpl)
+	if !ok || len(ci.fetches) == 0 || ci.projection != nil {
+		return nil
+	}
+	tmap, err := ct.dbmap.TableFor(ci.GetEntityType())
+	if err != nil {
+		return err
+	}
+	for _, f := range ci.fetches {
+		fi, ok := ct.dbmap.getByAny(tmap, f.fieldName)
+		if !ok || fi.relModelInfo == nil {
+			return &FieldError{TypeName: tmap.fullName, Field: f.fieldName}
+		}
+		if !fi.inModel {
+			return fmt.Errorf("<Criteria.SetFetchMode> `%s` has no field to fetch `%s` into", tmap.fullName, fi.name)
+		}
+		if len(list) == 0 {
+			continue
+		}
+		if err = ct.fetchField(ci, tmap, fi, f.mode, list); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ct CriteriaTranslator) fetchField(ci criteriaImpl, tmap *modelInfo, fi *fieldInfo, mode FetchMode, list []interface{}) error {
+	if fi.relThroughModelInfo != nil {
+		if mode == FetchJoin {
+			return fmt.Errorf("<Criteria.SetFetchMode> many to many `%s` can only be fetched by select", fi.fullName)
+		}
+		for _, model := range list {
+			if err := queryM2M(ct.dbmap, ct.executor(), model, fi.name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	pk := tmap.fields.GetOnePrimaryKey()
+	relPK := fi.relModelInfo.fields.GetOnePrimaryKey()
+	// the keys of the associated models: their primary keys, or the
+	// primary keys of the listed models for the reverse associations
+	keys := make([]interface{}, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, model := range list {
+		elem := reflect.Indirect(reflect.ValueOf(model))
+		key := elem.FieldByIndex(pk.fieldIndex)
+		if !fi.reverse {
+			key = relKey(elem.FieldByIndex(fi.fieldIndex), relPK)
+		}
+		if key.IsValid() && !seen[fmt.Sprint(key.Interface())] {
+			seen[fmt.Sprint(key.Interface())] = true
+			keys = append(keys, key.Interface())
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var related []interface{}
+	var err error
+	if mode == FetchJoin {
+		related, err = ct.fetchJoined(ci, fi, pk, list)
+	} else {
+		keyField := relPK.name
+		if fi.reverse {
+			keyField = fi.reverseFieldInfo.name
+		}
+		criteria := criteriaFor(ct.dbmap, fi.relModelInfo.model).Add(Restrictions.In(keyField, keys))
+		related, err = CriteriaTranslator{criteria: criteria, dbmap: ct.dbmap, exec: ct.exec}.List()
+	}
+	if err != nil && !NonFatalError(err) {
+		return err
+	}
+
+	// the associated models by key
+	byKey := make(map[string][]reflect.Value, len(related))
+	dup := make(map[string]bool, len(related))
+	for _, r := range related {
+		v := reflect.ValueOf(r)
+		id := fmt.Sprint(v.Elem().FieldByIndex(relPK.fieldIndex).Interface())
+		if dup[id] {
+			continue
+		}
+		dup[id] = true
+		key := v.Elem().FieldByIndex(relPK.fieldIndex)
+		if fi.reverse {
+			key = relKey(v.Elem().FieldByIndex(fi.reverseFieldInfo.fieldIndex), pk)
+		}
+		if key.IsValid() {
+			byKey[fmt.Sprint(key.Interface())] = append(byKey[fmt.Sprint(key.Interface())], v)
+		}
+	}
+
+	for _, model := range list {
+		elem := reflect.Indirect(reflect.ValueOf(model))
+		field := elem.FieldByIndex(fi.fieldIndex)
+		var key reflect.Value
+		if fi.reverse {
+			key = elem.FieldByIndex(pk.fieldIndex)
+		} else {
+			key = relKey(field, relPK)
+		}
+		if !key.IsValid() {
+			continue
+		}
+		models := byKey[fmt.Sprint(key.Interface())]
+		switch {
+		case fi.fieldType == RelReverseMany:
+			slice := reflect.MakeSlice(field.Type(), 0, len(models))
+			for _, m := range models {
+				slice = reflect.Append(slice, m)
+			}
+			field.Set(slice)
+		case len(models) > 0:
+			field.Set(models[0])
+		case fi.reverse:
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+	return err
+}
+
+// fetchJoined selects the associated models fi of the models of list,
+// whose primary key is pk, through the joins of ci.
+func (ct CriteriaTranslator) fetchJoined(ci criteriaImpl, fi *fieldInfo, pk *fieldInfo, list []interface{}) ([]interface{}, error) {
+	var join *criteriaJoin
+	for _, j := range ci.joins {
+		if j.field == fi && j.parent == ci.rootAlias {
+			join = j
+			break
+		}
+	}
+	if join == nil {
+		alias := snakeString(fi.name)
+		for n := 2; ci.hasAlias(alias); n++ {
+			alias = snakeString(fi.name) + strconv.Itoa(n)
+		}
+		join = ci.joinAs(fi, alias, InnerJoin)
+		ci.joins = append(append([]*criteriaJoin{}, ci.joins...), join)
+	}
+	// the associated models of the listed models, not of a page of the
+	// joined rows
+	ci.orders, ci.limit, ci.offset, ci.random, ci.sample = nil, 0, 0, 0, 0
+	keys := make([]interface{}, len(list))
+	for i, model := range list {
+		keys[i] = reflect.Indirect(reflect.ValueOf(model)).FieldByIndex(pk.fieldIndex).Interface()
+	}
+
+	relPK := fi.relModelInfo.fields.GetOnePrimaryKey()
+	cond := ci.rootAlias + "_." + pk.column + " in (?" + strings.Repeat(",?", len(keys)-1) + ")" +
+		" and " + join.alias + "_." + relPK.column + " is not null"
+	sub := CriteriaTranslator{criteria: ci, dbmap: ct.dbmap, exec: ct.exec, tempIn: ct.tempIn}
+	selectSQL, args, err := sub.toSelect(cond, keys)
+	if err != nil {
+		return nil, err
+	}
+	selectSQL.selectClause = join.alias + "_.*"
+	return ct.executor().Select(fi.relModelInfo.model, selectSQL.ToStatementString(), args...)
+}
+
+// relKey returns the primary key pk of the related model held by the
+// pointer field, the invalid Value if it's nil.
+func relKey(field reflect.Value, pk *fieldInfo) reflect.Value {
+	if field.Kind() != reflect.Ptr || field.IsNil() {
+		return reflect.Value{}
+	}
+	return field.Elem().FieldByIndex(pk.fieldIndex)
+}
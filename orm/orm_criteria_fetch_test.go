@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+// fetchDriver returns the books and authors of the fetch tests, and the
+// authors, or the books of the authors, queried with bind values.
+var fetchDriver = &fakeDriver{
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		fetchQueries = append(fetchQueries, query)
+		rows := &fakeRows{}
+		switch {
+		case strings.HasPrefix(query, "select * from rel_book"), strings.HasPrefix(query, "select this_.* from rel_book"):
+			rows.columns = []string{"id", "author_id", "editor_id", "critic_id"}
+			rows.values = [][]driver.Value{{int64(1), int64(1), int64(1), int64(1)}, {int64(2), int64(2), int64(1), int64(1)}}
+		case strings.HasPrefix(query, "select * from fetch_author"):
+			rows.columns = []string{"id", "name"}
+			rows.values = [][]driver.Value{{int64(1), "one"}, {int64(2), "two"}}
+		case strings.HasPrefix(query, "select * from fetch_book"):
+			rows.columns = []string{"id", "title", "author_id"}
+			for _, arg := range args {
+				rows.values = append(rows.values, []driver.Value{arg.(int64) + 10, "title", arg})
+			}
+		default:
+			rows.columns = []string{"id", "name"}
+			for _, arg := range args {
+				if id, ok := arg.(int64); ok {
+					rows.values = append(rows.values, []driver.Value{id, "author"})
+				}
+			}
+		}
+		return rows, nil
+	},
+}
+
+var fetchQueries []string
+
+func TestFetchModes(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &relAuthor{}, &relBook{}, &fetchAuthor{}, &fetchBook{})
+	defer Database().Set(nil)
+	dbmap.Db = fetchDriver.open(t)
+
+	// to one, by select
+	fetchQueries = nil
+	list, err := newTestCriteria(dbmap, &relBook{}).SetFetchMode("Author", FetchSelect).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].(*relBook).Author.Name != "author" || list[1].(*relBook).Editor.Name != "" {
+		t.Errorf("unexpected books %+v", list)
+	}
+	if len(fetchQueries) != 2 || fetchQueries[1] != "select * from rel_author this_ where id in (?,?)" {
+		t.Errorf("unexpected queries %q", fetchQueries)
+	}
+
+	// to one, through the joins
+	fetchQueries = nil
+	list, err = newTestCriteria(dbmap, &relBook{}).
+		CreateAlias("Editor", "e", InnerJoin).
+		Add(Restrictions.Eq("e.Name", "x")).
+		SetFetchMode("Editor", FetchJoin).
+		Limit(5).
+		List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[1].(*relBook).Editor.Name != "author" {
+		t.Errorf("unexpected books %+v", list)
+	}
+	want := "select e_.* from rel_book this_ inner join rel_author e_ on this_.editor_id = e_.id where e_.name = ? and this_.id in (?,?) and e_.id is not null"
+	if len(fetchQueries) != 2 || fetchQueries[1] != want {
+		t.Errorf("unexpected queries %q", fetchQueries)
+	}
+
+	// to many, by select
+	fetchQueries = nil
+	list, err = newTestCriteria(dbmap, &fetchAuthor{}).SetFetchMode("Books", FetchSelect).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("unexpected authors %+v", list)
+	}
+	for i, a := range list {
+		books := a.(*fetchAuthor).Books
+		if len(books) != 1 || books[0].Id != int64(i+11) {
+			t.Errorf("unexpected books of author %d: %+v", i+1, books)
+		}
+	}
+	if fetchQueries[1] != "select * from fetch_book this_ where author_id in (?,?)" {
+		t.Errorf("unexpected queries %q", fetchQueries)
+	}
+
+	if _, err = newTestCriteria(dbmap, &relBook{}).SetFetchMode("Title", FetchSelect).List(); err == nil {
+		t.Error("expected an error fetching an unknown association")
+	}
+	if _, err = newTestCriteria(dbmap, &relAuthor{}).SetFetchMode("Reviewed", FetchSelect).List(); err == nil {
+		t.Error("expected an error fetching an association without field")
+	}
+	if criteria := newTestCriteria(dbmap, &relBook{}).SetFetchMode("Author", FetchSelect).SetFetchMode("Author", FetchLazy); len(criteria.(criteriaImpl).fetches) != 0 {
+		t.Error("expected FetchLazy to remove the fetch")
+	}
+}
@@ -0,0 +1,245 @@
+package orm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JoinType is the join of an association, see Criteria.CreateAlias.
+type JoinType int
+
+const (
+	// InnerJoin matches the rows having an associated model.
+	InnerJoin JoinType = iota
+	// LeftJoin matches the rows with or without an associated model.
+	LeftJoin
+)
+
+func (t JoinType) String() string {
+	if t == LeftJoin {
+		return "left join"
+	}
+	return "inner join"
+}
+
+// criteriaJoin association of a criteria joined by CreateCriteria or
+// CreateAlias
+type criteriaJoin struct {
+	alias    string
+	parent   string // alias of the joined model the association belongs to
+	field    *fieldInfo
+	tmap     *modelInfo
+	joinType JoinType
+}
+
+// CreateCriteria joins the association fieldName of the model of the
+// criteria and returns the criteria of the associated model, so that
+// restrictions can be placed on it, eg
+//
+//	tx.CreateCriteria(&User{}).CreateCriteria("Profile").Add(Restrictions.Eq("Country", "fr")).List()
+//
+// lists the users whose profile is in France.  The criterions added to
+// the returned criteria, and to the criteria it creates, apply to the
+// associated models, while the criteria still lists, orders and projects
+// the models of the root criteria.  The rows of the models joined through
+// a to-many association are repeated once per associated model matched.
+// The criteria joining associations can't update nor delete.  A field
+// without association fails the criteria when it runs.
+func (ci criteriaImpl) CreateCriteria(fieldName string) Criteria {
+	fi, err := ci.association("CreateCriteria", fieldName)
+	if err != nil {
+		return ci.failed(err)
+	}
+	alias := snakeString(fi.name)
+	for n := 2; ci.hasAlias(alias); n++ {
+		alias = snakeString(fi.name) + strconv.Itoa(n)
+	}
+	ci.join = ci.joinAs(fi, alias, InnerJoin)
+	ci.joins = append(append([]*criteriaJoin{}, ci.joins...), ci.join)
+	return ci
+}
+
+// CreateAlias joins the association fieldName of the model of the
+// criteria, or of the model joined as "alias.Field", with joinType, and
+// names the associated model alias, eg
+//
+//	criteria.CreateAlias("Orders", "o", LeftJoin).Add(Restrictions.Gt("o.Total", 100))
+//
+// The fields of the associated model are named "alias.Field" by the
+// criterions, orders and projections of the criteria, and its columns
+// qualified by "alias_" in the statement.  Unlike CreateCriteria, the
+// criteria returned is still the criteria of its model.  An unknown
+// association or a taken alias fails the criteria when it runs.
+func (ci criteriaImpl) CreateAlias(fieldName, alias string, joinType JoinType) Criteria {
+	at := ci
+	if i := strings.Index(fieldName, "."); i > 0 {
+		j, ok := ci.joinNamed(fieldName[:i])
+		if !ok {
+			return ci.failed(criteriaError("Criteria.CreateAlias", "unknown alias `%s`", fieldName[:i]))
+		}
+		at, fieldName = ci.at(j), fieldName[i+1:]
+	}
+	fi, err := at.association("CreateAlias", fieldName)
+	if err != nil {
+		return ci.failed(err)
+	}
+	if ci.hasAlias(alias) {
+		return ci.failed(criteriaError("Criteria.CreateAlias", "alias `%s` is already taken", alias))
+	}
+	ci.joins = append(append([]*criteriaJoin{}, ci.joins...), at.joinAs(fi, alias, joinType))
+	return ci
+}
+
+// association returns the relation field fieldName of the model of ci.
+func (ci criteriaImpl) association(method, fieldName string) (*fieldInfo, error) {
+	tmap, err := ci.dbmap.TableFor(ci.GetEntityType())
+	if err != nil {
+		return nil, &CriteriaError{Op: "Criteria." + method, Err: err}
+	}
+	fi, ok := ci.dbmap.getByAny(tmap, fieldName)
+	if !ok || fi.relModelInfo == nil {
+		return nil, criteriaError("Criteria."+method, "`%s` has no association `%s`", tmap.fullName, fieldName)
+	}
+	return fi, nil
+}
+
+// failed returns ci failing with err when it runs, the first error of its
+// building kept.
+func (ci criteriaImpl) failed(err error) criteriaImpl {
+	if ci.err == nil {
+		ci.err = err
+	}
+	return ci
+}
+
+// joinAs returns the join of the association fi of the model of ci.
+func (ci criteriaImpl) joinAs(fi *fieldInfo, alias string, joinType JoinType) *criteriaJoin {
+	return &criteriaJoin{alias: alias, parent: ci.GetAlias(), field: fi, tmap: fi.relModelInfo, joinType: joinType}
+}
+
+// joinNamed returns the join aliased alias, nil for the root alias.
+func (ci criteriaImpl) joinNamed(alias string) (*criteriaJoin, bool) {
+	if alias == ci.rootAlias {
+		return nil, true
+	}
+	for _, j := range ci.joins {
+		if j.alias == alias {
+			return j, true
+		}
+	}
+	return nil, false
+}
+
+func (ci criteriaImpl) hasAlias(alias string) bool {
+	if alias == ci.rootAlias {
+		return true
+	}
+	for _, j := range ci.joins {
+		if j.alias == alias || j.alias+"_t" == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// root returns the root criteria of ci, created by newCriteria.
+func (ci criteriaImpl) root() criteriaImpl {
+	ci.join = nil
+	return ci
+}
+
+// at returns ci positioned at the model joined by join.
+func (ci criteriaImpl) at(join *criteriaJoin) criteriaImpl {
+	ci.join = join
+	return ci
+}
+
+// joinCriterion criterion added to a criteria created by CreateCriteria
+type joinCriterion struct {
+	join      *criteriaJoin
+	criterion Criterion
+}
+
+func (j joinCriterion) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	return j.criterion.ToSqlString(criteriaAt(criteria, j.join), dbmap)
+}
+
+func (j joinCriterion) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return j.criterion.GetValues(criteriaAt(criteria, j.join), dbmap)
+}
+
+func criteriaAt(criteria Criteria, join *criteriaJoin) Criteria {
+	if ci, ok := criteria.(criteriaImpl); ok {
+		return ci.at(join)
+	}
+	return criteria
+}
+
+// aliasedField returns the criteria of the model fieldName belongs to and
+// the name of the field in it, which is "alias.Field" for the models
+// joined by CreateAlias.
+func aliasedField(criteria Criteria, fieldName string) (Criteria, string) {
+	i := strings.Index(fieldName, ".")
+	ci, ok := criteria.(criteriaImpl)
+	if i <= 0 || !ok || len(ci.joins) == 0 {
+		return criteria, fieldName
+	}
+	if j, ok := ci.joinNamed(fieldName[:i]); ok {
+		return ci.at(j), fieldName[i+1:]
+	}
+	return criteria, fieldName
+}
+
+// hasJoins reports whether criteria joins associations, whose columns
+// are then qualified by the alias of their model.
+func hasJoins(criteria Criteria) bool {
+	ci, ok := criteria.(criteriaImpl)
+	return ok && len(ci.joins) > 0
+}
+
+// qualify returns column qualified by the alias of the model of criteria
+// when it joins associations.
+func qualify(criteria Criteria, column string) string {
+	if !hasJoins(criteria) {
+		return column
+	}
+	return criteria.GetAlias() + "_." + column
+}
+
+// joinClauses returns the inner joins of the associations of criteria.
+func joinClauses(criteria Criteria) string {
+	ci, ok := criteria.(criteriaImpl)
+	if !ok {
+		return ""
+	}
+	var s strings.Builder
+	for _, j := range ci.joins {
+		parentPK := j.field.mi.fields.GetOnePrimaryKey().column
+		pk := j.tmap.fields.GetOnePrimaryKey().column
+		switch {
+		case j.field.relThroughModelInfo != nil:
+			// many to many, through the model of the relation
+			through := j.alias + "_t"
+			fmt.Fprintf(&s, " %s %s %s_ on %s_.%s = %s_.%s", j.joinType, j.field.relThroughModelInfo.table, through,
+				through, j.field.reverseFieldInfo.column, j.parent, parentPK)
+			fmt.Fprintf(&s, " %s %s %s_ on %s_.%s = %s_.%s", j.joinType, j.tmap.table, j.alias,
+				j.alias, pk, through, j.field.reverseFieldInfoTwo.column)
+		case j.field.reverse:
+			fmt.Fprintf(&s, " %s %s %s_ on %s_.%s = %s_.%s", j.joinType, j.tmap.table, j.alias,
+				j.alias, j.field.reverseFieldInfo.column, j.parent, parentPK)
+		default:
+			fmt.Fprintf(&s, " %s %s %s_ on %s_.%s = %s_.%s", j.joinType, j.tmap.table, j.alias,
+				j.parent, j.field.column, j.alias, pk)
+		}
+	}
+	return s.String()
+}
+
+// noJoins fails the writes of a criteria joining associations.
+func noJoins(criteria Criteria, method string) error {
+	if hasJoins(criteria) {
+		return fmt.Errorf("<Criteria.%s> criteria joining associations can't write", method)
+	}
+	return nil
+}
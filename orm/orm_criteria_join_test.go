@@ -0,0 +1,108 @@
+package orm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCreateCriteria(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &relAuthor{}, &relBook{}, &affixTag{}, &affixPost{})
+	defer Database().Set(nil)
+
+	tests := []struct {
+		criteria Criteria
+		sql      string
+	}{
+		{newTestCriteria(dbmap, &relBook{}).Add(Restrictions.Gt("Id", 1)).CreateCriteria("Author").Add(Restrictions.Eq("Name", "x")),
+			"select this_.* from rel_book this_ inner join rel_author author_ on this_.author_id = author_.id where this_.id > ? and author_.name = ?"},
+		{newTestCriteria(dbmap, &relBook{}).CreateCriteria("Author").Add(Restrictions.Eq("Name", "x")).CreateCriteria("Reviewed").Add(Restrictions.IsNull("Editor")),
+			"select this_.* from rel_book this_ inner join rel_author author_ on this_.author_id = author_.id" +
+				" inner join rel_book reviewed_ on reviewed_.critic_id = author_.id where author_.name = ? and reviewed_.editor_id is null"},
+		{newTestCriteria(dbmap, &affixPost{}).CreateCriteria("Tags").Add(Restrictions.Eq("Name", "go")),
+			"select this_.* from affix_post this_ inner join affix_post_affix_tag tags_t_ on tags_t_.post_id = this_.post_id" +
+				" inner join affix_tag tags_ on tags_.tag_id = tags_t_.tag_id where tags_.name = ?"},
+	}
+	for _, test := range tests {
+		sql, args, err := test.criteria.SQL()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql != test.sql {
+			t.Errorf("expected %q, got %q", test.sql, sql)
+		}
+		if len(args) == 0 {
+			t.Error("expected bind values")
+		}
+	}
+
+	// the root model is still listed and ordered
+	criteria := newTestCriteria(dbmap, &relBook{}).CreateCriteria("Editor").Add(Restrictions.Eq("Name", "x")).OrderBy("-Id")
+	if typ := criteria.GetEntityType(); typ != reflect.TypeOf(relAuthor{}) {
+		t.Errorf("unexpected entity type %v", typ)
+	}
+	ct := CriteriaTranslator{criteria: criteria.(criteriaImpl).root(), dbmap: dbmap}
+	s, _, err := ct.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.orderByClause != "this_.id desc" || s.selectClause != "this_.*" {
+		t.Errorf("unexpected select %q order by %q", s.selectClause, s.orderByClause)
+	}
+
+	if _, err := criteria.Update(Params{"Id": 1}); err == nil {
+		t.Error("expected joined criteria not to update")
+	}
+	if _, err := criteria.Delete(); err == nil {
+		t.Error("expected joined criteria not to delete")
+	}
+	if _, _, err := criteria.Add(Restrictions.Eq("Title", "x")).SQL(); err == nil {
+		t.Error("expected an error restricting an unknown field of the association")
+	}
+	if _, err := newTestCriteria(dbmap, &relBook{}).CreateCriteria("Id").List(); !isCriteriaError(err) {
+		t.Errorf("expected CreateCriteria to fail on a field without association, got %v", err)
+	}
+}
+
+func TestResultDistinct(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &relAuthor{}, &relBook{})
+	defer Database().Set(nil)
+
+	criteria := newTestCriteria(dbmap, &relAuthor{}).SetResultDistinct().CreateCriteria("Reviewed").Add(Restrictions.IsNull("Editor"))
+	sql, _, err := criteria.SQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select distinct this_.* from rel_author this_ inner join rel_book reviewed_ on reviewed_.critic_id = this_.id where reviewed_.editor_id is null"; sql != want {
+		t.Errorf("expected %q, got %q", want, sql)
+	}
+
+	// not twice with a distinct projection
+	sql, _, err = criteria.SetProjection(Projections.Distinct(Projections.Property("Name"))).SQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select distinct this_.name from rel_author this_ inner join rel_book reviewed_ on reviewed_.critic_id = this_.id where reviewed_.editor_id is null"; sql != want {
+		t.Errorf("expected %q, got %q", want, sql)
+	}
+}
+
+func TestPropertyComparisonAcrossAliases(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &relAuthor{}, &relBook{})
+	defer Database().Set(nil)
+
+	criteria := newTestCriteria(dbmap, &relBook{}).
+		CreateAlias("Author", "a", InnerJoin).
+		CreateAlias("Editor", "e", LeftJoin).
+		Add(Restrictions.NeProperty("a.Name", "e.Name"))
+	sql, _, err := criteria.SQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select this_.* from rel_book this_ inner join rel_author a_ on this_.author_id = a_.id" +
+		" left join rel_author e_ on this_.editor_id = e_.id where a_.name <> e_.name"; sql != want {
+		t.Errorf("expected %q, got %q", want, sql)
+	}
+	if _, _, err := newTestCriteria(dbmap, &relBook{}).Add(Restrictions.EqProperty("Id", "Missing")).SQL(); err == nil {
+		t.Error("expected an error comparing an unknown field")
+	}
+}
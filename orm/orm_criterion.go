@@ -1,15 +1,21 @@
 package orm
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 //Criterion An object-oriented representation of a query criterion that may be used
 //as a restriction in a <tt>Criteria</tt> query.
 //Built-in criterion types are provided by the <tt>Restrictions</tt> factory
 //class. This interface might be implemented by application classes that
 //define custom restriction criteria.
+//GetValues returns the values bound to the "?" placeholders of the
+//condition ToSqlString renders, in order.  The criterions of a criteria
+//are joined with and, see Restrictions.Or for the other combinations.
 type Criterion interface {
 	ToSqlString(criteria Criteria, dbmap *DbMap) string
-	GetValues(criteria Criteria, dbmap *DbMap) interface{}
+	GetValues(criteria Criteria, dbmap *DbMap) []interface{}
 }
 
 var (
@@ -18,20 +24,218 @@ var (
 
 type Restriction struct{}
 
-func (r Restriction) Like(filedName string, value string) Criterion {
+// Like matches rows whose field contains value, or starts with it, ends
+// with it or equals it with the match mode given, eg
+//
+//	Restrictions.Like("Name", "jo", MatchStart)
+//
+// The wildcards of value, % and _, are matched as is.
+func (r Restriction) Like(fieldName string, value string, mode ...MatchMode) Criterion {
+	return &likeExpression{fieldName: fieldName, value: value, mode: matchMode(mode)}
+}
+
+// Ilike is Like ignoring case, with the ilike operator of the dialects
+// implementing ILiker, or else comparing lower cased values.
+func (r Restriction) Ilike(fieldName string, value string, mode ...MatchMode) Criterion {
+	return &likeExpression{fieldName: fieldName, value: value, mode: matchMode(mode), ignoreCase: true}
+}
+
+// matchMode returns the match mode of Like, MatchAnywhere by default.
+func matchMode(mode []MatchMode) MatchMode {
+	if len(mode) == 0 {
+		return MatchAnywhere
+	}
+	return mode[0]
+}
+
+// MatchMode is where the value of a like restriction is looked for in the
+// column, see Restrictions.Like.
+type MatchMode int
+
+const (
+	// MatchExact matches the whole column.
+	MatchExact MatchMode = iota
+	// MatchStart matches the start of the column.
+	MatchStart
+	// MatchEnd matches the end of the column.
+	MatchEnd
+	// MatchAnywhere matches anywhere in the column.
+	MatchAnywhere
+)
+
+// pattern returns the like pattern of value.
+func (m MatchMode) pattern(value string) string {
+	switch m {
+	case MatchStart:
+		return value + "%"
+	case MatchEnd:
+		return "%" + value
+	case MatchAnywhere:
+		return "%" + value + "%"
+	}
+	return value
+}
+
+// likeEscape is the escape character of the like patterns.
+const likeEscape = "!"
+
+// escapeLike escapes the wildcards of value, so that a like pattern built
+// with it matches value as is, reporting whether value had any.
+func escapeLike(dialect Dialect, value string) (string, bool) {
+	special := likeEscape + "%_"
+	if le, ok := dialect.(LikeEscaper); ok {
+		special += le.LikeWildcards()
+	}
+	if de, ok := dialect.(DefaultLikeEscaper); ok {
+		special += de.DefaultLikeEscape()
+	}
+	if !strings.ContainsAny(value, special) {
+		return value, false
+	}
+	var b strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(special, r) {
+			b.WriteString(likeEscape)
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), true
+}
+
+// escapeClause returns the escape clause of a like predicate on value.
+func escapeClause(dialect Dialect, value string) string {
+	if _, escaped := escapeLike(dialect, value); escaped {
+		return " escape '" + likeEscape + "'"
+	}
+	return ""
+}
+
+// Eq matches rows whose field equals value.
+func (r Restriction) Eq(fieldName string, value interface{}) Criterion {
+	return r.compare(fieldName, "=", value)
+}
+
+// Ne matches rows whose field differs from value.
+func (r Restriction) Ne(fieldName string, value interface{}) Criterion {
+	return r.compare(fieldName, "<>", value)
+}
+
+// Gt matches rows whose field is greater than value.
+func (r Restriction) Gt(fieldName string, value interface{}) Criterion {
+	return r.compare(fieldName, ">", value)
+}
+
+// Ge matches rows whose field is greater than or equal to value.
+func (r Restriction) Ge(fieldName string, value interface{}) Criterion {
+	return r.compare(fieldName, ">=", value)
+}
+
+// Lt matches rows whose field is less than value.
+func (r Restriction) Lt(fieldName string, value interface{}) Criterion {
+	return r.compare(fieldName, "<", value)
+}
+
+// Le matches rows whose field is less than or equal to value.
+func (r Restriction) Le(fieldName string, value interface{}) Criterion {
+	return r.compare(fieldName, "<=", value)
+}
+
+func (r Restriction) compare(fieldName, operator string, value interface{}) Criterion {
 	c := new(simpleExpression)
-	c.fieldName = filedName
-	c.value = "%" + value + "%"
-	c.operator = " like "
+	c.fieldName = fieldName
+	c.value = value
+	c.operator = operator
 	return c
 }
 
+// EqProperty matches rows whose field equals their other field, eg
+//
+//	Restrictions.EqProperty("StartDate", "EndDate")
+func (r Restriction) EqProperty(fieldName, otherFieldName string) Criterion {
+	return &propertyExpression{fieldName: fieldName, otherFieldName: otherFieldName, operator: "="}
+}
+
+// NeProperty matches rows whose field differs from their other field.
+func (r Restriction) NeProperty(fieldName, otherFieldName string) Criterion {
+	return &propertyExpression{fieldName: fieldName, otherFieldName: otherFieldName, operator: "<>"}
+}
+
+// GtProperty matches rows whose field is greater than their other field.
+func (r Restriction) GtProperty(fieldName, otherFieldName string) Criterion {
+	return &propertyExpression{fieldName: fieldName, otherFieldName: otherFieldName, operator: ">"}
+}
+
+// GeProperty matches rows whose field is greater than or equal to their
+// other field.
+func (r Restriction) GeProperty(fieldName, otherFieldName string) Criterion {
+	return &propertyExpression{fieldName: fieldName, otherFieldName: otherFieldName, operator: ">="}
+}
+
+// LtProperty matches rows whose field is less than their other field, eg
+// the rows valid at their check date:
+//
+//	Restrictions.And(Restrictions.LeProperty("ValidFrom", "CheckedAt"),
+//		Restrictions.LtProperty("CheckedAt", "ValidTo"))
+func (r Restriction) LtProperty(fieldName, otherFieldName string) Criterion {
+	return &propertyExpression{fieldName: fieldName, otherFieldName: otherFieldName, operator: "<"}
+}
+
+// LeProperty matches rows whose field is less than or equal to their
+// other field.
+func (r Restriction) LeProperty(fieldName, otherFieldName string) Criterion {
+	return &propertyExpression{fieldName: fieldName, otherFieldName: otherFieldName, operator: "<="}
+}
+
+// Between matches rows whose field lies between lo and hi, both included.
+func (r Restriction) Between(fieldName string, lo, hi interface{}) Criterion {
+	c := new(betweenExpression)
+	c.fieldName = fieldName
+	c.lo = lo
+	c.hi = hi
+	return c
+}
+
+// IsNull matches rows whose field is null.
+func (r Restriction) IsNull(fieldName string) Criterion {
+	c := new(nullExpression)
+	c.fieldName = fieldName
+	return c
+}
+
+// IsNotNull matches rows whose field isn't null.
+func (r Restriction) IsNotNull(fieldName string) Criterion {
+	c := new(nullExpression)
+	c.fieldName = fieldName
+	c.not = true
+	return c
+}
+
+// Search matches the field against the search terms using the dialect's
+// native full-text search.  Dialects without full-text support fall back
+// to a like match on the whole value.
+func (r Restriction) Search(fieldName string, value string) Criterion {
+	c := new(searchExpression)
+	c.fieldName = fieldName
+	c.value = value
+	return c
+}
+
+// Sql matches rows with a raw SQL condition, for what the other
+// criterions can't express, eg
+//
+//	Restrictions.Sql("lower({alias}.name) = lower(?)", name)
+//
+// The {alias} placeholders are replaced by the alias of the model of the
+// criteria, and values bound to the "?" placeholders of sql.
+func (r Restriction) Sql(sql string, values ...interface{}) Criterion {
+	return &sqlExpression{sql: sql, values: values}
+}
+
 //simpleExpression s
 type simpleExpression struct {
-	fieldName  string
-	value      interface{}
-	ignoreCase bool
-	operator   string
+	fieldName string
+	value     interface{}
+	operator  string
 }
 
 func (s simpleExpression) ToSqlString(criteria Criteria, dbmap *DbMap) (sql string) {
@@ -42,6 +246,160 @@ func (s simpleExpression) ToSqlString(criteria Criteria, dbmap *DbMap) (sql stri
 	return
 }
 
-func (s simpleExpression) GetValues(criteria Criteria, dbmap *DbMap) interface{} {
-	return s.value
+func (s simpleExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return []interface{}{s.value}
+}
+
+//likeExpression like criterion, escaping the wildcards of its value
+type likeExpression struct {
+	fieldName  string
+	value      string
+	mode       MatchMode
+	ignoreCase bool
+}
+
+func (l likeExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, l.fieldName)
+	escape := escapeClause(dbmap.Dialect, l.value)
+
+	if l.ignoreCase {
+		if il, ok := dbmap.Dialect.(ILiker); ok {
+			return il.ILike(cols[0], "?") + escape
+		}
+		return fmt.Sprintf("lower(%s) like lower(?)", cols[0]) + escape
+	}
+	return fmt.Sprintf("%s  like  ?", cols[0]) + escape
+}
+
+func (l likeExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	value, _ := escapeLike(dbmap.Dialect, l.value)
+	return []interface{}{l.mode.pattern(value)}
+}
+
+//propertyExpression comparison of two fields
+type propertyExpression struct {
+	fieldName      string
+	otherFieldName string
+	operator       string
+}
+
+func (p propertyExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, p.fieldName)
+	others := dbmap.findColumns(criteria, p.otherFieldName)
+	return cols[0] + " " + p.operator + " " + others[0]
+}
+
+func (p propertyExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return nil
+}
+
+//betweenExpression between criterion
+type betweenExpression struct {
+	fieldName string
+	lo, hi    interface{}
+}
+
+func (b betweenExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, b.fieldName)
+	return cols[0] + " between ? and ?"
+}
+
+func (b betweenExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return []interface{}{b.lo, b.hi}
+}
+
+//nullExpression is null and is not null criterion
+type nullExpression struct {
+	fieldName string
+	not       bool
+}
+
+func (s nullExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, s.fieldName)
+	if s.not {
+		return cols[0] + " is not null"
+	}
+	return cols[0] + " is null"
+}
+
+func (s nullExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return nil
+}
+
+//searchExpression full-text search criterion
+type searchExpression struct {
+	fieldName string
+	value     string
+}
+
+func (s searchExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, s.fieldName)
+
+	if fts, ok := dbmap.Dialect.(FullTextSearcher); ok {
+		return fts.FullTextMatch(cols[0], "?")
+	}
+	return fmt.Sprintf("%s like ?", cols[0])
+}
+
+func (s searchExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	if _, ok := dbmap.Dialect.(FullTextSearcher); ok {
+		return []interface{}{s.value}
+	}
+	return []interface{}{"%" + s.value + "%"}
+}
+
+// ArrayContains matches rows whose array field contains every element of
+// values, a slice.
+func (r Restriction) ArrayContains(fieldName string, values interface{}) Criterion {
+	return &arrayExpression{fieldName: fieldName, values: values, overlap: false}
+}
+
+// ArrayOverlap matches rows whose array field has at least one element of
+// values, a slice.
+func (r Restriction) ArrayOverlap(fieldName string, values interface{}) Criterion {
+	return &arrayExpression{fieldName: fieldName, values: values, overlap: true}
+}
+
+//sqlExpression raw SQL criterion
+type sqlExpression struct {
+	sql    string
+	values []interface{}
+}
+
+// ToSqlString parenthesizes the raw SQL, so that its or's don't escape the
+// conditions and'ed with it, eg those of the scopes and policies.
+func (s sqlExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	alias := criteria.GetAlias() + "_"
+	if ci, ok := criteria.(criteriaImpl); ok && ci.join == nil && ci.tableAlias != "" {
+		alias = ci.tableAlias
+	}
+	return "(" + strings.Replace(s.sql, "{alias}", alias, -1) + ")"
+}
+
+func (s sqlExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return s.values
+}
+
+//arrayExpression criterion on a native array field
+type arrayExpression struct {
+	fieldName string
+	values    interface{}
+	overlap   bool
+}
+
+func (a arrayExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, a.fieldName)
+
+	ad, ok := dbmap.Dialect.(ArrayDialect)
+	if !ok {
+		panic(criteriaError("Restrictions.Array", "dialect %T does not support array queries", dbmap.Dialect))
+	}
+	if a.overlap {
+		return ad.ArrayOverlap(cols[0], "?")
+	}
+	return ad.ArrayContains(cols[0], "?")
+}
+
+func (a arrayExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return []interface{}{Array(a.values)}
 }
@@ -0,0 +1,104 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+)
+
+var (
+	Example = ExampleFactory{}
+)
+
+// ExampleFactory builds the query by example criterions, see Example.
+type ExampleFactory struct{}
+
+// Create matches the rows whose fields equal the non-zero fields of
+// sample, a pointer to a model, eg
+//
+//	Example.Create(&User{Status: "active", City: "Berlin"})
+//
+// The primary keys and the associations are ignored.
+func (e ExampleFactory) Create(sample interface{}) *ExampleCriterion {
+	return &ExampleCriterion{sample: sample, exclude: make(map[string]bool)}
+}
+
+// ExampleCriterion matches the rows like a sample model, see
+// Example.Create.
+type ExampleCriterion struct {
+	sample  interface{}
+	exclude map[string]bool
+	like    bool
+	mode    MatchMode
+}
+
+// ExcludeProperty ignores the fields named, returning the criterion.
+func (e *ExampleCriterion) ExcludeProperty(fieldNames ...string) *ExampleCriterion {
+	for _, name := range fieldNames {
+		e.exclude[name] = true
+	}
+	return e
+}
+
+// EnableLike matches the string fields with like, looking for their
+// value where mode says, returning the criterion.
+func (e *ExampleCriterion) EnableLike(mode MatchMode) *ExampleCriterion {
+	e.like = true
+	e.mode = mode
+	return e
+}
+
+// fields returns the fields of the sample matched, whether they're
+// matched with like, and their values, the strings matched with like
+// before their pattern is built.
+func (e *ExampleCriterion) fields(dbmap *DbMap) (fields []*fieldInfo, likes []bool, values []interface{}) {
+	tmap, elem, err := dbmap.tableForPointer(e.sample, false)
+	if err != nil {
+		panic(&CriteriaError{Op: "Example", Err: err})
+	}
+	for _, fi := range tmap.fields.fieldsDB {
+		if fi.pk || fi.rel || fi.reverse || fi.fieldIndex == nil || e.exclude[fi.name] {
+			continue
+		}
+		v := elem.FieldByIndex(fi.fieldIndex)
+		if v.IsZero() {
+			continue
+		}
+		like := e.like && v.Kind() == reflect.String
+		fields = append(fields, fi)
+		likes = append(likes, like)
+		if like {
+			values = append(values, v.String())
+		} else {
+			values = append(values, v.Interface())
+		}
+	}
+	return fields, likes, values
+}
+
+func (e *ExampleCriterion) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	fields, likes, values := e.fields(dbmap)
+	if len(fields) == 0 {
+		return "1 = 1"
+	}
+	conds := make([]string, len(fields))
+	for i, fi := range fields {
+		col := dbmap.findColumns(criteria, fi.name)[0]
+		if likes[i] {
+			conds[i] = col + " like ?" + escapeClause(dbmap.Dialect, values[i].(string))
+		} else {
+			conds[i] = col + " = ?"
+		}
+	}
+	return "(" + strings.Join(conds, " and ") + ")"
+}
+
+func (e *ExampleCriterion) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	_, likes, values := e.fields(dbmap)
+	for i, like := range likes {
+		if like {
+			value, _ := escapeLike(dbmap.Dialect, values[i].(string))
+			values[i] = e.mode.pattern(value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,202 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultInListLimit is the InListLimit of DbMaps which don't set one.
+var DefaultInListLimit = 1000
+
+// tempInSeq numbers the temporary tables of oversized in lists.
+var tempInSeq int64
+
+func (m *DbMap) inListLimit() int {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	if m.InListLimit > 0 {
+		return m.InListLimit
+	}
+	return DefaultInListLimit
+}
+
+// In matches rows whose field equals one of the elements of values, a
+// slice.  Lists longer than DbMap.InListLimit are bound as one array on
+// dialects with arrays, or else loaded into a temporary table the query
+// joins, which avoids the bind variable limits of the databases.
+func (r Restriction) In(fieldName string, values interface{}) Criterion {
+	return newInExpression("In", fieldName, values, false)
+}
+
+// NotIn matches rows whose field equals none of the elements of values, a
+// slice, oversized lists being bound as by In.
+func (r Restriction) NotIn(fieldName string, values interface{}) Criterion {
+	return newInExpression("NotIn", fieldName, values, true)
+}
+
+func newInExpression(name, fieldName string, values interface{}, not bool) *inExpression {
+	c := new(inExpression)
+	c.not = not
+	c.fieldName = fieldName
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		c.err = criteriaError("Restrictions."+name, "values must be a slice, got %T", values)
+		return c
+	}
+	c.values = values
+	c.list = make([]interface{}, v.Len())
+	for i := range c.list {
+		c.list[i] = v.Index(i).Interface()
+	}
+	return c
+}
+
+type inExpression struct {
+	fieldName string
+	values    interface{}
+	list      []interface{}
+	not       bool
+	err       error // of the building of the criterion, see CriteriaError
+}
+
+// asArray reports whether the values are bound as one array.
+func (in *inExpression) asArray(dbmap *DbMap) bool {
+	_, ok := dbmap.Dialect.(ArrayDialect)
+	return ok && len(in.list) > dbmap.inListLimit()
+}
+
+func (in *inExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	if in.err != nil {
+		panic(in.err)
+	}
+	cols := dbmap.findColumns(criteria, in.fieldName)
+	switch {
+	case len(in.list) == 0 && in.not:
+		return "1 = 1"
+	case len(in.list) == 0:
+		return "1 = 0"
+	case in.asArray(dbmap) && in.not:
+		return "not (" + dbmap.Dialect.(ArrayDialect).ArrayAny(cols[0], "?") + ")"
+	case in.asArray(dbmap):
+		return dbmap.Dialect.(ArrayDialect).ArrayAny(cols[0], "?")
+	}
+	return cols[0] + notIn(in.not) + " (" + strings.Repeat("?,", len(in.list)-1) + "?)"
+}
+
+func (in *inExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	if in.asArray(dbmap) {
+		return []interface{}{Array(in.values)}
+	}
+	return in.list
+}
+
+// tempInExpression replaces an oversized inExpression once its values are
+// loaded into a temporary table.
+type tempInExpression struct {
+	fieldName string
+	table     string
+	not       bool
+}
+
+func (t tempInExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, t.fieldName)
+	return cols[0] + notIn(t.not) + " (select v from " + dbmap.Dialect.QuoteField(t.table) + ")"
+}
+
+// notIn returns the in operator, negated when not is set.
+func notIn(not bool) string {
+	if not {
+		return " not in"
+	}
+	return " in"
+}
+
+func (t tempInExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return nil
+}
+
+// withTempIn runs fn with the oversized in lists of the criteria loaded
+// into temporary tables, when the dialect has no arrays but temporary
+// tables.  Temporary tables only exist on the connection which created
+// them, so fn then runs in a transaction, through ct.executor(): the one
+// the criteria already runs in, if any.  The tables are dropped before
+// the transaction ends, so they don't outlive it on pooled connections.
+func (ct CriteriaTranslator) withTempIn(fn func(ct CriteriaTranslator) error) error {
+	tt, ok := ct.dbmap.Dialect.(TempTabler)
+	if _, arrays := ct.dbmap.Dialect.(ArrayDialect); !ok || arrays {
+		return fn(ct)
+	}
+	var big []*inExpression
+	for _, cr := range ct.criteria.GetCriterions() {
+		if in, ok := cr.(*inExpression); ok && len(in.list) > ct.dbmap.inListLimit() {
+			big = append(big, in)
+		}
+	}
+	if len(big) == 0 {
+		return fn(ct)
+	}
+
+	if tx, ok := ct.exec.(*Transaction); ok {
+		return ct.runTempIn(tx, tt, big, fn)
+	}
+	tx, err := ct.dbmap.Begin()
+	if err != nil {
+		return err
+	}
+	if err = ct.runTempIn(tx, tt, big, fn); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// runTempIn loads the in lists big into temporary tables of tx, runs fn
+// through tx and drops the tables.
+func (ct CriteriaTranslator) runTempIn(tx *Transaction, tt TempTabler, big []*inExpression, fn func(ct CriteriaTranslator) error) (err error) {
+	ct.exec = tx
+	ct.tempIn = make(map[Criterion]Criterion, len(big))
+	tables := make([]string, 0, len(big))
+	defer func() {
+		for _, table := range tables {
+			if _, dropErr := tx.Exec("drop table " + ct.dbmap.Dialect.QuoteField(table)); err == nil {
+				err = dropErr
+			}
+		}
+	}()
+
+	for _, in := range big {
+		table := fmt.Sprintf("orm_in_%d", atomic.AddInt64(&tempInSeq, 1))
+		sqlType := ct.dbmap.Dialect.ToSqlType(reflect.TypeOf(in.list[0]), 255, false)
+		if _, err = tx.Exec(tt.TempTable(table, sqlType)); err != nil {
+			return err
+		}
+		tables = append(tables, table)
+		if err = ct.loadTempIn(tx, table, in.list); err != nil {
+			return err
+		}
+		ct.tempIn[in] = tempInExpression{fieldName: in.fieldName, table: table, not: in.not}
+	}
+	return fn(ct)
+}
+
+// loadTempIn inserts values into table, InListLimit of them at a time.
+func (ct CriteriaTranslator) loadTempIn(tx *Transaction, table string, values []interface{}) error {
+	limit := ct.dbmap.inListLimit()
+	for start := 0; start < len(values); start += limit {
+		end := start + limit
+		if end > len(values) {
+			end = len(values)
+		}
+		binds := make([]string, end-start)
+		for i := range binds {
+			binds[i] = "(" + ct.dbmap.Dialect.BindVar(i) + ")"
+		}
+		query := "insert into " + ct.dbmap.Dialect.QuoteField(table) + " (v) values " + strings.Join(binds, ",")
+		if _, err := tx.Exec(query, values[start:end]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
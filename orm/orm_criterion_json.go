@@ -0,0 +1,93 @@
+package orm
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var jsonKeyRegexp = regexp.MustCompile(`^[[:word:]]+$`)
+
+// JSONContains matches rows whose json field contains value.  Values
+// other than strings and []byte are marshalled to json first, a value
+// which can't be failing the criteria.
+func (r Restriction) JSONContains(fieldName string, value interface{}) Criterion {
+	c := new(jsonExpression)
+	c.fieldName = fieldName
+	c.operator = "contains"
+	switch v := value.(type) {
+	case string:
+		c.value = v
+	case []byte:
+		c.value = string(v)
+	default:
+		b, err := json.Marshal(value)
+		if err != nil {
+			c.err = criteriaError("Restrictions.JSONContains", "can not marshal value: %w", err)
+		}
+		c.value = string(b)
+	}
+	return c
+}
+
+// JSONHasKey matches rows whose json field has the top level key.
+func (r Restriction) JSONHasKey(fieldName string, key string) Criterion {
+	c := new(jsonExpression)
+	c.fieldName = fieldName
+	c.operator = "haskey"
+	c.value = key
+	return c
+}
+
+// JSONExtract matches rows where the text found at path, a dot separated
+// list of keys like "address.city", equals value.  A path with other
+// characters than letters, digits and underscores fails the criteria.
+func (r Restriction) JSONExtract(fieldName string, path string, value interface{}) Criterion {
+	c := new(jsonExpression)
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if !jsonKeyRegexp.MatchString(key) {
+			c.err = criteriaError("Restrictions.JSONExtract", "wrong json path `%s`", path)
+		}
+	}
+	c.fieldName = fieldName
+	c.operator = "extract"
+	c.path = keys
+	c.value = value
+	return c
+}
+
+//jsonExpression criterion on a json/jsonb field
+type jsonExpression struct {
+	fieldName string
+	operator  string
+	path      []string
+	value     interface{}
+	err       error // of the building of the criterion, see CriteriaError
+}
+
+func (j jsonExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	if j.err != nil {
+		panic(j.err)
+	}
+	cols := dbmap.findColumns(criteria, j.fieldName)
+
+	jq, ok := dbmap.Dialect.(JSONQuerier)
+	if !ok {
+		panic(criteriaError("Restrictions.JSON", "dialect %T does not support json queries", dbmap.Dialect))
+	}
+
+	switch j.operator {
+	case "contains":
+		return jq.JSONContains(cols[0], "?")
+	case "haskey":
+		return jq.JSONHasKey(cols[0], "?")
+	default:
+		return fmt.Sprintf("%s = ?", jq.JSONExtract(cols[0], j.path))
+	}
+}
+
+func (j jsonExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return []interface{}{j.value}
+}
@@ -0,0 +1,90 @@
+package orm
+
+import "strings"
+
+// And matches rows matching every one of criterions.
+func (r Restriction) And(criterions ...Criterion) Criterion {
+	return r.Conjunction().Add(criterions...)
+}
+
+// Or matches rows matching at least one of criterions.
+func (r Restriction) Or(criterions ...Criterion) Criterion {
+	return r.Disjunction().Add(criterions...)
+}
+
+// Not matches rows not matching criterion, eg
+//
+//	Restrictions.Not(Restrictions.Between("Age", 18, 65))
+//
+// renders not (age between ? and ?).  As in SQL, the rows whose field is
+// null match neither criterion nor its negation.
+func (r Restriction) Not(criterion Criterion) Criterion {
+	return &notExpression{criterion: criterion}
+}
+
+// Conjunction returns an empty and junction, for criterions built one at
+// a time.  It matches every row until a criterion is added.
+func (r Restriction) Conjunction() *Junction {
+	return &Junction{operator: "and"}
+}
+
+// Disjunction returns an empty or junction, for criterions built one at a
+// time.  It matches no row until a criterion is added.
+func (r Restriction) Disjunction() *Junction {
+	return &Junction{operator: "or"}
+}
+
+// Junction groups criterions with and or or, see Restrictions.Conjunction
+// and Restrictions.Disjunction.  Its criterions are parenthesized, so
+// junctions nest, eg
+//
+//	Restrictions.Or(Restrictions.Eq("Status", "new"),
+//		Restrictions.And(Restrictions.Eq("Status", "open"), Restrictions.Gt("Priority", 2)))
+//
+// renders (status = ? or (status = ? and priority > ?)).  The in lists of
+// a junction are always bound inline, never from a temporary table.
+type Junction struct {
+	operator   string
+	criterions []Criterion
+}
+
+// Add appends criterions to the junction, returning it.
+func (j *Junction) Add(criterions ...Criterion) *Junction {
+	j.criterions = append(j.criterions, criterions...)
+	return j
+}
+
+func (j *Junction) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	if len(j.criterions) == 0 {
+		if j.operator == "or" {
+			return "1 = 0"
+		}
+		return "1 = 1"
+	}
+	conds := make([]string, len(j.criterions))
+	for i, cr := range j.criterions {
+		conds[i] = cr.ToSqlString(criteria, dbmap)
+	}
+	return "(" + strings.Join(conds, " "+j.operator+" ") + ")"
+}
+
+func (j *Junction) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	var values []interface{}
+	for _, cr := range j.criterions {
+		values = append(values, cr.GetValues(criteria, dbmap)...)
+	}
+	return values
+}
+
+// notExpression negation of a criterion
+type notExpression struct {
+	criterion Criterion
+}
+
+func (n notExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	return "not (" + n.criterion.ToSqlString(criteria, dbmap) + ")"
+}
+
+func (n notExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return n.criterion.GetValues(criteria, dbmap)
+}
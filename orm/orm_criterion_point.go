@@ -0,0 +1,23 @@
+package orm
+
+// DistanceLte matches rows whose point field is at most meters away from
+// point, eg to find the stores near a user.
+func (r Restriction) DistanceLte(fieldName string, point Point, meters float64) Criterion {
+	return &distanceExpression{fieldName: fieldName, point: point, meters: meters}
+}
+
+// distanceExpression criterion on a point field
+type distanceExpression struct {
+	fieldName string
+	point     Point
+	meters    float64
+}
+
+func (d distanceExpression) ToSqlString(criteria Criteria, dbmap *DbMap) string {
+	cols := dbmap.findColumns(criteria, d.fieldName)
+	return spatialDialect(dbmap.Dialect, d.fieldName).DistanceWithin(cols[0], "?", "?")
+}
+
+func (d distanceExpression) GetValues(criteria Criteria, dbmap *DbMap) []interface{} {
+	return []interface{}{d.point, d.meters}
+}
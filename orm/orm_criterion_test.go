@@ -0,0 +1,682 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchRestriction(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		sql     string
+		value   interface{}
+	}{
+		{MySQLDialect{"InnoDB", "UTF8"}, "match (body) against (? in natural language mode)", "golang orm"},
+		{PostgresDialect{}, "to_tsvector('english', body) @@ plainto_tsquery('english', ?)", "golang orm"},
+		{SqliteDialect{}, "body like ?", "%golang orm%"},
+	}
+
+	for _, test := range tests {
+		dbmap := registerTestModels(t, test.dialect, &searchArticle{})
+		criteria := newTestCriteria(dbmap, &searchArticle{})
+
+		cr := Restrictions.Search("Body", "golang orm")
+		if sql := cr.ToSqlString(criteria, dbmap); sql != test.sql {
+			t.Errorf("%T: expected %q, got %q", test.dialect, test.sql, sql)
+		}
+		if v := cr.GetValues(criteria, dbmap); len(v) != 1 || v[0] != test.value {
+			t.Errorf("%T: expected value %v, got %v", test.dialect, test.value, v)
+		}
+	}
+}
+
+func TestFullTextIndex(t *testing.T) {
+	if sql := (MySQLDialect{}).FullTextIndex("a_body_fts", "", "a", "body"); sql != "create fulltext index a_body_fts on `a` (`body`);" {
+		t.Errorf("unexpected mysql index: %s", sql)
+	}
+	if sql := (PostgresDialect{}).FullTextIndex("a_body_fts", "", "a", "body"); sql != `create index a_body_fts on "a" using gin (to_tsvector('english', "body"));` {
+		t.Errorf("unexpected postgres index: %s", sql)
+	}
+}
+
+func TestJSONRestrictions(t *testing.T) {
+	tests := []struct {
+		dialect   Dialect
+		criterion Criterion
+		sql       string
+		value     interface{}
+	}{
+		{PostgresDialect{}, Restrictions.JSONContains("Data", map[string]int{"a": 1}), `data @> ?`, `{"a":1}`},
+		{PostgresDialect{}, Restrictions.JSONHasKey("Data", "a"), `jsonb_exists(data, ?)`, "a"},
+		{PostgresDialect{}, Restrictions.JSONExtract("Data", "address.city", "Berlin"), `data#>>'{address,city}' = ?`, "Berlin"},
+		{PostgresDialect{}, Restrictions.JSONExtract("Data", "city", "Berlin"), `data->>'city' = ?`, "Berlin"},
+		{MySQLDialect{"InnoDB", "UTF8"}, Restrictions.JSONContains("Data", `["x"]`), `json_contains(data, ?)`, `["x"]`},
+		{MySQLDialect{"InnoDB", "UTF8"}, Restrictions.JSONHasKey("Data", "a"), `json_contains_path(data, 'one', concat('$.', ?))`, "a"},
+		{MySQLDialect{"InnoDB", "UTF8"}, Restrictions.JSONExtract("Data", "address.city", "Berlin"), `json_unquote(json_extract(data, '$.address.city')) = ?`, "Berlin"},
+	}
+
+	for _, test := range tests {
+		dbmap := registerTestModels(t, test.dialect, &jsonDocument{})
+		criteria := newTestCriteria(dbmap, &jsonDocument{})
+
+		if sql := test.criterion.ToSqlString(criteria, dbmap); sql != test.sql {
+			t.Errorf("%T: expected %q, got %q", test.dialect, test.sql, sql)
+		}
+		if v := test.criterion.GetValues(criteria, dbmap); len(v) != 1 || v[0] != test.value {
+			t.Errorf("%T: expected value %v, got %v", test.dialect, test.value, v)
+		}
+	}
+}
+
+func TestJSONExtractRejectsInjection(t *testing.T) {
+	dbmap := registerTestModels(t, PostgresDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.JSONExtract("Body", "a'); drop table x; --", "v"))
+	if _, _, err := criteria.SQL(); !isCriteriaError(err) {
+		t.Errorf("expected a *CriteriaError for an invalid json path, got %v", err)
+	}
+}
+
+// isCriteriaError reports whether err is a *CriteriaError.
+func isCriteriaError(err error) bool {
+	_, ok := err.(*CriteriaError)
+	return ok
+}
+
+func TestCriteriaErrors(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &searchArticle{}, &relAuthor{}, &relBook{})
+	defer Database().Set(nil)
+
+	detached := DetachedCriteriaFor(&relBook{}).Add(Restrictions.Eq("Missing", 1))
+	tests := map[string]Criterion{
+		"json":       Restrictions.JSONHasKey("Body", "a"),
+		"marshal":    Restrictions.JSONContains("Body", func() {}),
+		"array":      Restrictions.ArrayContains("Body", []string{"a"}),
+		"distance":   Restrictions.DistanceLte("Body", Point{}, 10),
+		"subquery":   Subqueries.PropertyIn("Id", detached),
+		"unattached": Subqueries.PropertyIn("Id", DetachedCriteriaFor(&scopedNote{})),
+		"example":    Example.Create(&scopedNote{TenantId: 1}),
+		"in":         Restrictions.In("Id", 1),
+	}
+	for name, criterion := range tests {
+		criteria := newTestCriteria(dbmap, &searchArticle{}).Add(criterion)
+		if _, err := criteria.List(); !isCriteriaError(err) {
+			t.Errorf("%s: expected a *CriteriaError, got %v", name, err)
+		}
+	}
+	var fe *FieldError
+	if _, _, err := newTestCriteria(dbmap, &relAuthor{}).Add(Subqueries.PropertyIn("Id", detached)).SQL(); !errors.As(err, &fe) {
+		t.Errorf("expected the *FieldError of the subquery, got %v", err)
+	}
+}
+
+func TestRandomAndSample(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		random  string
+		sample  string
+		args    int
+	}{
+		{PostgresDialect{}, "select * from search_article this_  order by  random() limit 5",
+			"select * from search_article this_ tablesample bernoulli (12.5)", 0},
+		{MySQLDialect{"InnoDB", "UTF8"}, "select * from search_article this_  order by  rand() limit 5",
+			"select * from search_article this_ where rand() < ?", 1},
+	}
+
+	for _, test := range tests {
+		dbmap := registerTestModels(t, test.dialect, &searchArticle{})
+
+		ct := CriteriaTranslator{criteria: newTestCriteria(dbmap, &searchArticle{}).Random(5), dbmap: dbmap}
+		s, _, err := ct.toSelect("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql := s.ToStatementString(); sql != test.random {
+			t.Errorf("%T: expected %q, got %q", test.dialect, test.random, sql)
+		}
+
+		ct = CriteriaTranslator{criteria: newTestCriteria(dbmap, &searchArticle{}).Sample(12.5), dbmap: dbmap}
+		s, args, err := ct.toSelect("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql := s.ToStatementString(); sql != test.sample || len(args) != test.args {
+			t.Errorf("%T: expected %q, got %q %v", test.dialect, test.sample, sql, args)
+		}
+	}
+
+	dbmap := registerTestModels(t, SqlServerDialect{}, &searchArticle{})
+	ct := CriteriaTranslator{criteria: newTestCriteria(dbmap, &searchArticle{}).Random(5), dbmap: dbmap}
+	if _, _, err := ct.toSelect("", nil); err == nil {
+		t.Error("expected an error for a dialect without random selection")
+	}
+}
+
+func TestRandomKeys(t *testing.T) {
+	keys := randomKeys(10, 1000, 30)
+	seen := map[interface{}]bool{}
+	for _, k := range keys {
+		if k.(int64) < 10 || k.(int64) > 1000 || seen[k] {
+			t.Fatalf("unexpected key %v in %v", k, keys)
+		}
+		seen[k] = true
+	}
+	if len(keys) != 30 {
+		t.Errorf("expected 30 keys, got %d", len(keys))
+	}
+	if keys := randomKeys(1, 3, 10); len(keys) != 3 {
+		t.Errorf("expected the whole range, got %v", keys)
+	}
+}
+
+func TestUpdateColValue(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.Like("Body", "go"))
+
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	query, args, err := ct.toUpdate(Params{"Id": ColValue(ColMinus, "50"), "Body": "orm"}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "update `search_article` set body = ?, id = id - ? where body  like  ?"; query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+	if len(args) != 3 || args[0] != "orm" || args[1] != int64(50) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	if _, _, err := ct.toUpdate(Params{"Missing": 1}, "", nil); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a non numeric value")
+		}
+	}()
+	ColValue(ColAdd, "abc")
+}
+
+func TestUpdateBatchChunk(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	mi, _ := modelCache.get("search_article")
+	key := integerKey(mi)
+	if key == nil || key.name != "Id" {
+		t.Fatalf("expected the Id key, got %v", key)
+	}
+
+	ct := CriteriaTranslator{criteria: newTestCriteria(dbmap, &searchArticle{}), dbmap: dbmap}
+	query, args, err := ct.toUpdate(Params{"Body": "x"}, "id >= ? and id < ?", []interface{}{1, 101})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "update `search_article` set body = ? where id >= ? and id < ?"; query != want || len(args) != 3 {
+		t.Errorf("expected %q, got %q %v", want, query, args)
+	}
+
+	if _, err := ct.UpdateBatch(Params{"Body": "x"}, 0); err == nil {
+		t.Error("expected an error for an empty chunk size")
+	}
+}
+
+func TestOrderedLimitedDelete(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		sql     string
+	}{
+		{MySQLDialect{"InnoDB", "UTF8"}, "delete from `search_article` where body  like  ? order by id desc limit 1000"},
+		{PostgresDialect{}, `delete from "search_article" where id in (select id from "search_article" where body  like  ?  order by  id desc limit 1000)`},
+	}
+	for _, test := range tests {
+		dbmap := registerTestModels(t, test.dialect, &searchArticle{})
+		criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.Like("Body", "go")).OrderBy("-Id").Limit(1000)
+
+		ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+		query, args, err := ct.toDelete()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != test.sql || len(args) != 1 {
+			t.Errorf("%T: expected %q, got %q %v", test.dialect, test.sql, query, args)
+		}
+
+		ct = CriteriaTranslator{criteria: newTestCriteria(dbmap, &searchArticle{}), dbmap: dbmap}
+		if query, _, _ := ct.toDelete(); query != "delete from "+test.dialect.QuotedTableForQuery("", "search_article") {
+			t.Errorf("%T: unexpected plain delete %q", test.dialect, query)
+		}
+
+		ct = CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+		s, _, err := ct.toSelect("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql := s.ToStatementString(); sql != "select * from search_article this_ where body  like  ?  order by  id desc limit 1000" {
+			t.Errorf("%T: unexpected select %q", test.dialect, sql)
+		}
+	}
+}
+
+func TestPaging(t *testing.T) {
+	tests := []struct {
+		dialect      Dialect
+		limit        int
+		sql          string
+		offsetDelete string
+	}{
+		{PostgresDialect{}, 20, "select * from search_article this_  order by  id limit 20 offset 40",
+			`delete from "search_article" where id in (select id from "search_article"  order by  id limit 20 offset 40)`},
+		{PostgresDialect{}, 0, "select * from search_article this_  order by  id offset 40", ""},
+		{SqliteDialect{}, 0, "select * from search_article this_  order by  id limit -1 offset 40", ""},
+		{MySQLDialect{"InnoDB", "UTF8"}, 20, "select * from search_article this_  order by  id limit 20 offset 40",
+			"delete from `search_article` where id in (select id from (select id from `search_article`  order by  id limit 20 offset 40) t_)"},
+		{MySQLDialect{"InnoDB", "UTF8"}, 0, "select * from search_article this_  order by  id limit 18446744073709551615 offset 40", ""},
+		{SqlServerDialect{}, 20, "select * from search_article this_ order by id offset 40 rows fetch next 20 rows only", ""},
+		{OracleDialect{}, 20, "select * from search_article this_ order by id offset 40 rows fetch next 20 rows only", ""},
+	}
+	for _, test := range tests {
+		dbmap := registerTestModels(t, test.dialect, &searchArticle{})
+		criteria := newTestCriteria(dbmap, &searchArticle{}).AddOrder(Order.Asc("Id")).SetFirstResult(40).SetMaxResults(test.limit)
+		if criteria.GetFirstResult() != 40 || criteria.GetMaxResults() != test.limit {
+			t.Errorf("%T: unexpected paging %d %d", test.dialect, criteria.GetFirstResult(), criteria.GetMaxResults())
+		}
+
+		ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+		s, _, err := ct.toSelect("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql := s.ToStatementString(); sql != test.sql {
+			t.Errorf("%T: expected %q, got %q", test.dialect, test.sql, sql)
+		}
+		if test.offsetDelete != "" {
+			if query, _, _ := ct.toDelete(); query != test.offsetDelete {
+				t.Errorf("%T: expected %q, got %q", test.dialect, test.offsetDelete, query)
+			}
+		}
+	}
+
+	// unordered pages on sql server, and first pages on oracle
+	dbmap := registerTestModels(t, SqlServerDialect{}, &searchArticle{})
+	criteria := newTestCriteria(dbmap, &searchArticle{}).SetMaxResults(5)
+	if sql, _, _ := criteria.SQL(); sql != "select * from search_article this_ order by (select null) offset 0 rows fetch next 5 rows only" {
+		t.Errorf("unexpected sql server page %q", sql)
+	}
+	dbmap = registerTestModels(t, OracleDialect{}, &searchArticle{})
+	criteria = newTestCriteria(dbmap, &searchArticle{}).AddOrder(Order.Desc("Id")).SetMaxResults(5)
+	if sql, _, _ := criteria.SQL(); sql != "select * from (select * from search_article this_ order by id desc) where rownum <= 5" {
+		t.Errorf("unexpected oracle page %q", sql)
+	}
+	Database().Set(nil)
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		analyze bool
+		want    string
+	}{
+		{PostgresDialect{}, true, "explain analyze select * from search_article this_ where body  like  ?"},
+		{MySQLDialect{"InnoDB", "UTF8"}, false, "explain select * from search_article this_ where body  like  ?"},
+		{SqliteDialect{}, false, "explain query plan select * from search_article this_ where body  like  ?"},
+		{SqliteDialect{}, true, ""},
+	}
+
+	for _, test := range tests {
+		dbmap := registerTestModels(t, test.dialect, &searchArticle{})
+		criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.Like("Body", "go"))
+
+		ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+		query, args, err := ct.toExplain(test.analyze)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%T: expected an error for explain analyze", test.dialect)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != test.want || len(args) != 1 {
+			t.Errorf("%T: expected %q, got %q %v", test.dialect, test.want, query, args)
+		}
+	}
+}
+
+// tempInDriver records the statements executed and the ends of their
+// transactions.
+var tempInDriver = &fakeDriver{
+	commit:   func() { recordTempIn("commit") },
+	rollback: func() { recordTempIn("rollback") },
+	exec: func(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
+		recordTempIn(query)
+		return fakeResult{}, nil
+	},
+}
+
+var tempInStatements []string
+
+func recordTempIn(statement string) {
+	tempInStatements = append(tempInStatements, strings.Fields(statement)[0])
+}
+
+func TestTempInTables(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	defer Database().Set(nil)
+	dbmap.Db = tempInDriver.open(t)
+	dbmap.InListLimit = 2
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.In("Id", []int64{1, 2, 3}))
+
+	// dropped before the end of the transaction
+	tempInStatements = nil
+	if _, err := criteria.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"create", "insert", "insert", "delete", "drop", "commit"}; !reflect.DeepEqual(tempInStatements, want) {
+		t.Errorf("expected %v, got %v", want, tempInStatements)
+	}
+
+	// in the transaction of the criteria
+	tx, err := dbmap.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tempInStatements = nil
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap, exec: tx}
+	if _, err = ct.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"create", "insert", "insert", "delete", "drop"}; !reflect.DeepEqual(tempInStatements, want) {
+		t.Errorf("expected %v, got %v", want, tempInStatements)
+	}
+	tx.Rollback()
+}
+
+func TestInRestriction(t *testing.T) {
+	ids := []int64{1, 2, 3}
+
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.In("Id", ids)).Add(Restrictions.Like("Body", "go"))
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	s, args, err := ct.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select * from search_article this_ where id in (?,?,?) and body  like  ?"; s.ToStatementString() != want {
+		t.Errorf("expected %q, got %q", want, s.ToStatementString())
+	}
+	if len(args) != 4 || args[2] != int64(3) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	// loaded into a temporary table
+	ct.tempIn = map[Criterion]Criterion{criteria.GetCriterions()[0]: tempInExpression{fieldName: "Id", table: "orm_in_1"}}
+	if s, args, _ = ct.toSelect("", nil); s.whereClause != "id in (select v from `orm_in_1`) and body  like  ?" || len(args) != 1 {
+		t.Errorf("unexpected temporary table join %q %v", s.whereClause, args)
+	}
+
+	// bound as an array
+	dbmap = registerTestModels(t, PostgresDialect{}, &searchArticle{})
+	dbmap.InListLimit = 2
+	criteria = newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.In("Id", ids))
+	ct = CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	if s, args, _ = ct.toSelect("", nil); s.whereClause != "id = any(?)" || len(args) != 1 {
+		t.Errorf("unexpected array binding %q %v", s.whereClause, args)
+	}
+}
+
+func TestComparisonRestrictions(t *testing.T) {
+	tests := []struct {
+		dialect   Dialect
+		criterion Criterion
+		sql       string
+		values    []interface{}
+	}{
+		{SqliteDialect{}, Restrictions.Ne("Id", 1), "id <> ?", []interface{}{1}},
+		{SqliteDialect{}, Restrictions.Gt("Id", 1), "id > ?", []interface{}{1}},
+		{SqliteDialect{}, Restrictions.Ge("Id", 1), "id >= ?", []interface{}{1}},
+		{SqliteDialect{}, Restrictions.Lt("Id", 1), "id < ?", []interface{}{1}},
+		{SqliteDialect{}, Restrictions.Le("Id", 1), "id <= ?", []interface{}{1}},
+		{SqliteDialect{}, Restrictions.Between("Id", 1, 9), "id between ? and ?", []interface{}{1, 9}},
+		{SqliteDialect{}, Restrictions.IsNotNull("Body"), "body is not null", nil},
+		{SqliteDialect{}, Restrictions.NotIn("Id", []int{1, 2}), "id not in (?,?)", []interface{}{1, 2}},
+		{SqliteDialect{}, Restrictions.NotIn("Id", []int{}), "1 = 1", nil},
+		{SqliteDialect{}, Restrictions.Ilike("Body", "Go"), "lower(body) like lower(?)", []interface{}{"%Go%"}},
+		{PostgresDialect{}, Restrictions.Ilike("Body", "Go"), "body ilike ?", []interface{}{"%Go%"}},
+		{SqliteDialect{}, Restrictions.EqProperty("Id", "Body"), "id = body", nil},
+		{SqliteDialect{}, Restrictions.NeProperty("Id", "Body"), "id <> body", nil},
+		{SqliteDialect{}, Restrictions.GtProperty("Id", "Body"), "id > body", nil},
+		{SqliteDialect{}, Restrictions.GeProperty("Id", "Body"), "id >= body", nil},
+		{SqliteDialect{}, Restrictions.LtProperty("Id", "Body"), "id < body", nil},
+		{SqliteDialect{}, Restrictions.LeProperty("Id", "Body"), "id <= body", nil},
+		{SqliteDialect{}, Restrictions.Like("Body", "Go", MatchStart), "body  like  ?", []interface{}{"Go%"}},
+		{SqliteDialect{}, Restrictions.Like("Body", "Go", MatchEnd), "body  like  ?", []interface{}{"%Go"}},
+		{SqliteDialect{}, Restrictions.Like("Body", "Go", MatchExact), "body  like  ?", []interface{}{"Go"}},
+		{SqliteDialect{}, Restrictions.Like("Body", "100%_!"), "body  like  ? escape '!'", []interface{}{"%100!%!_!!%"}},
+		{PostgresDialect{}, Restrictions.Ilike("Body", "a_b", MatchStart), "body ilike ? escape '!'", []interface{}{"a!_b%"}},
+		{SqliteDialect{}, Restrictions.Like("Body", "[a]"), "body  like  ?", []interface{}{"%[a]%"}},
+		{SqlServerDialect{}, Restrictions.Like("Body", "[a]"), "body  like  ? escape '!'", []interface{}{"%![a]%"}},
+		{PostgresDialect{}, Restrictions.Like("Body", `C:\dir`, MatchExact), "body  like  ? escape '!'", []interface{}{`C:!\dir`}},
+		{MySQLDialect{}, Restrictions.Like("Body", `a\_`), "body  like  ? escape '!'", []interface{}{`%a!\!_%`}},
+	}
+
+	for _, test := range tests {
+		dbmap := registerTestModels(t, test.dialect, &searchArticle{})
+		criteria := newTestCriteria(dbmap, &searchArticle{})
+
+		if sql := test.criterion.ToSqlString(criteria, dbmap); sql != test.sql {
+			t.Errorf("%T: expected %q, got %q", test.dialect, test.sql, sql)
+		}
+		if values := test.criterion.GetValues(criteria, dbmap); !reflect.DeepEqual(values, test.values) && len(values)+len(test.values) > 0 {
+			t.Errorf("%T: expected values %v, got %v", test.dialect, test.values, values)
+		}
+	}
+
+	// negated array binding and temporary table join
+	dbmap := registerTestModels(t, PostgresDialect{}, &searchArticle{})
+	dbmap.InListLimit = 1
+	criteria := newTestCriteria(dbmap, &searchArticle{})
+	if sql := Restrictions.NotIn("Id", []int64{1, 2}).ToSqlString(criteria, dbmap); sql != "not (id = any(?))" {
+		t.Errorf("unexpected negated array binding %q", sql)
+	}
+	if sql := (tempInExpression{fieldName: "Id", table: "orm_in_1", not: true}).ToSqlString(criteria, dbmap); sql != `id not in (select v from "orm_in_1")` {
+		t.Errorf("unexpected negated temporary table join %q", sql)
+	}
+}
+
+func TestJunctions(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).
+		Add(Restrictions.Or(
+			Restrictions.Eq("Body", "a"),
+			Restrictions.And(Restrictions.Gt("Id", 1), Restrictions.In("Id", []int{2, 3})),
+		)).
+		Add(Restrictions.IsNotNull("Body"))
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	s, args, err := ct.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(body = ? or (id > ? and id in (?,?))) and body is not null"; s.whereClause != want {
+		t.Errorf("expected %q, got %q", want, s.whereClause)
+	}
+	if want := []interface{}{"a", 1, 2, 3}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, got %v", want, args)
+	}
+
+	// empty junctions
+	criteria = newTestCriteria(dbmap, &searchArticle{})
+	if sql := Restrictions.Conjunction().ToSqlString(criteria, dbmap); sql != "1 = 1" {
+		t.Errorf("unexpected empty conjunction %q", sql)
+	}
+	if sql := Restrictions.Disjunction().ToSqlString(criteria, dbmap); sql != "1 = 0" {
+		t.Errorf("unexpected empty disjunction %q", sql)
+	}
+	not := Restrictions.Not(Restrictions.Or(Restrictions.Between("Id", 1, 9), Restrictions.Like("Body", "go")))
+	if sql := not.ToSqlString(criteria, dbmap); sql != "not ((id between ? and ? or body  like  ?))" {
+		t.Errorf("unexpected negation %q", sql)
+	}
+	if values := not.GetValues(criteria, dbmap); !reflect.DeepEqual(values, []interface{}{1, 9, "%go%"}) {
+		t.Errorf("unexpected negation values %v", values)
+	}
+	or := Restrictions.Disjunction().Add(Restrictions.IsNull("Body"))
+	if sql := or.Add(Restrictions.Lt("Id", 5)).ToSqlString(criteria, dbmap); sql != "(body is null or id < ?)" {
+		t.Errorf("unexpected disjunction %q", sql)
+	}
+}
+
+func TestAddOrder(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).AddOrder(Order.Desc("Body")).AddOrder(Order.Asc("Id"))
+	s, _, err := CriteriaTranslator{criteria: criteria, dbmap: dbmap}.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "body desc, id"; s.orderByClause != want {
+		t.Errorf("expected %q, got %q", want, s.orderByClause)
+	}
+	if _, _, err = criteria.AddOrder(Order.Asc("Title")).SQL(); err == nil {
+		t.Error("expected an error ordering by an unknown field")
+	}
+}
+
+func TestUnknownFieldErrors(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.Like("Bdy", "go"))
+	_, _, err := criteria.SQL()
+	if fe, ok := err.(*FieldError); !ok || fe.Field != "Bdy" {
+		t.Errorf("expected a *FieldError for the restriction, got %v", err)
+	}
+	if _, err := criteria.Delete(); err == nil {
+		t.Error("expected the delete to fail")
+	}
+
+	_, _, err = newTestCriteria(dbmap, &searchArticle{}).OrderBy("-Title").SQL()
+	if _, ok := err.(*FieldError); !ok {
+		t.Errorf("expected a *FieldError for the order, got %v", err)
+	}
+
+	tmap, _ := dbmap.TableFor(reflect.TypeOf(searchArticle{}))
+	if _, err := tmap.ColMapE("Title"); err == nil {
+		t.Error("expected ColMapE to fail")
+	}
+}
+
+func TestExample(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &relAuthor{}, &exampleUser{})
+	defer Database().Set(nil)
+
+	sample := &exampleUser{Id: 7, Name: "Ann", Age: 30, Author: &relAuthor{Id: 1}}
+	criteria := newTestCriteria(dbmap, &exampleUser{}).Add(Example.Create(sample))
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	s, args, err := ct.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(name = ? and age = ?)"; s.whereClause != want {
+		t.Errorf("expected %q, got %q", want, s.whereClause)
+	}
+	if want := []interface{}{"Ann", 30}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, got %v", want, args)
+	}
+
+	// like matching, excluded fields
+	example := Example.Create(sample).ExcludeProperty("Age").EnableLike(MatchStart)
+	ct.criteria = newTestCriteria(dbmap, &exampleUser{}).Add(example)
+	s, args, err = ct.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(name like ?)"; s.whereClause != want {
+		t.Errorf("expected %q, got %q", want, s.whereClause)
+	}
+	if want := []interface{}{"Ann%"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, got %v", want, args)
+	}
+	if sql := Example.Create(&exampleUser{}).ToSqlString(criteria, dbmap); sql != "1 = 1" {
+		t.Errorf("unexpected empty example %q", sql)
+	}
+}
+
+func TestSqlRestriction(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &relAuthor{}, &relBook{})
+	defer Database().Set(nil)
+
+	criteria := newTestCriteria(dbmap, &relBook{}).
+		Add(Restrictions.Sql("{alias}.id % ? = ?", 2, 0)).
+		CreateCriteria("Author").
+		Add(Restrictions.Sql("lower({alias}.name) = lower(?)", "Ann"))
+	ct := CriteriaTranslator{criteria: criteria.(criteriaImpl).root(), dbmap: dbmap}
+	s, args, err := ct.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(this_.id % ? = ?) and (lower(author_.name) = lower(?))"; s.whereClause != want {
+		t.Errorf("expected %q, got %q", want, s.whereClause)
+	}
+	if want := []interface{}{2, 0, "Ann"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, got %v", want, args)
+	}
+}
+
+func TestCountAndExists(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Add(Restrictions.Eq("Body", "a")).OrderBy("Id")
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	query, args, err := ct.toCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select count(*) from (select * from search_article this_ where body = ?) t_"; query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+	if want := []interface{}{"a"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, got %v", want, args)
+	}
+
+	// pages keep their order
+	ct.criteria = criteria.Limit(10)
+	if query, _, _ = ct.toCount(); query != "select count(*) from (select * from search_article this_ where body = ?  order by  id limit 10) t_" {
+		t.Errorf("unexpected paged count %q", query)
+	}
+
+	if query, _, _ = ct.toExists(); query != "select 1 from search_article this_ where body = ? limit 1" {
+		t.Errorf("unexpected exists %q", query)
+	}
+	exists, err := criteria.Exists()
+	if err != nil || !exists {
+		t.Errorf("expected a row, got %v %v", exists, err)
+	}
+}
+
+func TestConditionValuesFlattened(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).
+		Add(Restrictions.Between("Id", 1, 9)).
+		Add(Restrictions.In("Id", []int64{2, 3})).
+		Add(Restrictions.IsNull("Body")).
+		Add(Restrictions.Eq("Body", "a"))
+	ct := CriteriaTranslator{criteria: criteria, dbmap: dbmap}
+	s, args, err := ct.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "id between ? and ? and id in (?,?) and body is null and body = ?"; s.whereClause != want {
+		t.Errorf("expected %q, got %q", want, s.whereClause)
+	}
+	if want := []interface{}{1, 9, int64(2), int64(3), "a"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, got %v", want, args)
+	}
+}
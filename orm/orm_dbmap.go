@@ -3,12 +3,12 @@ package orm
 //getSQLAlias
 func (m *DbMap) getFieldSQLAlias(criteria Criteria, fieldName string) string {
 
-	tmap, err := m.TableFor(criteria.GetEntityType(), true)
+	tmap, err := m.TableFor(criteria.GetEntityType())
 
 	if err != nil {
 
 	} else {
-		cls, d := tmap.GetByAny(fieldName)
+		cls, d := m.getByAny(tmap, fieldName)
 		if d {
 			return criteria.GetAlias() + "_" + "." + cls.column
 		}
@@ -18,7 +18,7 @@ func (m *DbMap) getFieldSQLAlias(criteria Criteria, fieldName string) string {
 
 func (m *DbMap) getObjectSQLAlias(criteria Criteria) string {
 
-	tmap, err := m.TableFor(criteria.GetEntityType(), true)
+	tmap, err := m.TableFor(criteria.GetEntityType())
 
 	if err != nil {
 
@@ -29,18 +29,38 @@ func (m *DbMap) getObjectSQLAlias(criteria Criteria) string {
 	return ""
 }
 
+// findColumns returns the columns of fieldName in the model of criteria,
+// for criterions to render.  It panics with a *FieldError if the model has
+// no such field, which CriteriaTranslator returns as an error.
 func (m *DbMap) findColumns(criteria Criteria, fieldName string) []string {
-	columns := make([]string, 0)
-
-	tmap, err := m.TableFor(criteria.GetEntityType(), true)
+	columns, err := m.findColumnsE(criteria, fieldName)
+	if err != nil {
+		panic(err)
+	}
+	return columns
+}
 
+// findColumnsE is findColumns failing with a *FieldError, or the error of
+// the model lookup, instead of panicking.
+func (m *DbMap) findColumnsE(criteria Criteria, fieldName string) ([]string, error) {
+	criteria, fieldName = aliasedField(criteria, fieldName)
+	tmap, err := m.TableFor(criteria.GetEntityType())
 	if err != nil {
+		return nil, err
+	}
+	cls, ok := m.getByAny(tmap, fieldName)
+	if !ok {
+		return nil, &FieldError{TypeName: tmap.fullName, Field: fieldName}
+	}
+	return []string{qualify(criteria, cls.column)}, nil
+}
 
-	} else {
-		cls, d := tmap.GetByAny(fieldName)
-		if d {
-			columns = append(columns, cls.column)
-		}
+// getByAny returns the field of tmap named name, see modelInfo.GetByAny,
+// or else the one named by the mapper of m, see SetMapperFunc.
+func (m *DbMap) getByAny(tmap *modelInfo, name string) (*fieldInfo, bool) {
+	fi, ok := tmap.GetByAny(name)
+	if !ok && m.mapper != nil {
+		fi, ok = tmap.fields.GetByAny(m.mapper(name))
 	}
-	return columns
+	return fi, ok
 }
@@ -0,0 +1,34 @@
+package orm
+
+var (
+	Order = OrderFactory{}
+)
+
+// OrderFactory builds the orderings of Criteria.AddOrder, see Order.
+type OrderFactory struct{}
+
+// Asc sorts by the field, smallest first.
+func (o OrderFactory) Asc(fieldName string) Ordering {
+	return Ordering{fieldName: fieldName}
+}
+
+// Desc sorts by the field, largest first.
+func (o OrderFactory) Desc(fieldName string) Ordering {
+	return Ordering{fieldName: fieldName, desc: true}
+}
+
+// Ordering sorts the results of a criteria by a field, see Order.Asc and
+// Order.Desc.
+type Ordering struct {
+	fieldName string
+	desc      bool
+}
+
+// String returns the field name of the ordering as OrderBy takes it,
+// prefixed with "-" when descending.
+func (o Ordering) String() string {
+	if o.desc {
+		return "-" + o.fieldName
+	}
+	return o.fieldName
+}
@@ -0,0 +1,48 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Policy returns the criterion restricting the rows of a model that
+// principal, typically the current user, may access.  A nil criterion
+// grants access to every row.
+type Policy func(principal interface{}) Criterion
+
+// RegisterPolicy adds a row-level security policy to a registered model.
+// Criteria scoped with Criteria.For embed the criterions of every policy
+// of the model in their where clause, so authorization is resolved by
+// the database and counts and pages only ever cover accessible rows.
+func RegisterPolicy(model interface{}, policy Policy) {
+	typ := reflect.Indirect(reflect.ValueOf(model)).Type()
+	mi, ok := modelCache.getByFullName(getFullName(typ))
+	if !ok {
+		panic(fmt.Errorf("<orm.RegisterPolicy> model `%s` is not registered", getFullName(typ)))
+	}
+	mi.policies = append(mi.policies, policy)
+}
+
+// policyCriterions returns the criterions of the policies of the root
+// model of criteria, when it is scoped to a principal.  Partials follow
+// the policies of their model.
+func policyCriterions(criteria Criteria, dbmap *DbMap) ([]Criterion, error) {
+	principal, ok := criteria.GetPrincipal()
+	if !ok {
+		return nil, nil
+	}
+	tmap, err := dbmap.TableFor(criteria.GetEntityType())
+	if err != nil {
+		return nil, err
+	}
+	if tmap.partialOf != nil {
+		tmap = tmap.partialOf
+	}
+	criterions := make([]Criterion, 0, len(tmap.policies))
+	for _, policy := range tmap.policies {
+		if cr := policy(principal); cr != nil {
+			criterions = append(criterions, cr)
+		}
+	}
+	return criterions, nil
+}
@@ -0,0 +1,45 @@
+package orm
+
+import "testing"
+
+func TestPolicyCriterions(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	RegisterPolicy(&searchArticle{}, func(principal interface{}) Criterion {
+		if principal == "admin" {
+			return nil
+		}
+		return Restrictions.Like("Body", principal.(string))
+	})
+
+	criteria := newTestCriteria(dbmap, &searchArticle{})
+	tests := []struct {
+		criteria Criteria
+		sql      string
+		args     int
+	}{
+		{criteria, "select * from search_article this_", 0},
+		{criteria.For("admin"), "select * from search_article this_", 0},
+		{criteria.For("bob"), "select * from search_article this_ where body  like  ?", 1},
+		{criteria.Add(Restrictions.Sql("{alias}.id = ? or {alias}.id = ?", 1, 2)).For("bob"),
+			"select * from search_article this_ where (this_.id = ? or this_.id = ?) and body  like  ?", 3},
+	}
+	for _, test := range tests {
+		ct := CriteriaTranslator{criteria: test.criteria, dbmap: dbmap}
+		s, args, err := ct.toSelect("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sql := s.ToStatementString(); sql != test.sql || len(args) != test.args {
+			t.Errorf("expected %q, got %q %v", test.sql, sql, args)
+		}
+	}
+
+	ct := CriteriaTranslator{criteria: criteria.For("bob"), dbmap: dbmap}
+	query, _, err := ct.toUpdate(Params{"Body": "x"}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "update `search_article` set body = ? where body  like  ?"; query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+}
@@ -1,5 +1,327 @@
 package orm
 
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Projection is the select clause of a criteria, set by SetProjection.
+// Built-in projections are provided by the Projections factory.  The
+// projected criteria list one value per row when the projection has one
+// column, eg a count, or else a []interface{} of the columns of the row.
 type Projection interface {
 	ToSqlString(criteria Criteria, position int, dbMap *DbMap) string
 }
+
+// GroupedProjection is implemented by the projections grouping the rows,
+// eg GroupProperty, which return the group by clause of the criteria.
+type GroupedProjection interface {
+	Projection
+	ToGroupSqlString(criteria Criteria, dbMap *DbMap) string
+}
+
+var (
+	Projections = ProjectionFactory{}
+)
+
+// ProjectionFactory builds the projections, see Projections.
+type ProjectionFactory struct{}
+
+// RowCount counts the rows.
+func (p ProjectionFactory) RowCount() Projection {
+	return &aggregateProjection{function: "count"}
+}
+
+// Count counts the rows whose field isn't null.
+func (p ProjectionFactory) Count(fieldName string) Projection {
+	return &aggregateProjection{function: "count", fieldName: fieldName}
+}
+
+// CountDistinct counts the distinct values of the field.
+func (p ProjectionFactory) CountDistinct(fieldName string) Projection {
+	return &aggregateProjection{function: "count", fieldName: fieldName, distinct: true}
+}
+
+// Sum adds up the values of the field.
+func (p ProjectionFactory) Sum(fieldName string) Projection {
+	return &aggregateProjection{function: "sum", fieldName: fieldName}
+}
+
+// Avg averages the values of the field.
+func (p ProjectionFactory) Avg(fieldName string) Projection {
+	return &aggregateProjection{function: "avg", fieldName: fieldName}
+}
+
+// Min returns the smallest value of the field.
+func (p ProjectionFactory) Min(fieldName string) Projection {
+	return &aggregateProjection{function: "min", fieldName: fieldName}
+}
+
+// Max returns the largest value of the field.
+func (p ProjectionFactory) Max(fieldName string) Projection {
+	return &aggregateProjection{function: "max", fieldName: fieldName}
+}
+
+// Property selects the field.
+func (p ProjectionFactory) Property(fieldName string) Projection {
+	return &propertyProjection{fieldName: fieldName}
+}
+
+// GroupProperty selects the field and groups the rows by it, eg
+//
+//	Projections.ProjectionList().
+//		Add(Projections.GroupProperty("Status")).
+//		Add(Projections.RowCount())
+//
+// counts the rows of each status.
+func (p ProjectionFactory) GroupProperty(fieldName string) Projection {
+	return &propertyProjection{fieldName: fieldName, grouped: true}
+}
+
+// Distinct removes the duplicate rows of projection, eg
+//
+//	Projections.Distinct(Projections.Property("City"))
+//
+// lists each city once.  It wraps the whole projection, eg a list.
+func (p ProjectionFactory) Distinct(projection Projection) Projection {
+	return &distinctProjection{projection: projection}
+}
+
+// Alias names the column of projection alias, eg to fill the fields of
+// Transformers.AliasToStruct.
+func (p ProjectionFactory) Alias(projection Projection, alias string) Projection {
+	return &aliasedProjection{projection: projection, alias: alias}
+}
+
+// ProjectionList returns an empty list of projections, selected one after
+// the other.
+func (p ProjectionFactory) ProjectionList() *ProjectionList {
+	return &ProjectionList{}
+}
+
+// aggregateProjection aggregate function of a field, or of the rows
+type aggregateProjection struct {
+	function  string
+	fieldName string
+	distinct  bool
+}
+
+func (a aggregateProjection) ToSqlString(criteria Criteria, position int, dbMap *DbMap) string {
+	if a.fieldName == "" {
+		return a.function + "(*)"
+	}
+	cols := dbMap.findColumns(criteria, a.fieldName)
+	if a.distinct {
+		return a.function + "(distinct " + cols[0] + ")"
+	}
+	return a.function + "(" + cols[0] + ")"
+}
+
+// propertyProjection field projection, grouping the rows when grouped
+type propertyProjection struct {
+	fieldName string
+	grouped   bool
+}
+
+func (p propertyProjection) ToSqlString(criteria Criteria, position int, dbMap *DbMap) string {
+	return dbMap.findColumns(criteria, p.fieldName)[0]
+}
+
+func (p propertyProjection) ToGroupSqlString(criteria Criteria, dbMap *DbMap) string {
+	if !p.grouped {
+		return ""
+	}
+	return dbMap.findColumns(criteria, p.fieldName)[0]
+}
+
+// distinctProjection projection selecting distinct rows
+type distinctProjection struct {
+	projection Projection
+}
+
+func (d distinctProjection) ToSqlString(criteria Criteria, position int, dbMap *DbMap) string {
+	return "distinct " + d.projection.ToSqlString(criteria, position, dbMap)
+}
+
+func (d distinctProjection) ToGroupSqlString(criteria Criteria, dbMap *DbMap) string {
+	if g, ok := d.projection.(GroupedProjection); ok {
+		return g.ToGroupSqlString(criteria, dbMap)
+	}
+	return ""
+}
+
+// aliasedProjection projection naming its column
+type aliasedProjection struct {
+	projection Projection
+	alias      string
+}
+
+func (a aliasedProjection) ToSqlString(criteria Criteria, position int, dbMap *DbMap) string {
+	return a.projection.ToSqlString(criteria, position, dbMap) + " as " + dbMap.Dialect.QuoteField(a.alias)
+}
+
+func (a aliasedProjection) ToGroupSqlString(criteria Criteria, dbMap *DbMap) string {
+	if g, ok := a.projection.(GroupedProjection); ok {
+		return g.ToGroupSqlString(criteria, dbMap)
+	}
+	return ""
+}
+
+// ProjectionList selects several projections, see
+// Projections.ProjectionList.
+type ProjectionList struct {
+	projections []Projection
+}
+
+// Add appends projections to the list, returning it.
+func (l *ProjectionList) Add(projections ...Projection) *ProjectionList {
+	l.projections = append(l.projections, projections...)
+	return l
+}
+
+// Len returns the number of columns of the list.
+func (l *ProjectionList) Len() int {
+	n := 0
+	for _, p := range l.projections {
+		n += projectionLen(p)
+	}
+	return n
+}
+
+func (l *ProjectionList) ToSqlString(criteria Criteria, position int, dbMap *DbMap) string {
+	columns := make([]string, len(l.projections))
+	for i, p := range l.projections {
+		columns[i] = p.ToSqlString(criteria, position, dbMap)
+		position += projectionLen(p)
+	}
+	return strings.Join(columns, ", ")
+}
+
+func (l *ProjectionList) ToGroupSqlString(criteria Criteria, dbMap *DbMap) string {
+	var groups []string
+	for _, p := range l.projections {
+		if g, ok := p.(GroupedProjection); ok {
+			if group := g.ToGroupSqlString(criteria, dbMap); group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return strings.Join(groups, ", ")
+}
+
+// projectionLen returns the number of columns p selects.
+func projectionLen(p Projection) int {
+	switch p := p.(type) {
+	case *ProjectionList:
+		return p.Len()
+	case *distinctProjection:
+		return projectionLen(p.projection)
+	}
+	return 1
+}
+
+// projection renders the select and group by clauses of the projection
+// of the criteria.  It fails with the *FieldError of a projection naming
+// an unknown field.
+func (ct CriteriaTranslator) projection() (selectClause, groupBy string, err error) {
+	defer recoverCriteriaError(&err)
+	p := ct.criteria.GetProjection()
+	if projectionLen(p) == 0 {
+		return "", "", fmt.Errorf("<Criteria.SetProjection> empty projection list")
+	}
+	selectClause = p.ToSqlString(ct.criteria, 0, ct.dbmap)
+	if g, ok := p.(GroupedProjection); ok {
+		groupBy = g.ToGroupSqlString(ct.criteria, ct.dbmap)
+	}
+	return selectClause, groupBy, nil
+}
+
+// listProjected runs the select statement of a projected criteria,
+// returning the value of each row when the projection has one column, or
+// else the []interface{} of its columns, or the results of its
+// transformer when set.
+func (ct CriteriaTranslator) listProjected(query string, args []interface{}) ([]interface{}, error) {
+	rows, done, err := queryRows(ct.executor(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	transformer := ct.criteria.GetResultTransformer()
+	list := make([]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		// the drivers return the text columns as bytes
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		if transformer != nil {
+			result, err := transformer.TransformTuple(values, columns)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, result)
+		} else if len(columns) == 1 {
+			list = append(list, values[0])
+		} else {
+			list = append(list, values)
+		}
+	}
+	return list, rows.Err()
+}
+
+// parseText parses value into a typ, when value is the text or the bytes
+// scanned from a column, eg a MySQL decimal or sum, and typ a number, a
+// bool, text or bytes.  It reports false for the other values and types.
+func parseText(value interface{}, typ reflect.Type) (reflect.Value, bool, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return reflect.Value{}, false, nil
+	}
+	r := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		r.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return r, true, err
+		}
+		r.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if err := StrTo(s).setTo(r); err != nil {
+			return r, true, err
+		}
+	case reflect.Slice:
+		if typ.Elem().Kind() != reflect.Uint8 {
+			return reflect.Value{}, false, nil
+		}
+		r.SetBytes([]byte(s))
+	default:
+		return reflect.Value{}, false, nil
+	}
+	return r, true, nil
+}
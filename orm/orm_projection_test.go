@@ -0,0 +1,201 @@
+package orm
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestProjections(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &searchArticle{})
+	defer Database().Set(nil)
+
+	tests := []struct {
+		projection Projection
+		selects    string
+		groupBy    string
+	}{
+		{Projections.RowCount(), "count(*)", ""},
+		{Projections.Count("Body"), "count(body)", ""},
+		{Projections.CountDistinct("Body"), "count(distinct body)", ""},
+		{Projections.Sum("Id"), "sum(id)", ""},
+		{Projections.Avg("Id"), "avg(id)", ""},
+		{Projections.Min("Id"), "min(id)", ""},
+		{Projections.Max("Id"), "max(id)", ""},
+		{Projections.GroupProperty("Body"), "body", "body"},
+		{Projections.ProjectionList().Add(Projections.GroupProperty("Body"), Projections.Property("Id"), Projections.RowCount()), "body, id, count(*)", "body"},
+		{Projections.Distinct(Projections.Property("Body")), "distinct body", ""},
+		{Projections.Distinct(Projections.ProjectionList().Add(Projections.GroupProperty("Body"), Projections.RowCount())), "distinct body, count(*)", "body"},
+	}
+	for _, test := range tests {
+		criteria := newTestCriteria(dbmap, &searchArticle{}).SetProjection(test.projection)
+		s, _, err := CriteriaTranslator{criteria: criteria, dbmap: dbmap}.toSelect("", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.selectClause != test.selects || s.groupByClause != test.groupBy {
+			t.Errorf("expected select %q group by %q, got %q and %q", test.selects, test.groupBy, s.selectClause, s.groupByClause)
+		}
+	}
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).SetProjection(Projections.Sum("Missing"))
+	if _, _, err := (CriteriaTranslator{criteria: criteria, dbmap: dbmap}).toSelect("", nil); err == nil {
+		t.Error("expected an error projecting an unknown field")
+	}
+	criteria = newTestCriteria(dbmap, &searchArticle{}).SetProjection(Projections.ProjectionList())
+	if _, _, err := (CriteriaTranslator{criteria: criteria, dbmap: dbmap}).toSelect("", nil); err == nil {
+		t.Error("expected an error for an empty projection list")
+	}
+}
+
+func TestProjectedResults(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).
+		SetProjection(Projections.ProjectionList().Add(Projections.GroupProperty("Body"), Projections.RowCount())).
+		Limit(2)
+	list, err := criteria.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		[]interface{}{int64(1), "body 1", "ignored"},
+		[]interface{}{int64(2), "body 2", "ignored"},
+	}
+	if !reflect.DeepEqual(list, want) {
+		t.Errorf("expected %v, got %v", want, list)
+	}
+	if first := criteria.UniqueResult(); !reflect.DeepEqual(first, want[0]) {
+		t.Errorf("expected %v, got %v", want[0], first)
+	}
+	if none := criteria.Limit(0).UniqueResult(); none != nil {
+		t.Errorf("expected no result, got %v", none)
+	}
+}
+
+func TestFill(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+
+	var articles []*searchArticle
+	criteria := criteriaFor(dbmap, &articles)
+	if criteria == nil || criteria.GetEntityType() != reflect.TypeOf(searchArticle{}) {
+		t.Fatalf("expected the model to be inferred from the slice, got %v", criteria)
+	}
+	if err := criteria.Limit(2).Fill(&articles); !NonFatalError(err) {
+		t.Fatal(err)
+	}
+	if len(articles) != 2 || articles[1].Id != 2 || articles[1].Body != "body 2" {
+		t.Errorf("unexpected articles %v", articles)
+	}
+
+	var values []searchArticle
+	if err := criteria.Limit(1).Fill(&values); !NonFatalError(err) || len(values) != 1 || values[0].Id != 1 {
+		t.Errorf("unexpected values %v %v", values, err)
+	}
+
+	var rows [][]interface{}
+	projected := criteria.SetProjection(Projections.ProjectionList().Add(Projections.Property("Id"), Projections.Property("Body")))
+	if err := projected.Limit(1).Fill(&rows); err != nil || len(rows) != 1 || rows[0][1] != "body 1" {
+		t.Errorf("unexpected rows %v %v", rows, err)
+	}
+
+	var names []string
+	if err := criteria.Limit(1).Fill(&names); err == nil {
+		t.Error("expected an error filling strings with models")
+	}
+	if err := criteria.Fill(articles); err == nil {
+		t.Error("expected an error filling a slice which isn't a pointer")
+	}
+}
+
+func TestFillSlice(t *testing.T) {
+	var ids []int
+	if err := fillSlice(reflect.ValueOf(&ids).Elem(), []interface{}{int64(3), nil}); err != nil || !reflect.DeepEqual(ids, []int{3, 0}) {
+		t.Errorf("unexpected ids %v %v", ids, err)
+	}
+	var names []string
+	if err := fillSlice(reflect.ValueOf(&names).Elem(), []interface{}{int64(3)}); err == nil {
+		t.Error("expected integers not to be converted to strings")
+	}
+
+	// the numeric columns some drivers scan as text
+	var sums []float64
+	if err := fillSlice(reflect.ValueOf(&sums).Elem(), []interface{}{"12.5", []byte("3")}); err != nil || !reflect.DeepEqual(sums, []float64{12.5, 3}) {
+		t.Errorf("unexpected sums %v %v", sums, err)
+	}
+	var counts []int64
+	if err := fillSlice(reflect.ValueOf(&counts).Elem(), []interface{}{"12.5"}); err == nil {
+		t.Error("expected an error filling integers with a decimal")
+	}
+	var flags []bool
+	if err := fillSlice(reflect.ValueOf(&flags).Elem(), []interface{}{"1", "0"}); err != nil || !reflect.DeepEqual(flags, []bool{true, false}) {
+		t.Errorf("unexpected flags %v %v", flags, err)
+	}
+}
+
+type reportRow struct {
+	ID    int32
+	Body  string
+	Extra sql.NullString
+}
+
+func TestResultTransformer(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+
+	projection := Projections.ProjectionList().
+		Add(Projections.Alias(Projections.GroupProperty("Body"), "Body")).
+		Add(Projections.Alias(Projections.RowCount(), "Total"))
+	criteria := newTestCriteria(dbmap, &searchArticle{}).SetProjection(projection)
+	query, _, err := criteria.SQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select body as `Body`, count(*) as `Total` from search_article this_  group by body"; query != want {
+		t.Errorf("expected %q, got %q", want, query)
+	}
+
+	// the driver returns the id, body and extra columns
+	var rows []reportRow
+	err = criteria.SetResultTransformer(Transformers.AliasToStruct(&reportRow{})).Limit(2).Fill(&rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []reportRow{{1, "body 1", sql.NullString{String: "ignored", Valid: true}}, {2, "body 2", sql.NullString{String: "ignored", Valid: true}}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected %v, got %v", want, rows)
+	}
+
+	list, err := criteria.SetResultTransformer(Transformers.ToMap()).Limit(1).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{map[string]interface{}{"id": int64(1), "body": "body 1", "extra": "ignored"}}; !reflect.DeepEqual(list, want) {
+		t.Errorf("expected %v, got %v", want, list)
+	}
+
+	// the tuples of the drivers scanning numbers as bytes
+	type sumRow struct {
+		Body  string
+		Total int64
+		Avg   float64
+	}
+	row, err := Transformers.AliasToStruct(&sumRow{}).TransformTuple(
+		[]interface{}{[]byte("body 1"), []byte("3"), []byte("1.5")}, []string{"body", "total", "avg"})
+	if want := (&sumRow{"body 1", 3, 1.5}); err != nil || !reflect.DeepEqual(row, want) {
+		t.Errorf("expected %v, got %v %v", want, row, err)
+	}
+	if _, err = Transformers.AliasToStruct(&sumRow{}).TransformTuple([]interface{}{[]byte("1.5")}, []string{"total"}); err == nil {
+		t.Error("expected an error setting an integer to a decimal")
+	}
+
+	type missingRow struct {
+		Id   int64
+		Body string
+	}
+	if _, err := criteria.SetResultTransformer(Transformers.AliasToStruct(&missingRow{})).Limit(1).List(); err == nil {
+		t.Error("expected an error for a column matching no field")
+	}
+}
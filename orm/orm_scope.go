@@ -0,0 +1,88 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Scope returns the criterion restricting the rows of a model every query
+// sees, given the context of the DbMap, see DbMap.WithContext.  A nil
+// criterion doesn't restrict the rows.
+type Scope func(ctx context.Context) Criterion
+
+// RegisterScope adds a default scope to a registered model, eg to hide
+// the soft deleted rows, or those of the other tenants:
+//
+//	orm.RegisterScope(&Post{}, func(ctx context.Context) orm.Criterion {
+//		return orm.Restrictions.IsNull("DeletedAt")
+//	})
+//
+// The criterions of the scopes are added to the where clause of the
+// criteria of the model, and of Get.  Criteria.Unscoped and
+// DbMap.Unscoped opt out.
+func RegisterScope(model interface{}, scope Scope) {
+	typ := reflect.Indirect(reflect.ValueOf(model)).Type()
+	mi, ok := modelCache.getByFullName(getFullName(typ))
+	if !ok {
+		panic(fmt.Errorf("<orm.RegisterScope> model `%s` is not registered", getFullName(typ)))
+	}
+	mi.scopes = append(mi.scopes, scope)
+}
+
+// Unscoped returns a copy of m sharing its models and connections, whose
+// Get and criteria ignore the scopes of the models.
+func (m *DbMap) Unscoped() *DbMap {
+	c := m.clone()
+	c.unscoped = true
+	return c
+}
+
+// scopeCriterions returns the criterions of the scopes of tmap, unless
+// dbmap is unscoped.  Partials follow the scopes of their model.
+func scopeCriterions(tmap *modelInfo, dbmap *DbMap) []Criterion {
+	if dbmap.unscoped {
+		return nil
+	}
+	if tmap.partialOf != nil {
+		tmap = tmap.partialOf
+	}
+	ctx := dbmap.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var criterions []Criterion
+	for _, scope := range tmap.scopes {
+		if cr := scope(ctx); cr != nil {
+			criterions = append(criterions, cr)
+		}
+	}
+	return criterions
+}
+
+// scopedQuery appends the criterions of the scopes of table to query, the
+// get statement of the model, and their values to args, its keys.  The
+// statement doesn't alias the table, so {alias} is its name there.
+func scopedQuery(m *DbMap, table *modelInfo, query string, args []interface{}) (string, []interface{}, error) {
+	criterions := scopeCriterions(table, m)
+	if len(criterions) == 0 {
+		return query, args, nil
+	}
+	ci := *newCriteria(m, table, nil, table.gotype).(*criteriaImpl)
+	ci.tableAlias = m.Dialect.QuotedTableForQuery(table.schemaName, table.table)
+	ct := CriteriaTranslator{criteria: ci, dbmap: m}
+	n := len(args)
+	conds, args, err := ct.conditions(criterions, args)
+	if err != nil {
+		return "", nil, err
+	}
+	suffix := m.Dialect.QuerySuffix()
+	s := strings.TrimSuffix(query, suffix)
+	for _, cond := range conds {
+		// the criterions bind with ?, number them after the keys
+		cond, n = bindVars(m.Dialect, cond, n)
+		s += " and (" + cond + ")"
+	}
+	return s + suffix, args, nil
+}
@@ -0,0 +1,81 @@
+package orm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestScopeCriterions(t *testing.T) {
+	dbmap := registerTestModels(t, PostgresDialect{}, &scopedNote{})
+	defer Database().Set(nil)
+	RegisterScope(&scopedNote{}, func(ctx context.Context) Criterion {
+		return Restrictions.IsNull("DeletedAt")
+	})
+	RegisterScope(&scopedNote{}, func(ctx context.Context) Criterion {
+		if tenant, ok := ctx.Value(tenantKey{}).(int64); ok {
+			return Restrictions.Eq("TenantId", tenant)
+		}
+		return nil
+	})
+
+	tenant := dbmap.WithContext(context.WithValue(context.Background(), tenantKey{}, int64(3)))
+	tests := []struct {
+		criteria Criteria
+		sql      string
+		args     int
+	}{
+		{newTestCriteria(dbmap, &scopedNote{}), "select * from scoped_note this_ where deleted_at is null", 0},
+		{newTestCriteria(tenant, &scopedNote{}), "select * from scoped_note this_ where deleted_at is null and tenant_id = ?", 1},
+		{newTestCriteria(tenant, &scopedNote{}).Unscoped(), "select * from scoped_note this_", 0},
+		{newTestCriteria(tenant.Unscoped(), &scopedNote{}), "select * from scoped_note this_", 0},
+		// the or of a raw condition doesn't escape the scopes
+		{newTestCriteria(tenant, &scopedNote{}).Add(Restrictions.Sql("id = ? or id = ?", 1, 2)),
+			"select * from scoped_note this_ where (id = ? or id = ?) and deleted_at is null and tenant_id = ?", 3},
+	}
+	for _, test := range tests {
+		s, args, err := test.criteria.SQL()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != test.sql || len(args) != test.args {
+			t.Errorf("expected %q, got %q %v", test.sql, s, args)
+		}
+	}
+
+	table, err := tenant.TableFor(reflect.TypeOf(scopedNote{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, args, err := scopedQuery(tenant, table, "select * from \"scoped_note\" where \"id\"=$1;", []interface{}{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select * from \"scoped_note\" where \"id\"=$1 and (deleted_at is null) and (tenant_id = $2);"; query != want || len(args) != 2 || args[1] != int64(3) {
+		t.Errorf("expected %q, got %q %v", want, query, args)
+	}
+}
+
+func TestScopedQuery(t *testing.T) {
+	dbmap := registerTestModels(t, PostgresDialect{}, &scopedNote{})
+	defer Database().Set(nil)
+	RegisterScope(&scopedNote{}, func(ctx context.Context) Criterion {
+		return Restrictions.Sql("{alias}.tenant_id = ? or {alias}.deleted_at <> '?'", int64(3))
+	})
+
+	table, err := dbmap.TableFor(reflect.TypeOf(scopedNote{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, args, err := scopedQuery(dbmap, table, "select * from \"scoped_note\" where \"id\"=$1;", []interface{}{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the or kept apart from the key, the quoted ? not bound
+	want := "select * from \"scoped_note\" where \"id\"=$1 and ((\"scoped_note\".tenant_id = $2 or \"scoped_note\".deleted_at <> '?'));"
+	if query != want || len(args) != 2 {
+		t.Errorf("expected %q, got %q %v", want, query, args)
+	}
+}
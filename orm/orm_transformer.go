@@ -0,0 +1,132 @@
+package orm
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ResultTransformer turns the rows of a projected criteria into its
+// results, see Criteria.SetResultTransformer.  Built-in transformers are
+// provided by the Transformers factory.
+type ResultTransformer interface {
+	// TransformTuple returns the result of the row holding the values of
+	// the columns named aliases.
+	TransformTuple(tuple []interface{}, aliases []string) (interface{}, error)
+}
+
+var (
+	Transformers = TransformerFactory{}
+)
+
+// TransformerFactory builds the result transformers, see Transformers.
+type TransformerFactory struct{}
+
+// AliasToStruct fills a new struct of the type of ptrStruct, eg
+// &ReportRow{}, with each row, the columns going to the fields they're
+// aliased as, eg
+//
+//	criteria.SetProjection(Projections.ProjectionList().
+//		Add(Projections.Alias(Projections.GroupProperty("Status"), "Status")).
+//		Add(Projections.Alias(Projections.RowCount(), "Total"))).
+//		SetResultTransformer(Transformers.AliasToStruct(&ReportRow{}))
+//
+// lists a *ReportRow per status.  The columns match the fields by name,
+// ignoring case, or by their snake case name, eg "row_count" goes to
+// RowCount.  A column matching no field fails the transformation.
+func (t TransformerFactory) AliasToStruct(ptrStruct interface{}) ResultTransformer {
+	typ := reflect.TypeOf(ptrStruct)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("<Transformers.AliasToStruct> expects a pointer to a struct, got %T", ptrStruct))
+	}
+	return &aliasToStructTransformer{typ: typ.Elem()}
+}
+
+// ToMap returns each row as a map of its values by column alias.
+func (t TransformerFactory) ToMap() ResultTransformer {
+	return toMapTransformer{}
+}
+
+// aliasToStructTransformer transformer into the structs of typ
+type aliasToStructTransformer struct {
+	typ reflect.Type
+}
+
+func (a aliasToStructTransformer) TransformTuple(tuple []interface{}, aliases []string) (interface{}, error) {
+	result := reflect.New(a.typ)
+	for i, alias := range aliases {
+		field, ok := a.field(result.Elem(), alias)
+		if !ok {
+			return nil, fmt.Errorf("<Transformers.AliasToStruct> `%s` has no field for column `%s`", a.typ, alias)
+		}
+		if err := setTupleValue(field, tuple[i]); err != nil {
+			return nil, fmt.Errorf("<Transformers.AliasToStruct> column `%s`: %v", alias, err)
+		}
+	}
+	return result.Interface(), nil
+}
+
+// field returns the exported field of elem matching alias.
+func (a aliasToStructTransformer) field(elem reflect.Value, alias string) (reflect.Value, bool) {
+	for i := 0; i < a.typ.NumField(); i++ {
+		sf := a.typ.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		if strings.EqualFold(sf.Name, alias) || snakeString(sf.Name) == strings.ToLower(alias) {
+			return elem.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setTupleValue sets field to value, a value scanned from a column,
+// parsing the text of the numeric columns, see parseText.
+func setTupleValue(field reflect.Value, value interface{}) error {
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	parsed, parsable, err := parseText(value, field.Type())
+	switch {
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case parsable && err != nil:
+		return fmt.Errorf("cannot set a %s to %q: %v", field.Type(), value, err)
+	case parsable:
+		field.Set(parsed)
+	case v.Type().ConvertibleTo(field.Type()) && (v.Kind() == reflect.String) == (field.Kind() == reflect.String):
+		field.Set(v.Convert(field.Type()))
+	default:
+		return fmt.Errorf("cannot set a %s to %T", field.Type(), value)
+	}
+	return nil
+}
+
+// toMapTransformer transformer into maps by column alias
+type toMapTransformer struct{}
+
+func (t toMapTransformer) TransformTuple(tuple []interface{}, aliases []string) (interface{}, error) {
+	result := make(map[string]interface{}, len(aliases))
+	for i, alias := range aliases {
+		result[alias] = tuple[i]
+	}
+	return result, nil
+}
+
+// SetResultTransformer turns the rows of the projected criteria into
+// results with transformer, instead of a value or a []interface{} per
+// row.  It doesn't apply to the criteria listing models.
+func (ci criteriaImpl) SetResultTransformer(transformer ResultTransformer) Criteria {
+	ci.transformer = transformer
+	return ci
+}
+
+func (ci criteriaImpl) GetResultTransformer() ResultTransformer {
+	return ci.transformer
+}
@@ -0,0 +1,107 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// RegisterPartial registers partial, a struct holding a subset of the
+// fields of the registered model, as a read-only view of the model table.
+// Selecting partials only scans their columns, eg for hot list pages:
+//
+//	type PostSummary struct {
+//		Id    int64
+//		Title string
+//	}
+//
+//	orm.RegisterPartial(new(PostSummary), new(Post))
+//
+// Every field of partial must map to a column of model with the same type,
+// and the primary key of model must be included.  Only rel(fk) and
+// rel(one) relations can be part of a partial.  The policies of model
+// apply to the partial, which must hold the fields they restrict.
+// Insert, Update, Delete and the criteria writes fail on partials with a
+// *PartialWriteError; write through the full model instead.
+func RegisterPartial(partial interface{}, model interface{}) {
+	val := reflect.ValueOf(partial)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("<orm.RegisterPartial> cannot use non-ptr partial struct `%T`", partial))
+	}
+	typ := val.Elem().Type()
+
+	full, ok := modelCache.getByFullName(getFullName(reflect.Indirect(reflect.ValueOf(model)).Type()))
+	if !ok || full.partialOf != nil {
+		panic(fmt.Errorf("<orm.RegisterPartial> model `%T` of partial `%s` must be registered first", model, getFullName(typ)))
+	}
+	name := getFullName(typ)
+	if _, ok := modelCache.getByFullName(name); ok {
+		panic(fmt.Errorf("<orm.RegisterPartial> model `%s` repeat register, must be unique", name))
+	}
+
+	mi, err := newModelInfo(val, full.naming)
+	if err != nil {
+		panic(fmt.Errorf("<orm.RegisterPartial> partial `%s`: %s", name, err))
+	}
+	mi.gotype = typ
+	mi.table = full.table
+	mi.schemaName = full.schemaName
+	mi.pkg = typ.PkgPath()
+	mi.model = partial
+	mi.manual = true
+	mi.partialOf = full
+	if err = mi.checkPartial(); err != nil {
+		panic(fmt.Errorf("<orm.RegisterPartial> partial `%s` of `%s`: %s", name, full.fullName, err))
+	}
+	modelCache.setPartial(mi)
+}
+
+// checkPartial validates the fields of the partial mi against its model,
+// and takes the primary key of the model.
+func (mi *modelInfo) checkPartial() error {
+	full := mi.partialOf
+	for _, fi := range mi.fields.fieldsDB {
+		ffi := full.fields.GetByColumn(fi.column)
+		if ffi == nil || !ffi.dbcol {
+			return fmt.Errorf("field `%s` has no column `%s` in the model", fi.name, fi.column)
+		}
+		if fi.fieldType != ffi.fieldType || fi.gotype != ffi.gotype {
+			return fmt.Errorf("field `%s` is a %s, the model column `%s` a %s", fi.name, fi.gotype, ffi.column, ffi.gotype)
+		}
+		fi.pk = ffi.pk
+		fi.auto = ffi.auto
+		fi.null = ffi.null
+	}
+	if len(mi.fields.fieldsReverse) > 0 || len(mi.fields.fieldsByType[RelManyToMany]) > 0 {
+		return fmt.Errorf("only rel(fk) and rel(one) relations are allowed")
+	}
+
+	mi.fields.keys = make(map[string]*fieldInfo)
+	for _, key := range full.fields.keys {
+		fi := mi.fields.GetByColumn(key.column)
+		if fi == nil {
+			return fmt.Errorf("primary key `%s` is missing", key.name)
+		}
+		mi.fields.keys[fi.name] = fi
+	}
+	return nil
+}
+
+// resolvePartial links the relations of the partial mi, once the ones of
+// its model are.
+func (mi *modelInfo) resolvePartial() {
+	for _, fi := range mi.fields.fieldsRel {
+		fi.relModelInfo = mi.partialOf.fields.GetByColumn(fi.column).relModelInfo
+	}
+}
+
+// writable fails for partials, which are only read, and for the models
+// registered read only.
+func (mi *modelInfo) writable() error {
+	if mi.partialOf != nil {
+		return &PartialWriteError{TypeName: mi.name, ModelName: mi.partialOf.name}
+	}
+	if mi.readOnly {
+		return &ReadOnlyError{TypeName: mi.name}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package orm
+
+import "testing"
+
+type partialPostBody struct {
+	Title []byte
+}
+
+func TestRegisterPartial(t *testing.T) {
+	ResetModelCache()
+	dbmap := &DbMap{Dialect: PostgresDialect{}}
+	Database().Set(dbmap)
+	RegisterModel(new(relAuthor))
+	RegisterModel(new(partialPost))
+	RegisterPartial(new(partialPostSummary), new(partialPost))
+	BootStrap()
+
+	ct := CriteriaTranslator{criteria: newTestCriteria(dbmap, &partialPostSummary{}), dbmap: dbmap}
+	s, _, err := ct.toSelect("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `select "id","title","author_id" from partial_post this_`; s.ToStatementString() != want {
+		t.Errorf("expected %q, got %q", want, s.ToStatementString())
+	}
+
+	mi, _ := modelCache.getByFullName(getFullName(ct.criteria.GetEntityType()))
+	if mi.fields.GetByName("Author").relModelInfo == nil || mi.fields.GetByName("Id") != mi.fields.GetOnePrimaryKey() {
+		t.Error("partial relations and keys not resolved")
+	}
+
+	if _, _, err := ct.toUpdate(Params{"Title": "x"}, "", nil); err == nil {
+		t.Error("expected an error updating a partial")
+	}
+	if err := insert(dbmap, dbmap, &partialPostSummary{}); err == nil {
+		t.Error("expected an error inserting a partial")
+	} else if _, ok := err.(*PartialWriteError); !ok {
+		t.Errorf("expected a *PartialWriteError, got %T", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a mistyped partial field")
+		}
+	}()
+	RegisterPartial(new(partialPostBody), new(partialPost))
+}
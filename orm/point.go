@@ -0,0 +1,137 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Point is a geographic location, stored in the native spatial column
+// of the dialect: a MySQL point, or a PostGIS geography(Point,4326).
+// Points are bound as well-known text, and scanned from the text or the
+// binary representations.
+//
+// Use Restrictions.DistanceLte to query the rows near a point.
+type Point struct {
+	Lng float64
+	Lat float64
+}
+
+// String returns the well-known text of the point, like POINT(2.35 48.85).
+func (p Point) String() string {
+	return fmt.Sprintf("POINT(%s %s)", strconv.FormatFloat(p.Lng, 'f', -1, 64), strconv.FormatFloat(p.Lat, 'f', -1, 64))
+}
+
+// Value implements the driver Valuer interface.
+func (p Point) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+// Scan implements the Scanner interface.
+func (p *Point) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		*p = Point{}
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("orm: can not scan %T into a point", src)
+	}
+
+	text := string(data)
+	if i := strings.Index(text, ";"); i > 0 && strings.HasPrefix(strings.ToUpper(text), "SRID=") {
+		text = text[i+1:]
+	}
+	if strings.HasPrefix(strings.ToUpper(text), "POINT") {
+		return p.parseText(text)
+	}
+	if b, err := hex.DecodeString(text); err == nil {
+		data = b
+	} else if len(data) == 25 {
+		// MySQL prefixes the well-known binary with the SRID.
+		data = data[4:]
+	}
+	return p.parseBinary(data)
+}
+
+// parseText parses the well-known text of a point.
+func (p *Point) parseText(text string) error {
+	var lng, lat float64
+	coords := strings.TrimSpace(text[len("POINT"):])
+	if _, err := fmt.Sscanf(coords, "(%g %g)", &lng, &lat); err != nil {
+		return fmt.Errorf("orm: wrong point `%s`", text)
+	}
+	p.Lng, p.Lat = lng, lat
+	return nil
+}
+
+// parseBinary parses the well-known binary of a point, or the extended
+// one of PostGIS which may hold the SRID.
+func (p *Point) parseBinary(data []byte) error {
+	if len(data) < 21 {
+		return fmt.Errorf("orm: wrong point binary of %d bytes", len(data))
+	}
+	var order binary.ByteOrder = binary.BigEndian
+	if data[0] == 1 {
+		order = binary.LittleEndian
+	}
+	typ := order.Uint32(data[1:5])
+	data = data[5:]
+	if typ&0x20000000 != 0 {
+		if len(data) < 20 {
+			return fmt.Errorf("orm: wrong point binary")
+		}
+		data = data[4:]
+	}
+	if typ&0xff != 1 || len(data) < 16 {
+		return fmt.Errorf("orm: geometry type %d is not a point", typ&0xff)
+	}
+	p.Lng = math.Float64frombits(order.Uint64(data[0:8]))
+	p.Lat = math.Float64frombits(order.Uint64(data[8:16]))
+	return nil
+}
+
+// bootPoints checks that the dialect of the database, if set, supports the
+// point fields of mi, which spatialDialect would otherwise panic on.
+func bootPoints(mi *modelInfo) (errs []error) {
+	m := Database().lookup()
+	if m == nil || m.Dialect == nil {
+		return nil
+	}
+	if _, ok := m.Dialect.(SpatialDialect); ok {
+		return nil
+	}
+	for _, fi := range mi.fields.columns {
+		if fi.point {
+			errs = append(errs, fmt.Errorf("field `%s` is a point, dialect %T has no spatial support", fi.fullName, m.Dialect))
+		}
+	}
+	return errs
+}
+
+// spatialDialect returns the dialect as a SpatialDialect, panicking if it
+// has no spatial support.
+func spatialDialect(dialect Dialect, field string) SpatialDialect {
+	sd, ok := dialect.(SpatialDialect)
+	if !ok {
+		panic(criteriaError("orm.Point", "dialect %T does not support point field `%s`", dialect, field))
+	}
+	return sd
+}
+
+// sqlForBindVar returns the expression binding col to bindVar, converting
+// the text of a point to the spatial type of the column.
+func sqlForBindVar(dialect Dialect, col *fieldInfo, bindVar string) string {
+	if col.point {
+		return spatialDialect(dialect, col.fullName).PointFromText(bindVar)
+	}
+	return bindVar
+}
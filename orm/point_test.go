@@ -0,0 +1,81 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPointScan(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want Point
+	}{
+		{"POINT(2.35 48.85)", Point{2.35, 48.85}},
+		{[]byte("SRID=4326;POINT(-73.5 40.25)"), Point{-73.5, 40.25}},
+		// PostGIS hex extended well-known binary, with the SRID
+		{"0101000020E6100000000000000000F03F0000000000000040", Point{1, 2}},
+		// MySQL internal format, the SRID followed by the well-known binary
+		{[]byte("\x00\x00\x00\x00\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x08\x40\x00\x00\x00\x00\x00\x00\x10\x40"), Point{3, 4}},
+	}
+	for _, test := range tests {
+		var p Point
+		if err := p.Scan(test.in); err != nil {
+			t.Errorf("%q: unexpected error %v", test.in, err)
+		} else if p != test.want {
+			t.Errorf("%q: expected %v, got %v", test.in, test.want, p)
+		}
+	}
+	var p Point
+	if err := p.Scan("LINESTRING(0 0, 1 1)"); err == nil {
+		t.Error("expected an error scanning a line")
+	}
+	if v, _ := (Point{2.35, 48.85}).Value(); v != "POINT(2.35 48.85)" {
+		t.Errorf("unexpected point value %v", v)
+	}
+}
+
+func TestPointFieldUnsupported(t *testing.T) {
+	ResetModelCache()
+	Database().Set(&DbMap{Dialect: SqliteDialect{}})
+	defer Database().Set(nil)
+	RegisterModel(&pointStore{})
+	err := BootStrapE()
+	if err == nil || !strings.Contains(err.Error(), "has no spatial support") {
+		t.Errorf("expected an unsupported point error, got %v", err)
+	}
+}
+
+func TestPointField(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &pointStore{})
+	defer Database().Set(nil)
+	mi, _ := modelCache.get("point_store")
+
+	if ddl := mi.SqlForCreate(false); !strings.Contains(ddl, "`location` point") {
+		t.Errorf("unexpected ddl %q", ddl)
+	}
+	bi, err := mi.bindInsert(reflect.ValueOf(&pointStore{Location: Point{1, 2}}).Elem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(bi.query, "ST_GeomFromText(?)") != 2 {
+		t.Errorf("unexpected insert %q", bi.query)
+	}
+
+	criteria := newTestCriteria(dbmap, &pointStore{})
+	near := Restrictions.DistanceLte("Location", Point{1, 2}, 500)
+	if sql := near.ToSqlString(criteria, dbmap); sql != "ST_Distance_Sphere(location, ST_GeomFromText(?)) <= ?" {
+		t.Errorf("unexpected distance sql %q", sql)
+	}
+	if values := near.GetValues(criteria, dbmap); !reflect.DeepEqual(values, []interface{}{Point{1, 2}, 500.0}) {
+		t.Errorf("unexpected distance values %v", values)
+	}
+
+	Database().Set(&DbMap{Dialect: PostgresDialect{}})
+	if ddl := mi.SqlForCreate(false); !strings.Contains(ddl, `"location" geography(Point,4326)`) {
+		t.Errorf("unexpected postgres ddl %q", ddl)
+	}
+	if sql := near.ToSqlString(criteria, &DbMap{Dialect: PostgresDialect{}}); !strings.HasPrefix(sql, "ST_DWithin(") {
+		t.Errorf("unexpected postgres distance sql %q", sql)
+	}
+}
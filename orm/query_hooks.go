@@ -0,0 +1,97 @@
+package orm
+
+import (
+	"context"
+	"time"
+)
+
+// QueryEvent describes a statement run through a DbMap, see QueryHook.
+type QueryEvent struct {
+	// Ctx starts as the context of the DbMap, see DbMap.WithContext, or
+	// context.Background().  BeforeQuery may replace it, eg with one
+	// holding a tracing span; the statement runs with it, and the
+	// following hooks and AfterQuery get it.
+	Ctx context.Context
+
+	// Query and Args are sent to the database once every BeforeQuery
+	// has run, which may rewrite them.
+	Query string
+	Args  []interface{}
+
+	// Started is the time the statement was sent to the database.
+	Started time.Time
+
+	// Err is the result of the statement, set before AfterQuery.  It is
+	// always nil for QueryRow, whose error only surfaces on Scan.
+	Err error
+}
+
+// QueryHook runs around every Exec, Query and QueryRow of a DbMap and of
+// its transactions, the statements of Insert, Update, Select and the
+// criteria included.
+type QueryHook interface {
+	// BeforeQuery is called before the statement is sent, in the order
+	// the hooks were added.
+	BeforeQuery(e *QueryEvent)
+
+	// AfterQuery is called once the statement returned, in the reverse
+	// order.
+	AfterQuery(e *QueryEvent)
+}
+
+// AddQueryHook appends h to the hooks of m.  Hooks must be added before
+// m is used concurrently.
+func (m *DbMap) AddQueryHook(h QueryHook) {
+	m.queryHooks = append(m.queryHooks, h)
+}
+
+// QueryRewriter returns the statement query and args to send instead of
+// query and args, eg with a tenant predicate appended or a comment
+// naming the action:
+//
+//	dbmap.AddRewriter(func(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+//		return "/* controller=App.Index */ " + query, args
+//	})
+//
+// ctx is the context of the DbMap, see DbMap.WithContext.
+type QueryRewriter func(ctx context.Context, query string, args []interface{}) (string, []interface{})
+
+// AddRewriter appends r to the rewriters of m, which run in the order
+// they were added on the final statements of m and of its transactions,
+// those of Insert, Update, the criteria and the raw queries included,
+// before the hooks.  Rewriters must be added before m is used
+// concurrently.
+func (m *DbMap) AddRewriter(r QueryRewriter) {
+	m.rewriters = append(m.rewriters, r)
+}
+
+// hooked reports whether the statements of m go through beforeQuery and
+// afterQuery.
+func (m *DbMap) hooked() bool {
+	return len(m.queryHooks) > 0 || len(m.rewriters) > 0
+}
+
+// beforeQuery rewrites the statement and runs the hooks, returning the
+// event whose context, query and args the statement runs with.
+func (m *DbMap) beforeQuery(ctx context.Context, query string, args []interface{}) *QueryEvent {
+	for _, r := range m.rewriters {
+		query, args = r(ctx, query, args)
+	}
+	e := &QueryEvent{Ctx: ctx, Query: query, Args: args}
+	for _, h := range m.queryHooks {
+		h.BeforeQuery(e)
+	}
+	e.Started = time.Now()
+	return e
+}
+
+// afterQuery completes e with the error errp points at, nil when the
+// error isn't known yet, and runs the hooks in reverse.
+func (m *DbMap) afterQuery(e *QueryEvent, errp *error) {
+	if errp != nil {
+		e.Err = *errp
+	}
+	for i := len(m.queryHooks) - 1; i >= 0; i-- {
+		m.queryHooks[i].AfterQuery(e)
+	}
+}
@@ -0,0 +1,124 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tenantHook struct {
+	calls *[]string
+	name  string
+}
+
+func (h tenantHook) BeforeQuery(e *QueryEvent) {
+	*h.calls = append(*h.calls, "before "+h.name)
+	e.Query = strings.Replace(e.Query, "{tenant}", "acme", 1)
+}
+
+func (h tenantHook) AfterQuery(e *QueryEvent) {
+	*h.calls = append(*h.calls, "after "+h.name+" "+e.Query)
+}
+
+func TestQueryHooks(t *testing.T) {
+	var calls []string
+	m := &DbMap{}
+	m.AddQueryHook(tenantHook{&calls, "a"})
+	m.AddQueryHook(tenantHook{&calls, "b"})
+
+	e := m.beforeQuery(m.baseContext(), "select * from {tenant}.post", nil)
+	failed := errors.New("no such table")
+	m.afterQuery(e, &failed)
+
+	want := []string{
+		"before a",
+		"before b",
+		"after b select * from acme.post",
+		"after a select * from acme.post",
+	}
+	if strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+	if e.Err != failed || e.Ctx == nil || e.Started.IsZero() {
+		t.Errorf("event not completed: %+v", e)
+	}
+}
+
+type actionKey struct{}
+
+func TestRewriters(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	defer Database().Set(nil)
+	var calls []string
+	dbmap.AddRewriter(func(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+		if action, ok := ctx.Value(actionKey{}).(string); ok {
+			query = "/* controller=" + action + " */ " + query
+		}
+		return query, args
+	})
+	dbmap.AddRewriter(func(ctx context.Context, query string, args []interface{}) (string, []interface{}) {
+		return strings.Replace(query, "{tenant}", "?", 1), append(args, "acme")
+	})
+	dbmap.AddQueryHook(tenantHook{&calls, "log"})
+
+	m := dbmap.WithContext(context.WithValue(context.Background(), actionKey{}, "App.Index"))
+	m.DryRun(true)
+	if err := m.Insert(&searchArticle{Body: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Exec("delete from search_article where tenant = {tenant}"); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := m.Statements()
+	if len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %v", statements)
+	}
+	for _, s := range statements {
+		if !strings.HasPrefix(s.Query, "/* controller=App.Index */ ") {
+			t.Errorf("statement not rewritten: %q", s.Query)
+		}
+	}
+	if s := statements[1]; !strings.HasSuffix(s.Query, "tenant = ?") || len(s.Args) != 1 || s.Args[0] != "acme" {
+		t.Errorf("tenant predicate not bound: %q %v", s.Query, s.Args)
+	}
+	if len(calls) != 4 || !strings.HasPrefix(calls[1], "after log /* controller=App.Index */ ") {
+		t.Errorf("hooks should see the rewritten statements, got %q", calls)
+	}
+}
+
+// cancelHook replaces the context of the statements with a cancelled one,
+// recording the action of the context it was given.
+type cancelHook struct {
+	actions *[]string
+}
+
+func (h cancelHook) BeforeQuery(e *QueryEvent) {
+	action, _ := e.Ctx.Value(actionKey{}).(string)
+	*h.actions = append(*h.actions, action)
+	ctx, cancel := context.WithCancel(e.Ctx)
+	cancel()
+	e.Ctx = ctx
+}
+
+func (h cancelHook) AfterQuery(e *QueryEvent) {}
+
+func TestQueryHookContext(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	defer Database().Set(nil)
+	dbmap.Db = hangDriver.open(t)
+	dbmap.QueryTimeout = time.Second
+	var actions []string
+	dbmap.AddQueryHook(cancelHook{&actions})
+
+	// seeded with the context of the DbMap, the statement runs with the one of the hook
+	m := dbmap.WithContext(context.WithValue(context.Background(), actionKey{}, "App.Index"))
+	if _, err := m.Exec("update search_article set body = ''"); err != context.Canceled {
+		t.Errorf("expected the context of the hook to cancel the statement, got %v", err)
+	}
+	if len(actions) != 1 || actions[0] != "App.Index" {
+		t.Errorf("expected the hook to get the context of the DbMap, got %q", actions)
+	}
+}
@@ -0,0 +1,77 @@
+package orm
+
+import (
+	"sync"
+	"time"
+)
+
+// QueryLogEntry is a statement recorded by a QueryLog.
+type QueryLogEntry struct {
+	Seq      uint64
+	Query    string
+	Args     []interface{}
+	Started  time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// QueryLog is a QueryHook keeping the last statements run, eg to show the
+// statements of a failed request:
+//
+//	mark := log.Mark()
+//	... handle the request ...
+//	queries := log.Since(mark)
+//
+// The statements of concurrent requests are interleaved in the log.
+type QueryLog struct {
+	mu      sync.Mutex
+	entries []QueryLogEntry // ring buffer
+	seq     uint64          // sequence of the last entry
+}
+
+// NewQueryLog returns a QueryLog keeping the last size statements.
+func NewQueryLog(size int) *QueryLog {
+	return &QueryLog{entries: make([]QueryLogEntry, size)}
+}
+
+func (l *QueryLog) BeforeQuery(e *QueryEvent) {}
+
+func (l *QueryLog) AfterQuery(e *QueryEvent) {
+	if len(l.entries) == 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	l.entries[l.seq%uint64(len(l.entries))] = QueryLogEntry{
+		Seq:      l.seq,
+		Query:    e.Query,
+		Args:     e.Args,
+		Started:  e.Started,
+		Duration: time.Since(e.Started),
+		Err:      e.Err,
+	}
+}
+
+// Mark returns the sequence of the last statement logged.
+func (l *QueryLog) Mark() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.seq
+}
+
+// Since returns the statements logged after mark which are still kept,
+// oldest first.
+func (l *QueryLog) Since(mark uint64) []QueryLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	size := uint64(len(l.entries))
+	if l.seq-mark > size {
+		mark = l.seq - size
+	}
+	entries := make([]QueryLogEntry, 0, l.seq-mark)
+	for seq := mark + 1; seq <= l.seq; seq++ {
+		entries = append(entries, l.entries[seq%size])
+	}
+	return entries
+}
@@ -0,0 +1,25 @@
+package orm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestQueryLog(t *testing.T) {
+	log := NewQueryLog(3)
+	log.AfterQuery(&QueryEvent{Query: "select 0", Started: time.Now()})
+	mark := log.Mark()
+
+	if entries := log.Since(mark); len(entries) != 0 {
+		t.Errorf("expected no entries, got %v", entries)
+	}
+	for i := 1; i <= 4; i++ {
+		log.AfterQuery(&QueryEvent{Query: fmt.Sprintf("select %d", i), Started: time.Now()})
+	}
+
+	entries := log.Since(mark)
+	if len(entries) != 3 || entries[0].Query != "select 2" || entries[2].Query != "select 4" {
+		t.Errorf("expected the last 3 statements, got %v", entries)
+	}
+}
@@ -0,0 +1,86 @@
+package orm
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy retries the statements and transactions of a DbMap failing
+// on a deadlock or a serialization failure, as told by the RetryDetector
+// of the dialect:
+//
+//	dbmap.RetryPolicy = &orm.RetryPolicy{MaxRetries: 3, Backoff: 10 * time.Millisecond}
+//
+// Statements run in a Transaction are not retried on their own, as the
+// database aborts the whole transaction; use DbMap.RunInTransaction.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+
+	// Backoff is the wait before the first retry, doubled for each of
+	// the next ones up to MaxBackoff, if set.  Waits are jittered so
+	// that the transactions which deadlocked don't collide again.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// sqlState returns the SQLSTATE code of err, for the drivers reporting it.
+func sqlState(err error) string {
+	var e interface{ SQLState() string }
+	if errors.As(err, &e) {
+		return e.SQLState()
+	}
+	return ""
+}
+
+// retryable reports whether err is worth retrying with the dialect of m.
+func (m *DbMap) retryable(err error) bool {
+	rd, ok := m.Dialect.(RetryDetector)
+	return ok && rd.Retryable(err)
+}
+
+// retry runs fn, and again following the RetryPolicy as long as it fails
+// with a retryable error.
+func (m *DbMap) retry(fn func() error) error {
+	err := fn()
+	p := m.RetryPolicy
+	if p == nil {
+		return err
+	}
+	wait := p.Backoff
+	for i := 0; i < p.MaxRetries && err != nil && m.retryable(err); i++ {
+		if wait > 0 {
+			time.Sleep(wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1)))
+		}
+		if wait *= 2; p.MaxBackoff > 0 && wait > p.MaxBackoff {
+			wait = p.MaxBackoff
+		}
+		err = fn()
+	}
+	return err
+}
+
+// RunInTransaction runs fn in a transaction, committed if fn returns nil
+// and rolled back otherwise.  With a RetryPolicy the whole transaction
+// runs again when it fails on a deadlock or a serialization failure, so
+// fn must not have effects outside of tx.
+func (m *DbMap) RunInTransaction(fn func(tx *Transaction) error) error {
+	return m.retry(func() error {
+		tx, err := m.Begin()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+				panic(p)
+			}
+		}()
+		if err = fn(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+}
@@ -0,0 +1,66 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+)
+
+type stateError string
+
+func (e stateError) Error() string    { return "pq: state " + string(e) }
+func (e stateError) SQLState() string { return string(e) }
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		err     error
+		want    bool
+	}{
+		{PostgresDialect{}, stateError("40P01"), true},
+		{PostgresDialect{}, stateError("23505"), false},
+		{PostgresDialect{}, errors.New("pq: could not serialize access due to concurrent update"), true},
+		{MySQLDialect{}, errors.New("Error 1213: Deadlock found when trying to get lock"), true},
+		{MySQLDialect{}, errors.New("Error 1062: Duplicate entry"), false},
+		{SqliteDialect{}, errors.New("database is locked"), true},
+		{SqlServerDialect{}, errors.New("deadlock"), false},
+	}
+	for _, test := range tests {
+		m := &DbMap{Dialect: test.dialect}
+		if got := m.retryable(test.err); got != test.want {
+			t.Errorf("%T: retryable(%q) = %v, want %v", test.dialect, test.err, got, test.want)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	locked := errors.New("database is locked")
+	m := &DbMap{Dialect: SqliteDialect{}}
+
+	calls := 0
+	if err := m.retry(func() error { calls++; return locked }); err != locked || calls != 1 {
+		t.Errorf("retried without a policy: %v after %d calls", err, calls)
+	}
+
+	m.RetryPolicy = &RetryPolicy{MaxRetries: 2}
+	calls = 0
+	err := m.retry(func() error {
+		if calls++; calls < 3 {
+			return locked
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected success on the last retry, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	if err := m.retry(func() error { calls++; return locked }); err != locked || calls != 3 {
+		t.Errorf("expected to give up after 2 retries, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	failed := errors.New("no such table")
+	if err := m.retry(func() error { calls++; return failed }); err != failed || calls != 1 {
+		t.Errorf("retried a fatal error: %v after %d calls", err, calls)
+	}
+}
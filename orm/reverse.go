@@ -0,0 +1,129 @@
+package orm
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// GenerateModels writes the Go source of the package pkg declaring a model
+// for every table of s, eg read by DbMap.Inspect, with the orm tags of its
+// keys, sizes and unique columns.  Single column foreign keys become rel(fk)
+// fields of the model of the referenced table.  An init function registers
+// the models.
+func GenerateModels(w io.Writer, s *DbSchema, pkg string) error {
+	var (
+		buf     bytes.Buffer
+		body    bytes.Buffer
+		imports = make(map[string]bool)
+		models  []string
+	)
+	for _, t := range s.Tables {
+		name := camelString(t.Name)
+		models = append(models, name)
+		fmt.Fprintf(&body, "type %s struct {\n", name)
+		for _, c := range t.Columns {
+			field, typ, tags := reverseField(s, t, c)
+			if strings.Contains(typ, ".") {
+				imports[strings.SplitN(strings.TrimLeft(typ, "*[]"), ".", 2)[0]] = true
+			}
+			if len(tags) > 0 {
+				fmt.Fprintf(&body, "\t%s %s `orm:\"%s\"`\n", field, typ, strings.Join(tags, ";"))
+			} else {
+				fmt.Fprintf(&body, "\t%s %s\n", field, typ)
+			}
+		}
+		fmt.Fprintf(&body, "}\n\n")
+		if snakeString(name) != t.Name {
+			fmt.Fprintf(&body, "func (m *%s) TableName() string {\n\treturn %q\n}\n\n", name, t.Name)
+		}
+	}
+
+	fmt.Fprintf(&buf, "// Code generated by revel orm reverse. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	fmt.Fprintf(&buf, "import (\n")
+	if imports["time"] {
+		fmt.Fprintf(&buf, "\t\"time\"\n\n")
+	}
+	fmt.Fprintf(&buf, "\t\"github.com/dancewing/revel/orm\"\n)\n\n")
+	buf.Write(body.Bytes())
+	fmt.Fprintf(&buf, "func init() {\n")
+	for _, name := range models {
+		fmt.Fprintf(&buf, "\torm.RegisterModel(new(%s))\n", name)
+	}
+	fmt.Fprintf(&buf, "}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+var reverseInt = regexp.MustCompile(`^(tiny|small|medium|big)?int`)
+
+var reverseSize = regexp.MustCompile(`^(?:var)?char(?:acter)?(?: varying)?\((\d+)\)`)
+
+// reverseField returns the field, Go type and orm tags of the column c.
+func reverseField(s *DbSchema, t *TableSchema, c *ColumnSchema) (field, typ string, tags []string) {
+	field = camelString(c.Name)
+	column := snakeString(field)
+
+	for _, con := range t.Constraints {
+		if len(con.Columns) != 1 || con.Columns[0] != c.Name {
+			continue
+		}
+		switch con.Kind {
+		case "PRIMARY KEY":
+			tags = append(tags, "pk")
+		case "UNIQUE":
+			tags = append(tags, "unique")
+		case "FOREIGN KEY":
+			if s.Table(con.RefTable) == nil || !strings.HasSuffix(c.Name, "_id") {
+				continue
+			}
+			field = camelString(strings.TrimSuffix(c.Name, "_id"))
+			typ = "*" + camelString(con.RefTable)
+			column = snakeString(field) + "_id"
+			tags = append(tags, "rel(fk)")
+		}
+	}
+	if typ == "" {
+		typ = reverseType(c.Type)
+		if len(tags) > 0 && tags[0] == "pk" && typ == "int64" {
+			tags = append(tags, "auto")
+		}
+		if m := reverseSize.FindStringSubmatch(strings.ToLower(c.Type)); m != nil {
+			tags = append(tags, "size("+m[1]+")")
+		}
+	}
+	if column != c.Name {
+		tags = append(tags, "column("+c.Name+")")
+	}
+	if c.Nullable && (len(tags) == 0 || tags[0] != "pk") {
+		tags = append(tags, "null")
+	}
+	return
+}
+
+// reverseType returns the Go type of the columns of type sqlType.
+func reverseType(sqlType string) string {
+	t := strings.ToLower(sqlType)
+	switch {
+	case t == "tinyint(1)" || strings.HasPrefix(t, "bool"):
+		return "bool"
+	case reverseInt.MatchString(t) || strings.Contains(t, "serial"):
+		return "int64"
+	case strings.HasPrefix(t, "float") || strings.HasPrefix(t, "double") || strings.HasPrefix(t, "real") ||
+		strings.HasPrefix(t, "numeric") || strings.HasPrefix(t, "decimal"):
+		return "float64"
+	case strings.HasPrefix(t, "date") || strings.HasPrefix(t, "time"):
+		return "time.Time"
+	case strings.Contains(t, "blob") || strings.HasPrefix(t, "bytea") || strings.Contains(t, "binary"):
+		return "[]byte"
+	}
+	return "string"
+}
@@ -0,0 +1,60 @@
+package orm
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestGenerateModels(t *testing.T) {
+	s := &DbSchema{Tables: []*TableSchema{
+		{
+			Name: "author",
+			Columns: []*ColumnSchema{
+				{Name: "id", Type: "integer"},
+				{Name: "name", Type: "character varying(100)"},
+				{Name: "email", Type: "varchar(255)", Nullable: true},
+				{Name: "born", Type: "timestamp with time zone", Nullable: true},
+			},
+			Constraints: []*ConstraintSchema{
+				{Name: "author_pkey", Kind: "PRIMARY KEY", Columns: []string{"id"}},
+				{Name: "author_email_key", Kind: "UNIQUE", Columns: []string{"email"}},
+			},
+		},
+		{
+			Name: "BookV2",
+			Columns: []*ColumnSchema{
+				{Name: "id", Type: "bigint"},
+				{Name: "writer_id", Type: "integer", Nullable: true},
+				{Name: "Price", Type: "numeric(10,2)", Default: sql.NullString{String: "0", Valid: true}},
+			},
+			Constraints: []*ConstraintSchema{
+				{Kind: "PRIMARY KEY", Columns: []string{"id"}},
+				{Name: "fk_writer", Kind: "FOREIGN KEY", Columns: []string{"writer_id"}, RefTable: "author", RefColumns: []string{"id"}},
+			},
+		},
+	}}
+
+	var buf bytes.Buffer
+	if err := GenerateModels(&buf, s, "models"); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"package models",
+		"\t\"time\"\n",
+		"Id    int64     `orm:\"pk;auto\"`",
+		"Name  string    `orm:\"size(100)\"`",
+		"Email string    `orm:\"unique;size(255);null\"`",
+		"Born  time.Time `orm:\"null\"`",
+		"Writer *Author `orm:\"rel(fk);null\"`",
+		"Price  float64 `orm:\"column(Price)\"`",
+		"func (m *BookV2) TableName() string {\n\treturn \"BookV2\"\n}",
+		"orm.RegisterModel(new(Author))\n\torm.RegisterModel(new(BookV2))",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated source misses %q:\n%s", want, src)
+		}
+	}
+}
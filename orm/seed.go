@@ -0,0 +1,95 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// SeedTable is the table recording the seeds run, see RunSeeds.
+var SeedTable = "orm_seed"
+
+var seeds struct {
+	sync.Mutex
+	names []string
+	funcs map[string]func(tx *Transaction) error
+}
+
+// RegisterSeed registers the seed name, inserting the initial data of the
+// application, eg an admin user or the rows of lookup tables, with tx.
+// RunSeeds runs every seed once per database.
+func RegisterSeed(name string, seed func(tx *Transaction) error) {
+	seeds.Lock()
+	defer seeds.Unlock()
+	if _, ok := seeds.funcs[name]; ok {
+		panic(fmt.Errorf("<orm.RegisterSeed> seed `%s` repeat register, must be unique", name))
+	}
+	if seeds.funcs == nil {
+		seeds.funcs = make(map[string]func(tx *Transaction) error)
+	}
+	seeds.names = append(seeds.names, name)
+	seeds.funcs[name] = seed
+}
+
+// RunSeeds runs the seeds not run yet on the database of Database(), see
+// DbMap.RunSeeds.
+func RunSeeds() error {
+	return Database().Get().RunSeeds()
+}
+
+// RunSeeds runs the registered seeds not run yet on the database of m, in
+// the order of their registration.  Each seed runs in a transaction which
+// records it in the SeedTable, so a failed seed runs again next time and
+// the seeds after it don't run.
+func (m *DbMap) RunSeeds() error {
+	query := fmt.Sprintf("%s %s (%s %s not null primary key, %s %s not null)%s",
+		m.Dialect.IfTableNotExists("create table", "", SeedTable),
+		m.Dialect.QuotedTableForQuery("", SeedTable),
+		m.Dialect.QuoteField("name"), m.Dialect.ToSqlType(reflect.TypeOf(""), 255, false),
+		m.Dialect.QuoteField("run"), m.Dialect.ToSqlType(reflect.TypeOf(time.Time{}), 0, false),
+		m.Dialect.QuerySuffix())
+	if _, err := m.Exec(query); err != nil {
+		return err
+	}
+
+	var run []string
+	_, err := m.Select(&run, fmt.Sprintf("select %s from %s",
+		m.Dialect.QuoteField("name"), m.Dialect.QuotedTableForQuery("", SeedTable)))
+	if err != nil {
+		return err
+	}
+	done := make(map[string]bool, len(run))
+	for _, name := range run {
+		done[name] = true
+	}
+
+	seeds.Lock()
+	names := append([]string(nil), seeds.names...)
+	funcs := make(map[string]func(tx *Transaction) error, len(names))
+	for _, name := range names {
+		funcs[name] = seeds.funcs[name]
+	}
+	seeds.Unlock()
+	insert := fmt.Sprintf("insert into %s (%s, %s) values (%s, %s)%s",
+		m.Dialect.QuotedTableForQuery("", SeedTable),
+		m.Dialect.QuoteField("name"), m.Dialect.QuoteField("run"),
+		m.Dialect.BindVar(0), m.Dialect.BindVar(1), m.Dialect.QuerySuffix())
+	for _, name := range names {
+		if done[name] {
+			continue
+		}
+		seed := funcs[name]
+		err := m.RunInTransaction(func(tx *Transaction) error {
+			if err := seed(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(insert, name, time.Now())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("<orm.RunSeeds> seed `%s`: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// seedDriver keeps the names inserted into the seed table, and fails the
+// transactions inserting into the fail table.
+var seedDriver = &fakeDriver{
+	begin:  func() { seedTx = nil },
+	commit: func() { seedRun = append(seedRun, seedTx...) },
+	exec: func(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
+		switch {
+		case strings.HasPrefix(query, "insert into fail"):
+			return nil, errors.New("seed failed")
+		case strings.HasPrefix(query, `insert into "orm_seed"`):
+			seedTx = append(seedTx, args[0].(string))
+		}
+		return driver.RowsAffected(1), nil
+	},
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		rows := &fakeRows{columns: []string{"name"}}
+		for _, name := range seedRun {
+			rows.values = append(rows.values, []driver.Value{name})
+		}
+		return rows, nil
+	},
+}
+
+var (
+	seedRun []string
+	seedTx  []string // the seeds run in the transaction
+)
+
+func TestRunSeeds(t *testing.T) {
+	dbmap := &DbMap{Db: seedDriver.open(t), Dialect: SqliteDialect{}}
+	defer func() { seeds.names, seeds.funcs = nil, nil }()
+
+	calls := make(map[string]int)
+	RegisterSeed("admin", func(tx *Transaction) error {
+		calls["admin"]++
+		_, err := tx.Exec("insert into user (name) values ('admin')")
+		return err
+	})
+	RegisterSeed("countries", func(tx *Transaction) error {
+		calls["countries"]++
+		_, err := tx.Exec("insert into fail (name) values ('fr')")
+		return err
+	})
+	RegisterSeed("currencies", func(tx *Transaction) error {
+		calls["currencies"]++
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := dbmap.RunSeeds(); err == nil || !strings.Contains(err.Error(), "seed `countries`") {
+			t.Errorf("expected the countries seed to fail, got %v", err)
+		}
+	}
+	if calls["admin"] != 1 || calls["countries"] != 2 || calls["currencies"] != 0 {
+		t.Errorf("unexpected seed calls %v", calls)
+	}
+	if len(seedRun) != 1 || seedRun[0] != "admin" {
+		t.Errorf("unexpected seeds recorded %v", seedRun)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a repeated seed to panic")
+		}
+	}()
+	RegisterSeed("admin", nil)
+}
@@ -93,9 +93,10 @@ func SelectNullStr(e SqlExecutor, query string, args ...interface{}) (sql.NullSt
 // SelectOne executes the given query (which should be a SELECT statement)
 // and binds the result to holder, which must be a pointer.
 //
-// If no row is found, an error (sql.ErrNoRows specifically) will be returned
+// If no row is found, ErrNoRows will be returned
 //
-// If more than one row is found, an error will be returned.
+// If more than one row is found, an error matching ErrMultiRows will be
+// returned.
 //
 func SelectOne(m *DbMap, e SqlExecutor, holder interface{}, query string, args ...interface{}) error {
 	t := reflect.TypeOf(holder)
@@ -131,7 +132,7 @@ func SelectOne(m *DbMap, e SqlExecutor, holder interface{}, query string, args .
 		if list != nil && len(list) > 0 { // FIXME: invert if/else
 			// check for multiple rows
 			if len(list) > 1 {
-				return fmt.Errorf("gorp: multiple rows returned for: %s - %v", query, args)
+				return fmt.Errorf("%w for: %s - %v", ErrMultiRows, query, args)
 			}
 
 			// Initialize if nil
@@ -144,7 +145,7 @@ func SelectOne(m *DbMap, e SqlExecutor, holder interface{}, query string, args .
 			dest.Elem().Set(src.Elem())
 		} else {
 			// No rows found, return a proper error.
-			return sql.ErrNoRows
+			return ErrNoRows
 		}
 
 		return nonFatalErr
@@ -162,14 +163,15 @@ func selectVal(e SqlExecutor, holder interface{}, query string, args ...interfac
 			query, args = maybeExpandNamedQuery(m.dbmap, query, args)
 		}
 	}
-	rows, err := e.Query(query, args...)
+	rows, done, err := queryRows(e, query, args...)
 	if err != nil {
 		return err
 	}
+	defer done()
 	defer rows.Close()
 
 	if !rows.Next() {
-		return sql.ErrNoRows
+		return ErrNoRows
 	}
 
 	return rows.Scan(holder)
@@ -212,6 +214,9 @@ func hookedselect(m *DbMap, exec SqlExecutor, i interface{}, query string,
 	return list, nonFatalErr
 }
 
+// selectDests recycles the destinations of the rows scanned by Select.
+var selectDests scanPool
+
 func rawselect(m *DbMap, exec SqlExecutor, i interface{}, query string,
 	args ...interface{}) ([]interface{}, error) {
 	var (
@@ -255,10 +260,11 @@ func rawselect(m *DbMap, exec SqlExecutor, i interface{}, query string,
 	}
 
 	// Run the query
-	rows, err := exec.Query(query, args...)
+	rows, done, err := queryRows(exec, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer done()
 	defer rows.Close()
 
 	// Fetch the column names as returned from db
@@ -271,7 +277,11 @@ func rawselect(m *DbMap, exec SqlExecutor, i interface{}, query string,
 		return nil, fmt.Errorf("gorp: select into non-struct slice requires 1 column, got %d", len(cols))
 	}
 
-	var colToFieldIndex [][]int
+	var (
+		colToFieldIndex [][]int
+		colInfos        = make([]*fieldInfo, len(cols))
+		table           *modelInfo
+	)
 	if intoStruct {
 		colToFieldIndex, err = columnToFieldIndex(m, t, tableName, cols)
 		if err != nil {
@@ -280,6 +290,11 @@ func rawselect(m *DbMap, exec SqlExecutor, i interface{}, query string,
 			}
 			nonFatalErr = err
 		}
+		if table = tableOrNil(m, t, tableName); table != nil {
+			for x := range cols {
+				colInfos[x] = colMapOrNil(table, cols[x])
+			}
+		}
 	}
 
 	conv := m.TypeConverter
@@ -290,6 +305,13 @@ func rawselect(m *DbMap, exec SqlExecutor, i interface{}, query string,
 		sliceValue = reflect.Indirect(reflect.ValueOf(i))
 	)
 
+	// the destinations are reused from row to row
+	destp := selectDests.get(len(cols))
+	defer selectDests.put(destp)
+	dest := *destp
+	var custScan []CustomScanner
+	var dummy dummyField
+
 	for {
 		if !rows.Next() {
 			// if error occured return rawselect
@@ -305,25 +327,22 @@ func rawselect(m *DbMap, exec SqlExecutor, i interface{}, query string,
 			v.Interface().(DynamicTable).SetTableName(tableName)
 		}
 
-		dest := make([]interface{}, len(cols))
-
-		custScan := make([]CustomScanner, 0)
+		custScan = custScan[:0]
 
 		for x := range cols {
 			f := v.Elem()
 			if intoStruct {
 				index := colToFieldIndex[x]
 				if index == nil {
-					// this field is not present in the struct, so create a dummy
-					// value for rows.Scan to scan into
-					var dummy dummyField
+					// this field is not present in the struct, so scan it
+					// into a dummy value
 					dest[x] = &dummy
 					continue
 				}
 				f = f.FieldByIndex(index)
 			}
-			target := f.Addr().Interface()
-			if conv != nil {
+			target, ok := scanTarget(colInfos[x], f.Addr().Interface())
+			if !ok && conv != nil {
 				scanner, ok := conv.FromDb(target)
 				if ok {
 					target = scanner.Holder
@@ -344,6 +363,9 @@ func rawselect(m *DbMap, exec SqlExecutor, i interface{}, query string,
 				return nil, err
 			}
 		}
+		if intoStruct {
+			trackChanges(table, v.Elem())
+		}
 
 		if appendToSlice {
 			if !pointerElements {
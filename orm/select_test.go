@@ -0,0 +1,99 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+)
+
+// rowsDriver returns as many (id, body, extra) rows as the number its
+// queries end with.
+var rowsDriver = &fakeDriver{
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		var n int
+		for i := len(query) - 1; i >= 0 && query[i] >= '0' && query[i] <= '9'; i-- {
+			n, _ = strconv.Atoi(query[i:])
+		}
+		return &numberedRows{n: n}, nil
+	},
+}
+
+type numberedRows struct{ i, n int }
+
+func (r *numberedRows) Columns() []string { return []string{"id", "body", "extra"} }
+func (r *numberedRows) Close() error      { return nil }
+
+func (r *numberedRows) Next(dest []driver.Value) error {
+	if r.i == r.n {
+		return io.EOF
+	}
+	r.i++
+	dest[0], dest[1], dest[2] = int64(r.i), fmt.Sprintf("body %d", r.i), "ignored"
+	return nil
+}
+
+func openRowsDbMap(t testing.TB) *DbMap {
+	ResetModelCache()
+	dbmap := &DbMap{Db: rowsDriver.open(t), Dialect: MySQLDialect{"InnoDB", "UTF8"}}
+	Database().Set(dbmap)
+	RegisterModel(&searchArticle{})
+	BootStrap()
+	return dbmap
+}
+
+func TestSelectReusesDestinations(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+
+	for _, n := range []int{3, 1} {
+		var articles []searchArticle
+		_, err := dbmap.Select(&articles, "select * from search_article limit "+strconv.Itoa(n))
+		if err != nil && !NonFatalError(err) {
+			t.Fatal(err)
+		}
+		if len(articles) != n {
+			t.Fatalf("expected %d articles, got %d", n, len(articles))
+		}
+		for i, a := range articles {
+			if a.Id != int64(i+1) || a.Body != fmt.Sprintf("body %d", i+1) {
+				t.Errorf("unexpected article %d: %+v", i, a)
+			}
+		}
+	}
+}
+
+func TestDbMapCreateCriteria(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+
+	var articles []*searchArticle
+	if err := dbmap.CreateCriteria(&articles).Limit(2).Fill(&articles); err != nil && !NonFatalError(err) {
+		t.Fatal(err)
+	}
+	if len(articles) != 2 || articles[1].Body != "body 2" {
+		t.Errorf("expected 2 articles, got %+v", articles)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a model not registered")
+		}
+	}()
+	dbmap.CreateCriteria(&relAuthor{})
+}
+
+func BenchmarkSelect(b *testing.B) {
+	dbmap := openRowsDbMap(b)
+	defer Database().Set(nil)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var articles []*searchArticle
+		if _, err := dbmap.Select(&articles, "select * from search_article limit 1000"); err != nil && !NonFatalError(err) {
+			b.Fatal(err)
+		}
+	}
+}
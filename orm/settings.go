@@ -0,0 +1,162 @@
+package orm
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// A Setting applies the value of a reloadable tunable, one which can
+// change while the application runs without recreating connections.
+// Settings run with settingsMu held, which the statements hold for
+// reading while they read the tunables.
+type Setting func(m *DbMap, value string) error
+
+var (
+	settings = make(map[string]Setting)
+
+	// settingsMu guards the registered settings, and the reloadable
+	// fields of the DbMaps, DefaultRelsDepth and DefaultCollation against
+	// the reloads.
+	settingsMu sync.RWMutex
+)
+
+// RegisterSetting makes key, eg "orm.trace", reloadable through
+// DbMap.ApplySettings.
+func RegisterSetting(key string, setting Setting) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	settings[key] = setting
+}
+
+// ApplySettings applies the registered settings found in values, keyed
+// like in app.conf.  Unknown keys are ignored, so the whole orm section
+// of a configuration file can be passed.  Settings are applied in key
+// order and the first invalid value stops the reload.  It is safe to
+// call while m runs statements, eg from a file watcher.
+func (m *DbMap) ApplySettings(values map[string]string) error {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if _, ok := settings[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := settings[key](m, values[key]); err != nil {
+			return fmt.Errorf("<orm.ApplySettings> %s: %s", key, err)
+		}
+	}
+	return nil
+}
+
+// queryTimeout returns the QueryTimeout of m, see ApplySettings.
+func (m *DbMap) queryTimeout() time.Duration {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return m.QueryTimeout
+}
+
+// checkUnique returns the CheckUnique of m, see ApplySettings.
+func (m *DbMap) checkUnique() bool {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return m.CheckUnique
+}
+
+// clone returns a copy of m sharing its models and connections.
+func (m *DbMap) clone() *DbMap {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	c := *m
+	return &c
+}
+
+func init() {
+	RegisterSetting("orm.trace", func(m *DbMap, value string) error {
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		if !on {
+			m.logger, m.logPrefix = nil, ""
+		} else if m.logger == nil {
+			m.logger = log.New(os.Stdout, "", log.LstdFlags)
+		}
+		return nil
+	})
+	RegisterSetting("orm.trace.prefix", func(m *DbMap, value string) error {
+		if value != "" {
+			value += " "
+		}
+		m.logPrefix = value
+		return nil
+	})
+	RegisterSetting("orm.checkunique", func(m *DbMap, value string) error {
+		on, err := strconv.ParseBool(value)
+		m.CheckUnique = on
+		return err
+	})
+	RegisterSetting("orm.slowquery", func(m *DbMap, value string) error {
+		threshold, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		m.SlowQueryThreshold = threshold
+		return nil
+	})
+	RegisterSetting("orm.loglevel", func(m *DbMap, value string) error {
+		level, err := parseLogLevel(value)
+		if err != nil {
+			return err
+		}
+		m.QueryLogLevel = level
+		return nil
+	})
+	RegisterSetting("orm.criteriacache.ttl", func(m *DbMap, value string) error {
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		if m.CriteriaCache != nil {
+			m.CriteriaCache.SetTTL(ttl)
+		}
+		return nil
+	})
+	RegisterSetting("orm.querytimeout", func(m *DbMap, value string) error {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		m.QueryTimeout = timeout
+		return nil
+	})
+	RegisterSetting("orm.inlistlimit", func(m *DbMap, value string) error {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		m.InListLimit = limit
+		return nil
+	})
+	RegisterSetting("orm.relsdepth", func(m *DbMap, value string) error {
+		depth, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		DefaultRelsDepth = depth
+		return nil
+	})
+	RegisterSetting("orm.collation", func(m *DbMap, value string) error {
+		DefaultCollation = value
+		return nil
+	})
+}
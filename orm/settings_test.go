@@ -0,0 +1,66 @@
+package orm
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestApplySettings(t *testing.T) {
+	m := &DbMap{}
+	depth := DefaultRelsDepth
+	defer func() { DefaultRelsDepth, DefaultCollation = depth, "" }()
+
+	err := m.ApplySettings(map[string]string{
+		"orm.trace":        "true",
+		"orm.trace.prefix": "[db]",
+		"orm.checkunique":  "true",
+		"orm.relsdepth":    "4",
+		"orm.collation":    "utf8mb4_bin",
+		"orm.driver":       "mysql",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.logger == nil || m.logPrefix != "[db] " || !m.CheckUnique || DefaultRelsDepth != 4 || DefaultCollation != "utf8mb4_bin" {
+		t.Errorf("settings not applied: %+v depth %d", m, DefaultRelsDepth)
+	}
+
+	if err = m.ApplySettings(map[string]string{"orm.trace": "false"}); err != nil || m.logger != nil {
+		t.Errorf("trace not turned off (%v)", err)
+	}
+	if err = m.ApplySettings(map[string]string{"orm.relsdepth": "deep"}); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+}
+
+// Run with -race: the reloads don't race with the statements.
+func TestApplySettingsWhileQuerying(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			var articles []searchArticle
+			_, err := dbmap.WithContext(context.Background()).Select(&articles, "select * from search_article limit 1")
+			if err != nil && !NonFatalError(err) {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for _, timeout := range []string{"1m", "2m", "3m"} {
+		err := dbmap.ApplySettings(map[string]string{
+			"orm.querytimeout": timeout,
+			"orm.slowquery":    timeout,
+			"orm.checkunique":  "true",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
@@ -1,5 +1,10 @@
 package orm
 
+import "strconv"
+
+// Select is a select statement of a criteria.  Its dialect pages the rows
+// when limit or offset is set, see Paginator.
+
 type Select struct {
 	selectClause         string
 	fromClause           string
@@ -8,6 +13,9 @@ type Select struct {
 	outerJoinsAfterWhere string
 	orderByClause        string
 	groupByClause        string
+	limit                int
+	offset               int
+	dialect              Dialect
 }
 
 func (s Select) ToStatementString() (sql string) {
@@ -38,9 +46,26 @@ func (s Select) ToStatementString() (sql string) {
 		sql += "  group by " + s.groupByClause
 	}
 
-	if s.orderByClause != "" {
-		sql += "  order by  " + s.orderByClause
+	if p, ok := s.dialect.(Paginator); ok && (s.limit > 0 || s.offset > 0) {
+		return p.LimitSQL(sql, s.orderByClause, s.offset, s.limit)
 	}
+	return limitSQL(sql, s.orderByClause, s.offset, s.limit, "")
+}
 
-	return
+// limitSQL appends the order by, limit and offset clauses to query,
+// with a limit of noLimit when only offset is set and noLimit isn't
+// empty.
+func limitSQL(query, orderBy string, offset, limit int, noLimit string) string {
+	if orderBy != "" {
+		query += "  order by  " + orderBy
+	}
+	if limit > 0 {
+		query += " limit " + strconv.Itoa(limit)
+	} else if offset > 0 && noLimit != "" {
+		query += " limit " + noLimit
+	}
+	if offset > 0 {
+		query += " offset " + strconv.Itoa(offset)
+	}
+	return query
 }
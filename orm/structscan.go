@@ -0,0 +1,177 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SelectStruct runs query and scans its rows into dest, a pointer to a
+// struct filled from the first row, or a pointer to a slice of structs or
+// of struct pointers the rows are appended to.  Unlike Select the struct
+// needn't be a model, which suits reporting queries: each column maps to
+// the field named by the column tag, or else to the field whose snake
+// case name it is, eg total_amount to TotalAmount, looking into the
+// anonymous and embedded structs.  The columns are renamed by the mapper
+// of m first, see SetMapperFunc.
+//
+// The columns without a field are skipped and reported by a
+// NoFieldInTypeError once the rows are scanned.  ErrNoRows is returned
+// when dest is a struct and no row matched.
+func (m *DbMap) SelectStruct(dest interface{}, query string, args ...interface{}) error {
+	return selectStruct(m, m, dest, query, args...)
+}
+
+// SelectStruct has the same behavior as DbMap.SelectStruct(), but runs in
+// a transaction.
+func (t *Transaction) SelectStruct(dest interface{}, query string, args ...interface{}) error {
+	return selectStruct(t.dbmap, t, dest, query, args...)
+}
+
+// SetMapperFunc sets the function naming the field a column maps to, for
+// the databases whose legacy column names the orm doesn't match, eg
+// strings.ToLower for ALLCAPS columns.  It returns a field name, or a
+// column name as the orm names it.  The columns scanned by Select and
+// SelectStruct are renamed by f, and so are the field names given to the
+// criteria when the model has no such field.  nil removes the mapper.
+func (m *DbMap) SetMapperFunc(f func(column string) string) {
+	m.mapper = f
+}
+
+func selectStruct(m *DbMap, exec SqlExecutor, dest interface{}, query string, args ...interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("<orm.SelectStruct> dest must be a pointer to a struct or a slice, got %T", dest)
+	}
+	v = v.Elem()
+	t := v.Type()
+	slice, pointerElements := t.Kind() == reflect.Slice, false
+	if slice {
+		t = t.Elem()
+		if pointerElements = t.Kind() == reflect.Ptr; pointerElements {
+			t = t.Elem()
+		}
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("<orm.SelectStruct> dest must be a pointer to a struct or a slice, got %T", dest)
+	}
+
+	if len(args) == 1 {
+		query, args = maybeExpandNamedQuery(m, query, args)
+	}
+	rows, done, err := queryRows(exec, query, args...)
+	if err != nil {
+		return err
+	}
+	defer done()
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	var nonFatalErr error
+	indexes := structFieldIndexes(t, cols, m.mapper)
+	var missing []string
+	for x, index := range indexes {
+		if index == nil {
+			missing = append(missing, cols[x])
+		}
+	}
+	if len(missing) > 0 {
+		nonFatalErr = &NoFieldInTypeError{TypeName: t.Name(), MissingColNames: missing}
+	}
+
+	dests := make([]interface{}, len(cols))
+	var dummy dummyField
+	found := false
+	for rows.Next() {
+		elem := reflect.New(t)
+		for x, index := range indexes {
+			if index == nil {
+				dests[x] = &dummy
+			} else {
+				dests[x] = elem.Elem().FieldByIndex(index).Addr().Interface()
+			}
+		}
+		if err = rows.Scan(dests...); err != nil {
+			return err
+		}
+		found = true
+		if !slice {
+			v.Set(elem.Elem())
+			break
+		}
+		if pointerElements {
+			v.Set(reflect.Append(v, elem))
+		} else {
+			v.Set(reflect.Append(v, elem.Elem()))
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	if !slice && !found {
+		return ErrNoRows
+	}
+	return nonFatalErr
+}
+
+// structFieldIndexes returns the indexes of the fields of the struct type
+// t the columns cols, renamed by mapper when not nil, map to, see
+// SelectStruct, nil for the columns without a field.
+func structFieldIndexes(t reflect.Type, cols []string, mapper func(string) string) [][]int {
+	fields := make(map[string][]int)
+	structColumns(t, nil, "", fields)
+	indexes := make([][]int, len(cols))
+	for x, col := range cols {
+		if mapper != nil {
+			col = mapper(col)
+		}
+		indexes[x] = fields[strings.ToLower(col)]
+	}
+	return indexes
+}
+
+// structColumns adds to fields the columns of the fields of t, prefixed
+// with prefix, by lower-cased column name.  The fields of t shadow those
+// of its embedded structs.
+func structColumns(t reflect.Type, index []int, prefix string, fields map[string][]int) {
+	type nested struct {
+		index  []int
+		typ    reflect.Type
+		prefix string
+	}
+	var embedded []nested
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+		attrs, tags := parseStructTag(fieldTag(sf))
+		if attrs["-"] {
+			continue
+		}
+		if sf.Type.Kind() == reflect.Struct && (sf.Anonymous || attrs["embedded"]) {
+			embedded = append(embedded, nested{fieldIndex, sf.Type, prefix + tags["prefix"]})
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		names := []string{tags["column"], snakeString(sf.Name), sf.Name}
+		if names[0] != "" {
+			names = names[:1]
+		}
+		for _, name := range names {
+			name = strings.ToLower(prefix + name)
+			if _, ok := fields[name]; !ok {
+				fields[name] = fieldIndex
+			}
+		}
+	}
+	for _, e := range embedded {
+		structColumns(e.typ, e.index, e.prefix, fields)
+	}
+}
@@ -0,0 +1,68 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestSelectStruct(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+
+	var lines []*reportLine
+	if err := dbmap.SelectStruct(&lines, "select id, body, extra from report limit 2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	for i, l := range lines {
+		if l.ID != int64(i+1) || l.Text != fmt.Sprintf("body %d", i+1) || l.Extra.Tra != "ignored" {
+			t.Errorf("unexpected line %d: %+v", i, l)
+		}
+	}
+
+	var line reportLine
+	if err := dbmap.SelectStruct(&line, "select id, body, extra from report limit 3"); err != nil || line.ID != 1 {
+		t.Errorf("expected the first line, got %+v (%v)", line, err)
+	}
+	if err := dbmap.SelectStruct(&line, "select id, body, extra from report limit 0"); !errors.Is(err, ErrNoRows) {
+		t.Errorf("expected ErrNoRows, got %v", err)
+	}
+
+	var partial []struct{ Id int64 }
+	err := dbmap.SelectStruct(&partial, "select id, body, extra from report limit 1")
+	if fe, ok := err.(*NoFieldInTypeError); !ok || len(fe.MissingColNames) != 2 || len(partial) != 1 || partial[0].Id != 1 {
+		t.Errorf("expected the id scanned and the other columns reported, got %+v (%v)", partial, err)
+	}
+
+	if err := dbmap.SelectStruct(new(int), "select 1"); err == nil {
+		t.Error("expected an error scanning into an int")
+	}
+}
+
+func TestMapperFunc(t *testing.T) {
+	dbmap := openRowsDbMap(t)
+	defer Database().Set(nil)
+	legacy := map[string]string{"id": "Ident", "body": "Content", "extra": "body", "ART_BODY": "Body"}
+	dbmap.SetMapperFunc(func(column string) string { return legacy[column] })
+
+	var rows []struct {
+		Ident   int64
+		Content string
+	}
+	if err := dbmap.SelectStruct(&rows, "select id, body, extra from report limit 1"); !NonFatalError(err) || len(rows) != 1 || rows[0].Ident != 1 || rows[0].Content != "body 1" {
+		t.Errorf("expected the mapped columns scanned, got %+v (%v)", rows, err)
+	}
+
+	var articles []searchArticle
+	if _, err := dbmap.Select(&articles, "select id, body, extra from search_article limit 1"); !NonFatalError(err) || len(articles) != 1 || articles[0].Body != "ignored" {
+		t.Errorf("expected extra scanned into Body, got %+v (%v)", articles, err)
+	}
+
+	mi, _ := modelCache.get("search_article")
+	if fi, ok := dbmap.getByAny(mi, "ART_BODY"); !ok || fi.name != "Body" {
+		t.Errorf("expected ART_BODY mapped to Body, got %v", fi)
+	}
+}
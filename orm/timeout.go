@@ -0,0 +1,72 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// baseContext returns the context of m, see WithContext, or the
+// background context.
+func (m *DbMap) baseContext() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+// statementContext returns the context of a statement, derived from ctx
+// and ending within timeout unless it is zero, and the function releasing
+// it once the statement, and its rows, are done.  The rows returned by
+// Query and QueryRow outlive the call, so the context of those is only
+// released at its deadline; the ORM reads through queryRows and scanRow,
+// which release it once the rows are closed.
+func statementContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// queryRows runs query through exec, returning the function releasing its
+// statement context, to call once the rows are closed.
+func queryRows(exec SqlExecutor, query string, args ...interface{}) (*sql.Rows, context.CancelFunc, error) {
+	switch e := exec.(type) {
+	case *DbMap:
+		return e.query(query, args)
+	case *Transaction:
+		return e.query(query, args)
+	}
+	rows, err := exec.Query(query, args...)
+	return rows, func() {}, err
+}
+
+// scanRow scans the row of query, run through exec, into dest, and
+// releases its statement context.
+func scanRow(exec SqlExecutor, query string, args []interface{}, dest ...interface{}) error {
+	var (
+		row    *sql.Row
+		cancel context.CancelFunc = func() {}
+	)
+	switch e := exec.(type) {
+	case *DbMap:
+		row, cancel = e.queryRow(query, args)
+	case *Transaction:
+		row, cancel = e.queryRow(query, args)
+	default:
+		row = exec.QueryRow(query, args...)
+	}
+	defer cancel()
+	return row.Scan(dest...)
+}
+
+// withQueryTimeout returns m running its statements with timeout, m itself
+// when timeout is zero or already the one of m.
+func (m *DbMap) withQueryTimeout(timeout time.Duration) *DbMap {
+	if timeout <= 0 || timeout == m.queryTimeout() {
+		return m
+	}
+	c := m.clone()
+	c.QueryTimeout = timeout
+	return c
+}
@@ -0,0 +1,49 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+// hangDriver runs statements which only end when cancelled.
+var hangDriver = &fakeDriver{
+	exec: func(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	},
+}
+
+func TestQueryTimeout(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &searchArticle{})
+	dbmap.Db = hangDriver.open(t)
+	dbmap.QueryTimeout = 20 * time.Millisecond
+
+	started := time.Now()
+	if _, err := dbmap.Exec("update search_article set body = ''"); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to cancel the statement, got %v", err)
+	}
+	if d := time.Since(started); d > time.Second {
+		t.Errorf("statement cancelled after %s", d)
+	}
+
+	criteria := newTestCriteria(dbmap, &searchArticle{}).Timeout(10 * time.Millisecond)
+	if criteria.GetTimeout() != 10*time.Millisecond {
+		t.Errorf("unexpected criteria timeout %s", criteria.GetTimeout())
+	}
+	if _, err := criteria.Delete(); err != context.DeadlineExceeded {
+		t.Errorf("expected the criteria timeout to cancel the delete, got %v", err)
+	}
+
+	// derived from the context of the DbMap
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := dbmap.WithContext(ctx).Exec("update search_article set body = ''"); err != context.Canceled {
+		t.Errorf("expected the context of the DbMap to cancel the statement, got %v", err)
+	}
+
+	if dbmap.withQueryTimeout(0) != dbmap || dbmap.withQueryTimeout(time.Second).QueryTimeout != time.Second {
+		t.Error("unexpected DbMap for the criteria timeout")
+	}
+}
@@ -0,0 +1,109 @@
+package orm
+
+import "reflect"
+
+// Tracked, embedded in a model, turns on its change tracking: the values
+// of its columns are remembered when it is loaded, inserted or updated,
+// and Update then sets only the columns changed since, so concurrent
+// writes to the other columns aren't clobbered.  An unchanged model isn't
+// updated at all.  UpdateColumns still sets the columns of the fields named.
+//
+// Example:
+//
+//	type User struct {
+//		orm.Tracked
+//		Id   int64 `orm:"pk;auto"`
+//		Name string
+//	}
+type Tracked struct {
+	original map[string]interface{}
+}
+
+// tracker is implemented by the models embedding Tracked.
+type tracker interface {
+	tracked() *Tracked
+}
+
+func (t *Tracked) tracked() *Tracked {
+	return t
+}
+
+// Changed returns the names of the fields of model, a pointer to a model
+// embedding Tracked, changed since it was loaded, inserted or updated.
+func Changed(model interface{}) []string {
+	table, elem, err := Database().Get().tableForPointer(model, false)
+	if err != nil {
+		return nil
+	}
+	tr, ok := model.(tracker)
+	if !ok || tr.tracked().original == nil {
+		return nil
+	}
+	t := tr.tracked()
+	var names []string
+	for _, fi := range table.fields.fieldsDB {
+		if t.changed(fi, elem) {
+			names = append(names, fi.name)
+		}
+	}
+	return names
+}
+
+// changed reports whether the column fi of elem differs from its
+// remembered value.
+func (t *Tracked) changed(fi *fieldInfo, elem reflect.Value) bool {
+	v, ok := t.original[fi.name]
+	return !ok || !reflect.DeepEqual(v, trackedValue(fi, elem))
+}
+
+// trackChanges remembers the values of the columns of elem, if its model
+// embeds Tracked.
+func trackChanges(table *modelInfo, elem reflect.Value) {
+	tr, ok := elem.Addr().Interface().(tracker)
+	if !ok || table == nil {
+		return
+	}
+	t := tr.tracked()
+	t.original = make(map[string]interface{}, len(table.fields.fieldsDB))
+	for _, fi := range table.fields.fieldsDB {
+		t.original[fi.name] = trackedValue(fi, elem)
+	}
+}
+
+// changedFilter returns the filter of the columns of elem changed since
+// they were remembered, the version column included, and whether any
+// changed.  It returns nil if elem isn't tracked.
+func changedFilter(table *modelInfo, elem reflect.Value) (ColumnFilter, bool) {
+	tr, ok := elem.Addr().Interface().(tracker)
+	if !ok || tr.tracked().original == nil {
+		return nil, true
+	}
+	t := tr.tracked()
+	changed := make(map[*fieldInfo]bool)
+	for _, fi := range table.fields.fieldsDB {
+		if fi != table.version && t.changed(fi, elem) {
+			changed[fi] = true
+		}
+	}
+	if len(changed) == 0 {
+		return nil, false
+	}
+	return func(col *fieldInfo) bool {
+		return changed[col] || col == table.version
+	}, true
+}
+
+// trackedValue returns a copy of the value of the column fi of elem, the
+// key of the related model for relations.
+func trackedValue(fi *fieldInfo, elem reflect.Value) interface{} {
+	if fi.fieldType&IsRelField > 0 && !fi.array {
+		return bindValue(fi, elem)
+	}
+	v := elem.FieldByIndex(fi.fieldIndex)
+	if v.Kind() == reflect.Slice && !v.IsNil() {
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c.Interface()
+	}
+	return v.Interface()
+}
@@ -0,0 +1,84 @@
+package orm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTrackedUpdate(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &trackedUser{})
+	defer Database().Set(nil)
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+
+	user := &trackedUser{Id: 1, Name: "alice", Email: "alice@example.com"}
+	if changed := Changed(user); changed != nil {
+		t.Errorf("untracked user changed %v", changed)
+	}
+	// tracked as if loaded, the writes of the dry-run mode don't track
+	table, err := dbmap.TableFor(reflect.TypeOf(trackedUser{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trackChanges(table, reflect.ValueOf(user).Elem())
+
+	if _, err := dbmap.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	if statements := dbmap.Statements(); len(statements) != 0 {
+		t.Errorf("unchanged user updated: %v", statements)
+	}
+
+	user.Email = "alice@example.org"
+	if changed := Changed(user); !reflect.DeepEqual(changed, []string{"Email"}) {
+		t.Errorf("expected Email changed, got %v", changed)
+	}
+	if _, err := dbmap.Update(user); err != nil {
+		t.Fatal(err)
+	}
+	statements := dbmap.Statements()
+	if want := "update `tracked_user` set `email`=? where `id`=?;"; len(statements) != 1 || statements[0].Query != want {
+		t.Errorf("expected %q, got %v", want, statements)
+	}
+	if changed := Changed(user); !reflect.DeepEqual(changed, []string{"Email"}) {
+		t.Errorf("expected Email still changed after a dry-run update, got %v", changed)
+	}
+
+	// the plans of filtered updates are not cached
+	if _, err := dbmap.UpdateColumns(&trackedUser{Id: 2, Name: "bob"}, "Name"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dbmap.Update(&trackedUser{Id: 2, Name: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	statements = dbmap.Statements()
+	if len(statements) != 2 || !strings.Contains(statements[1].Query, "`name`=?") || !strings.Contains(statements[1].Query, "`email`=?") {
+		t.Errorf("expected an update of every column, got %v", statements)
+	}
+}
+
+func TestUpdateColumns(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &auditPost{})
+	defer Database().Set(nil)
+	dbmap.DryRun(true)
+	defer dbmap.DryRun(false)
+
+	post := &auditPost{Id: 1, Title: "orm", CreatedBy: 3}
+	if _, err := dbmap.WithContext(WithUser(context.Background(), 7)).UpdateColumns(post, "Title"); err != nil {
+		t.Fatal(err)
+	}
+	statements := dbmap.Statements()
+	if len(statements) != 1 || !strings.Contains(statements[0].Query, "`title`=?") || !strings.Contains(statements[0].Query, "`updated_by`=?") || strings.Contains(statements[0].Query, "created_by") {
+		t.Errorf("unexpected update %v", statements)
+	}
+
+	if _, err := dbmap.UpdateColumns(post); err == nil {
+		t.Error("expected an error without fields")
+	}
+	_, err := dbmap.UpdateColumns(post, "Body")
+	if fe, ok := err.(*FieldError); !ok || fe.Field != "Body" {
+		t.Errorf("expected a *FieldError, got %v", err)
+	}
+}
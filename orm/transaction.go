@@ -12,6 +12,7 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"reflect"
@@ -39,13 +40,13 @@ func (t *Transaction) Update(list ...interface{}) (int64, error) {
 }
 
 // UpdateColumns had the same behavior as DbMap.UpdateColumns(), but runs in a transaction.
-func (t *Transaction) UpdateColumns(filter ColumnFilter, list ...interface{}) (int64, error) {
-	return update(t.dbmap, t, filter, list...)
+func (t *Transaction) UpdateColumns(model interface{}, fields ...string) (int64, error) {
+	return updateColumns(t.dbmap, t, model, fields)
 }
 
 // Delete has the same behavior as DbMap.Delete(), but runs in a transaction.
 func (t *Transaction) Delete(list ...interface{}) (int64, error) {
-	return delete(t.dbmap, t, list...)
+	return deleteRows(t.dbmap, t, list...)
 }
 
 // Get has the same behavior as DbMap.Get(), but runs in a transaction.
@@ -59,12 +60,17 @@ func (t *Transaction) Select(i interface{}, query string, args ...interface{}) (
 }
 
 // Exec has the same behavior as DbMap.Exec(), but runs in a transaction.
-func (t *Transaction) Exec(query string, args ...interface{}) (sql.Result, error) {
-	if t.dbmap.logger != nil {
-		now := time.Now()
-		defer t.dbmap.trace(now, query, args...)
+func (t *Transaction) Exec(query string, args ...interface{}) (res sql.Result, err error) {
+	ctx := t.dbmap.baseContext()
+	if t.dbmap.hooked() {
+		e := t.dbmap.beforeQuery(ctx, query, args)
+		defer t.dbmap.afterQuery(e, &err)
+		ctx, query, args = e.Ctx, e.Query, e.Args
 	}
-	return exec(t, query, args...)
+	if t.dbmap.tracing() {
+		defer t.dbmap.trace(time.Now(), query, args, &err)
+	}
+	return exec(ctx, t, query, args...)
 }
 
 // SelectInt is a convenience wrapper around the gorp.SelectInt function.
@@ -103,12 +109,11 @@ func (t *Transaction) SelectOne(holder interface{}, query string, args ...interf
 }
 
 // Commit commits the underlying database transaction.
-func (t *Transaction) Commit() error {
+func (t *Transaction) Commit() (err error) {
 	if !t.closed {
 		t.closed = true
-		if t.dbmap.logger != nil {
-			now := time.Now()
-			defer t.dbmap.trace(now, "commit;")
+		if t.dbmap.tracing() {
+			defer t.dbmap.trace(time.Now(), "commit;", nil, &err)
 		}
 		return t.tx.Commit()
 	}
@@ -117,12 +122,11 @@ func (t *Transaction) Commit() error {
 }
 
 // Rollback rolls back the underlying database transaction.
-func (t *Transaction) Rollback() error {
+func (t *Transaction) Rollback() (err error) {
 	if !t.closed {
 		t.closed = true
-		if t.dbmap.logger != nil {
-			now := time.Now()
-			defer t.dbmap.trace(now, "rollback;")
+		if t.dbmap.tracing() {
+			defer t.dbmap.trace(time.Now(), "rollback;", nil, &err)
 		}
 		return t.tx.Rollback()
 	}
@@ -133,88 +137,127 @@ func (t *Transaction) Rollback() error {
 // Savepoint creates a savepoint with the given name. The name is interpolated
 // directly into the SQL SAVEPOINT statement, so you must sanitize it if it is
 // derived from user input.
-func (t *Transaction) Savepoint(name string) error {
+func (t *Transaction) Savepoint(name string) (err error) {
 	query := "savepoint " + t.dbmap.Dialect.QuoteField(name)
-	if t.dbmap.logger != nil {
-		now := time.Now()
-		defer t.dbmap.trace(now, query, nil)
+	if t.dbmap.tracing() {
+		defer t.dbmap.trace(time.Now(), query, nil, &err)
 	}
-	_, err := t.tx.Exec(query)
+	_, err = t.tx.Exec(query)
 	return err
 }
 
 // RollbackToSavepoint rolls back to the savepoint with the given name. The
 // name is interpolated directly into the SQL SAVEPOINT statement, so you must
 // sanitize it if it is derived from user input.
-func (t *Transaction) RollbackToSavepoint(savepoint string) error {
+func (t *Transaction) RollbackToSavepoint(savepoint string) (err error) {
 	query := "rollback to savepoint " + t.dbmap.Dialect.QuoteField(savepoint)
-	if t.dbmap.logger != nil {
-		now := time.Now()
-		defer t.dbmap.trace(now, query, nil)
+	if t.dbmap.tracing() {
+		defer t.dbmap.trace(time.Now(), query, nil, &err)
 	}
-	_, err := t.tx.Exec(query)
+	_, err = t.tx.Exec(query)
 	return err
 }
 
 // ReleaseSavepint releases the savepoint with the given name. The name is
 // interpolated directly into the SQL SAVEPOINT statement, so you must sanitize
 // it if it is derived from user input.
-func (t *Transaction) ReleaseSavepoint(savepoint string) error {
+func (t *Transaction) ReleaseSavepoint(savepoint string) (err error) {
 	query := "release savepoint " + t.dbmap.Dialect.QuoteField(savepoint)
-	if t.dbmap.logger != nil {
-		now := time.Now()
-		defer t.dbmap.trace(now, query, nil)
+	if t.dbmap.tracing() {
+		defer t.dbmap.trace(time.Now(), query, nil, &err)
 	}
-	_, err := t.tx.Exec(query)
+	_, err = t.tx.Exec(query)
 	return err
 }
 
 // Prepare has the same behavior as DbMap.Prepare(), but runs in a transaction.
-func (t *Transaction) Prepare(query string) (*sql.Stmt, error) {
-	if t.dbmap.logger != nil {
-		now := time.Now()
-		defer t.dbmap.trace(now, query, nil)
+func (t *Transaction) Prepare(query string) (stmt *sql.Stmt, err error) {
+	if t.dbmap.tracing() {
+		defer t.dbmap.trace(time.Now(), query, nil, &err)
 	}
 	return t.tx.Prepare(query)
 }
 
 func (t *Transaction) QueryRow(query string, args ...interface{}) *sql.Row {
-	if t.dbmap.logger != nil {
-		now := time.Now()
-		defer t.dbmap.trace(now, query, args...)
+	row, _ := t.queryRow(query, args)
+	return row
+}
+
+// queryRow is QueryRow returning the function releasing the statement
+// context, once the row is scanned.
+func (t *Transaction) queryRow(query string, args []interface{}) (*sql.Row, context.CancelFunc) {
+	ctx := t.dbmap.baseContext()
+	if t.dbmap.hooked() {
+		e := t.dbmap.beforeQuery(ctx, query, args)
+		defer t.dbmap.afterQuery(e, nil)
+		ctx, query, args = e.Ctx, e.Query, e.Args
 	}
-	return t.tx.QueryRow(query, args...)
+	if t.dbmap.tracing() {
+		defer t.dbmap.trace(time.Now(), query, args, nil)
+	}
+	ctx, cancel := statementContext(ctx, t.dbmap.queryTimeout())
+	return t.tx.QueryRowContext(ctx, query, args...), cancel
 }
 
 func (t *Transaction) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	if t.dbmap.logger != nil {
-		now := time.Now()
-		defer t.dbmap.trace(now, query, args...)
+	rows, _, err := t.query(query, args)
+	return rows, err
+}
+
+// query is Query returning the function releasing the statement context,
+// once the rows are closed.
+func (t *Transaction) query(query string, args []interface{}) (rows *sql.Rows, cancel context.CancelFunc, err error) {
+	ctx := t.dbmap.baseContext()
+	if t.dbmap.hooked() {
+		e := t.dbmap.beforeQuery(ctx, query, args)
+		defer t.dbmap.afterQuery(e, &err)
+		ctx, query, args = e.Ctx, e.Query, e.Args
 	}
-	return t.tx.Query(query, args...)
+	if t.dbmap.tracing() {
+		defer t.dbmap.trace(time.Now(), query, args, &err)
+	}
+	ctx, cancel = statementContext(ctx, t.dbmap.queryTimeout())
+	if rows, err = t.tx.QueryContext(ctx, query, args...); err != nil {
+		cancel()
+	}
+	return rows, cancel, t.dbmap.translateError(err)
 }
 
 //CreateCriteria for
 func (t *Transaction) CreateCriteria(ptrStructOrTableName interface{}) (criteria Criteria) {
+	criteria = criteriaFor(t.dbmap, ptrStructOrTableName)
+	if criteria == nil {
+		panic(fmt.Errorf("<Transaction.CreateCriteria> table name: `%s` not exists", ptrStructOrTableName))
+	}
+	return
+}
+
+// criteriaFor returns the criteria of the model of ptrStructOrTableName,
+// nil if it isn't registered.  The model of a pointer to a slice, eg
+// &[]*User{}, is the type of its elements, for Criteria.Fill.
+func criteriaFor(m *DbMap, ptrStructOrTableName interface{}) (criteria Criteria) {
 
 	val := reflect.ValueOf(ptrStructOrTableName)
 	typ := reflect.Indirect(val).Type()
+	if typ.Kind() == reflect.Slice && val.Kind() == reflect.Ptr {
+		if typ = typ.Elem(); typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		ptrStructOrTableName = reflect.New(typ).Interface()
+	}
 
 	switch ptrStructOrTableName.(type) {
 	case string:
 		name := snakeString(ptrStructOrTableName.(string))
-		if tmap, er := t.dbmap.TableForName(name, true); er == nil {
-			criteria = newCriteria(t.dbmap, tmap, ptrStructOrTableName, typ)
+		if tmap, er := m.TableForName(name); er == nil {
+			criteria = newCriteria(m, tmap, ptrStructOrTableName, typ)
 		}
 	case interface{}:
-		if tmap, er := t.dbmap.TableFor(typ, true); er == nil {
-			criteria = newCriteria(t.dbmap, tmap, ptrStructOrTableName, typ)
+		if tmap, er := m.TableFor(typ); er == nil {
+			criteria = newCriteria(m, tmap, ptrStructOrTableName, typ)
 		}
 	default:
 
-	}
-	if criteria == nil {
-		panic(fmt.Errorf("<Transaction.CreateCriteria> table name: `%s` not exists", ptrStructOrTableName))
 	}
 	return
 }
@@ -226,3 +269,8 @@ func (t *Transaction) SaveM2M(model interface{}, fields ...string) error {
 func (t *Transaction) QueryM2M(model interface{}, fields ...string) error {
 	return queryM2M(t.dbmap, t, model, fields...)
 }
+
+// LoadRelated has the same behavior as DbMap.LoadRelated(), but runs in a transaction.
+func (t *Transaction) LoadRelated(model interface{}, fields ...string) error {
+	return loadRelated(t.dbmap, t, model, fields...)
+}
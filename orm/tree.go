@@ -0,0 +1,565 @@
+package orm
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Tree storage strategies for self-referential models, selected with the
+// `tree` tag on the rel(fk) field pointing to the parent row:
+//
+//	type Category struct {
+//		Id     int64     `orm:"pk;auto"`
+//		Parent *Category `orm:"rel(fk);null;tree(path)"`
+//		Path   string    `orm:"tree_path"`
+//	}
+//
+// With tree(adjacency) only the parent is stored, and the trees are walked
+// one level per query.  A self-referential rel(fk) not tagged tree is a
+// plain relation.  With tree(path) the field tagged `tree_path` holds the ids
+// of all ancestors and the row itself, eg "/1/5/9/".  With tree(closure)
+// a "<table>_closure" table holding every (ancestor, descendant, depth)
+// pair is created and maintained next to the model table.
+//
+// The hierarchy is maintained on Insert and Delete.  Changing the parent
+// of an existing row must be done with MoveTree, plain Update does not
+// rewrite the stored hierarchy.  Outside of a transaction, the statements
+// writing a node of a path or closure tree and its hierarchy run in one.
+const (
+	TreeAdjacency = "adjacency"
+	TreePath      = "path"
+	TreeClosure   = "closure"
+)
+
+// combine model info to the closure table model info of a tree model.
+func newClosureModelInfo(m *modelInfo) (mi *modelInfo) {
+	pk := m.fields.GetOnePrimaryKey()
+
+	mi = new(modelInfo)
+	mi.manual = false
+	mi.naming = m.naming
+	mi.affix = m.affix
+	mi.fields = newFields()
+	mi.schemaName = m.schemaName
+	table := m.affix.trim(m.table) + "_closure"
+	mi.table = mi.affix.apply(table)
+	mi.name = camelString(table)
+	mi.fullName = m.pkg + "." + mi.name
+
+	ancestor := new(fieldInfo)
+	descendant := new(fieldInfo)
+	for _, f := range []*fieldInfo{ancestor, descendant} {
+		f.dbcol = true
+		f.rel = true
+		f.gotype = pk.gotype
+		f.fieldType = RelForeignKey
+		f.relTable = m.table
+		f.relModelInfo = m
+		f.onDelete = odCascade
+		f.isNotNull = true
+		f.mi = mi
+	}
+	ancestor.name = "Ancestor"
+	ancestor.column = "ancestor"
+	descendant.name = "Descendant"
+	descendant.column = "descendant"
+
+	depth := new(fieldInfo)
+	depth.dbcol = true
+	depth.name = "Depth"
+	depth.column = "depth"
+	depth.fieldType = TypeIntegerField
+	depth.gotype = reflect.TypeOf(int(0))
+	depth.isNotNull = true
+	depth.mi = mi
+
+	for _, f := range []*fieldInfo{ancestor, descendant, depth} {
+		f.fullName = mi.fullName + "." + f.name
+		mi.fields.Add(f)
+	}
+	mi.fields.keys[ancestor.name] = ancestor
+	mi.fields.keys[descendant.name] = descendant
+	return
+}
+
+// treeColumns returns the quoted, comma separated list of the scannable
+// columns of a tree model.  Relation columns are left out, so the parent
+// of the returned rows is not loaded.
+func (t *modelInfo) treeColumns(alias string) string {
+	dialect := Database().Get().Dialect
+	cols := make([]string, 0, len(t.fields.fieldsDB))
+	for _, fi := range t.fields.fieldsDB {
+		if fi.rel || fi.transient {
+			continue
+		}
+		cols = append(cols, alias+"."+dialect.QuoteField(fi.column))
+	}
+	return strings.Join(cols, ", ")
+}
+
+// treeNode resolves the model info and primary key of a tree model pointer.
+func treeNode(m *DbMap, node interface{}) (*modelInfo, reflect.Value, interface{}, error) {
+	table, elem, err := m.tableForPointer(node, true)
+	if err != nil {
+		return nil, reflect.Value{}, nil, err
+	}
+	if table.treeParent == nil {
+		return nil, reflect.Value{}, nil, fmt.Errorf("model `%s` is not a tree, missing tree tag", table.fullName)
+	}
+	_, pk, exist := getExistPk(table, elem)
+	if !exist {
+		return nil, reflect.Value{}, nil, fmt.Errorf("tree node of `%s` has no primary key value", table.fullName)
+	}
+	return table, elem, pk, nil
+}
+
+// treeParentPk returns the primary key of the parent referenced by elem.
+func treeParentPk(table *modelInfo, elem reflect.Value) (interface{}, bool) {
+	pv := elem.FieldByIndex(table.treeParent.fieldIndex)
+	if pv.IsNil() {
+		return nil, false
+	}
+	_, pk, exist := getExistPk(table, pv.Elem())
+	return pk, exist
+}
+
+func treeSelectPath(m *DbMap, exec SqlExecutor, table *modelInfo, pk interface{}) (string, error) {
+	dialect := m.Dialect
+	query := fmt.Sprintf("select %s from %s where %s=%s",
+		dialect.QuoteField(table.treePath.column),
+		dialect.QuotedTableForQuery(table.schemaName, table.table),
+		dialect.QuoteField(table.fields.GetOnePrimaryKey().column),
+		dialect.BindVar(0))
+	return SelectStr(exec, query, pk)
+}
+
+func treeUpdatePath(m *DbMap, exec SqlExecutor, table *modelInfo, path string, pk interface{}) error {
+	dialect := m.Dialect
+	query := fmt.Sprintf("update %s set %s=%s where %s=%s",
+		dialect.QuotedTableForQuery(table.schemaName, table.table),
+		dialect.QuoteField(table.treePath.column),
+		dialect.BindVar(0),
+		dialect.QuoteField(table.fields.GetOnePrimaryKey().column),
+		dialect.BindVar(1))
+	_, err := exec.Exec(query, path, pk)
+	return err
+}
+
+// treeTransaction reports whether the statements writing a node of table
+// through exec need a transaction of their own: those rewriting the stored
+// hierarchy of path and closure trees, when exec is the DbMap itself, so
+// that a failure part-way doesn't leave the tree half rewritten.
+func treeTransaction(m *DbMap, exec SqlExecutor, table *modelInfo) bool {
+	_, isDbMap := exec.(*DbMap)
+	return isDbMap && m.dryRun == nil && table.treeParent != nil && table.treeParent.tree != TreeAdjacency
+}
+
+// treeInsert stores the hierarchy of a freshly inserted tree node.
+func treeInsert(m *DbMap, exec SqlExecutor, table *modelInfo, elem reflect.Value) error {
+	if table.treeParent == nil {
+		return nil
+	}
+	_, pk, _ := getExistPk(table, elem)
+	parent, hasParent := treeParentPk(table, elem)
+	dialect := m.Dialect
+
+	switch table.treeParent.tree {
+	case TreePath:
+		path := "/"
+		if hasParent {
+			pp, err := treeSelectPath(m, exec, table, parent)
+			if err != nil {
+				return err
+			}
+			path = pp
+		}
+		path += fmt.Sprint(pk) + "/"
+		if err := treeUpdatePath(m, exec, table, path, pk); err != nil {
+			return err
+		}
+		elem.FieldByIndex(table.treePath.fieldIndex).SetString(path)
+	case TreeClosure:
+		closure := dialect.QuotedTableForQuery(table.schemaName, table.treeClosure.table)
+		if hasParent {
+			query := fmt.Sprintf("insert into %s (ancestor, descendant, depth) select ancestor, %s, depth + 1 from %s where descendant=%s",
+				closure, dialect.BindVar(0), closure, dialect.BindVar(1))
+			if _, err := exec.Exec(query, pk, parent); err != nil {
+				return err
+			}
+		}
+		query := fmt.Sprintf("insert into %s (ancestor, descendant, depth) values (%s, %s, 0)",
+			closure, dialect.BindVar(0), dialect.BindVar(1))
+		if _, err := exec.Exec(query, pk, pk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// treeDelete removes the closure rows of the subtree of a tree node
+// about to be deleted.  Path trees need no maintenance on delete.
+func treeDelete(m *DbMap, exec SqlExecutor, table *modelInfo, elem reflect.Value) error {
+	if table.treeParent == nil || table.treeParent.tree != TreeClosure {
+		return nil
+	}
+	_, pk, _ := getExistPk(table, elem)
+	closure := m.Dialect.QuotedTableForQuery(table.schemaName, table.treeClosure.table)
+	// the derived table keeps MySQL from rejecting a subquery on the
+	// table being deleted from
+	query := fmt.Sprintf("delete from %s where descendant in (select descendant from (select descendant from %s where ancestor=%s) sub)",
+		closure, closure, m.Dialect.BindVar(0))
+	_, err := exec.Exec(query, pk)
+	return err
+}
+
+func moveTree(m *DbMap, exec SqlExecutor, node interface{}, newParent interface{}) error {
+	table, elem, pk, err := treeNode(m, node)
+	if err != nil {
+		return err
+	}
+	dialect := m.Dialect
+	quotedTable := dialect.QuotedTableForQuery(table.schemaName, table.table)
+	pkColumn := dialect.QuoteField(table.fields.GetOnePrimaryKey().column)
+
+	var parentPk interface{}
+	parentValue := reflect.Zero(table.treeParent.addrValue.Type())
+	if newParent != nil {
+		var ptable *modelInfo
+		if ptable, _, parentPk, err = treeNode(m, newParent); err != nil {
+			return err
+		}
+		if ptable != table {
+			return fmt.Errorf("can not move `%s` under a `%s`", table.fullName, ptable.fullName)
+		}
+		parentValue = reflect.ValueOf(newParent)
+	}
+
+	switch table.treeParent.tree {
+	case TreeAdjacency:
+		if newParent != nil {
+			ancestors, err := treeAncestorPks(exec, table, parentPk)
+			if err != nil {
+				return err
+			}
+			for _, id := range append(ancestors, parentPk) {
+				if fmt.Sprint(id) == fmt.Sprint(pk) {
+					return fmt.Errorf("can not move tree node `%v` into its own subtree", pk)
+				}
+			}
+		}
+	case TreePath:
+		oldPath, err := treeSelectPath(m, exec, table, pk)
+		if err != nil {
+			return err
+		}
+		newPath := "/"
+		if newParent != nil {
+			pp, err := treeSelectPath(m, exec, table, parentPk)
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(pp, oldPath) {
+				return fmt.Errorf("can not move tree node `%v` into its own subtree", pk)
+			}
+			newPath = pp
+		}
+		newPath += fmt.Sprint(pk) + "/"
+
+		query := fmt.Sprintf("select %s, %s from %s where %s like %s",
+			pkColumn, dialect.QuoteField(table.treePath.column), quotedTable,
+			dialect.QuoteField(table.treePath.column), dialect.BindVar(0))
+		rows, done, err := queryRows(exec, query, oldPath+"%")
+		if err != nil {
+			return err
+		}
+		defer done()
+		var (
+			pks   []interface{}
+			paths []string
+		)
+		for rows.Next() {
+			var (
+				id   interface{}
+				path string
+			)
+			if err = rows.Scan(&id, &path); err != nil {
+				rows.Close()
+				return err
+			}
+			pks = append(pks, id)
+			paths = append(paths, newPath+path[len(oldPath):])
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for i := range pks {
+			if err = treeUpdatePath(m, exec, table, paths[i], pks[i]); err != nil {
+				return err
+			}
+		}
+		elem.FieldByIndex(table.treePath.fieldIndex).SetString(newPath)
+	case TreeClosure:
+		closure := dialect.QuotedTableForQuery(table.schemaName, table.treeClosure.table)
+		if newParent != nil {
+			n, err := SelectInt(exec, fmt.Sprintf("select count(*) from %s where ancestor=%s and descendant=%s",
+				closure, dialect.BindVar(0), dialect.BindVar(1)), pk, parentPk)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				return fmt.Errorf("can not move tree node `%v` into its own subtree", pk)
+			}
+		}
+
+		s := bytes.Buffer{}
+		s.WriteString(fmt.Sprintf("delete from %s where descendant in (select descendant from (select descendant from %s where ancestor=%s) sub)",
+			closure, closure, dialect.BindVar(0)))
+		s.WriteString(fmt.Sprintf(" and ancestor not in (select descendant from (select descendant from %s where ancestor=%s) sub2)",
+			closure, dialect.BindVar(1)))
+		if _, err := exec.Exec(s.String(), pk, pk); err != nil {
+			return err
+		}
+		if newParent != nil {
+			query := fmt.Sprintf("insert into %s (ancestor, descendant, depth) select super.ancestor, sub.descendant, super.depth + sub.depth + 1 from %s super, %s sub where super.descendant=%s and sub.ancestor=%s",
+				closure, closure, closure, dialect.BindVar(0), dialect.BindVar(1))
+			if _, err := exec.Exec(query, parentPk, pk); err != nil {
+				return err
+			}
+		}
+	}
+
+	query := fmt.Sprintf("update %s set %s=%s where %s=%s", quotedTable,
+		dialect.QuoteField(table.treeParent.column), dialect.BindVar(0), pkColumn, dialect.BindVar(1))
+	if _, err := exec.Exec(query, parentPk, pk); err != nil {
+		return err
+	}
+	elem.FieldByIndex(table.treeParent.fieldIndex).Set(parentValue)
+	return nil
+}
+
+// sqlForTreeQuery builds the select returning the descendants (or the
+// ancestors, nearest first) of a node.  Ancestors of path trees are
+// looked up by the ancestorIds found in the node's path.
+func (t *modelInfo) sqlForTreeQuery(descendants bool, ancestorIds int) string {
+	dialect := Database().Get().Dialect
+	quotedTable := dialect.QuotedTableForQuery(t.schemaName, t.table)
+	pkColumn := dialect.QuoteField(t.fields.GetOnePrimaryKey().column)
+
+	s := bytes.Buffer{}
+	s.WriteString(fmt.Sprintf("select %s from %s t", t.treeColumns("t"), quotedTable))
+	switch t.treeParent.tree {
+	case TreePath:
+		path := dialect.QuoteField(t.treePath.column)
+		if descendants {
+			s.WriteString(fmt.Sprintf(" where t.%s like %s and t.%s<>%s order by t.%s",
+				path, dialect.BindVar(0), pkColumn, dialect.BindVar(1), path))
+		} else {
+			s.WriteString(fmt.Sprintf(" where t.%s in (", pkColumn))
+			for i := 0; i < ancestorIds; i++ {
+				if i > 0 {
+					s.WriteString(", ")
+				}
+				s.WriteString(dialect.BindVar(i))
+			}
+			s.WriteString(fmt.Sprintf(") order by t.%s desc", path))
+		}
+	case TreeClosure:
+		closure := dialect.QuotedTableForQuery(t.schemaName, t.treeClosure.table)
+		if descendants {
+			s.WriteString(fmt.Sprintf(" join %s c on t.%s=c.descendant where c.ancestor=%s and c.depth>0 order by c.depth",
+				closure, pkColumn, dialect.BindVar(0)))
+		} else {
+			s.WriteString(fmt.Sprintf(" join %s c on t.%s=c.ancestor where c.descendant=%s and c.depth>0 order by c.depth",
+				closure, pkColumn, dialect.BindVar(0)))
+		}
+	}
+	s.WriteString(dialect.QuerySuffix())
+	return s.String()
+}
+
+// sqlForTreeIn builds the select returning the rows whose column of fi is
+// one of n values, ordered by primary key.
+func (t *modelInfo) sqlForTreeIn(fi *fieldInfo, n int) string {
+	dialect := Database().Get().Dialect
+	s := bytes.Buffer{}
+	s.WriteString(fmt.Sprintf("select %s from %s t where t.%s in (", t.treeColumns("t"),
+		dialect.QuotedTableForQuery(t.schemaName, t.table), dialect.QuoteField(fi.column)))
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+		s.WriteString(dialect.BindVar(i))
+	}
+	s.WriteString(fmt.Sprintf(") order by t.%s", dialect.QuoteField(t.fields.GetOnePrimaryKey().column)))
+	s.WriteString(dialect.QuerySuffix())
+	return s.String()
+}
+
+// treeAncestorPks returns the primary keys of the ancestors of the row
+// pk of an adjacency tree, nearest first.  It stops at a cycle.
+func treeAncestorPks(exec SqlExecutor, table *modelInfo, pk interface{}) ([]interface{}, error) {
+	dialect := Database().Get().Dialect
+	query := fmt.Sprintf("select %s from %s where %s=%s%s", dialect.QuoteField(table.treeParent.column),
+		dialect.QuotedTableForQuery(table.schemaName, table.table),
+		dialect.QuoteField(table.fields.GetOnePrimaryKey().column), dialect.BindVar(0), dialect.QuerySuffix())
+	var ids []interface{}
+	seen := map[string]bool{fmt.Sprint(pk): true}
+	for id := pk; ; {
+		var parent interface{}
+		if err := scanRow(exec, query, []interface{}{id}, &parent); err != nil {
+			return nil, err
+		}
+		if b, ok := parent.([]byte); ok {
+			parent = string(b)
+		}
+		if parent == nil || seen[fmt.Sprint(parent)] {
+			return ids, nil
+		}
+		seen[fmt.Sprint(parent)] = true
+		ids = append(ids, parent)
+		id = parent
+	}
+}
+
+func treeAdjacencyAncestors(exec SqlExecutor, table *modelInfo, pk interface{}) ([]interface{}, error) {
+	ids, err := treeAncestorPks(exec, table, pk)
+	if err != nil || len(ids) == 0 {
+		return []interface{}{}, err
+	}
+	rows, err := exec.Select(reflect.New(table.gotype).Interface(), table.sqlForTreeIn(table.fields.GetOnePrimaryKey(), len(ids)), ids...)
+	if err != nil {
+		return nil, err
+	}
+	byPk := make(map[string]interface{}, len(rows))
+	for _, row := range rows {
+		_, id, _ := getExistPk(table, reflect.ValueOf(row).Elem())
+		byPk[fmt.Sprint(id)] = row
+	}
+	list := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		if row, ok := byPk[fmt.Sprint(id)]; ok {
+			list = append(list, row)
+		}
+	}
+	return list, nil
+}
+
+// treeAdjacencyDescendants walks the subtree of the row pk one level per
+// query.
+func treeAdjacencyDescendants(exec SqlExecutor, table *modelInfo, pk interface{}) ([]interface{}, error) {
+	list := []interface{}{}
+	level := []interface{}{pk}
+	seen := map[string]bool{fmt.Sprint(pk): true}
+	for len(level) > 0 {
+		rows, err := exec.Select(reflect.New(table.gotype).Interface(), table.sqlForTreeIn(table.treeParent, len(level)), level...)
+		if err != nil {
+			return nil, err
+		}
+		level = nil
+		for _, row := range rows {
+			_, id, _ := getExistPk(table, reflect.ValueOf(row).Elem())
+			if seen[fmt.Sprint(id)] {
+				continue
+			}
+			seen[fmt.Sprint(id)] = true
+			list = append(list, row)
+			level = append(level, id)
+		}
+	}
+	return list, nil
+}
+
+func treeChildren(m *DbMap, exec SqlExecutor, node interface{}) ([]interface{}, error) {
+	table, _, pk, err := treeNode(m, node)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Select(reflect.New(table.gotype).Interface(), table.sqlForTreeIn(table.treeParent, 1), pk)
+}
+
+func treeQuery(m *DbMap, exec SqlExecutor, node interface{}, descendants bool) ([]interface{}, error) {
+	table, _, pk, err := treeNode(m, node)
+	if err != nil {
+		return nil, err
+	}
+
+	if table.treeParent.tree == TreeAdjacency {
+		if descendants {
+			return treeAdjacencyDescendants(exec, table, pk)
+		}
+		return treeAdjacencyAncestors(exec, table, pk)
+	}
+
+	args := []interface{}{pk}
+	if table.treeParent.tree == TreePath {
+		path, err := treeSelectPath(m, exec, table, pk)
+		if err != nil {
+			return nil, err
+		}
+		if descendants {
+			args = []interface{}{path + "%", pk}
+		} else {
+			ids := strings.Split(strings.Trim(path, "/"), "/")
+			args = args[:0]
+			for _, id := range ids[:len(ids)-1] {
+				args = append(args, id)
+			}
+			if len(args) == 0 {
+				return []interface{}{}, nil
+			}
+		}
+	}
+	return exec.Select(reflect.New(table.gotype).Interface(), table.sqlForTreeQuery(descendants, len(args)), args...)
+}
+
+// Children returns the rows right below node in its tree, ordered by
+// primary key.  Relation fields of the rows are not loaded.
+func (m *DbMap) Children(node interface{}) ([]interface{}, error) {
+	return treeChildren(m, m, node)
+}
+
+// Descendants returns every row below node in its tree, ordered from the
+// top of the subtree down.  Relation fields of the rows are not loaded.
+func (m *DbMap) Descendants(node interface{}) ([]interface{}, error) {
+	return treeQuery(m, m, node, true)
+}
+
+// Ancestors returns every row above node in its tree, nearest first.
+// Relation fields of the rows are not loaded.
+func (m *DbMap) Ancestors(node interface{}) ([]interface{}, error) {
+	return treeQuery(m, m, node, false)
+}
+
+// MoveTree moves node with its whole subtree under newParent, or to the
+// root when newParent is nil, rewriting the stored hierarchy.
+func (m *DbMap) MoveTree(node interface{}, newParent interface{}) error {
+	if table, _, _, err := treeNode(m, node); err == nil && treeTransaction(m, m, table) {
+		return m.RunInTransaction(func(tx *Transaction) error {
+			return moveTree(m, tx, node, newParent)
+		})
+	}
+	return moveTree(m, m, node, newParent)
+}
+
+// Children has the same behavior as DbMap.Children(), but runs in a transaction.
+func (t *Transaction) Children(node interface{}) ([]interface{}, error) {
+	return treeChildren(t.dbmap, t, node)
+}
+
+// Descendants has the same behavior as DbMap.Descendants(), but runs in a transaction.
+func (t *Transaction) Descendants(node interface{}) ([]interface{}, error) {
+	return treeQuery(t.dbmap, t, node, true)
+}
+
+// Ancestors has the same behavior as DbMap.Ancestors(), but runs in a transaction.
+func (t *Transaction) Ancestors(node interface{}) ([]interface{}, error) {
+	return treeQuery(t.dbmap, t, node, false)
+}
+
+// MoveTree has the same behavior as DbMap.MoveTree(), but runs in a transaction.
+func (t *Transaction) MoveTree(node interface{}, newParent interface{}) error {
+	return moveTree(t.dbmap, t, node, newParent)
+}
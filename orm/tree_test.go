@@ -0,0 +1,183 @@
+package orm
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPathTreeQueries(t *testing.T) {
+	registerTestModels(t, PostgresDialect{}, &pathCategory{})
+
+	mi, _ := modelCache.get("path_category")
+	if mi.treeParent == nil || mi.treePath == nil {
+		t.Fatal("expected path tree metadata to be bootstrapped")
+	}
+
+	tests := []struct {
+		descendants bool
+		ancestors   int
+		want        string
+	}{
+		{true, 0, `select t."id", t."name", t."path" from "path_category" t where t."path" like $1 and t."id"<>$2 order by t."path";`},
+		{false, 2, `select t."id", t."name", t."path" from "path_category" t where t."id" in ($1, $2) order by t."path" desc;`},
+	}
+	for _, test := range tests {
+		if got := mi.sqlForTreeQuery(test.descendants, test.ancestors); got != test.want {
+			t.Errorf("sqlForTreeQuery(%v):\n got: %s\nwant: %s", test.descendants, got, test.want)
+		}
+	}
+}
+
+func TestClosureTreeQueries(t *testing.T) {
+	registerTestModels(t, SqliteDialect{}, &closureCategory{})
+
+	mi, _ := modelCache.get("closure_category")
+	if mi.treeClosure == nil {
+		t.Fatal("expected closure table to be registered")
+	}
+	if _, ok := modelCache.get("closure_category_closure"); !ok {
+		t.Fatal("closure table missing from model cache")
+	}
+
+	want := `select t."id", t."name" from "closure_category" t join "closure_category_closure" c on t."id"=c.descendant where c.ancestor=? and c.depth>0 order by c.depth;`
+	if got := mi.sqlForTreeQuery(true, 0); got != want {
+		t.Errorf("descendants:\n got: %s\nwant: %s", got, want)
+	}
+
+	ddl := mi.treeClosure.SqlForCreate(false)
+	for _, col := range []string{`"ancestor" integer not null`, `"descendant" integer not null`, `"depth" integer not null`, `primary key (`} {
+		if !strings.Contains(ddl, col) {
+			t.Errorf("closure ddl %q missing %q", ddl, col)
+		}
+	}
+}
+
+// adjDriver serves the adj_category rows of adjParents, by id.
+var adjDriver = &fakeDriver{
+	query: func(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+		rows := &fakeRows{}
+		if strings.HasPrefix(query, "select `parent_id`") {
+			rows.columns = []string{"parent_id"}
+			if parent := adjParents[args[0].(int64)]; parent != 0 {
+				rows.values = append(rows.values, []driver.Value{parent})
+			} else {
+				rows.values = append(rows.values, []driver.Value{nil})
+			}
+			return rows, nil
+		}
+		rows.columns = []string{"id", "name"}
+		byParent := strings.Contains(query, "t.`parent_id` in")
+		for id := int64(1); id <= int64(len(adjParents)); id++ {
+			for _, arg := range args {
+				if byParent && adjParents[id] == arg.(int64) || !byParent && id == arg.(int64) {
+					rows.values = append(rows.values, []driver.Value{id, fmt.Sprint("c", id)})
+				}
+			}
+		}
+		return rows, nil
+	},
+}
+
+var adjParents = map[int64]int64{1: 0, 2: 1, 3: 1, 4: 2, 5: 4}
+
+func TestAdjacencyTree(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &adjCategory{})
+	defer Database().Set(nil)
+
+	mi, _ := modelCache.get("adj_category")
+	if mi.treeParent == nil || mi.treeParent.tree != TreeAdjacency {
+		t.Fatal("expected a model tagged tree(adjacency) to be an adjacency tree")
+	}
+	want := "select t.`id`, t.`name` from `adj_category` t where t.`parent_id` in (?, ?) order by t.`id`;"
+	if got := mi.sqlForTreeIn(mi.treeParent, 2); got != want {
+		t.Errorf("sqlForTreeIn:\n got: %s\nwant: %s", got, want)
+	}
+
+	dbmap.Db = adjDriver.open(t)
+
+	ids := func(rows []interface{}) (ids []int64) {
+		for _, row := range rows {
+			ids = append(ids, row.(*adjCategory).Id)
+		}
+		return ids
+	}
+	tests := []struct {
+		name  string
+		query func(node interface{}) ([]interface{}, error)
+		node  int64
+		want  []int64
+	}{
+		{"Children", dbmap.Children, 1, []int64{2, 3}},
+		{"Descendants", dbmap.Descendants, 1, []int64{2, 3, 4, 5}},
+		{"Ancestors", dbmap.Ancestors, 5, []int64{4, 2, 1}},
+		{"Ancestors", dbmap.Ancestors, 1, nil},
+	}
+	for _, test := range tests {
+		rows, err := test.query(&adjCategory{Id: test.node})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(rows); fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("%s(%d): expected %v, got %v", test.name, test.node, test.want, got)
+		}
+	}
+
+	err := dbmap.MoveTree(&adjCategory{Id: 2}, &adjCategory{Id: 5})
+	if err == nil || !strings.Contains(err.Error(), "own subtree") {
+		t.Errorf("expected a move into the subtree to fail, got %v", err)
+	}
+}
+
+func TestSelfReferenceNotTree(t *testing.T) {
+	dbmap := registerTestModels(t, MySQLDialect{"InnoDB", "UTF8"}, &selfRefNode{})
+	defer Database().Set(nil)
+
+	mi, _ := modelCache.get("self_ref_node")
+	if mi.treeParent != nil {
+		t.Error("expected a self-referential rel(fk) not tagged tree to be a plain relation")
+	}
+	if !batchInsertable(dbmap, mi, &selfRefNode{}) {
+		t.Error("expected the model batch insertable")
+	}
+}
+
+func TestTreeTransactions(t *testing.T) {
+	dbmap := registerTestModels(t, SqliteDialect{}, &pathCategory{}, &closureCategory{})
+	defer Database().Set(nil)
+	dbmap.Db = tempInDriver.open(t)
+
+	// the node and its path are written together
+	tempInStatements = nil
+	if err := dbmap.Insert(&pathCategory{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"insert", "update", "commit"}; fmt.Sprint(tempInStatements) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, tempInStatements)
+	}
+
+	// the node and its closure rows are deleted together
+	tempInStatements = nil
+	if _, err := dbmap.Delete(&closureCategory{Id: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"delete", "delete", "commit"}; fmt.Sprint(tempInStatements) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, tempInStatements)
+	}
+
+	// in the transaction of the caller
+	tx, err := dbmap.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tempInStatements = nil
+	if err = tx.Insert(&pathCategory{Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+	if want := []string{"insert", "update", "rollback"}; fmt.Sprint(tempInStatements) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, tempInStatements)
+	}
+}
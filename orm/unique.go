@@ -0,0 +1,148 @@
+package orm
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// checkUnique looks for another row holding the values elem has in each
+// of its unique fields and unique together sets, so a duplicate is
+// reported as a *UniqueError naming the offending fields rather than as
+// a driver specific constraint violation.  Sets with a NULL value are
+// skipped, and the row of elem itself is excluded so updates pass.  The
+// database constraints remain the final guard against concurrent writes.
+func checkUnique(m *DbMap, exec SqlExecutor, table *modelInfo, elem reflect.Value) error {
+	keys := make([]*fieldInfo, 0, len(table.fields.keys))
+	for _, fi := range table.fields.fieldsDB {
+		if fi.pk {
+			keys = append(keys, fi)
+		}
+	}
+
+sets:
+	for _, set := range table.uniqueSets() {
+		args := make([]interface{}, 0, len(set)+len(keys))
+		for _, fi := range set {
+			val, err := uniqueValue(m, fi, elem)
+			if err != nil {
+				return err
+			}
+			if val == nil {
+				continue sets
+			}
+			args = append(args, val)
+		}
+		for _, fi := range keys {
+			val, err := uniqueValue(m, fi, elem)
+			if err != nil {
+				return err
+			}
+			args = append(args, val)
+		}
+
+		count, err := SelectInt(exec, table.sqlForUnique(set, keys, m.Dialect), args...)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			names := make([]string, len(set))
+			for i, fi := range set {
+				names[i] = fi.name
+			}
+			return &UniqueError{TypeName: table.name, Fields: names}
+		}
+	}
+	return nil
+}
+
+// uniqueSets returns the unique fields, one per set, followed by the
+// unique together sets.  Primary keys are left to the database.
+func (t *modelInfo) uniqueSets() [][]*fieldInfo {
+	sets := make([][]*fieldInfo, 0)
+	for _, fi := range t.fields.fieldsDB {
+		if fi.unique && !fi.pk {
+			sets = append(sets, []*fieldInfo{fi})
+		}
+	}
+	for _, names := range t.uniqueTogether {
+		set := make([]*fieldInfo, 0, len(names))
+		for _, name := range names {
+			fi, ok := t.fields.GetByAny(name)
+			if !ok {
+				panic(fmt.Errorf("<orm.checkUnique> unknown unique together field `%s` for `%s`", name, t.fullName))
+			}
+			set = append(set, fi)
+		}
+		sets = append(sets, set)
+	}
+	return sets
+}
+
+// uniqueValue returns the value bound for fi, or nil for NULL.
+func uniqueValue(m *DbMap, fi *fieldInfo, elem reflect.Value) (interface{}, error) {
+	v := elem.FieldByIndex(fi.fieldIndex)
+	if fi.fieldType&IsRelField > 0 {
+		if v.IsNil() {
+			return nil, nil
+		}
+		_, pk, exist := getExistPk(fi.relModelInfo, v.Elem())
+		if !exist {
+			return nil, nil
+		}
+		return pk, nil
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, nil
+	}
+
+	val := v.Interface()
+	if fi.array {
+		return Array(val), nil
+	}
+	if fi.interval {
+		return Interval(val), nil
+	}
+	if m.TypeConverter != nil {
+		var err error
+		if val, err = m.TypeConverter.ToDb(val); err != nil {
+			return nil, err
+		}
+	}
+	if valuer, ok := val.(driver.Valuer); ok {
+		dv, err := valuer.Value()
+		if err != nil || dv == nil {
+			return nil, err
+		}
+	}
+	return val, nil
+}
+
+func (t *modelInfo) sqlForUnique(set, keys []*fieldInfo, dialect Dialect) string {
+	s := bytes.Buffer{}
+	s.WriteString(fmt.Sprintf("select count(*) from %s where ", dialect.QuotedTableForQuery(t.schemaName, t.table)))
+	i := 0
+	for _, fi := range set {
+		if i > 0 {
+			s.WriteString(" and ")
+		}
+		s.WriteString(dialect.QuoteField(fi.column))
+		s.WriteString("=")
+		s.WriteString(dialect.BindVar(i))
+		i++
+	}
+	if len(keys) > 0 {
+		conds := make([]string, len(keys))
+		for j, fi := range keys {
+			conds[j] = dialect.QuoteField(fi.column) + "=" + dialect.BindVar(i)
+			i++
+		}
+		s.WriteString(" and not (")
+		s.WriteString(strings.Join(conds, " and "))
+		s.WriteString(")")
+	}
+	s.WriteString(dialect.QuerySuffix())
+	return s.String()
+}
@@ -0,0 +1,42 @@
+package orm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUniqueSets(t *testing.T) {
+	registerTestModels(t, PostgresDialect{}, &uniqueAccount{})
+	mi, _ := modelCache.get("unique_account")
+	mi.SetUniqueTogether("Org", "Login")
+
+	sets := mi.uniqueSets()
+	if len(sets) != 2 || len(sets[0]) != 1 || sets[0][0].name != "Email" || len(sets[1]) != 2 {
+		t.Fatalf("unexpected unique sets %v", sets)
+	}
+
+	keys := []*fieldInfo{mi.fields.GetOnePrimaryKey()}
+	if sql, want := mi.sqlForUnique(sets[1], keys, PostgresDialect{}),
+		`select count(*) from "unique_account" where "org"=$1 and "login"=$2 and not ("id"=$3);`; sql != want {
+		t.Errorf("expected %q, got %q", want, sql)
+	}
+
+	elem := reflect.ValueOf(&uniqueAccount{Org: "acme"}).Elem()
+	if val, err := uniqueValue(Database().Get(), mi.fields.GetByName("Login"), elem); err != nil || val != nil {
+		t.Errorf("expected a NULL login to be skipped, got %v (%v)", val, err)
+	}
+	if val, _ := uniqueValue(Database().Get(), mi.fields.GetByName("Org"), elem); val != "acme" {
+		t.Errorf("unexpected value %v", val)
+	}
+}
+
+func TestUniqueError(t *testing.T) {
+	err := &UniqueError{TypeName: "User", Fields: []string{"Email"}}
+	if err.Error() != "orm: a User with this Email already exists" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+	err.Fields = []string{"Org", "Login"}
+	if err.Error() != "orm: a User with these Org, Login already exists" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
@@ -338,7 +338,11 @@ outFor:
 			}
 			arg = v
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			arg = val.Int()
+			if d, ok := arg.(time.Duration); ok && fi != nil && fi.interval {
+				arg = sqlInterval(d)
+			} else {
+				arg = val.Int()
+			}
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 			arg = val.Uint()
 		case reflect.Float32:
@@ -388,7 +392,7 @@ outFor:
 				typ := val.Type()
 				name := getFullName(typ)
 				var value interface{}
-				if mmi, err := Database().Get().TableFor(typ, true); err != nil {
+				if mmi, err := Database().Get().TableFor(typ); err == nil && mmi.requirePK() == nil {
 					if _, vu, exist := getExistPk(mmi, val); exist {
 						value = vu
 					}
@@ -451,6 +455,9 @@ func getFieldValue(m interface{}, field string) (arg interface{}) {
 
 	kind := val.Kind()
 	if kind == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
 		val = val.Elem()
 		kind = val.Kind()
 		arg = val.Interface()
@@ -503,7 +510,7 @@ func getFieldValue(m interface{}, field string) (arg interface{}) {
 			typ := val.Type()
 			name := getFullName(typ)
 			var value interface{}
-			if mmi, err := Database().Get().TableFor(typ, true); err != nil {
+			if mmi, err := Database().Get().TableFor(typ); err == nil && mmi.requirePK() == nil {
 				if _, vu, exist := getExistPk(mmi, val); exist {
 					value = vu
 				}
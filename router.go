@@ -166,6 +166,13 @@ func (router *Router) updateTree() *Error {
 	return nil
 }
 
+// ParseRoutesFile reads the given routes file, and those of the modules it
+// includes, without checking that their actions exist, e.g. for the harness
+// to generate the reverse routes before the controllers are compiled.
+func ParseRoutesFile(routesPath string) ([]*Route, *Error) {
+	return parseRoutesFile(routesPath, "", false)
+}
+
 // parseRoutesFile reads the given routes file and returns the contained routes.
 func parseRoutesFile(routesPath, joinedPath string, validate bool) ([]*Route, *Error) {
 	contentBytes, err := ioutil.ReadFile(routesPath)